@@ -5,10 +5,22 @@ const (
 	ConditionReady                       = "Ready"
 	ConditionFailed                      = "Failed"
 	ConditionProgressing                 = "Progressing"
+	ConditionVerified                    = "Verified"
 	ConditionAvailable                   = "Available"
 	ConditionKubernetesUpgradeInProgress = "KubernetesUpgradeInProgress"
 	ConditionKubernetesUpgradeSucceeded  = "KubernetesUpgradeSucceeded"
 	ConditionKubernetesUpgradeFailed     = "KubernetesUpgradeFailed"
+	ConditionPreUpgradeBackupReady       = "PreUpgradeBackupReady"
+	ConditionHealthy                     = "Healthy"
+	ConditionRolloutPaused               = "RolloutPaused"
+	ConditionWaitingForMaintenanceWindow = "WaitingForMaintenanceWindow"
+	ConditionApproved                    = "Approved"
+	ConditionScaleDownBlocked            = "ScaleDownBlocked"
+	ConditionRemediationAllowed          = "RemediationAllowed"
+	ConditionDeletionBlocked             = "DeletionBlocked"
+	ConditionHostsClaimed                = "HostsClaimed"
+	ConditionDrained                     = "Drained"
+	ConditionTooManyMissedStarts         = "TooManyMissedStarts"
 
 	// State of the Talos control plane
 	StateAvailable               = "Available"               // Control plane is ready to bootstrap the cluster
@@ -25,9 +37,21 @@ const (
 	// State for TalosControlPlane and TalosMachine
 	StatePending = "Pending" // Control plane is being created / Machine has finished booting into Talos
 
+	// Rollout phases of a machine
+	MachineRolloutUpdated = "Updated" // Machine runs its desired version
+	MachineRolloutPending = "Pending" // Machine got a new version and is not available on it yet
+	MachineRolloutHeld    = "Held"    // Machine is kept on its version by the rollout
+
+	// DHCP modes of the PXE server
+	DHCPModeServer = "Server" // Leases the addresses of the machines and of the discovery range
+	DHCPModeProxy  = "Proxy"  // Only adds the boot information, another DHCP server leases the addresses
+
 	// State secret labels — used to identify per-control-plane state backup Secrets
 	StateSecretLabelKey   = "talos.alperen.cloud/type"
 	StateSecretLabelValue = "state"
+	// Values of StateSecretLabelKey for the Secrets holding the secrets bundle and the machine configs
+	SecretBundleSecretLabelValue  = "secret-bundle"
+	MachineConfigSecretLabelValue = "machine-config"
 
 	// Finalizers
 	TalosClusterFinalizer             = "taloscluster.talos.alperen.cloud/finalizer"
@@ -47,5 +71,21 @@ const (
 	GroupKindMachine      = "TalosMachine"
 
 	//
-	TalosEtcdBackupScheduleLabelKey = "talos.alperen.cloud/etcd-backup-schedule"
+	TalosEtcdBackupScheduleLabelKey   = "talos.alperen.cloud/etcd-backup-schedule"
+	TalosEtcdBackupPreUpgradeLabelKey = "talos.alperen.cloud/pre-upgrade-backup"
+	// TalosHostDiscoveryLabelKey is set on the TalosHosts created by a TalosHostDiscovery to its name
+	TalosHostDiscoveryLabelKey = "talos.alperen.cloud/host-discovery"
+
+	// SkipUpgradeChecksAnnotation disables the upgrade path checks of the admission webhooks when set to "true"
+	SkipUpgradeChecksAnnotation = "talos.alperen.cloud/skip-upgrade-checks"
+	// ApprovedRevisionAnnotation approves a TalosUpgradePlan like spec.approvedRevision
+	ApprovedRevisionAnnotation = "talos.alperen.cloud/approved-revision"
+	// RemediationAnnotation requests the remediation of a TalosMachine, it is set by a TalosMachineHealthCheck
+	RemediationAnnotation = "talos.alperen.cloud/remediation"
+	// DeletionProtectionAnnotation blocks the deletion of a resource while it is set to "true". Children
+	// inherit it from their parent with the value DeletionProtectionInherited.
+	DeletionProtectionAnnotation = "talos.alperen.cloud/deletion-protection"
+	// DeletionProtectionInherited is the value of the DeletionProtectionAnnotation on a child that is
+	// protected because its parent is
+	DeletionProtectionInherited = "inherited"
 )
@@ -48,6 +48,7 @@ type TalosClusterSpec struct {
 	PxeServerSpec *PxeServerSpec `json:"pxeServerSpec,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.discoveryRange) || !has(self.dhcpMode) || self.dhcpMode == 'Server'",message="discoveryRange requires the Server dhcpMode"
 type PxeServerSpec struct {
 	// address is the IP address of the PXE server.
 	// +kubebuilder:validation:Pattern=`^(\d{1,3}\.){3}\d{1,3}$`
@@ -56,6 +57,30 @@ type PxeServerSpec struct {
 	// interface is the interface connected to the network used for PXE boot (as given by Linux).
 	// +kubebuilder:validation:Required
 	Interface *string `json:"interface,omitempty"`
+	// discoveryRange is a range of addresses that are leased to machines that are not part of the cluster,
+	// e.g. machines booted from an ISO into maintenance mode. A TalosHostDiscovery with dhcpLeases adds
+	// them to the TalosHost inventory.
+	// +kubebuilder:validation:Optional
+	DiscoveryRange *DHCPRange `json:"discoveryRange,omitempty"`
+	// dhcpMode is how the PXE server answers DHCP requests. Server leases the addresses of the machines
+	// and of the discoveryRange. Proxy leaves the addresses to another DHCP server on the network and
+	// only adds the boot information for the machines of the cluster (ProxyDHCP).
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Server;Proxy
+	// +kubebuilder:default=Server
+	DHCPMode string `json:"dhcpMode,omitempty"`
+}
+
+// DHCPRange is a range of IP addresses leased by DHCP
+type DHCPRange struct {
+	// start is the first address of the range.
+	// +kubebuilder:validation:Pattern=`^(\d{1,3}\.){3}\d{1,3}$`
+	// +kubebuilder:validation:Required
+	Start string `json:"start"`
+	// end is the last address of the range.
+	// +kubebuilder:validation:Pattern=`^(\d{1,3}\.){3}\d{1,3}$`
+	// +kubebuilder:validation:Required
+	End string `json:"end"`
 }
 
 // TalosClusterStatus defines the observed state of TalosCluster.
@@ -25,13 +25,18 @@ import (
 
 const (
 	// RollingUpdateStrategyType upgrades machines one cohort at a time, gated by MaxUnavailable
-	// and a per-machine health check. This is currently the only supported strategy.
+	// and a per-machine health check.
 	RollingUpdateStrategyType RolloutStrategyType = "RollingUpdate"
+	// PartitionStrategyType only upgrades the machines inside the partition, e.g. to canary a
+	// single machine. Inside the partition machines are upgraded like with RollingUpdate.
+	PartitionStrategyType RolloutStrategyType = "Partition"
+	// RecreateStrategyType upgrades all machines at once
+	RecreateStrategyType RolloutStrategyType = "Recreate"
 )
 
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.clusterDomain) || self.clusterDomain == oldSelf.clusterDomain", message="ClusterDomain is immutable"
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.mode) || self.mode == oldSelf.mode", message="Mode is immutable"
-// +kubebuilder:validation:XValidation:rule="self.mode != 'metal' || size(self.metalSpec.machines) > 0",message="Machines is required when mode is 'metal'"
+// +kubebuilder:validation:XValidation:rule="self.mode != 'metal' || size(self.metalSpec.machines) > 0 || has(self.metalSpec.hostSelector)",message="Machines or hostSelector is required when mode is 'metal'"
 // +kubebuilder:validation:XValidation:rule="self.mode != 'container' || self.replicas >= 1",message="replicas must be at least 1 when mode is 'container'"
 
 // TalosControlPlaneSpec defines the desired state of TalosControlPlane.
@@ -110,15 +115,64 @@ type TalosControlPlaneSpec struct {
 	// +kubebuilder:default=reset
 	DeletionPolicy string `json:"deletionPolicy"`
 
+	// reset controls how control plane machines are reset when they are deleted with the reset deletion policy.
+	// Machines are forcefully reset and rebooted with all partitions wiped when it is not set.
+	// +kubebuilder:validation:Optional
+	Reset *ResetSpec `json:"reset,omitempty"`
+
 	// rolloutStrategy controls how Talos version upgrades are propagated to the control plane machines.
 	// only applied when mode is metal.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={type: "RollingUpdate", rollingUpdate: {maxUnavailable: 1}}
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// drain controls how the Kubernetes Nodes of the control plane machines are drained before they are
+	// upgraded or reset. only applied when mode is metal.
+	// +kubebuilder:validation:Optional
+	Drain *DrainSpec `json:"drain,omitempty"`
+
+	// upgrade controls how Talos upgrades of the control plane machines are verified and what happens if they
+	// fail. A failed upgrade pauses the rollout. only applied when mode is metal.
+	// +kubebuilder:validation:Optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+
+	// maintenanceWindows restricts when Talos upgrades of the control plane machines and Kubernetes
+	// upgrades may start. Upgrades that already started are finished outside of a window. Without
+	// windows upgrades start as soon as the version changes.
+	// +kubebuilder:validation:Optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// preUpgradeBackup takes an etcd snapshot before a Talos or Kubernetes upgrade is rolled out.
+	// The upgrade is held until the snapshot is ready.
+	// +kubebuilder:validation:Optional
+	PreUpgradeBackup *PreUpgradeBackup `json:"preUpgradeBackup,omitempty"`
+}
+
+// PreUpgradeBackup describes the TalosEtcdBackup created before an upgrade.
+type PreUpgradeBackup struct {
+	// backupStorage specifies where to store the pre-upgrade etcd backup.
+	// +kubebuilder:validation:Required
+	BackupStorage BackupStorage `json:"backupStorage"`
+
+	// encryption configures client-side encryption of the pre-upgrade etcd backup.
+	// +kubebuilder:validation:Optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	// keep is how many pre-upgrade backups of the control plane are kept. Once a new backup is ready the
+	// oldest ones are deleted together with their snapshots.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=3
+	Keep int32 `json:"keep,omitempty"`
+
+	// verify re-downloads the snapshot after the upload and validates its checksum before the upgrade proceeds.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	Verify bool `json:"verify,omitempty"`
 }
 
 // RolloutStrategyType is the type of rollout strategy used for control plane upgrades.
-// +kubebuilder:validation:Enum=RollingUpdate
+// +kubebuilder:validation:Enum=RollingUpdate;Partition;Recreate
 type RolloutStrategyType string
 
 // RolloutStrategy describes how to roll out Talos version upgrades across control plane machines.
@@ -128,9 +182,54 @@ type RolloutStrategy struct {
 	// +kubebuilder:default=RollingUpdate
 	Type RolloutStrategyType `json:"type,omitempty"`
 
-	// rollingUpdate is the spec for the RollingUpdate strategy. Only honoured when type is RollingUpdate.
+	// rollingUpdate is the spec for the RollingUpdate strategy. Honoured when type is RollingUpdate
+	// or Partition.
 	// +kubebuilder:validation:Optional
 	RollingUpdate *RollingUpdateRolloutStrategy `json:"rollingUpdate,omitempty"`
+
+	// partition is the spec for the Partition strategy. Required when type is Partition.
+	// +kubebuilder:validation:Optional
+	Partition *PartitionRolloutStrategy `json:"partition,omitempty"`
+}
+
+// PartitionRolloutStrategy selects the machines that get a new Talos version. The other machines keep
+// their version until the partition is moved.
+type PartitionRolloutStrategy struct {
+	// partition is the ordinal of the first machine to upgrade. Machines are numbered in the order of
+	// metalSpec.machines starting at 0. Machines with an ordinal lower than the partition are not
+	// upgraded. Defaults to 0, which upgrades all machines.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Partition int32 `json:"partition,omitempty"`
+
+	// selector additionally upgrades the TalosMachines whose labels match, regardless of their ordinal.
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// RolloutStatus reports the progress of a Talos version rollout across the machines.
+type RolloutStatus struct {
+	// updatedMachines is the number of machines that run their desired version.
+	UpdatedMachines int32 `json:"updatedMachines"`
+	// pendingMachines is the number of machines that got a new version and are not available on it yet.
+	PendingMachines int32 `json:"pendingMachines"`
+	// heldMachines is the number of machines that are kept on their version by the rollout.
+	HeldMachines int32 `json:"heldMachines"`
+	// machines reports the rollout progress of each machine.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Machines []MachineRolloutStatus `json:"machines,omitempty"`
+}
+
+// MachineRolloutStatus reports the rollout progress of a single machine.
+type MachineRolloutStatus struct {
+	// name of the TalosMachine.
+	Name string `json:"name"`
+	// version is the Talos version the TalosMachine is set to.
+	Version string `json:"version"`
+	// phase is Updated, Pending or Held.
+	Phase string `json:"phase"`
 }
 
 // RollingUpdateRolloutStrategy is the spec for the RollingUpdate rollout strategy.
@@ -141,6 +240,37 @@ type RollingUpdateRolloutStrategy struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=1
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// minReadySeconds is how long a machine has to pass its health checks after it was upgraded
+	// before the next machine is released. Defaults to 0.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// healthTimeout is how long a machine may fail its health checks before the rollout is paused.
+	// A paused rollout does not release further machines until all machines are healthy again.
+	// Defaults to 10m.
+	// +kubebuilder:validation:Optional
+	HealthTimeout *metav1.Duration `json:"healthTimeout,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period of time in which upgrades may start.
+type MaintenanceWindow struct {
+	// schedule is a cron expression defining when the window opens.
+	// For example: "0 22 * * 1-5" for 10 PM on weekdays
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// duration is how long the window stays open, e.g. "4h".
+	// +kubebuilder:validation:Required
+	Duration metav1.Duration `json:"duration"`
+
+	// timeZone is the IANA time zone name the schedule is interpreted in, e.g. "Europe/Berlin".
+	// Defaults to the time zone of the operator.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
 }
 
 // CNIConfig represents the CNI configuration options.
@@ -171,10 +301,20 @@ type FlannelCNIConfig struct {
 	KubeNetworkPoliciesEnabled *bool `json:"kubeNetworkPoliciesEnabled,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="has(self.hostSelector) == has(self.replicas)",message="hostSelector and replicas must be set together"
 type MetalSpec struct {
 	// machines is a list of machine specifications for the Talos control plane.
 	// +listType=atomic
 	Machines []Machine `json:"machines,omitempty"`
+	// hostSelector claims TalosHosts of the inventory whose labels match as machines, in addition to the
+	// machines that are listed. The claim is recorded in spec.claimedBy of the TalosHost.
+	// +kubebuilder:validation:Optional
+	HostSelector *metav1.LabelSelector `json:"hostSelector,omitempty"`
+	// replicas is the number of TalosHosts that are claimed with hostSelector. Hosts above it are
+	// released once their machine is deleted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
 	// machineSpec defines the specifications for each Talos control plane machine.
 	// +kubebuilder:validation:Optional
 	MachineSpec *MachineSpec `json:"machineSpec,omitempty"`
@@ -195,6 +335,9 @@ type Machine struct {
 	// pxeClientSpec defines the specifications of the machines relevant for PXE boot.
 	// +kubebuilder:validation:Optional
 	PxeClientSpec *PxeClientSpec `json:"pxeClientSpec,omitempty"`
+	// bmc is the baseboard management controller the operator powers the machine on and off with.
+	// +kubebuilder:validation:Optional
+	BMC *BMCSpec `json:"bmc,omitempty"`
 	// machineRef is a reference to a Kubernetes object from which the machine IP address can be extracted.
 	// +kubebuilder:validation:Optional
 	MachineRef *corev1.ObjectReference `json:"machineRef,omitempty"`
@@ -221,6 +364,39 @@ type PxeClientSpec struct {
 	KernelCmdlineArgs *string `json:"kernelCmdlineArgs,omitempty"`
 }
 
+// BMCSpec describes the baseboard management controller of a machine. The operator powers the
+// machine on, boots it from the network once while it is provisioned, power cycles it when it hangs
+// and powers it off when it is reset or deleted.
+// +kubebuilder:validation:XValidation:rule="self.protocol != 'redfish' || self.address.startsWith('http://') || self.address.startsWith('https://')",message="the address of a redfish BMC must be an http or https URL"
+type BMCSpec struct {
+	// protocol is the protocol the BMC is managed with, redfish or ipmi.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=redfish;ipmi
+	// +kubebuilder:default=redfish
+	Protocol string `json:"protocol,omitempty"`
+	// address is the URL of the Redfish service, e.g. https://10.0.0.5, or the host[:port] of the
+	// IPMI interface, e.g. 10.0.0.5:623.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+	// credentialsSecretRef is the Secret in the namespace of the machine that holds the username and
+	// password keys to authenticate against the BMC.
+	// +kubebuilder:validation:Required
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+	// systemID is the ID of the Redfish system of the machine. The first system of the service is used
+	// when it is not set.
+	// +kubebuilder:validation:Optional
+	SystemID string `json:"systemID,omitempty"`
+	// insecureSkipVerify skips the verification of the TLS certificate of the Redfish service.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// bootTimeout is how long a machine may take to boot into Talos after it was powered on before it
+	// is power cycled. Defaults to 10m.
+	// +kubebuilder:validation:Optional
+	BootTimeout *metav1.Duration `json:"bootTimeout,omitempty"`
+}
+
 // META is network metadata for Talos machines
 type META struct {
 	// hostname is the hostname for the Talos machines.
@@ -244,10 +420,20 @@ type TalosControlPlaneStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
-	// config is the reference to the Talos configuration used for the control plane.
+	// config is the Talos configuration used for the control plane.
+	// It is superseded by configSecretRef and only read to migrate objects created by older versions.
+	// +optional
 	Config string `json:"config,omitempty"`
-	// secretBundle is the reference to the secrets bundle used for the control plane.
+	// secretBundle is the secrets bundle used for the control plane.
+	// It is superseded by secretBundleRef and only read to migrate objects created by older versions.
+	// +optional
 	SecretBundle string `json:"secretBundle,omitempty"`
+	// configSecretRef references the Secret key holding the Talos configuration of the control plane.
+	// +optional
+	ConfigSecretRef *corev1.SecretKeySelector `json:"configSecretRef,omitempty"`
+	// secretBundleRef references the Secret key holding the secrets bundle of the control plane.
+	// +optional
+	SecretBundleRef *corev1.SecretKeySelector `json:"secretBundleRef,omitempty"`
 	// bundleConfig is the reference to the bundle configuration used for the control plane.
 	BundleConfig string `json:"bundleConfig,omitempty"`
 	// imported is only valid when ReconcileMode is 'import' and indicates whether the Talos control plane has been imported.
@@ -255,6 +441,18 @@ type TalosControlPlaneStatus struct {
 	// observedKubeVersion is the observed version of Kubernetes.
 	// +optional
 	ObservedKubeVersion string `json:"observedKubeVersion,omitempty"`
+	// preUpgradeBackupName is the name of the TalosEtcdBackup taken before the most recent upgrade.
+	// +optional
+	PreUpgradeBackupName string `json:"preUpgradeBackupName,omitempty"`
+	// nextMaintenanceWindow is when the next maintenance window opens while an upgrade waits for it.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+	// rollout reports the progress of the Talos version rollout across the control plane machines.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// claimedHosts are the names of the TalosHosts claimed through metalSpec.hostSelector.
+	// +optional
+	ClaimedHosts []string `json:"claimedHosts,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -30,12 +30,45 @@ type TalosEtcdBackupSpec struct {
 	// backupStorage specifies where to store the etcd backup.
 	// +kubebuilder:validation:required
 	BackupStorage BackupStorage `json:"backupStorage"`
+
+	// encryption configures client-side encryption of the etcd snapshot and the state secret before they are uploaded.
+	// +optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	// verify re-downloads the snapshot after the upload and validates its checksum and size.
+	// The backup is only marked ready once the verification succeeded.
+	// +kubebuilder:default:=false
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+}
+
+// BackupEncryption defines how backups are encrypted before they leave the operator.
+type BackupEncryption struct {
+	// type is the encryption algorithm. The age key is an X25519 identity (AGE-SECRET-KEY-1...),
+	// the aes-gcm key is 32 bytes, raw or base64 encoded.
+	// +kubebuilder:validation:Enum=age;aes-gcm
+	// +kubebuilder:validation:Required
+	Type string `json:"type"`
+	// keySecretRef is a reference to the secret key containing the encryption key. The same key is used to decrypt on restore.
+	// +kubebuilder:validation:Required
+	KeySecretRef *corev1.SecretKeySelector `json:"keySecretRef"`
 }
 
+// BackupStorage selects the storage backend for the etcd backup. Exactly one backend must be set.
+// +kubebuilder:validation:XValidation:rule="[has(self.s3), has(self.filesystem), has(self.azure), has(self.gcs)].filter(x, x).size() == 1",message="Exactly one of s3, filesystem, azure or gcs must be specified"
 type BackupStorage struct {
 	// s3 specifies the S3-compatible storage configuration for the etcd backup.
-	// +kubebuilder:validation:Required
+	// +optional
 	S3 *S3Storage `json:"s3,omitempty"`
+	// filesystem specifies a directory in the operator pod to store the etcd backup.
+	// +optional
+	Filesystem *FilesystemStorage `json:"filesystem,omitempty"`
+	// azure specifies the Azure Blob storage configuration for the etcd backup.
+	// +optional
+	Azure *AzureBlobStorage `json:"azure,omitempty"`
+	// gcs specifies the Google Cloud Storage configuration for the etcd backup.
+	// +optional
+	GCS *GCSStorage `json:"gcs,omitempty"`
 }
 
 type S3Storage struct {
@@ -54,6 +87,33 @@ type S3Storage struct {
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
 }
 
+type FilesystemStorage struct {
+	// path is the absolute path of the directory to store the etcd backup in. It is usually
+	// a PersistentVolumeClaim mounted into the operator pod.
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+}
+
+type AzureBlobStorage struct {
+	// container is the name of the Azure Blob container to store the etcd backup.
+	Container string `json:"container"`
+	// accountName is the name of the Azure storage account.
+	AccountName string `json:"accountName"`
+	// endpoint is the Blob service endpoint (optional, defaults to https://<accountName>.blob.core.windows.net/).
+	Endpoint string `json:"endpoint,omitempty"`
+	// accountKey is the shared key of the storage account (optional, the operator's Azure workload identity is used if omitted).
+	AccountKey *corev1.SecretKeySelector `json:"accountKey,omitempty"`
+}
+
+type GCSStorage struct {
+	// bucket is the name of the GCS bucket to store the etcd backup.
+	Bucket string `json:"bucket"`
+	// endpoint is the GCS service endpoint (optional, for GCS emulators).
+	Endpoint string `json:"endpoint,omitempty"`
+	// credentials is the service account key JSON (optional, the operator's application default credentials are used if omitted).
+	Credentials *corev1.SecretKeySelector `json:"credentials,omitempty"`
+}
+
 // TalosEtcdBackupStatus defines the observed state of TalosEtcdBackup.
 type TalosEtcdBackupStatus struct {
 	// filename is the name of the backup file in the storage backend.
@@ -62,6 +122,33 @@ type TalosEtcdBackupStatus struct {
 	// stateFilename is the name of the paired state-secret backup in the storage backend.
 	// +optional
 	StateFilename string `json:"stateFilename,omitempty"`
+	// encrypted is true when the backup files are encrypted.
+	// +optional
+	Encrypted bool `json:"encrypted,omitempty"`
+	// sha256 is the hex encoded SHA-256 checksum of the etcd snapshot before encryption.
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+	// size is the size of the etcd snapshot in bytes before encryption.
+	// +optional
+	Size int64 `json:"size,omitempty"`
+	// etcdRevision is the etcd revision observed right before the snapshot was taken.
+	// +optional
+	EtcdRevision int64 `json:"etcdRevision,omitempty"`
+	// talosVersion is the Talos version of the node the snapshot was taken from.
+	// +optional
+	TalosVersion string `json:"talosVersion,omitempty"`
+	// kubernetesVersion is the Kubernetes version of the cluster when the snapshot was taken.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// startTime is the time the backup was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// completionTime is the time the snapshot and the state secret were uploaded.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// verificationTime is the time the uploaded snapshot was downloaded and its checksum validated.
+	// +optional
+	VerificationTime *metav1.Time `json:"verificationTime,omitempty"`
 	// conditions represent the current state of the TalosEtcdBackup resource.
 	// +listType=map
 	// +listMapKey=type
@@ -73,6 +160,9 @@ type TalosEtcdBackupStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=teb
 // +kubebuilder:printcolumn:name="Filename",type=string,JSONPath=`.status.filename`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.size`
+// +kubebuilder:printcolumn:name="SHA256",type=string,JSONPath=`.status.sha256`,priority=1
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.status.etcdRevision`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // TalosEtcdBackup is the Schema for the talosetcdbackups API.
@@ -28,23 +28,102 @@ type TalosEtcdBackupScheduleSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	Schedule string `json:"schedule"`
 
+	// timeZone is the IANA time zone name the schedule is interpreted in, e.g. "Europe/Berlin".
+	// Defaults to the time zone of the operator.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// concurrencyPolicy specifies how to treat a scheduled backup while a previous one is still running.
+	// Allow runs them concurrently, Forbid skips the new run and Replace deletes the running backup
+	// before the new one is created.
+	// +kubebuilder:default:=Allow
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// startingDeadlineSeconds is the deadline in seconds for starting a backup that missed its
+	// scheduled time, e.g. because the operator was down. Runs that miss the deadline are skipped
+	// and recorded in status.missedSchedules. Without a deadline the most recent missed run is started.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
 	// backupTemplate is the template for creating TalosEtcdBackup resources.
 	// +kubebuilder:validation:Required
 	BackupTemplate TalosEtcdBackupTemplateSpec `json:"backupTemplate"`
 
 	// retention specifies how many successful backups to keep.
-	// Older backups will be automatically deleted
+	// Older backups will be automatically deleted. It is ignored if retentionPolicy is set.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default:=5
 	// +optional
 	Retention *int32 `json:"retention,omitempty"`
 
+	// retentionPolicy specifies which backups to keep. A successful backup is kept if any of the
+	// keep rules selects it, maxAge is applied on top of them. Deleting a backup also deletes its
+	// files from the backup storage.
+	// +optional
+	RetentionPolicy *BackupRetentionPolicy `json:"retentionPolicy,omitempty"`
+
 	// paused can be set to true to pause the backup schedule.
 	// +kubebuilder:default:=false
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 }
 
+// ConcurrencyPolicy describes how overlapping scheduled backups are handled.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows backups to run concurrently
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips a scheduled backup while the previous one is still running
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the running backup and starts the new one
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// MissedSchedule records a scheduled backup that was not started.
+type MissedSchedule struct {
+	// scheduledTime is the time the backup was scheduled for.
+	ScheduledTime metav1.Time `json:"scheduledTime"`
+	// reason is why the backup was not started.
+	Reason string `json:"reason"`
+}
+
+// BackupRetentionPolicy defines which backups of a schedule are kept. The hourly, daily, weekly and
+// monthly rules keep the newest successful backup of each of the last N periods that have a backup (grandfather-father-son).
+type BackupRetentionPolicy struct {
+	// maxAge deletes backups older than this duration, even if a keep rule selects them.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+	// keepLastSuccessful keeps the N most recent successful backups.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLastSuccessful *int32 `json:"keepLastSuccessful,omitempty"`
+	// keepLastFailed keeps the N most recent failed backups. Failed backups are kept if unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLastFailed *int32 `json:"keepLastFailed,omitempty"`
+	// keepHourly keeps the newest successful backup of each of the last N hours.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepHourly *int32 `json:"keepHourly,omitempty"`
+	// keepDaily keeps the newest successful backup of each of the last N days.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+	// keepWeekly keeps the newest successful backup of each of the last N ISO weeks.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+	// keepMonthly keeps the newest successful backup of each of the last N months.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+}
+
 // TalosEtcdBackupTemplateSpec defines the template for creating TalosEtcdBackup resources
 type TalosEtcdBackupTemplateSpec struct {
 	// spec is the specification of the TalosEtcdBackup to be created.
@@ -70,6 +149,10 @@ type TalosEtcdBackupScheduleStatus struct {
 	// +optional
 	ActiveBackups []string `json:"activeBackups,omitempty"`
 
+	// missedSchedules is the history of the most recent scheduled backups that were not started.
+	// +optional
+	MissedSchedules []MissedSchedule `json:"missedSchedules,omitempty"`
+
 	// conditions represent the current state of the TalosEtcdBackupSchedule resource.
 	// +listType=map
 	// +listMapKey=type
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TalosEtcdRestoreSpec defines the desired state of TalosEtcdRestore.
+// +kubebuilder:validation:XValidation:rule="has(self.backupRef) != has(self.source)",message="Specify either backupRef or source, but not both"
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="TalosEtcdRestore spec is immutable"
+type TalosEtcdRestoreSpec struct {
+	// talosControlPlaneRef is a reference to the TalosControlPlane to restore.
+	// +kubebuilder:validation:Required
+	TalosControlPlaneRef *corev1.LocalObjectReference `json:"talosControlPlaneRef"`
+
+	// backupRef is a reference to the TalosEtcdBackup to restore from.
+	// +optional
+	BackupRef *corev1.LocalObjectReference `json:"backupRef,omitempty"`
+
+	// source points to an etcd snapshot in the backup storage that is not tracked by a TalosEtcdBackup.
+	// +optional
+	Source *EtcdRestoreSource `json:"source,omitempty"`
+
+	// skipHashCheck skips the snapshot integrity check. It is only needed for snapshots
+	// copied directly from the etcd data directory instead of being taken via the Talos API.
+	// +kubebuilder:default:=false
+	// +optional
+	SkipHashCheck bool `json:"skipHashCheck,omitempty"`
+}
+
+// EtcdRestoreSource defines the location of a raw etcd snapshot in the backup storage.
+type EtcdRestoreSource struct {
+	// backupStorage specifies where the etcd snapshot is stored.
+	// +kubebuilder:validation:Required
+	BackupStorage BackupStorage `json:"backupStorage"`
+
+	// key is the object key of the etcd snapshot in the backup storage.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// stateKey is the object key of the paired state secret in the backup storage.
+	// Defaults to the state key derived from key.
+	// +optional
+	StateKey string `json:"stateKey,omitempty"`
+
+	// encryption configures how the snapshot and the state secret were encrypted when they were uploaded.
+	// +optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+}
+
+// TalosEtcdRestoreStatus defines the observed state of TalosEtcdRestore.
+type TalosEtcdRestoreStatus struct {
+	// snapshotKey is the object key of the etcd snapshot that is restored.
+	// +optional
+	SnapshotKey string `json:"snapshotKey,omitempty"`
+	// stateKey is the object key of the state secret that is restored.
+	// +optional
+	StateKey string `json:"stateKey,omitempty"`
+	// node is the address of the control plane node etcd is recovered on.
+	// +optional
+	Node string `json:"node,omitempty"`
+	// recoveryStartTime is the time the snapshot upload to the node started. Once it is set etcd may be
+	// recovered and the recovery is never started again.
+	// +optional
+	RecoveryStartTime *metav1.Time `json:"recoveryStartTime,omitempty"`
+	// recoveryTime is the time etcd was recovered from the snapshot on the node. The recovery is never
+	// repeated once it is set.
+	// +optional
+	RecoveryTime *metav1.Time `json:"recoveryTime,omitempty"`
+	// conditions represent the current state of the TalosEtcdRestore resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=ter
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.status.snapshotKey`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.status.node`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TalosEtcdRestore is the Schema for the talosetcdrestores API.
+type TalosEtcdRestore struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TalosEtcdRestore
+	// +required
+	Spec TalosEtcdRestoreSpec `json:"spec"`
+
+	// status defines the observed state of TalosEtcdRestore
+	// +optional
+	Status TalosEtcdRestoreStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TalosEtcdRestoreList contains a list of TalosEtcdRestore
+type TalosEtcdRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TalosEtcdRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TalosEtcdRestore{}, &TalosEtcdRestoreList{})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TalosHostSpec defines the desired state of TalosHost
+type TalosHostSpec struct {
+	// address is the IP address the Talos API of the host is reached on.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(\d{1,3}\.){3}\d{1,3}$`
+	Address string `json:"address"`
+	// macAddress is the MAC address of the network interface the host boots from.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`
+	MACAddress string `json:"macAddress,omitempty"`
+	// architecture is the CPU architecture of the host.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=amd64;arm64
+	// +kubebuilder:default=amd64
+	Architecture string `json:"architecture,omitempty"`
+	// hardware are the hardware facts of the host. They are informational, select hosts by their labels.
+	// +kubebuilder:validation:Optional
+	Hardware *Hardware `json:"hardware,omitempty"`
+	// bmc is the baseboard management controller of the host. It is handed to the machine that is
+	// created for the host once it is claimed.
+	// +kubebuilder:validation:Optional
+	BMC *BMCSpec `json:"bmc,omitempty"`
+	// claimedBy is the TalosControlPlane or TalosWorker that claimed the host through its hostSelector.
+	// It is set and cleared by the operator. Set it by hand to assign a host to a control plane or worker.
+	// +kubebuilder:validation:Optional
+	ClaimedBy *corev1.ObjectReference `json:"claimedBy,omitempty"`
+}
+
+// Hardware are the hardware facts of a host
+type Hardware struct {
+	// systemUUID is the UUID of the system as reported by SMBIOS.
+	// +kubebuilder:validation:Optional
+	SystemUUID string `json:"systemUUID,omitempty"`
+	// manufacturer is the manufacturer of the system.
+	// +kubebuilder:validation:Optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// productName is the product name of the system.
+	// +kubebuilder:validation:Optional
+	ProductName string `json:"productName,omitempty"`
+	// cpuModel is the model of the CPU.
+	// +kubebuilder:validation:Optional
+	CPUModel string `json:"cpuModel,omitempty"`
+	// cpus is the number of CPU cores.
+	// +kubebuilder:validation:Optional
+	CPUs int32 `json:"cpus,omitempty"`
+	// threads is the number of CPU threads.
+	// +kubebuilder:validation:Optional
+	Threads int32 `json:"threads,omitempty"`
+	// memory is the size of the memory.
+	// +kubebuilder:validation:Optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// disks are the disks of the host.
+	// +kubebuilder:validation:Optional
+	// +listType=atomic
+	Disks []Disk `json:"disks,omitempty"`
+	// nics are the physical network interfaces of the host.
+	// +kubebuilder:validation:Optional
+	// +listType=atomic
+	NICs []NetworkInterface `json:"nics,omitempty"`
+}
+
+// DiskType is the kind of a disk
+// +kubebuilder:validation:Enum=nvme;ssd;hdd
+type DiskType string
+
+const (
+	DiskTypeNVMe DiskType = "nvme"
+	DiskTypeSSD  DiskType = "ssd"
+	DiskTypeHDD  DiskType = "hdd"
+)
+
+// Disk is a disk of a host
+type Disk struct {
+	// name is the device path of the disk, e.g. /dev/sda.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// size is the size of the disk.
+	// +kubebuilder:validation:Optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// model is the model of the disk.
+	// +kubebuilder:validation:Optional
+	Model string `json:"model,omitempty"`
+	// serial is the serial number of the disk.
+	// +kubebuilder:validation:Optional
+	Serial string `json:"serial,omitempty"`
+	// transport is the transport of the disk, e.g. nvme, sata, usb or virtio.
+	// +kubebuilder:validation:Optional
+	Transport string `json:"transport,omitempty"`
+	// rotational is true for spinning disks.
+	// +kubebuilder:validation:Optional
+	Rotational bool `json:"rotational,omitempty"`
+	// type is nvme for NVMe disks, hdd for rotational disks and ssd for the others.
+	// +kubebuilder:validation:Optional
+	Type DiskType `json:"type,omitempty"`
+}
+
+// NetworkInterface is a physical network interface of a host
+type NetworkInterface struct {
+	// name is the name of the interface, e.g. enp1s0.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// macAddress is the hardware address of the interface.
+	// +kubebuilder:validation:Optional
+	MACAddress string `json:"macAddress,omitempty"`
+	// linkUp is true if the interface has a carrier.
+	// +kubebuilder:validation:Optional
+	LinkUp bool `json:"linkUp,omitempty"`
+	// speedMbps is the negotiated speed of the link in Mbit/s.
+	// +kubebuilder:validation:Optional
+	SpeedMbps int32 `json:"speedMbps,omitempty"`
+}
+
+// TalosHostStatus defines the observed state of TalosHost.
+type TalosHostStatus struct {
+	// conditions represent the current state of the TalosHost resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=th
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+// +kubebuilder:printcolumn:name="MAC",type=string,JSONPath=`.spec.macAddress`
+// +kubebuilder:printcolumn:name="Arch",type=string,JSONPath=`.spec.architecture`
+// +kubebuilder:printcolumn:name="Claimed By",type=string,JSONPath=`.spec.claimedBy.name`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TalosHost is the Schema for the taloshosts API. It is a bare metal host of the inventory that a
+// TalosControlPlane or TalosWorker claims through the hostSelector of its metalSpec.
+type TalosHost struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TalosHost
+	// +required
+	Spec TalosHostSpec `json:"spec"`
+
+	// status defines the observed state of TalosHost
+	// +optional
+	Status TalosHostStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TalosHostList contains a list of TalosHost
+type TalosHostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TalosHost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TalosHost{}, &TalosHostList{})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TalosHostDiscoverySpec defines the desired state of TalosHostDiscovery
+// +kubebuilder:validation:XValidation:rule="(has(self.cidrs) && size(self.cidrs) > 0) || self.dhcpLeases",message="cidrs or dhcpLeases is required"
+type TalosHostDiscoverySpec struct {
+	// cidrs are the networks that are scanned for machines in maintenance mode. Networks are limited
+	// to 4096 addresses, i.e. a /20.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MaxLength=18
+	// +kubebuilder:validation:items:Pattern=`^(\d{1,3}\.){3}\d{1,3}/\d{1,2}$`
+	// +kubebuilder:validation:XValidation:rule="self.all(c, int(c.split('/')[1]) >= 20)",message="cidrs must not be larger than a /20"
+	// +listType=atomic
+	CIDRs []string `json:"cidrs,omitempty"`
+	// dhcpLeases probes the addresses leased by the PXE boot stack of the operator besides the cidrs.
+	// Requires the PXE boot stack to be enabled and a discoveryRange on the pxeServerSpec of a TalosCluster.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	DHCPLeases bool `json:"dhcpLeases,omitempty"`
+	// port is the port of the Talos API that is probed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=50000
+	Port int32 `json:"port,omitempty"`
+	// interval is how often the networks are scanned.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="10m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// hostLabels are added to the TalosHosts that are discovered, e.g. to put them into a pool that a
+	// hostSelector claims from.
+	// +kubebuilder:validation:Optional
+	HostLabels map[string]string `json:"hostLabels,omitempty"`
+}
+
+// TalosHostDiscoveryStatus defines the observed state of TalosHostDiscovery.
+type TalosHostDiscoveryStatus struct {
+	// conditions represent the current state of the TalosHostDiscovery resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// lastScanTime is when the networks were scanned last.
+	// +optional
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+	// scannedAddresses is the number of addresses that were probed by the last scan.
+	// +optional
+	ScannedAddresses int32 `json:"scannedAddresses,omitempty"`
+	// discoveredHosts is the number of TalosHosts that were created by the discovery.
+	// +optional
+	DiscoveredHosts int32 `json:"discoveredHosts,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=thd
+// +kubebuilder:printcolumn:name="Scanned",type=integer,JSONPath=`.status.scannedAddresses`
+// +kubebuilder:printcolumn:name="Discovered",type=integer,JSONPath=`.status.discoveredHosts`
+// +kubebuilder:printcolumn:name="Last Scan",type=date,JSONPath=`.status.lastScanTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TalosHostDiscovery is the Schema for the taloshostdiscoveries API. It periodically scans networks
+// for machines booted into Talos maintenance mode and adds them to the TalosHost inventory.
+type TalosHostDiscovery struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TalosHostDiscovery
+	// +required
+	Spec TalosHostDiscoverySpec `json:"spec"`
+
+	// status defines the observed state of TalosHostDiscovery
+	// +optional
+	Status TalosHostDiscoveryStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TalosHostDiscoveryList contains a list of TalosHostDiscovery
+type TalosHostDiscoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TalosHostDiscovery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TalosHostDiscovery{}, &TalosHostDiscoveryList{})
+}
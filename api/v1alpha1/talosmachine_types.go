@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -52,16 +53,105 @@ type TalosMachineSpec struct {
 	// +kubebuilder:default=reset
 	DeletionPolicy string `json:"deletionPolicy"`
 
+	// reset controls how the machine is reset when it is deleted with the reset deletion policy.
+	// The machine is forcefully reset and rebooted with all partitions wiped when it is not set.
+	// +kubebuilder:validation:Optional
+	Reset *ResetSpec `json:"reset,omitempty"`
+
 	// pxeClientSpec defines the specifications of the machines relevant for PXE boot.
 	// +kubebuilder:validation:Optional
 	PxeClientSpec *PxeClientSpec `json:"pxeClientSpec,omitempty"`
+
+	// bmc is the baseboard management controller the operator powers the machine on and off with.
+	// +kubebuilder:validation:Optional
+	BMC *BMCSpec `json:"bmc,omitempty"`
+
+	// drain controls how the Kubernetes Node of the machine is drained before it is upgraded or reset.
+	// The Node is drained with the defaults when it is not set.
+	// +kubebuilder:validation:Optional
+	Drain *DrainSpec `json:"drain,omitempty"`
+
+	// upgrade controls how a Talos upgrade of the machine is verified and what happens if it fails.
+	// +kubebuilder:validation:Optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+}
+
+// UpgradeSpec describes how a Talos upgrade of a machine is verified. An upgrade fails if the machine
+// does not report the new version within the timeout.
+type UpgradeSpec struct {
+	// timeout is how long the machine may take to come back on the new version after the upgrade was
+	// started. The machine is marked Failed afterwards. Defaults to 30m.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// rollback makes the machine boot the previous Talos installation when the upgrade failed and the
+	// machine is still reachable.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	Rollback bool `json:"rollback,omitempty"`
+}
+
+// SystemPartitionLabel is the label of a partition of the Talos system disk
+// +kubebuilder:validation:Enum=STATE;EPHEMERAL;META
+type SystemPartitionLabel string
+
+// ResetSpec describes how a machine is reset when it is deleted, e.g. to hand it back to a pool of
+// bare metal machines.
+type ResetSpec struct {
+	// graceful cordons and drains the machine and makes a control plane machine leave etcd before it
+	// is reset. A forced reset wipes the machine right away.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	Graceful bool `json:"graceful,omitempty"`
+	// systemPartitionsToWipe are the labels of the system disk partitions that are wiped. All
+	// partitions are wiped when it is empty.
+	// +kubebuilder:validation:Optional
+	SystemPartitionsToWipe []SystemPartitionLabel `json:"systemPartitionsToWipe,omitempty"`
+	// userDisksToWipe are block devices besides the system disk that are wiped as well, e.g. /dev/sdb.
+	// +kubebuilder:validation:Optional
+	UserDisksToWipe []string `json:"userDisksToWipe,omitempty"`
+	// afterReset is Reboot to boot the machine again, e.g. into maintenance mode or PXE, or PowerOff to
+	// shut it down.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Reboot;PowerOff
+	// +kubebuilder:default=Reboot
+	AfterReset string `json:"afterReset,omitempty"`
+	// timeout is how long a failing reset is retried after the machine was deleted. Once it expired, a
+	// graceful reset is retried as a forced reset and the machine is released without a reset if that
+	// fails as well. The reset is retried until it succeeds when it is not set.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// DrainSpec describes how the Kubernetes Node of a machine is cordoned and drained before a Talos
+// upgrade, a reset or the removal of the machine.
+type DrainSpec struct {
+	// enabled cordons the Node and evicts its pods before the machine is upgraded or reset. The Node
+	// is uncordoned once the machine is back and Ready.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+	// timeout is how long the eviction of the pods is retried. Evictions blocked by a
+	// PodDisruptionBudget are retried until the timeout expires. Defaults to 10m.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// force proceeds with the upgrade or reset once the timeout expired even if pods could not be
+	// evicted, and deletes pods that are not managed by a controller. Without it the operation waits
+	// until the Node is drained.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	Force bool `json:"force,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!(has(self.installDisk) && has(self.installDiskSelector))",message="installDisk and installDiskSelector are mutually exclusive"
 type MachineSpec struct {
 	// installDisk is the disk to use for installing Talos on the control plane machines.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Pattern=`^/dev/(sd[a-z][0-9]*|vd[a-z][0-9]*|nvme[0-9]+n[0-9]+(p[0-9]+)?)$`
 	InstallDisk *string `json:"installDisk,omitempty"`
+	// installDiskSelector picks the install disk of each machine from the disks it reports, for
+	// machines whose disks are not named alike.
+	// +kubebuilder:validation:Optional
+	InstallDiskSelector *InstallDiskSelector `json:"installDiskSelector,omitempty"`
 	// wipe indicates whether to wipe the disk before installation.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
@@ -99,16 +189,96 @@ type MachineSpec struct {
 	ConfigPatches []runtime.RawExtension `json:"configPatches,omitempty"`
 }
 
+// InstallDiskSelector selects the install disk of a machine by its facts. All fields that are set
+// must match. Of the disks that match, the one with the lowest device path is used.
+type InstallDiskSelector struct {
+	// minSize is the minimum size of the disk.
+	// +kubebuilder:validation:Optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+	// maxSize is the maximum size of the disk.
+	// +kubebuilder:validation:Optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// type is the kind of the disk: nvme, ssd or hdd.
+	// +kubebuilder:validation:Optional
+	Type DiskType `json:"type,omitempty"`
+	// model is a glob matched against the model of the disk, e.g. "Samsung SSD 9*".
+	// +kubebuilder:validation:Optional
+	Model string `json:"model,omitempty"`
+	// serial is the serial number of the disk.
+	// +kubebuilder:validation:Optional
+	Serial string `json:"serial,omitempty"`
+}
+
+// BMCStatus is the power state of a machine as reported by its BMC
+type BMCStatus struct {
+	// powerState is the power state of the machine: On, Off or Unknown.
+	// +optional
+	PowerState string `json:"powerState,omitempty"`
+	// lastPowerAction is the last power action the operator took: PowerOn, PowerCycle or PowerOff.
+	// +optional
+	LastPowerAction string `json:"lastPowerAction,omitempty"`
+	// lastPowerActionTime is the time of the last power action.
+	// +optional
+	LastPowerActionTime *metav1.Time `json:"lastPowerActionTime,omitempty"`
+}
+
+// PlannedConfigChange is a config change of a machine that has not been applied yet.
+type PlannedConfigChange struct {
+	// hash is the hash of the config to apply.
+	Hash string `json:"hash"`
+	// appliedHash is the hash of the config that is currently applied.
+	// +optional
+	AppliedHash string `json:"appliedHash,omitempty"`
+	// diff are the changes reported by the machine for an apply-config dry run.
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
+
 // TalosMachineStatus defines the observed state of TalosMachine.
 type TalosMachineStatus struct {
 	// observedVersion is the version of Talos running on this machine.
 	ObservedVersion string `json:"observedVersion,omitempty"`
-	// config is the base64 encoded Talos configuration.
+	// config is the Talos configuration applied to the machine.
+	// It is superseded by configSecretRef and only read to migrate objects created by older versions.
+	// +optional
 	Config string `json:"config,omitempty"`
+	// configSecretRef references the Secret key holding the Talos configuration applied to the machine.
+	// +optional
+	ConfigSecretRef *corev1.SecretKeySelector `json:"configSecretRef,omitempty"`
 	// imported is only valid when ReconcileMode is 'import' and indicates whether the Talos machine has been imported.
 	Imported *bool `json:"imported,omitempty"`
 	// state is the current state of the machine (e.g., "Ready", "Provisioning", "Failed").
 	State string `json:"state,omitempty"`
+	// upgradeStartTime is the time the current Talos upgrade of the machine was started. It is cleared
+	// once the machine is available again.
+	// +optional
+	UpgradeStartTime *metav1.Time `json:"upgradeStartTime,omitempty"`
+	// previousVersion is the version of Talos the machine ran before the current or last upgrade.
+	// +optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// failedVersion is the version of a failed Talos upgrade. The upgrade is not retried until the
+	// version of the machine is changed.
+	// +optional
+	FailedVersion string `json:"failedVersion,omitempty"`
+	// nodeName is the name of the Kubernetes Node of the machine.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// drainStartTime is the time the operator cordoned the Node and started to drain it. It is
+	// cleared once the Node is uncordoned.
+	// +optional
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+	// hardware are the hardware facts the machine reported through the Talos API.
+	// +optional
+	Hardware *Hardware `json:"hardware,omitempty"`
+	// installDisk is the disk Talos is installed on, as resolved from the installDiskSelector.
+	// +optional
+	InstallDisk string `json:"installDisk,omitempty"`
+	// bmc is the power state of the machine as reported by its BMC.
+	// +optional
+	BMC *BMCStatus `json:"bmc,omitempty"`
+	// plannedConfig is the config change that waits for the TalosUpgradePlan of the cluster to be approved.
+	// +optional
+	PlannedConfig *PlannedConfigChange `json:"plannedConfig,omitempty"`
 	// conditions represent the latest available observations of a TalosMachine's current state.
 	// +listType=map
 	// +listMapKey=type
@@ -122,6 +292,7 @@ type TalosMachineStatus struct {
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
 // +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.endpoint`
+// +kubebuilder:printcolumn:name="Power",type=string,JSONPath=`.status.bmc.powerState`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // TalosMachine is the Schema for the talosmachines API.
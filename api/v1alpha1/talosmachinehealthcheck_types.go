@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// UnhealthyConditionType is a check a TalosMachineHealthCheck runs against its machines
+// +kubebuilder:validation:Enum=NodeNotReady;TalosAPIUnreachable;ServiceNotRunning
+type UnhealthyConditionType string
+
+const (
+	// NodeNotReadyCondition fails while the Node of the machine does not report the Ready condition
+	NodeNotReadyCondition UnhealthyConditionType = "NodeNotReady"
+	// TalosAPIUnreachableCondition fails while the machine does not answer on the Talos API
+	TalosAPIUnreachableCondition UnhealthyConditionType = "TalosAPIUnreachable"
+	// ServiceNotRunningCondition fails while one of the given Talos services is not Running
+	ServiceNotRunningCondition UnhealthyConditionType = "ServiceNotRunning"
+)
+
+// RemediationStrategy is what a TalosMachineHealthCheck does to an unhealthy machine
+// +kubebuilder:validation:Enum=Reboot;Reset;Replace
+type RemediationStrategy string
+
+const (
+	// RebootRemediation reboots the machine
+	RebootRemediation RemediationStrategy = "Reboot"
+	// ResetRemediation wipes the machine and installs it again with its config
+	ResetRemediation RemediationStrategy = "Reset"
+	// ReplaceRemediation marks the machine for replacement and leaves it as it is
+	ReplaceRemediation RemediationStrategy = "Replace"
+)
+
+// HealthCheckOwnerReference selects the TalosMachines of a TalosControlPlane or TalosWorker
+type HealthCheckOwnerReference struct {
+	// kind is TalosControlPlane or TalosWorker.
+	// +kubebuilder:validation:Enum=TalosControlPlane;TalosWorker
+	Kind string `json:"kind"`
+	// name is the name of the TalosControlPlane or TalosWorker in the namespace of the health check.
+	Name string `json:"name"`
+}
+
+// UnhealthyCondition marks a machine as unhealthy once its check failed for longer than the timeout
+// +kubebuilder:validation:XValidation:rule="self.type != 'ServiceNotRunning' || size(self.services) > 0",message="services must be set for the ServiceNotRunning condition"
+type UnhealthyCondition struct {
+	// type is NodeNotReady, TalosAPIUnreachable or ServiceNotRunning.
+	Type UnhealthyConditionType `json:"type"`
+	// services are the Talos services, e.g. kubelet or etcd, that have to be Running for the
+	// ServiceNotRunning condition.
+	// +optional
+	Services []string `json:"services,omitempty"`
+	// timeout is how long the check has to fail before the machine is unhealthy.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// MachineRemediation defines how unhealthy machines are remediated
+type MachineRemediation struct {
+	// strategy is Reboot, Reset or Replace. Reset wipes the machine and installs it again, control
+	// plane machines leave etcd before. Replace only marks the machine with the
+	// talos.alperen.cloud/remediation annotation.
+	// +kubebuilder:default=Reboot
+	// +optional
+	Strategy RemediationStrategy `json:"strategy,omitempty"`
+}
+
+// TalosMachineHealthCheckSpec defines the desired state of TalosMachineHealthCheck.
+// +kubebuilder:validation:XValidation:rule="has(self.selector) || has(self.ownerRef)",message="either selector or ownerRef must be set"
+type TalosMachineHealthCheckSpec struct {
+	// selector selects the TalosMachines by label. Combined with ownerRef, machines have to match both.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ownerRef selects the TalosMachines of a TalosControlPlane or TalosWorker.
+	// +optional
+	OwnerRef *HealthCheckOwnerReference `json:"ownerRef,omitempty"`
+
+	// unhealthyConditions are the checks a machine has to pass. A machine failing any of them for
+	// longer than its timeout is unhealthy.
+	// +kubebuilder:validation:MinItems=1
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
+
+	// remediation defines what is done to unhealthy machines.
+	// +optional
+	Remediation MachineRemediation `json:"remediation,omitempty"`
+
+	// maxUnhealthy is the number or percentage of selected machines that may be unhealthy at once.
+	// No machine is remediated while more machines are unhealthy, as remediating them is likely to
+	// make an outage worse. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+}
+
+// HealthCheckFailure is an unhealthy condition a machine currently fails
+type HealthCheckFailure struct {
+	// type is the unhealthy condition that fails.
+	Type UnhealthyConditionType `json:"type"`
+	// since is when the failure was first observed.
+	Since metav1.Time `json:"since"`
+	// message describes the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MachineHealthStatus is the health of a machine selected by a TalosMachineHealthCheck
+type MachineHealthStatus struct {
+	// name is the name of the TalosMachine.
+	Name string `json:"name"`
+	// healthy is false once a failure outlasted the timeout of its condition.
+	Healthy bool `json:"healthy"`
+	// failures are the unhealthy conditions the machine currently fails.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Failures []HealthCheckFailure `json:"failures,omitempty"`
+	// lastRemediationTime is when the machine was remediated last.
+	// +optional
+	LastRemediationTime *metav1.Time `json:"lastRemediationTime,omitempty"`
+	// remediations is how often the machine was remediated.
+	// +optional
+	Remediations int32 `json:"remediations,omitempty"`
+	// probeError is why the checks of the machine could not be run on the last pass. The machine
+	// keeps its health and is not remediated meanwhile.
+	// +optional
+	ProbeError string `json:"probeError,omitempty"`
+}
+
+// TalosMachineHealthCheckStatus defines the observed state of TalosMachineHealthCheck.
+type TalosMachineHealthCheckStatus struct {
+	// expectedMachines is the number of machines selected by the health check.
+	// +optional
+	ExpectedMachines int32 `json:"expectedMachines,omitempty"`
+	// currentHealthy is the number of selected machines that are healthy.
+	// +optional
+	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
+	// machines is the health of the selected machines.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Machines []MachineHealthStatus `json:"machines,omitempty"`
+	// conditions represent the current state of the TalosMachineHealthCheck resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=tmhc
+// +kubebuilder:printcolumn:name="Remediation",type=string,JSONPath=`.spec.remediation.strategy`
+// +kubebuilder:printcolumn:name="Expected",type=integer,JSONPath=`.status.expectedMachines`
+// +kubebuilder:printcolumn:name="Healthy",type=integer,JSONPath=`.status.currentHealthy`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TalosMachineHealthCheck is the Schema for the talosmachinehealthchecks API.
+type TalosMachineHealthCheck struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TalosMachineHealthCheck
+	// +required
+	Spec TalosMachineHealthCheckSpec `json:"spec"`
+
+	// status defines the observed state of TalosMachineHealthCheck
+	// +optional
+	Status TalosMachineHealthCheckStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TalosMachineHealthCheckList contains a list of TalosMachineHealthCheck
+type TalosMachineHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TalosMachineHealthCheck `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TalosMachineHealthCheck{}, &TalosMachineHealthCheckList{})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PlannedActionTalosUpgrade upgrades the Talos version of a TalosMachine
+	PlannedActionTalosUpgrade = "TalosUpgrade"
+	// PlannedActionConfigChange applies a changed machine config to a TalosMachine
+	PlannedActionConfigChange = "ConfigChange"
+	// PlannedActionKubernetesUpgrade upgrades the Kubernetes version of a TalosControlPlane
+	PlannedActionKubernetesUpgrade = "KubernetesUpgrade"
+
+	// Phases of a TalosUpgradePlan
+	UpgradePlanPhaseUpToDate         = "UpToDate"         // No changes are pending
+	UpgradePlanPhaseAwaitingApproval = "AwaitingApproval" // Changes are pending and wait for the revision to be approved
+	UpgradePlanPhaseApproved         = "Approved"         // The approved changes are being executed
+	UpgradePlanPhaseApplied          = "Applied"          // All approved changes were executed
+)
+
+// TalosUpgradePlanSpec defines the desired state of TalosUpgradePlan.
+type TalosUpgradePlanSpec struct {
+	// talosControlPlaneRef is a reference to the TalosControlPlane whose changes are planned. The
+	// TalosWorkers referencing the control plane are part of the plan as well.
+	// +kubebuilder:validation:Required
+	TalosControlPlaneRef *corev1.LocalObjectReference `json:"talosControlPlaneRef"`
+
+	// approvedRevision approves the plan with the given revision for execution. Copy status.revision
+	// after reviewing the planned actions. A plan whose revision changed is not approved anymore.
+	// The talos.alperen.cloud/approved-revision annotation approves the plan as well.
+	// +optional
+	ApprovedRevision string `json:"approvedRevision,omitempty"`
+}
+
+// PlannedAction is a change the operator executes once the plan is approved.
+type PlannedAction struct {
+	// type is TalosUpgrade, ConfigChange or KubernetesUpgrade.
+	Type string `json:"type"`
+	// target is the name of the TalosMachine or, for Kubernetes upgrades, the TalosControlPlane.
+	Target string `json:"target"`
+	// from is the current version or the hash of the applied config.
+	// +optional
+	From string `json:"from,omitempty"`
+	// to is the desired version or the hash of the config to apply.
+	To string `json:"to"`
+	// diff are the config changes reported by the machine for an apply-config dry run.
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
+
+// TalosUpgradePlanStatus defines the observed state of TalosUpgradePlan.
+type TalosUpgradePlanStatus struct {
+	// phase is UpToDate, AwaitingApproval, Approved or Applied.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// revision identifies the planned actions and the specs they were planned from.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// specHash is the hash of the control plane and worker specs the actions were planned from.
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+	// actions are the changes the operator executes once the revision is approved.
+	// +optional
+	Actions []PlannedAction `json:"actions,omitempty"`
+	// conditions represent the current state of the TalosUpgradePlan resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=tup
+// +kubebuilder:printcolumn:name="ControlPlane",type=string,JSONPath=`.spec.talosControlPlaneRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Revision",type=string,JSONPath=`.status.revision`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TalosUpgradePlan is the Schema for the talosupgradeplans API.
+type TalosUpgradePlan struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TalosUpgradePlan
+	// +required
+	Spec TalosUpgradePlanSpec `json:"spec"`
+
+	// status defines the observed state of TalosUpgradePlan
+	// +optional
+	Status TalosUpgradePlanStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TalosUpgradePlanList contains a list of TalosUpgradePlan
+type TalosUpgradePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TalosUpgradePlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TalosUpgradePlan{}, &TalosUpgradePlanList{})
+}
@@ -74,11 +74,32 @@ type TalosWorkerSpec struct {
 	// +kubebuilder:default=reset
 	DeletionPolicy string `json:"deletionPolicy"`
 
+	// reset controls how worker machines are reset when they are deleted with the reset deletion policy.
+	// Machines are forcefully reset and rebooted with all partitions wiped when it is not set.
+	// +kubebuilder:validation:Optional
+	Reset *ResetSpec `json:"reset,omitempty"`
+
 	// rolloutStrategy controls how Talos version upgrades are propagated to the worker machines.
 	// only applied when mode is metal.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={type: "RollingUpdate", rollingUpdate: {maxUnavailable: 1}}
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// drain controls how the Kubernetes Nodes of the worker machines are drained before they are
+	// upgraded or reset. only applied when mode is metal.
+	// +kubebuilder:validation:Optional
+	Drain *DrainSpec `json:"drain,omitempty"`
+
+	// upgrade controls how Talos upgrades of the worker machines are verified and what happens if they
+	// fail. A failed upgrade pauses the rollout. only applied when mode is metal.
+	// +kubebuilder:validation:Optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+
+	// maintenanceWindows restricts when Talos upgrades of the worker machines may start. Upgrades that
+	// already started are finished outside of a window. Without windows upgrades start as soon as
+	// the version changes.
+	// +kubebuilder:validation:Optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
 }
 
 // TalosWorkerStatus defines the observed state of TalosWorker.
@@ -89,11 +110,25 @@ type TalosWorkerStatus struct {
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// config is the serialized Talos configuration for the worker.
+	// It is superseded by configSecretRef and only read to migrate objects created by older versions.
+	// +optional
 	Config string `json:"config,omitempty"`
+	// configSecretRef references the Secret key holding the Talos configuration of the worker.
+	// +optional
+	ConfigSecretRef *corev1.SecretKeySelector `json:"configSecretRef,omitempty"`
 	// imported is only valid when ReconcileMode is 'import' and indicates whether the Talos worker has been imported.
 	Imported *bool `json:"imported,omitempty"`
 	// state represents the current state of the Talos worker (e.g., "Ready", "Provisioning", "Failed").
 	State string `json:"state,omitempty"`
+	// nextMaintenanceWindow is when the next maintenance window opens while an upgrade waits for it.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+	// rollout reports the progress of the Talos version rollout across the worker machines.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// claimedHosts are the names of the TalosHosts claimed through metalSpec.hostSelector.
+	// +optional
+	ClaimedHosts []string `json:"claimedHosts,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -28,6 +28,136 @@ import (
 	apiv1alpha1 "sigs.k8s.io/cluster-api-addon-provider-helm/api/v1alpha1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureBlobStorage) DeepCopyInto(out *AzureBlobStorage) {
+	*out = *in
+	if in.AccountKey != nil {
+		in, out := &in.AccountKey, &out.AccountKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureBlobStorage.
+func (in *AzureBlobStorage) DeepCopy() *AzureBlobStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureBlobStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCSpec) DeepCopyInto(out *BMCSpec) {
+	*out = *in
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+	if in.BootTimeout != nil {
+		in, out := &in.BootTimeout, &out.BootTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCSpec.
+func (in *BMCSpec) DeepCopy() *BMCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCStatus) DeepCopyInto(out *BMCStatus) {
+	*out = *in
+	if in.LastPowerActionTime != nil {
+		in, out := &in.LastPowerActionTime, &out.LastPowerActionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCStatus.
+func (in *BMCStatus) DeepCopy() *BMCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupEncryption) DeepCopyInto(out *BackupEncryption) {
+	*out = *in
+	if in.KeySecretRef != nil {
+		in, out := &in.KeySecretRef, &out.KeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupEncryption.
+func (in *BackupEncryption) DeepCopy() *BackupEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionPolicy) DeepCopyInto(out *BackupRetentionPolicy) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.KeepLastSuccessful != nil {
+		in, out := &in.KeepLastSuccessful, &out.KeepLastSuccessful
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepLastFailed != nil {
+		in, out := &in.KeepLastFailed, &out.KeepLastFailed
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepHourly != nil {
+		in, out := &in.KeepHourly, &out.KeepHourly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepDaily != nil {
+		in, out := &in.KeepDaily, &out.KeepDaily
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepWeekly != nil {
+		in, out := &in.KeepWeekly, &out.KeepWeekly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepMonthly != nil {
+		in, out := &in.KeepMonthly, &out.KeepMonthly
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionPolicy.
+func (in *BackupRetentionPolicy) DeepCopy() *BackupRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorage) DeepCopyInto(out *BackupStorage) {
 	*out = *in
@@ -36,6 +166,21 @@ func (in *BackupStorage) DeepCopyInto(out *BackupStorage) {
 		*out = new(S3Storage)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Filesystem != nil {
+		in, out := &in.Filesystem, &out.Filesystem
+		*out = new(FilesystemStorage)
+		**out = **in
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(AzureBlobStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSStorage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorage.
@@ -73,6 +218,102 @@ func (in *CNIConfig) DeepCopy() *CNIConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPRange) DeepCopyInto(out *DHCPRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPRange.
+func (in *DHCPRange) DeepCopy() *DHCPRange {
+	if in == nil {
+		return nil
+	}
+	out := new(DHCPRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Disk) DeepCopyInto(out *Disk) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disk.
+func (in *Disk) DeepCopy() *Disk {
+	if in == nil {
+		return nil
+	}
+	out := new(Disk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainSpec) DeepCopyInto(out *DrainSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainSpec.
+func (in *DrainSpec) DeepCopy() *DrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdRestoreSource) DeepCopyInto(out *EtcdRestoreSource) {
+	*out = *in
+	in.BackupStorage.DeepCopyInto(&out.BackupStorage)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRestoreSource.
+func (in *EtcdRestoreSource) DeepCopy() *EtcdRestoreSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdRestoreSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemStorage) DeepCopyInto(out *FilesystemStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemStorage.
+func (in *FilesystemStorage) DeepCopy() *FilesystemStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FlannelCNIConfig) DeepCopyInto(out *FlannelCNIConfig) {
 	*out = *in
@@ -98,6 +339,89 @@ func (in *FlannelCNIConfig) DeepCopy() *FlannelCNIConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSStorage) DeepCopyInto(out *GCSStorage) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSStorage.
+func (in *GCSStorage) DeepCopy() *GCSStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hardware) DeepCopyInto(out *Hardware) {
+	*out = *in
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]Disk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]NetworkInterface, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hardware.
+func (in *Hardware) DeepCopy() *Hardware {
+	if in == nil {
+		return nil
+	}
+	out := new(Hardware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckFailure) DeepCopyInto(out *HealthCheckFailure) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckFailure.
+func (in *HealthCheckFailure) DeepCopy() *HealthCheckFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckOwnerReference) DeepCopyInto(out *HealthCheckOwnerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckOwnerReference.
+func (in *HealthCheckOwnerReference) DeepCopy() *HealthCheckOwnerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckOwnerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmSpec) DeepCopyInto(out *HelmSpec) {
 	*out = *in
@@ -128,6 +452,31 @@ func (in *HelmSpec) DeepCopy() *HelmSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallDiskSelector) DeepCopyInto(out *InstallDiskSelector) {
+	*out = *in
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallDiskSelector.
+func (in *InstallDiskSelector) DeepCopy() *InstallDiskSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallDiskSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *META) DeepCopyInto(out *META) {
 	*out = *in
@@ -166,6 +515,11 @@ func (in *Machine) DeepCopyInto(out *Machine) {
 		*out = new(PxeClientSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BMC != nil {
+		in, out := &in.BMC, &out.BMC
+		*out = new(BMCSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MachineRef != nil {
 		in, out := &in.MachineRef, &out.MachineRef
 		*out = new(v1.ObjectReference)
@@ -198,41 +552,102 @@ func (in *Machine) DeepCopy() *Machine {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
+func (in *MachineHealthStatus) DeepCopyInto(out *MachineHealthStatus) {
 	*out = *in
-	if in.InstallDisk != nil {
-		in, out := &in.InstallDisk, &out.InstallDisk
-		*out = new(string)
-		**out = **in
-	}
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
-	}
-	if in.Meta != nil {
-		in, out := &in.Meta, &out.Meta
-		*out = new(META)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Registries != nil {
-		in, out := &in.Registries, &out.Registries
-		*out = new(runtime.RawExtension)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AdditionalConfig != nil {
-		in, out := &in.AdditionalConfig, &out.AdditionalConfig
-		*out = make([]runtime.RawExtension, len(*in))
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]HealthCheckFailure, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ConfigPatches != nil {
-		in, out := &in.ConfigPatches, &out.ConfigPatches
-		*out = make([]runtime.RawExtension, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.LastRemediationTime != nil {
+		in, out := &in.LastRemediationTime, &out.LastRemediationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthStatus.
+func (in *MachineHealthStatus) DeepCopy() *MachineHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineRemediation) DeepCopyInto(out *MachineRemediation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineRemediation.
+func (in *MachineRemediation) DeepCopy() *MachineRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineRolloutStatus) DeepCopyInto(out *MachineRolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineRolloutStatus.
+func (in *MachineRolloutStatus) DeepCopy() *MachineRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
+	*out = *in
+	if in.InstallDisk != nil {
+		in, out := &in.InstallDisk, &out.InstallDisk
+		*out = new(string)
+		**out = **in
+	}
+	if in.InstallDiskSelector != nil {
+		in, out := &in.InstallDiskSelector, &out.InstallDiskSelector
+		*out = new(InstallDiskSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Meta != nil {
+		in, out := &in.Meta, &out.Meta
+		*out = new(META)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalConfig != nil {
+		in, out := &in.AdditionalConfig, &out.AdditionalConfig
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigPatches != nil {
+		in, out := &in.ConfigPatches, &out.ConfigPatches
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -246,6 +661,27 @@ func (in *MachineSpec) DeepCopy() *MachineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetalSpec) DeepCopyInto(out *MetalSpec) {
 	*out = *in
@@ -256,6 +692,16 @@ func (in *MetalSpec) DeepCopyInto(out *MetalSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HostSelector != nil {
+		in, out := &in.HostSelector, &out.HostSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	if in.MachineSpec != nil {
 		in, out := &in.MachineSpec, &out.MachineSpec
 		*out = new(MachineSpec)
@@ -273,6 +719,108 @@ func (in *MetalSpec) DeepCopy() *MetalSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissedSchedule) DeepCopyInto(out *MissedSchedule) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissedSchedule.
+func (in *MissedSchedule) DeepCopy() *MissedSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(MissedSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionRolloutStrategy) DeepCopyInto(out *PartitionRolloutStrategy) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionRolloutStrategy.
+func (in *PartitionRolloutStrategy) DeepCopy() *PartitionRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedAction) DeepCopyInto(out *PlannedAction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedAction.
+func (in *PlannedAction) DeepCopy() *PlannedAction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedConfigChange) DeepCopyInto(out *PlannedConfigChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedConfigChange.
+func (in *PlannedConfigChange) DeepCopy() *PlannedConfigChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedConfigChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreUpgradeBackup) DeepCopyInto(out *PreUpgradeBackup) {
+	*out = *in
+	in.BackupStorage.DeepCopyInto(&out.BackupStorage)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreUpgradeBackup.
+func (in *PreUpgradeBackup) DeepCopy() *PreUpgradeBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(PreUpgradeBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PxeClientSpec) DeepCopyInto(out *PxeClientSpec) {
 	*out = *in
@@ -316,6 +864,11 @@ func (in *PxeServerSpec) DeepCopyInto(out *PxeServerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DiscoveryRange != nil {
+		in, out := &in.DiscoveryRange, &out.DiscoveryRange
+		*out = new(DHCPRange)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PxeServerSpec.
@@ -328,6 +881,36 @@ func (in *PxeServerSpec) DeepCopy() *PxeServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResetSpec) DeepCopyInto(out *ResetSpec) {
+	*out = *in
+	if in.SystemPartitionsToWipe != nil {
+		in, out := &in.SystemPartitionsToWipe, &out.SystemPartitionsToWipe
+		*out = make([]SystemPartitionLabel, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserDisksToWipe != nil {
+		in, out := &in.UserDisksToWipe, &out.UserDisksToWipe
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResetSpec.
+func (in *ResetSpec) DeepCopy() *ResetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpdateRolloutStrategy) DeepCopyInto(out *RollingUpdateRolloutStrategy) {
 	*out = *in
@@ -336,6 +919,11 @@ func (in *RollingUpdateRolloutStrategy) DeepCopyInto(out *RollingUpdateRolloutSt
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.HealthTimeout != nil {
+		in, out := &in.HealthTimeout, &out.HealthTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateRolloutStrategy.
@@ -348,6 +936,26 @@ func (in *RollingUpdateRolloutStrategy) DeepCopy() *RollingUpdateRolloutStrategy
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.Machines != nil {
+		in, out := &in.Machines, &out.Machines
+		*out = make([]MachineRolloutStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 	*out = *in
@@ -356,6 +964,11 @@ func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 		*out = new(RollingUpdateRolloutStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(PartitionRolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
@@ -461,26 +1074,716 @@ func (in *TalosClusterAddonList) DeepCopyInto(out *TalosClusterAddonList) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonList.
-func (in *TalosClusterAddonList) DeepCopy() *TalosClusterAddonList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonList.
+func (in *TalosClusterAddonList) DeepCopy() *TalosClusterAddonList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosClusterAddonList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonRelease) DeepCopyInto(out *TalosClusterAddonRelease) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonRelease.
+func (in *TalosClusterAddonRelease) DeepCopy() *TalosClusterAddonRelease {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonRelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosClusterAddonRelease) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonReleaseList) DeepCopyInto(out *TalosClusterAddonReleaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosClusterAddonRelease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseList.
+func (in *TalosClusterAddonReleaseList) DeepCopy() *TalosClusterAddonReleaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonReleaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosClusterAddonReleaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonReleaseSpec) DeepCopyInto(out *TalosClusterAddonReleaseSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	in.HelmSpec.DeepCopyInto(&out.HelmSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseSpec.
+func (in *TalosClusterAddonReleaseSpec) DeepCopy() *TalosClusterAddonReleaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonReleaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonReleaseStatus) DeepCopyInto(out *TalosClusterAddonReleaseStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseStatus.
+func (in *TalosClusterAddonReleaseStatus) DeepCopy() *TalosClusterAddonReleaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonReleaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonSpec) DeepCopyInto(out *TalosClusterAddonSpec) {
+	*out = *in
+	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+	in.HelmSpec.DeepCopyInto(&out.HelmSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonSpec.
+func (in *TalosClusterAddonSpec) DeepCopy() *TalosClusterAddonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterAddonStatus) DeepCopyInto(out *TalosClusterAddonStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonStatus.
+func (in *TalosClusterAddonStatus) DeepCopy() *TalosClusterAddonStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterAddonStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterList) DeepCopyInto(out *TalosClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterList.
+func (in *TalosClusterList) DeepCopy() *TalosClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterSpec) DeepCopyInto(out *TalosClusterSpec) {
+	*out = *in
+	if in.ControlPlane != nil {
+		in, out := &in.ControlPlane, &out.ControlPlane
+		*out = new(TalosControlPlaneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneRef != nil {
+		in, out := &in.ControlPlaneRef, &out.ControlPlaneRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Worker != nil {
+		in, out := &in.Worker, &out.Worker
+		*out = new(TalosWorkerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkerRef != nil {
+		in, out := &in.WorkerRef, &out.WorkerRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.PxeServerSpec != nil {
+		in, out := &in.PxeServerSpec, &out.PxeServerSpec
+		*out = new(PxeServerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterSpec.
+func (in *TalosClusterSpec) DeepCopy() *TalosClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosClusterStatus) DeepCopyInto(out *TalosClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterStatus.
+func (in *TalosClusterStatus) DeepCopy() *TalosClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosControlPlane) DeepCopyInto(out *TalosControlPlane) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlane.
+func (in *TalosControlPlane) DeepCopy() *TalosControlPlane {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosControlPlane)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosControlPlane) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosControlPlaneList) DeepCopyInto(out *TalosControlPlaneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosControlPlane, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneList.
+func (in *TalosControlPlaneList) DeepCopy() *TalosControlPlaneList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosControlPlaneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosControlPlaneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosControlPlaneSpec) DeepCopyInto(out *TalosControlPlaneSpec) {
+	*out = *in
+	in.MetalSpec.DeepCopyInto(&out.MetalSpec)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PodCIDR != nil {
+		in, out := &in.PodCIDR, &out.PodCIDR
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceCIDR != nil {
+		in, out := &in.ServiceCIDR, &out.ServiceCIDR
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigRef != nil {
+		in, out := &in.ConfigRef, &out.ConfigRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CNI != nil {
+		in, out := &in.CNI, &out.CNI
+		*out = new(CNIConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reset != nil {
+		in, out := &in.Reset, &out.Reset
+		*out = new(ResetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreUpgradeBackup != nil {
+		in, out := &in.PreUpgradeBackup, &out.PreUpgradeBackup
+		*out = new(PreUpgradeBackup)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneSpec.
+func (in *TalosControlPlaneSpec) DeepCopy() *TalosControlPlaneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosControlPlaneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosControlPlaneStatus) DeepCopyInto(out *TalosControlPlaneStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigSecretRef != nil {
+		in, out := &in.ConfigSecretRef, &out.ConfigSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretBundleRef != nil {
+		in, out := &in.SecretBundleRef, &out.SecretBundleRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Imported != nil {
+		in, out := &in.Imported, &out.Imported
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClaimedHosts != nil {
+		in, out := &in.ClaimedHosts, &out.ClaimedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneStatus.
+func (in *TalosControlPlaneStatus) DeepCopy() *TalosControlPlaneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosControlPlaneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackup) DeepCopyInto(out *TalosEtcdBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackup.
+func (in *TalosEtcdBackup) DeepCopy() *TalosEtcdBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosEtcdBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupList) DeepCopyInto(out *TalosEtcdBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosEtcdBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupList.
+func (in *TalosEtcdBackupList) DeepCopy() *TalosEtcdBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosEtcdBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupSchedule) DeepCopyInto(out *TalosEtcdBackupSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupSchedule.
+func (in *TalosEtcdBackupSchedule) DeepCopy() *TalosEtcdBackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosEtcdBackupSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupScheduleList) DeepCopyInto(out *TalosEtcdBackupScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosEtcdBackupSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleList.
+func (in *TalosEtcdBackupScheduleList) DeepCopy() *TalosEtcdBackupScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosEtcdBackupScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupScheduleSpec) DeepCopyInto(out *TalosEtcdBackupScheduleSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.BackupTemplate.DeepCopyInto(&out.BackupTemplate)
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(BackupRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleSpec.
+func (in *TalosEtcdBackupScheduleSpec) DeepCopy() *TalosEtcdBackupScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupScheduleStatus) DeepCopyInto(out *TalosEtcdBackupScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulBackupTime != nil {
+		in, out := &in.LastSuccessfulBackupTime, &out.LastSuccessfulBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ActiveBackups != nil {
+		in, out := &in.ActiveBackups, &out.ActiveBackups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MissedSchedules != nil {
+		in, out := &in.MissedSchedules, &out.MissedSchedules
+		*out = make([]MissedSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleStatus.
+func (in *TalosEtcdBackupScheduleStatus) DeepCopy() *TalosEtcdBackupScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupSpec) DeepCopyInto(out *TalosEtcdBackupSpec) {
+	*out = *in
+	if in.TalosControlPlaneRef != nil {
+		in, out := &in.TalosControlPlaneRef, &out.TalosControlPlaneRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	in.BackupStorage.DeepCopyInto(&out.BackupStorage)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupSpec.
+func (in *TalosEtcdBackupSpec) DeepCopy() *TalosEtcdBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TalosEtcdBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupStatus) DeepCopyInto(out *TalosEtcdBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VerificationTime != nil {
+		in, out := &in.VerificationTime, &out.VerificationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupStatus.
+func (in *TalosEtcdBackupStatus) DeepCopy() *TalosEtcdBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonList)
+	out := new(TalosEtcdBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosClusterAddonList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TalosEtcdBackupTemplateSpec) DeepCopyInto(out *TalosEtcdBackupTemplateSpec) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupTemplateSpec.
+func (in *TalosEtcdBackupTemplateSpec) DeepCopy() *TalosEtcdBackupTemplateSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(TalosEtcdBackupTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonRelease) DeepCopyInto(out *TalosClusterAddonRelease) {
+func (in *TalosEtcdRestore) DeepCopyInto(out *TalosEtcdRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -488,18 +1791,18 @@ func (in *TalosClusterAddonRelease) DeepCopyInto(out *TalosClusterAddonRelease)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonRelease.
-func (in *TalosClusterAddonRelease) DeepCopy() *TalosClusterAddonRelease {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdRestore.
+func (in *TalosEtcdRestore) DeepCopy() *TalosEtcdRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonRelease)
+	out := new(TalosEtcdRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosClusterAddonRelease) DeepCopyObject() runtime.Object {
+func (in *TalosEtcdRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -507,31 +1810,31 @@ func (in *TalosClusterAddonRelease) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonReleaseList) DeepCopyInto(out *TalosClusterAddonReleaseList) {
+func (in *TalosEtcdRestoreList) DeepCopyInto(out *TalosEtcdRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TalosClusterAddonRelease, len(*in))
+		*out = make([]TalosEtcdRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseList.
-func (in *TalosClusterAddonReleaseList) DeepCopy() *TalosClusterAddonReleaseList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdRestoreList.
+func (in *TalosEtcdRestoreList) DeepCopy() *TalosEtcdRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonReleaseList)
+	out := new(TalosEtcdRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosClusterAddonReleaseList) DeepCopyObject() runtime.Object {
+func (in *TalosEtcdRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -539,25 +1842,46 @@ func (in *TalosClusterAddonReleaseList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonReleaseSpec) DeepCopyInto(out *TalosClusterAddonReleaseSpec) {
+func (in *TalosEtcdRestoreSpec) DeepCopyInto(out *TalosEtcdRestoreSpec) {
 	*out = *in
-	out.ClusterRef = in.ClusterRef
-	in.HelmSpec.DeepCopyInto(&out.HelmSpec)
+	if in.TalosControlPlaneRef != nil {
+		in, out := &in.TalosControlPlaneRef, &out.TalosControlPlaneRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.BackupRef != nil {
+		in, out := &in.BackupRef, &out.BackupRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(EtcdRestoreSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseSpec.
-func (in *TalosClusterAddonReleaseSpec) DeepCopy() *TalosClusterAddonReleaseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdRestoreSpec.
+func (in *TalosEtcdRestoreSpec) DeepCopy() *TalosEtcdRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonReleaseSpec)
+	out := new(TalosEtcdRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonReleaseStatus) DeepCopyInto(out *TalosClusterAddonReleaseStatus) {
+func (in *TalosEtcdRestoreStatus) DeepCopyInto(out *TalosEtcdRestoreStatus) {
 	*out = *in
+	if in.RecoveryStartTime != nil {
+		in, out := &in.RecoveryStartTime, &out.RecoveryStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RecoveryTime != nil {
+		in, out := &in.RecoveryTime, &out.RecoveryTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -567,81 +1891,96 @@ func (in *TalosClusterAddonReleaseStatus) DeepCopyInto(out *TalosClusterAddonRel
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonReleaseStatus.
-func (in *TalosClusterAddonReleaseStatus) DeepCopy() *TalosClusterAddonReleaseStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdRestoreStatus.
+func (in *TalosEtcdRestoreStatus) DeepCopy() *TalosEtcdRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonReleaseStatus)
+	out := new(TalosEtcdRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonSpec) DeepCopyInto(out *TalosClusterAddonSpec) {
+func (in *TalosHost) DeepCopyInto(out *TalosHost) {
 	*out = *in
-	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
-	in.HelmSpec.DeepCopyInto(&out.HelmSpec)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonSpec.
-func (in *TalosClusterAddonSpec) DeepCopy() *TalosClusterAddonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHost.
+func (in *TalosHost) DeepCopy() *TalosHost {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonSpec)
+	out := new(TalosHost)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosHost) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterAddonStatus) DeepCopyInto(out *TalosClusterAddonStatus) {
+func (in *TalosHostDiscovery) DeepCopyInto(out *TalosHostDiscovery) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterAddonStatus.
-func (in *TalosClusterAddonStatus) DeepCopy() *TalosClusterAddonStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostDiscovery.
+func (in *TalosHostDiscovery) DeepCopy() *TalosHostDiscovery {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterAddonStatus)
+	out := new(TalosHostDiscovery)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosHostDiscovery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterList) DeepCopyInto(out *TalosClusterList) {
+func (in *TalosHostDiscoveryList) DeepCopyInto(out *TalosHostDiscoveryList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TalosCluster, len(*in))
+		*out = make([]TalosHostDiscovery, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterList.
-func (in *TalosClusterList) DeepCopy() *TalosClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostDiscoveryList.
+func (in *TalosHostDiscoveryList) DeepCopy() *TalosHostDiscoveryList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterList)
+	out := new(TalosHostDiscoveryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosClusterList) DeepCopyObject() runtime.Object {
+func (in *TalosHostDiscoveryList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -649,47 +1988,39 @@ func (in *TalosClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterSpec) DeepCopyInto(out *TalosClusterSpec) {
+func (in *TalosHostDiscoverySpec) DeepCopyInto(out *TalosHostDiscoverySpec) {
 	*out = *in
-	if in.ControlPlane != nil {
-		in, out := &in.ControlPlane, &out.ControlPlane
-		*out = new(TalosControlPlaneSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ControlPlaneRef != nil {
-		in, out := &in.ControlPlaneRef, &out.ControlPlaneRef
-		*out = new(v1.LocalObjectReference)
-		**out = **in
-	}
-	if in.Worker != nil {
-		in, out := &in.Worker, &out.Worker
-		*out = new(TalosWorkerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.WorkerRef != nil {
-		in, out := &in.WorkerRef, &out.WorkerRef
-		*out = new(v1.LocalObjectReference)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.PxeServerSpec != nil {
-		in, out := &in.PxeServerSpec, &out.PxeServerSpec
-		*out = new(PxeServerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.HostLabels != nil {
+		in, out := &in.HostLabels, &out.HostLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterSpec.
-func (in *TalosClusterSpec) DeepCopy() *TalosClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostDiscoverySpec.
+func (in *TalosHostDiscoverySpec) DeepCopy() *TalosHostDiscoverySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosClusterSpec)
+	out := new(TalosHostDiscoverySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosClusterStatus) DeepCopyInto(out *TalosClusterStatus) {
+func (in *TalosHostDiscoveryStatus) DeepCopyInto(out *TalosHostDiscoveryStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -698,71 +2029,48 @@ func (in *TalosClusterStatus) DeepCopyInto(out *TalosClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosClusterStatus.
-func (in *TalosClusterStatus) DeepCopy() *TalosClusterStatus {
-	if in == nil {
-		return nil
+	if in.LastScanTime != nil {
+		in, out := &in.LastScanTime, &out.LastScanTime
+		*out = (*in).DeepCopy()
 	}
-	out := new(TalosClusterStatus)
-	in.DeepCopyInto(out)
-	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosControlPlane) DeepCopyInto(out *TalosControlPlane) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlane.
-func (in *TalosControlPlane) DeepCopy() *TalosControlPlane {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostDiscoveryStatus.
+func (in *TalosHostDiscoveryStatus) DeepCopy() *TalosHostDiscoveryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosControlPlane)
+	out := new(TalosHostDiscoveryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosControlPlane) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosControlPlaneList) DeepCopyInto(out *TalosControlPlaneList) {
+func (in *TalosHostList) DeepCopyInto(out *TalosHostList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TalosControlPlane, len(*in))
+		*out = make([]TalosHost, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneList.
-func (in *TalosControlPlaneList) DeepCopy() *TalosControlPlaneList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostList.
+func (in *TalosHostList) DeepCopy() *TalosHostList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosControlPlaneList)
+	out := new(TalosHostList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosControlPlaneList) DeepCopyObject() runtime.Object {
+func (in *TalosHostList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -770,53 +2078,37 @@ func (in *TalosControlPlaneList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosControlPlaneSpec) DeepCopyInto(out *TalosControlPlaneSpec) {
+func (in *TalosHostSpec) DeepCopyInto(out *TalosHostSpec) {
 	*out = *in
-	in.MetalSpec.DeepCopyInto(&out.MetalSpec)
-	if in.StorageClassName != nil {
-		in, out := &in.StorageClassName, &out.StorageClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.PodCIDR != nil {
-		in, out := &in.PodCIDR, &out.PodCIDR
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ServiceCIDR != nil {
-		in, out := &in.ServiceCIDR, &out.ServiceCIDR
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ConfigRef != nil {
-		in, out := &in.ConfigRef, &out.ConfigRef
-		*out = new(v1.ConfigMapKeySelector)
+	if in.Hardware != nil {
+		in, out := &in.Hardware, &out.Hardware
+		*out = new(Hardware)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CNI != nil {
-		in, out := &in.CNI, &out.CNI
-		*out = new(CNIConfig)
+	if in.BMC != nil {
+		in, out := &in.BMC, &out.BMC
+		*out = new(BMCSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.RolloutStrategy != nil {
-		in, out := &in.RolloutStrategy, &out.RolloutStrategy
-		*out = new(RolloutStrategy)
-		(*in).DeepCopyInto(*out)
+	if in.ClaimedBy != nil {
+		in, out := &in.ClaimedBy, &out.ClaimedBy
+		*out = new(v1.ObjectReference)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneSpec.
-func (in *TalosControlPlaneSpec) DeepCopy() *TalosControlPlaneSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostSpec.
+func (in *TalosHostSpec) DeepCopy() *TalosHostSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosControlPlaneSpec)
+	out := new(TalosHostSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosControlPlaneStatus) DeepCopyInto(out *TalosControlPlaneStatus) {
+func (in *TalosHostStatus) DeepCopyInto(out *TalosHostStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -825,25 +2117,20 @@ func (in *TalosControlPlaneStatus) DeepCopyInto(out *TalosControlPlaneStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Imported != nil {
-		in, out := &in.Imported, &out.Imported
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosControlPlaneStatus.
-func (in *TalosControlPlaneStatus) DeepCopy() *TalosControlPlaneStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosHostStatus.
+func (in *TalosHostStatus) DeepCopy() *TalosHostStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosControlPlaneStatus)
+	out := new(TalosHostStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackup) DeepCopyInto(out *TalosEtcdBackup) {
+func (in *TalosMachine) DeepCopyInto(out *TalosMachine) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -851,50 +2138,18 @@ func (in *TalosEtcdBackup) DeepCopyInto(out *TalosEtcdBackup) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackup.
-func (in *TalosEtcdBackup) DeepCopy() *TalosEtcdBackup {
-	if in == nil {
-		return nil
-	}
-	out := new(TalosEtcdBackup)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosEtcdBackup) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupList) DeepCopyInto(out *TalosEtcdBackupList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]TalosEtcdBackup, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupList.
-func (in *TalosEtcdBackupList) DeepCopy() *TalosEtcdBackupList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachine.
+func (in *TalosMachine) DeepCopy() *TalosMachine {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupList)
+	out := new(TalosMachine)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosEtcdBackupList) DeepCopyObject() runtime.Object {
+func (in *TalosMachine) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -902,7 +2157,7 @@ func (in *TalosEtcdBackupList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupSchedule) DeepCopyInto(out *TalosEtcdBackupSchedule) {
+func (in *TalosMachineHealthCheck) DeepCopyInto(out *TalosMachineHealthCheck) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -910,18 +2165,18 @@ func (in *TalosEtcdBackupSchedule) DeepCopyInto(out *TalosEtcdBackupSchedule) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupSchedule.
-func (in *TalosEtcdBackupSchedule) DeepCopy() *TalosEtcdBackupSchedule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineHealthCheck.
+func (in *TalosMachineHealthCheck) DeepCopy() *TalosMachineHealthCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupSchedule)
+	out := new(TalosMachineHealthCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosEtcdBackupSchedule) DeepCopyObject() runtime.Object {
+func (in *TalosMachineHealthCheck) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -929,31 +2184,31 @@ func (in *TalosEtcdBackupSchedule) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupScheduleList) DeepCopyInto(out *TalosEtcdBackupScheduleList) {
+func (in *TalosMachineHealthCheckList) DeepCopyInto(out *TalosMachineHealthCheckList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TalosEtcdBackupSchedule, len(*in))
+		*out = make([]TalosMachineHealthCheck, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleList.
-func (in *TalosEtcdBackupScheduleList) DeepCopy() *TalosEtcdBackupScheduleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineHealthCheckList.
+func (in *TalosMachineHealthCheckList) DeepCopy() *TalosMachineHealthCheckList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupScheduleList)
+	out := new(TalosMachineHealthCheckList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosEtcdBackupScheduleList) DeepCopyObject() runtime.Object {
+func (in *TalosMachineHealthCheckList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -961,45 +2216,52 @@ func (in *TalosEtcdBackupScheduleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupScheduleSpec) DeepCopyInto(out *TalosEtcdBackupScheduleSpec) {
+func (in *TalosMachineHealthCheckSpec) DeepCopyInto(out *TalosMachineHealthCheckSpec) {
 	*out = *in
-	in.BackupTemplate.DeepCopyInto(&out.BackupTemplate)
-	if in.Retention != nil {
-		in, out := &in.Retention, &out.Retention
-		*out = new(int32)
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OwnerRef != nil {
+		in, out := &in.OwnerRef, &out.OwnerRef
+		*out = new(HealthCheckOwnerReference)
+		**out = **in
+	}
+	if in.UnhealthyConditions != nil {
+		in, out := &in.UnhealthyConditions, &out.UnhealthyConditions
+		*out = make([]UnhealthyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Remediation = in.Remediation
+	if in.MaxUnhealthy != nil {
+		in, out := &in.MaxUnhealthy, &out.MaxUnhealthy
+		*out = new(intstr.IntOrString)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleSpec.
-func (in *TalosEtcdBackupScheduleSpec) DeepCopy() *TalosEtcdBackupScheduleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineHealthCheckSpec.
+func (in *TalosMachineHealthCheckSpec) DeepCopy() *TalosMachineHealthCheckSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupScheduleSpec)
+	out := new(TalosMachineHealthCheckSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupScheduleStatus) DeepCopyInto(out *TalosEtcdBackupScheduleStatus) {
+func (in *TalosMachineHealthCheckStatus) DeepCopyInto(out *TalosMachineHealthCheckStatus) {
 	*out = *in
-	if in.LastScheduleTime != nil {
-		in, out := &in.LastScheduleTime, &out.LastScheduleTime
-		*out = (*in).DeepCopy()
-	}
-	if in.LastSuccessfulBackupTime != nil {
-		in, out := &in.LastSuccessfulBackupTime, &out.LastSuccessfulBackupTime
-		*out = (*in).DeepCopy()
-	}
-	if in.NextScheduleTime != nil {
-		in, out := &in.NextScheduleTime, &out.NextScheduleTime
-		*out = (*in).DeepCopy()
-	}
-	if in.ActiveBackups != nil {
-		in, out := &in.ActiveBackups, &out.ActiveBackups
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Machines != nil {
+		in, out := &in.Machines, &out.Machines
+		*out = make([]MachineHealthStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1010,77 +2272,165 @@ func (in *TalosEtcdBackupScheduleStatus) DeepCopyInto(out *TalosEtcdBackupSchedu
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupScheduleStatus.
-func (in *TalosEtcdBackupScheduleStatus) DeepCopy() *TalosEtcdBackupScheduleStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineHealthCheckStatus.
+func (in *TalosMachineHealthCheckStatus) DeepCopy() *TalosMachineHealthCheckStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupScheduleStatus)
+	out := new(TalosMachineHealthCheckStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupSpec) DeepCopyInto(out *TalosEtcdBackupSpec) {
+func (in *TalosMachineList) DeepCopyInto(out *TalosMachineList) {
 	*out = *in
-	if in.TalosControlPlaneRef != nil {
-		in, out := &in.TalosControlPlaneRef, &out.TalosControlPlaneRef
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TalosMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	in.BackupStorage.DeepCopyInto(&out.BackupStorage)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupSpec.
-func (in *TalosEtcdBackupSpec) DeepCopy() *TalosEtcdBackupSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineList.
+func (in *TalosMachineList) DeepCopy() *TalosMachineList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupSpec)
+	out := new(TalosMachineList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TalosMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupStatus) DeepCopyInto(out *TalosEtcdBackupStatus) {
+func (in *TalosMachineSpec) DeepCopyInto(out *TalosMachineSpec) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MachineSpec != nil {
+		in, out := &in.MachineSpec, &out.MachineSpec
+		*out = new(MachineSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneRef != nil {
+		in, out := &in.ControlPlaneRef, &out.ControlPlaneRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.WorkerRef != nil {
+		in, out := &in.WorkerRef, &out.WorkerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.ConfigRef != nil {
+		in, out := &in.ConfigRef, &out.ConfigRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reset != nil {
+		in, out := &in.Reset, &out.Reset
+		*out = new(ResetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PxeClientSpec != nil {
+		in, out := &in.PxeClientSpec, &out.PxeClientSpec
+		*out = new(PxeClientSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMC != nil {
+		in, out := &in.BMC, &out.BMC
+		*out = new(BMCSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeSpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupStatus.
-func (in *TalosEtcdBackupStatus) DeepCopy() *TalosEtcdBackupStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineSpec.
+func (in *TalosMachineSpec) DeepCopy() *TalosMachineSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupStatus)
+	out := new(TalosMachineSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosEtcdBackupTemplateSpec) DeepCopyInto(out *TalosEtcdBackupTemplateSpec) {
+func (in *TalosMachineStatus) DeepCopyInto(out *TalosMachineStatus) {
 	*out = *in
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.ConfigSecretRef != nil {
+		in, out := &in.ConfigSecretRef, &out.ConfigSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Imported != nil {
+		in, out := &in.Imported, &out.Imported
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpgradeStartTime != nil {
+		in, out := &in.UpgradeStartTime, &out.UpgradeStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainStartTime != nil {
+		in, out := &in.DrainStartTime, &out.DrainStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Hardware != nil {
+		in, out := &in.Hardware, &out.Hardware
+		*out = new(Hardware)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMC != nil {
+		in, out := &in.BMC, &out.BMC
+		*out = new(BMCStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlannedConfig != nil {
+		in, out := &in.PlannedConfig, &out.PlannedConfig
+		*out = new(PlannedConfigChange)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosEtcdBackupTemplateSpec.
-func (in *TalosEtcdBackupTemplateSpec) DeepCopy() *TalosEtcdBackupTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineStatus.
+func (in *TalosMachineStatus) DeepCopy() *TalosMachineStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosEtcdBackupTemplateSpec)
+	out := new(TalosMachineStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosMachine) DeepCopyInto(out *TalosMachine) {
+func (in *TalosUpgradePlan) DeepCopyInto(out *TalosUpgradePlan) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1088,18 +2438,18 @@ func (in *TalosMachine) DeepCopyInto(out *TalosMachine) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachine.
-func (in *TalosMachine) DeepCopy() *TalosMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosUpgradePlan.
+func (in *TalosUpgradePlan) DeepCopy() *TalosUpgradePlan {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosMachine)
+	out := new(TalosUpgradePlan)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosMachine) DeepCopyObject() runtime.Object {
+func (in *TalosUpgradePlan) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1107,31 +2457,31 @@ func (in *TalosMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosMachineList) DeepCopyInto(out *TalosMachineList) {
+func (in *TalosUpgradePlanList) DeepCopyInto(out *TalosUpgradePlanList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TalosMachine, len(*in))
+		*out = make([]TalosUpgradePlan, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineList.
-func (in *TalosMachineList) DeepCopy() *TalosMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosUpgradePlanList.
+func (in *TalosUpgradePlanList) DeepCopy() *TalosUpgradePlanList {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosMachineList)
+	out := new(TalosUpgradePlanList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TalosMachineList) DeepCopyObject() runtime.Object {
+func (in *TalosUpgradePlanList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1139,52 +2489,32 @@ func (in *TalosMachineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosMachineSpec) DeepCopyInto(out *TalosMachineSpec) {
+func (in *TalosUpgradePlanSpec) DeepCopyInto(out *TalosUpgradePlanSpec) {
 	*out = *in
-	if in.MachineSpec != nil {
-		in, out := &in.MachineSpec, &out.MachineSpec
-		*out = new(MachineSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ControlPlaneRef != nil {
-		in, out := &in.ControlPlaneRef, &out.ControlPlaneRef
-		*out = new(v1.ObjectReference)
-		**out = **in
-	}
-	if in.WorkerRef != nil {
-		in, out := &in.WorkerRef, &out.WorkerRef
-		*out = new(v1.ObjectReference)
+	if in.TalosControlPlaneRef != nil {
+		in, out := &in.TalosControlPlaneRef, &out.TalosControlPlaneRef
+		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
-	if in.ConfigRef != nil {
-		in, out := &in.ConfigRef, &out.ConfigRef
-		*out = new(v1.ConfigMapKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PxeClientSpec != nil {
-		in, out := &in.PxeClientSpec, &out.PxeClientSpec
-		*out = new(PxeClientSpec)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineSpec.
-func (in *TalosMachineSpec) DeepCopy() *TalosMachineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosUpgradePlanSpec.
+func (in *TalosUpgradePlanSpec) DeepCopy() *TalosUpgradePlanSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosMachineSpec)
+	out := new(TalosUpgradePlanSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TalosMachineStatus) DeepCopyInto(out *TalosMachineStatus) {
+func (in *TalosUpgradePlanStatus) DeepCopyInto(out *TalosUpgradePlanStatus) {
 	*out = *in
-	if in.Imported != nil {
-		in, out := &in.Imported, &out.Imported
-		*out = new(bool)
-		**out = **in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]PlannedAction, len(*in))
+		copy(*out, *in)
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1195,12 +2525,12 @@ func (in *TalosMachineStatus) DeepCopyInto(out *TalosMachineStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosMachineStatus.
-func (in *TalosMachineStatus) DeepCopy() *TalosMachineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosUpgradePlanStatus.
+func (in *TalosUpgradePlanStatus) DeepCopy() *TalosUpgradePlanStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TalosMachineStatus)
+	out := new(TalosUpgradePlanStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1279,11 +2609,33 @@ func (in *TalosWorkerSpec) DeepCopyInto(out *TalosWorkerSpec) {
 		*out = new(v1.ConfigMapKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Reset != nil {
+		in, out := &in.Reset, &out.Reset
+		*out = new(ResetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RolloutStrategy != nil {
 		in, out := &in.RolloutStrategy, &out.RolloutStrategy
 		*out = new(RolloutStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosWorkerSpec.
@@ -1306,11 +2658,30 @@ func (in *TalosWorkerStatus) DeepCopyInto(out *TalosWorkerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConfigSecretRef != nil {
+		in, out := &in.ConfigSecretRef, &out.ConfigSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Imported != nil {
 		in, out := &in.Imported, &out.Imported
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClaimedHosts != nil {
+		in, out := &in.ClaimedHosts, &out.ClaimedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TalosWorkerStatus.
@@ -1322,3 +2693,44 @@ func (in *TalosWorkerStatus) DeepCopy() *TalosWorkerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyCondition.
+func (in *UnhealthyCondition) DeepCopy() *UnhealthyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeSpec) DeepCopyInto(out *UpgradeSpec) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeSpec.
+func (in *UpgradeSpec) DeepCopy() *UpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,11 +19,13 @@ package main
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"path"
+	"strconv"
+	// Embed the time zone database so backup schedules can use any IANA time zone
+	// regardless of what is installed in the image.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -44,6 +46,9 @@ import (
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
 	"github.com/alperencelik/talos-operator/internal/controller"
+	operatormetrics "github.com/alperencelik/talos-operator/internal/metrics"
+	webhookv1alpha1 "github.com/alperencelik/talos-operator/internal/webhook/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
 	"github.com/alperencelik/talos-operator/pkg/talos"
 	"github.com/alperencelik/talos-operator/pkg/tracing"
 	// +kubebuilder:scaffold:imports
@@ -87,6 +92,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var webhookPort int
 	var enableTracing bool
 	var otlpEndpoint string
 	var tlsOpts []func(*tls.Config)
@@ -100,6 +106,7 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", true,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the admission webhook server listens on.")
 	flag.BoolVar(&enableTracing, "enable-tracing", false,
 		"Enable distributed tracing via OpenTelemetry and operatortrace.")
 	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "localhost:4318",
@@ -125,6 +132,7 @@ func main() {
 	}
 
 	webhookServer := webhook.NewServer(webhook.Options{
+		Port:    webhookPort,
 		TLSOpts: tlsOpts,
 	})
 
@@ -190,10 +198,34 @@ func main() {
 		k8sClient = mgr.GetClient()
 	}
 
+	// The PXE boot service answers the DHCP, TFTP and HTTP requests of the metal machines. It runs
+	// with the manager, the reconcilers replace its configuration.
+	var bootServer *pxe.Server
+	if os.Getenv("ENABLE_PXE_BOOT_STACK") == controller.PxeBootStackEnabled {
+		httpPort := pxe.DefaultHTTPPort
+		if port := os.Getenv("PXE_HTTP_PORT"); port != "" {
+			if httpPort, err = strconv.Atoi(port); err != nil {
+				setupLog.Error(err, "invalid PXE_HTTP_PORT", "port", port)
+				os.Exit(1)
+			}
+		}
+		bootServer = pxe.NewServer(pxe.Options{
+			HTTPPort:           httpPort,
+			AssetsDir:          os.Getenv("PXE_ASSETS_DIR"),
+			TalosImagesBaseURL: os.Getenv("TALOS_IMAGES_BASE_URL"),
+			IPXEBaseURL:        os.Getenv("IPXE_BASE_URL"),
+		})
+		if err := mgr.Add(bootServer); err != nil {
+			setupLog.Error(err, "unable to add PXE boot service")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.TalosClusterReconciler{
-		Client:   k8sClient,
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("taloscluster-controller"),
+		Client:     k8sClient,
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("taloscluster-controller"),
+		BootServer: bootServer,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TalosCluster")
 		os.Exit(1)
@@ -215,9 +247,10 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controller.TalosMachineReconciler{
-		Client:   k8sClient,
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("talosmachine-controller"),
+		Client:     k8sClient,
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("talosmachine-controller"),
+		BootServer: bootServer,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TalosMachine")
 		os.Exit(1)
@@ -236,6 +269,30 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "TalosEtcdBackupSchedule")
 		os.Exit(1)
 	}
+	if err := (&controller.TalosEtcdRestoreReconciler{
+		Client:   k8sClient,
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("talosetcdrestore-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TalosEtcdRestore")
+		os.Exit(1)
+	}
+	if err := (&controller.TalosUpgradePlanReconciler{
+		Client:   k8sClient,
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("talosupgradeplan-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TalosUpgradePlan")
+		os.Exit(1)
+	}
+	if err := (&controller.TalosMachineHealthCheckReconciler{
+		Client:   k8sClient,
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("talosmachinehealthcheck-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TalosMachineHealthCheck")
+		os.Exit(1)
+	}
 	if err := (&controller.TalosClusterAddonReconciler{
 		Client:   k8sClient,
 		Scheme:   mgr.GetScheme(),
@@ -252,8 +309,38 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "TalosClusterAddonRelease")
 		os.Exit(1)
 	}
+	if err := (&controller.TalosHostDiscoveryReconciler{
+		Client:     k8sClient,
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("taloshostdiscovery-controller"),
+		BootServer: bootServer,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TalosHostDiscovery")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		webhooks := map[string]func(ctrl.Manager) error{
+			"TalosCluster":            webhookv1alpha1.SetupTalosClusterWebhookWithManager,
+			"TalosControlPlane":       webhookv1alpha1.SetupTalosControlPlaneWebhookWithManager,
+			"TalosWorker":             webhookv1alpha1.SetupTalosWorkerWebhookWithManager,
+			"TalosMachine":            webhookv1alpha1.SetupTalosMachineWebhookWithManager,
+			"TalosEtcdBackupSchedule": webhookv1alpha1.SetupTalosEtcdBackupScheduleWebhookWithManager,
+		}
+		for kind, setup := range webhooks {
+			if err := setup(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", kind)
+				os.Exit(1)
+			}
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err := operatormetrics.RegisterCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -263,26 +350,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	if os.Getenv("ENABLE_PXE_BOOT_STACK") == controller.PxeBootStackEnabled {
-		// Create Matchbox configuration directories if they don't exist already
-		for _, dir := range []string{
-			controller.MatchboxAssetsDir, controller.MatchboxGroupsDir, controller.MatchboxProfilesDir,
-		} {
-			var path = path.Join(controller.MatchboxConfigPath, dir)
-			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-				if err := os.Mkdir(path, os.ModePerm); err != nil {
-					setupLog.Error(err, "unable to create Matchbox configuration directory", "directory", path)
-				}
-			}
-		}
-		// Writing base configuration for dnsmasq
-		if err := os.WriteFile(controller.DnsmasqConfigPath,
-			[]byte(controller.DefaultDnsmasqConfig), os.ModePerm,
-		); err != nil {
-			setupLog.Error(err, "unable to create dnsmasq base configuration")
-		}
-	}
-
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -60,3 +60,21 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Name of the webhook service
+*/}}
+{{- define "talos-operator.webhookServiceName" -}}
+{{- printf "%s-webhook" (include "talos-operator.fullname" .) | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Name of the secret holding the webhook serving certificate
+*/}}
+{{- define "talos-operator.webhookCertSecretName" -}}
+{{- if .Values.webhook.certManager.enabled }}
+{{- printf "%s-webhook-cert" (include "talos-operator.fullname" .) | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- required "webhook.existingSecret is required when webhook.certManager.enabled is false" .Values.webhook.existingSecret }}
+{{- end }}
+{{- end }}
@@ -1,331 +1,116 @@
 package controller
 
 import (
-	_ "embed"
-	"errors"
+	"context"
 	"fmt"
-	"html/template"
-	"io"
-	"net/http"
-	"os"
-	"path"
-	"path/filepath"
-	"strconv"
-	"syscall"
+	"net"
+	"net/netip"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
 )
 
-// Represents a Talos machine's PXE specifications
-type Machine struct {
-	Id                string
-	MacAddress        string
-	IpAddress         string
-	TalosVersion      string
-	CpuArchitecture   string
-	KernelCmdlineArgs string
-}
-
-// Represents a Talos cluster's PXE specifications
-type Cluster struct {
-	Name         string
-	PxeInterface string
-	PxeIpAddress string
-	MatchboxPort string
-	Machines     []Machine
-}
-
-// Loading configuration templates
-//
-//go:embed templates/dnsmasq-config.conf
-var dnsmasqConfigTmpl string
-
-//go:embed templates/matchbox-group.json
-var matchboxGroupTmpl string
-
-//go:embed templates/matchbox-profile.json
-var matchboxProfileTmpl string
-
-func getClustersPxeSpecs(tcList talosv1alpha1.TalosClusterList) []Cluster {
-	var clusters []Cluster
-	for i, tc := range tcList.Items {
-		if tc.Spec.PxeServerSpec != nil {
-			// Creating a structure for this cluster
-			clusters = append(clusters, Cluster{
-				tc.Name,
-				*tc.Spec.PxeServerSpec.Interface,
-				*tc.Spec.PxeServerSpec.Address,
-				os.Getenv("MATCHBOX_PORT"),
-				make([]Machine, 0),
-			})
-			// Adding machines in the cluster's structure
-			var machineIndex = 0
-			// Control plane machines
-			if tc.Spec.ControlPlane != nil && tc.Spec.ControlPlane.Mode == TalosModeMetal {
-				for _, m := range tc.Spec.ControlPlane.MetalSpec.Machines {
-					if m.PxeClientSpec != nil && m.Address != nil {
-						var kernelCmdline string
-						if m.PxeClientSpec.KernelCmdlineArgs != nil {
-							kernelCmdline = *m.PxeClientSpec.KernelCmdlineArgs
-						} else {
-							kernelCmdline = ""
-						}
-						clusters[i].Machines = append(clusters[i].Machines, Machine{
-							fmt.Sprintf("%s-%d", tc.Name, machineIndex),
-							*m.PxeClientSpec.MacAddress,
-							*m.Address,
-							tc.Spec.ControlPlane.Version,
-							*m.PxeClientSpec.CpuArchitecture,
-							kernelCmdline,
-						})
-						machineIndex++
-					}
-				}
-			}
-			// Worker machines
-			if tc.Spec.Worker != nil && tc.Spec.Worker.Mode == TalosModeMetal {
-				for _, m := range tc.Spec.Worker.MetalSpec.Machines {
-					if m.PxeClientSpec != nil && m.Address != nil {
-						var kernelCmdline string
-						if m.PxeClientSpec.KernelCmdlineArgs != nil {
-							kernelCmdline = *m.PxeClientSpec.KernelCmdlineArgs
-						} else {
-							kernelCmdline = ""
-						}
-						clusters[i].Machines = append(clusters[i].Machines, Machine{
-							fmt.Sprintf("%s-%d", tc.Name, machineIndex),
-							*m.PxeClientSpec.MacAddress,
-							*m.Address,
-							tc.Spec.Worker.Version,
-							*m.PxeClientSpec.CpuArchitecture,
-							kernelCmdline,
-						})
-						machineIndex++
-					}
-				}
-			}
-		}
-	}
-
-	return clusters
-}
-
-func updatePxeBootStackConfig(clusters []Cluster) error {
-	// Cleaning up previous Matchbox configuration
-	for _, dir := range []string{MatchboxGroupsDir, MatchboxProfilesDir} {
-		dirPath := path.Join(MatchboxConfigPath, dir)
-		files, err := os.ReadDir(dirPath)
-		if err != nil {
-			return err
+// bootConfig returns the configuration of the PXE boot service for the TalosClusters with a PXE server.
+// Clusters that are being deleted are left out. A machine boots the Talos version of its TalosMachine
+// once it exists, so a reinstall boots the version the machine was rolled out to. Clusters with an
+// invalid PXE configuration are left out as well and returned with their error by namespace and name,
+// so they do not hold back the configuration of the other clusters.
+func bootConfig(clusters []talosv1alpha1.TalosCluster, machines []talosv1alpha1.TalosMachine) (pxe.Config, map[string]error) {
+	versions := make(map[string]string)
+	for _, tm := range machines {
+		if tm.Spec.PxeClientSpec == nil || tm.Spec.PxeClientSpec.MacAddress == nil || tm.Spec.Version == "" {
+			continue
 		}
-		for _, f := range files {
-			if err := os.Remove(path.Join(dirPath, f.Name())); err != nil {
-				return err
-			}
+		if mac, err := net.ParseMAC(*tm.Spec.PxeClientSpec.MacAddress); err == nil {
+			versions[tm.Namespace+"/"+mac.String()] = tm.Spec.Version
 		}
 	}
-
-	// Generating configurations from templates and saving to files
-	// dnsmasq
-	configChanged, err := generateConfiguration(dnsmasqConfigTmpl, DnsmasqConfigPath, clusters)
-	if err != nil {
-		return err
-	}
-	// Matchbox
-	// Does not need to be restarted, so we don't have to check if its config changed
-	for _, c := range clusters {
-		for _, m := range c.Machines {
-			// Groups
-			if _, err := generateConfiguration(matchboxGroupTmpl,
-				path.Join(MatchboxConfigPath, MatchboxGroupsDir, fmt.Sprintf("%s.json", m.Id)), m,
-			); err != nil {
-				return err
-			}
-			// Profiles
-			if _, err := generateConfiguration(matchboxProfileTmpl,
-				path.Join(MatchboxConfigPath, MatchboxProfilesDir, fmt.Sprintf("%s.json", m.Id)), m,
-			); err != nil {
-				return err
-			}
+	var cfg pxe.Config
+	invalid := make(map[string]error)
+	for _, tc := range clusters {
+		spec := tc.Spec.PxeServerSpec
+		if spec == nil || spec.Address == nil || spec.Interface == nil || tc.DeletionTimestamp != nil {
+			continue
 		}
-	}
-
-	// Restart PXE boot stack to apply the new configuration, if necessary
-	if configChanged {
-		if err := restartPxeBootStack(); err != nil {
-			return err
+		key := tc.Namespace + "/" + tc.Name
+		serverAddress, err := netip.ParseAddr(*spec.Address)
+		if err != nil {
+			invalid[key] = fmt.Errorf("invalid PXE server address of TalosCluster %s: %w", tc.Name, err)
+			continue
 		}
-	}
-
-	return nil
-}
-
-func generateConfiguration(tmplString string, destPath string, data any) (bool, error) {
-	// Saving current config to check if it changed
-	configChanged := false
-	content, err := os.ReadFile(DnsmasqConfigPath)
-	if err != nil {
-		return configChanged, err
-	}
-	previousConfig := string(content)
-
-	file, err := os.Create(destPath)
-	if err != nil {
-		return configChanged, err
-	}
-	tmpl, err := template.New("").Parse(tmplString)
-	if err != nil {
-		return configChanged, err
-	}
-	if err := tmpl.Execute(file, data); err != nil {
-		return configChanged, err
-	}
-	if err := file.Close(); err != nil {
-		return configChanged, err
-	}
-
-	// Checking if config changed
-	content, err = os.ReadFile(DnsmasqConfigPath)
-	if err != nil {
-		return configChanged, err
-	}
-	if string(content) != previousConfig {
-		configChanged = true
-	}
-
-	return configChanged, nil
-}
-
-func downloadBootImages(clusters []Cluster) error {
-	// Determining every combination of Talos version + CPU architecture to take into account when downloading boot images
-	var downloadList = make(map[string]string) // Maps a download URL to a destination path
-	for _, c := range clusters {
-		for _, m := range c.Machines {
-			// Kernel
-			downloadList[fmt.Sprintf("%s/%s/vmlinuz-%s",
-				os.Getenv("TALOS_IMAGES_BASE_URL"), m.TalosVersion, m.CpuArchitecture,
-			)] = fmt.Sprintf("%s/%s/vmlinuz-%s-%s",
-				MatchboxConfigPath, MatchboxAssetsDir, m.TalosVersion, m.CpuArchitecture,
-			)
-			// initramfs
-			downloadList[fmt.Sprintf("%s/%s/initramfs-%s.xz",
-				os.Getenv("TALOS_IMAGES_BASE_URL"), m.TalosVersion, m.CpuArchitecture,
-			)] = fmt.Sprintf("%s/%s/initramfs-%s-%s.xz",
-				MatchboxConfigPath, MatchboxAssetsDir, m.TalosVersion, m.CpuArchitecture,
-			)
-			// iPXE
-			var ipxeArch = ""
-			var ipxeFile = ""
-			switch m.CpuArchitecture {
-			case "amd64":
-				ipxeArch = IpxeEfiX8664Arch
-				ipxeFile = IpxeEfiX8664File
-			case "arm64":
-				ipxeArch = IpxeEfiArm64Arch
-				ipxeFile = IpxeEfiArm64File
-			}
-			downloadList[fmt.Sprintf("%s/%s/%s",
-				os.Getenv("IPXE_BASE_URL"), ipxeArch, IpxeDownloadFile,
-			)] = fmt.Sprintf("%s/%s", TftpDir, ipxeFile)
+		network := pxe.Network{
+			Name:          key,
+			Interface:     *spec.Interface,
+			ServerAddress: serverAddress,
+			ProxyDHCP:     spec.DHCPMode == talosv1alpha1.DHCPModeProxy,
 		}
-	}
-
-	// Cleaning up unused Talos and iPXE images
-	for _, dir := range []string{path.Join(MatchboxConfigPath, MatchboxAssetsDir), TftpDir} {
-		files, err := os.ReadDir(dir)
-		if err != nil {
-			return err
+		if r := spec.DiscoveryRange; r != nil {
+			network.DiscoveryStart, _ = netip.ParseAddr(r.Start)
+			network.DiscoveryEnd, _ = netip.ParseAddr(r.End)
 		}
-		for _, f := range files {
-			absPath := path.Join(dir, f.Name())
-			found := false
-			for _, p := range downloadList {
-				if absPath == p {
-					found = true
-				}
-			}
-			if !found {
-				if err := os.Remove(absPath); err != nil {
-					return err
-				}
+		// Control plane machines, then worker machines
+		if tc.Spec.ControlPlane != nil && tc.Spec.ControlPlane.Mode == TalosModeMetal {
+			if network.Machines, err = appendBootMachines(network.Machines, &tc, tc.Spec.ControlPlane.MetalSpec.Machines, tc.Spec.ControlPlane.Version, versions); err != nil {
+				invalid[key] = err
+				continue
 			}
 		}
-	}
-
-	// Downloading images if they do not exist yet
-	for url, path := range downloadList {
-		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-			if err := downloadFile(url, path); err != nil {
-				return err
+		if tc.Spec.Worker != nil && tc.Spec.Worker.Mode == TalosModeMetal {
+			if network.Machines, err = appendBootMachines(network.Machines, &tc, tc.Spec.Worker.MetalSpec.Machines, tc.Spec.Worker.Version, versions); err != nil {
+				invalid[key] = err
+				continue
 			}
 		}
+		cfg.Networks = append(cfg.Networks, network)
 	}
-
-	return nil
+	return cfg, invalid
 }
 
-func downloadFile(url string, path string) error {
-	// Create destination file
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close() //nolint:errcheck
-
-	// Download file
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	// Write downloaded file
-	if resp.StatusCode == 200 {
-		if _, err := io.Copy(file, resp.Body); err != nil {
-			return err
+// appendBootMachines appends the metal machines of the cluster that boot from the network. versions
+// holds the Talos versions of the TalosMachines by namespace and MAC address.
+func appendBootMachines(bootMachines []pxe.Machine, tc *talosv1alpha1.TalosCluster, machines []talosv1alpha1.Machine, version string, versions map[string]string) ([]pxe.Machine, error) {
+	for _, m := range machines {
+		if m.PxeClientSpec == nil || m.PxeClientSpec.MacAddress == nil || m.Address == nil {
+			continue
 		}
-	} else {
-		return fmt.Errorf("unable to download '%s' (HTTP status code: %d)", url, resp.StatusCode)
-	}
-
-	return nil
-}
-
-func restartPxeBootStack() error {
-	// Restart dnsmasq
-	// Retrieving PID of process
-	var pid = -1
-	procs, err := os.ReadDir(ProcPath)
-	if err != nil {
-		return err
-	}
-	for _, proc := range procs {
-		currentPid, err := strconv.Atoi(proc.Name())
-		// If there is an error, then it is not a PID
-		if err == nil {
-			cmdlinePath := filepath.Join(ProcPath, proc.Name(), ProcCmdlineFile)
-			content, err := os.ReadFile(cmdlinePath)
-			if err != nil {
-				return err
-			} else {
-				if string(content) == DnsmasqCmdline {
-					pid = currentPid
-				}
-			}
+		mac, err := net.ParseMAC(*m.PxeClientSpec.MacAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAC address of machine %s of TalosCluster %s: %w", *m.Address, tc.Name, err)
 		}
-	}
-	if pid != -1 {
-		// Retrieving process object from PID
-		p, err := os.FindProcess(pid)
+		address, err := netip.ParseAddr(*m.Address)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("invalid address of machine %s of TalosCluster %s: %w", *m.Address, tc.Name, err)
 		}
-		// Sending SIGTERM to restart dnsmasq
-		if err := p.Signal(syscall.SIGTERM); err != nil {
-			return err
+		machine := pxe.Machine{MACAddress: mac, Address: address, TalosVersion: version}
+		if v, ok := versions[tc.Namespace+"/"+mac.String()]; ok {
+			machine.TalosVersion = v
 		}
-		return nil
-	} else {
-		return fmt.Errorf("could not find dnsmasq process")
+		if m.PxeClientSpec.CpuArchitecture != nil {
+			machine.Architecture = *m.PxeClientSpec.CpuArchitecture
+		}
+		if m.PxeClientSpec.KernelCmdlineArgs != nil {
+			machine.KernelArgs = *m.PxeClientSpec.KernelCmdlineArgs
+		}
+		bootMachines = append(bootMachines, machine)
 	}
+	return bootMachines, nil
+}
+
+// updateBootServer replaces the configuration of the PXE boot service with the one of the TalosClusters
+// and TalosMachines of all namespaces, the boot service serves them all. It returns the errors of the
+// clusters that were left out because of an invalid PXE configuration by namespace and name.
+func updateBootServer(ctx context.Context, c client.Client, srv *pxe.Server) (map[string]error, error) {
+	var tcList talosv1alpha1.TalosClusterList
+	if err := c.List(ctx, &tcList); err != nil {
+		return nil, fmt.Errorf("failed to list TalosClusters: %w", err)
+	}
+	var tmList talosv1alpha1.TalosMachineList
+	if err := c.List(ctx, &tmList); err != nil {
+		return nil, fmt.Errorf("failed to list TalosMachines: %w", err)
+	}
+	cfg, invalid := bootConfig(tcList.Items, tmList.Items)
+	srv.Update(cfg)
+	return invalid, nil
 }
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
+)
+
+func newPxeTestCluster(name, iface string) talosv1alpha1.TalosCluster {
+	tc := talosv1alpha1.TalosCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace}}
+	tc.Spec.PxeServerSpec = &talosv1alpha1.PxeServerSpec{Address: ptr.To("10.0.0.1"), Interface: ptr.To(iface)}
+	tc.Spec.ControlPlane = &talosv1alpha1.TalosControlPlaneSpec{Mode: TalosModeMetal, Version: "v1.13.0"}
+	tc.Spec.ControlPlane.MetalSpec.Machines = []talosv1alpha1.Machine{
+		{Address: ptr.To("10.0.0.2"), PxeClientSpec: &talosv1alpha1.PxeClientSpec{
+			MacAddress: ptr.To("52:54:00:00:00:02"), CpuArchitecture: ptr.To("amd64"), KernelCmdlineArgs: ptr.To("talos.dashboard.disabled=1")}},
+		// Machines installed from an ISO do not boot from the network
+		{Address: ptr.To("10.0.0.3")},
+	}
+	tc.Spec.Worker = &talosv1alpha1.TalosWorkerSpec{Mode: TalosModeMetal, Version: "v1.13.0"}
+	tc.Spec.Worker.MetalSpec.Machines = []talosv1alpha1.Machine{
+		{Address: ptr.To("10.0.0.4"), PxeClientSpec: &talosv1alpha1.PxeClientSpec{MacAddress: ptr.To("52-54-00-00-00-04"), CpuArchitecture: ptr.To("arm64")}},
+	}
+	return tc
+}
+
+func TestBootConfig(t *testing.T) {
+	proxy := newPxeTestCluster("proxy", "eth2")
+	proxy.Namespace = "other"
+	proxy.Spec.PxeServerSpec.DHCPMode = talosv1alpha1.DHCPModeProxy
+	server := newPxeTestCluster("server", "eth1")
+	server.Spec.PxeServerSpec.DiscoveryRange = &talosv1alpha1.DHCPRange{Start: "10.0.0.100", End: "10.0.0.200"}
+	deleted := newPxeTestCluster("deleted", "eth3")
+	deleted.DeletionTimestamp = &metav1.Time{}
+	// The worker was rolled out to a newer version than the cluster has
+	rolled := newRolloutTestMachine("server-10.0.0.4", "v1.13.1", nil)
+	rolled.Spec.PxeClientSpec = &talosv1alpha1.PxeClientSpec{MacAddress: ptr.To("52:54:00:00:00:04")}
+
+	cfg, invalid := bootConfig([]talosv1alpha1.TalosCluster{server, proxy, deleted}, []talosv1alpha1.TalosMachine{rolled})
+	if len(invalid) != 0 {
+		t.Fatalf("unexpected errors: %v", invalid)
+	}
+	if len(cfg.Networks) != 2 {
+		t.Fatalf("expected the networks of the clusters that are not deleted, got %+v", cfg.Networks)
+	}
+	n := cfg.Networks[0]
+	if n.Interface != "eth1" || n.ServerAddress != netip.MustParseAddr("10.0.0.1") || n.ProxyDHCP ||
+		n.DiscoveryStart != netip.MustParseAddr("10.0.0.100") || n.DiscoveryEnd != netip.MustParseAddr("10.0.0.200") {
+		t.Errorf("unexpected network %+v", n)
+	}
+	if len(n.Machines) != 2 {
+		t.Fatalf("expected the machines that boot from the network, got %+v", n.Machines)
+	}
+	cp, worker := n.Machines[0], n.Machines[1]
+	if cp.MACAddress.String() != "52:54:00:00:00:02" || cp.Address != netip.MustParseAddr("10.0.0.2") || cp.TalosVersion != "v1.13.0" ||
+		cp.Architecture != "amd64" || cp.KernelArgs != "talos.dashboard.disabled=1" {
+		t.Errorf("unexpected control plane machine %+v", cp)
+	}
+	if worker.MACAddress.String() != "52:54:00:00:00:04" || worker.TalosVersion != "v1.13.1" || worker.Architecture != "arm64" {
+		t.Errorf("expected the worker to boot the version of its TalosMachine, got %+v", worker)
+	}
+	if !cfg.Networks[1].ProxyDHCP || cfg.Networks[1].Machines[1].TalosVersion != "v1.13.0" {
+		t.Errorf("expected a ProxyDHCP network with the versions of its cluster, got %+v", cfg.Networks[1])
+	}
+
+	// A cluster with an invalid MAC address is left out without holding back the other clusters
+	broken := newPxeTestCluster("broken", "eth2")
+	broken.Namespace = "other"
+	broken.Spec.Worker.MetalSpec.Machines[0].PxeClientSpec.MacAddress = ptr.To("52:54:00")
+	cfg, invalid = bootConfig([]talosv1alpha1.TalosCluster{server, broken}, nil)
+	if len(cfg.Networks) != 1 || cfg.Networks[0].Name != DefaultNamespace+"/server" {
+		t.Errorf("expected only the valid cluster to be served, got %+v", cfg.Networks)
+	}
+	if len(invalid) != 1 || invalid["other/broken"] == nil {
+		t.Errorf("expected the invalid MAC address to be reported for its cluster, got %v", invalid)
+	}
+}
+
+func TestUpdateBootServer(t *testing.T) {
+	tc := newPxeTestCluster("test", "eth1")
+	c := newTestClient(t, &tc)
+	srv := pxe.NewServer(pxe.Options{AssetsDir: t.TempDir()})
+	if invalid, err := updateBootServer(context.Background(), c, srv); err != nil || len(invalid) != 0 {
+		t.Fatalf("unexpected error: %v, %v", invalid, err)
+	}
+	if cfg := srv.Config(); len(cfg.Networks) != 1 || len(cfg.Networks[0].Machines) != 2 {
+		t.Errorf("expected the boot service to serve the cluster, got %+v", cfg)
+	}
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// deletionBlockedRequeue is how often a held deletion checks whether the protection was removed
+const deletionBlockedRequeue = 30 * time.Second
+
+// deletionProtected returns true if the object is protected from deletion, either by itself or by
+// its parent
+func deletionProtected(obj client.Object) bool {
+	value := obj.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation]
+	return strings.EqualFold(value, "true") || value == talosv1alpha1.DeletionProtectionInherited
+}
+
+// inheritDeletionProtection passes the deletion protection of the parent on to the child. A child
+// that is protected by itself keeps its protection, while an inherited protection is removed together
+// with the protection of the parent. A child that is created copies the annotations of its parent, so
+// its protection is always inherited.
+func inheritDeletionProtection(parent, child client.Object) {
+	value, ok := child.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation]
+	if ok && value != talosv1alpha1.DeletionProtectionInherited && !child.GetCreationTimestamp().Time.IsZero() {
+		return
+	}
+	annotations := maps.Clone(child.GetAnnotations())
+	if deletionProtected(parent) {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[talosv1alpha1.DeletionProtectionAnnotation] = talosv1alpha1.DeletionProtectionInherited
+	} else {
+		delete(annotations, talosv1alpha1.DeletionProtectionAnnotation)
+	}
+	child.SetAnnotations(annotations)
+}
+
+// deletionProtectionChanged returns true if the deletion protection annotation of the object changed
+func deletionProtectionChanged(oldObj, newObj client.Object) bool {
+	return oldObj.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation] != newObj.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation]
+}
+
+// deletionProtectionPredicate passes updates of the deletion protection annotation, so it is inherited
+// by the children and a held deletion continues once the annotation is removed
+var deletionProtectionPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return deletionProtectionChanged(e.ObjectOld, e.ObjectNew)
+	},
+}
+
+// holdProtectedDeletion returns true if the deleted object is protected from deletion. Its finalizer
+// is held until the protection is removed, which is reported with the DeletionBlocked condition. This
+// covers clusters without the admission webhooks, which reject the deletion right away.
+func holdProtectedDeletion(ctx context.Context, c client.Client, recorder events.EventRecorder, obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+	if !deletionProtected(obj) {
+		return false, nil
+	}
+	if meta.IsStatusConditionTrue(*conditions, talosv1alpha1.ConditionDeletionBlocked) {
+		return true, nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	message := fmt.Sprintf("Deletion is blocked by the %s annotation, remove it to delete the resource", talosv1alpha1.DeletionProtectionAnnotation)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionDeletionBlocked,
+		Status:  metav1.ConditionTrue,
+		Reason:  "DeletionProtected",
+		Message: message,
+	})
+	recorder.Eventf(obj, nil, corev1.EventTypeWarning, "DeletionBlocked", "DeletionBlocked", message)
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return true, fmt.Errorf("failed to set DeletionBlocked condition of %s: %w", obj.GetName(), err)
+	}
+	return true, nil
+}
+
+// releaseInheritedProtection removes the protection a child inherited from its parent, so the parent
+// can delete it. It only guards against deleting the child by hand.
+func releaseInheritedProtection(ctx context.Context, c client.Client, obj client.Object) error {
+	if obj.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation] != talosv1alpha1.DeletionProtectionInherited {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := maps.Clone(obj.GetAnnotations())
+	delete(annotations, talosv1alpha1.DeletionProtectionAnnotation)
+	obj.SetAnnotations(annotations)
+	return client.IgnoreNotFound(c.Patch(ctx, obj, patch))
+}
+
+// deleteChild deletes a child on behalf of its parent after releasing its inherited protection. A
+// child that is already being deleted only has its protection released.
+func deleteChild(ctx context.Context, c client.Client, obj client.Object) error {
+	if err := releaseInheritedProtection(ctx, c, obj); err != nil {
+		return err
+	}
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+	if err := c.Delete(ctx, obj); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestInheritDeletionProtection(t *testing.T) {
+	protected := map[string]string{talosv1alpha1.DeletionProtectionAnnotation: "true"}
+	parent := &talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Annotations: protected}}
+
+	// A created child copies the annotation of its parent, which is inherited
+	created := &talosv1alpha1.TalosMachine{ObjectMeta: metav1.ObjectMeta{Annotations: protected}}
+	inheritDeletionProtection(parent, created)
+	if got := created.Annotations[talosv1alpha1.DeletionProtectionAnnotation]; got != talosv1alpha1.DeletionProtectionInherited {
+		t.Fatalf("expected the protection to be inherited, got %q", got)
+	}
+	if parent.Annotations[talosv1alpha1.DeletionProtectionAnnotation] != "true" {
+		t.Fatalf("expected the annotations of the parent to be left alone, got %v", parent.Annotations)
+	}
+
+	existing := &talosv1alpha1.TalosMachine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}}
+	inheritDeletionProtection(parent, existing)
+	if !deletionProtected(existing) {
+		t.Fatalf("expected the existing child to inherit the protection, got %v", existing.Annotations)
+	}
+	parent.Annotations = nil
+	inheritDeletionProtection(parent, existing)
+	if deletionProtected(existing) {
+		t.Errorf("expected the inherited protection to be removed with the one of the parent, got %v", existing.Annotations)
+	}
+
+	// A protection set on the child itself is kept
+	existing.Annotations = map[string]string{talosv1alpha1.DeletionProtectionAnnotation: "true"}
+	inheritDeletionProtection(parent, existing)
+	if !deletionProtected(existing) {
+		t.Errorf("expected the child to keep its own protection, got %v", existing.Annotations)
+	}
+}
+
+func TestHoldProtectedDeletion(t *testing.T) {
+	ctx := context.Background()
+	tm := newRolloutTestMachine("test-cp-0", "v1.13.0", nil)
+	tm.Annotations = map[string]string{talosv1alpha1.DeletionProtectionAnnotation: talosv1alpha1.DeletionProtectionInherited}
+	tm.Finalizers = []string{talosv1alpha1.TalosMachineFinalizer}
+	c := newTestClient(t, &tm)
+	recorder := events.NewFakeRecorder(10)
+	key := client.ObjectKeyFromObject(&tm)
+
+	if err := c.Delete(ctx, &tm); err != nil {
+		t.Fatalf("failed to delete machine: %v", err)
+	}
+	if err := c.Get(ctx, key, &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	blocked, err := holdProtectedDeletion(ctx, c, recorder, &tm, &tm.Status.Conditions)
+	if err != nil || !blocked {
+		t.Fatalf("expected the deletion to be blocked, got %v, %v", blocked, err)
+	}
+	if err := c.Get(ctx, key, &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(tm.Status.Conditions, talosv1alpha1.ConditionDeletionBlocked) {
+		t.Errorf("expected the DeletionBlocked condition, got %+v", tm.Status.Conditions)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a DeletionBlocked event, got %d events", len(recorder.Events))
+	}
+
+	// The parent releases the inherited protection when it tears the machine down
+	if err := deleteChild(ctx, c, &tm); err != nil {
+		t.Fatalf("failed to delete machine: %v", err)
+	}
+	if err := c.Get(ctx, key, &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if blocked, err := holdProtectedDeletion(ctx, c, recorder, &tm, &tm.Status.Conditions); err != nil || blocked {
+		t.Errorf("expected the deletion to continue, got %v, %v", blocked, err)
+	}
+}
+
+func TestDeleteChildKeepsOwnProtection(t *testing.T) {
+	ctx := context.Background()
+	tm := newRolloutTestMachine("test-cp-0", "v1.13.0", nil)
+	tm.Annotations = map[string]string{talosv1alpha1.DeletionProtectionAnnotation: "true"}
+	tm.Finalizers = []string{talosv1alpha1.TalosMachineFinalizer}
+	c := newTestClient(t, &tm)
+
+	if err := deleteChild(ctx, c, &tm); err != nil {
+		t.Fatalf("failed to delete machine: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&tm), &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.DeletionTimestamp.IsZero() || !deletionProtected(&tm) {
+		t.Errorf("expected the machine to be deleted and keep its own protection, got %v at %v", tm.Annotations, tm.DeletionTimestamp)
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/storage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newStorageBackend creates the storage backend selected by the backup storage spec and resolves its credentials from secrets
+func newStorageBackend(ctx context.Context, c client.Client, namespace string, bs *talosv1alpha1.BackupStorage) (storage.Backend, error) {
+	if bs == nil {
+		return nil, fmt.Errorf("backup storage configuration is not specified")
+	}
+	switch {
+	case bs.S3 != nil:
+		s3Config, err := getS3Config(ctx, c, namespace, bs.S3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get S3 configuration: %w", err)
+		}
+		return storage.NewS3Client(ctx, s3Config)
+	case bs.Filesystem != nil:
+		return storage.NewFilesystemClient(&storage.FilesystemConfig{
+			Path: bs.Filesystem.Path,
+		})
+	case bs.Azure != nil:
+		azureConfig := &storage.AzureBlobConfig{
+			AccountName: bs.Azure.AccountName,
+			Container:   bs.Azure.Container,
+			Endpoint:    bs.Azure.Endpoint,
+		}
+		if bs.Azure.AccountKey != nil {
+			accountKey, err := getSecretValue(ctx, c, namespace, bs.Azure.AccountKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Azure account key: %w", err)
+			}
+			azureConfig.AccountKey = accountKey
+		}
+		return storage.NewAzureBlobClient(azureConfig)
+	case bs.GCS != nil:
+		gcsConfig := &storage.GCSConfig{
+			Bucket:   bs.GCS.Bucket,
+			Endpoint: bs.GCS.Endpoint,
+		}
+		if bs.GCS.Credentials != nil {
+			credentials, err := getSecretValue(ctx, c, namespace, bs.GCS.Credentials)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GCS credentials: %w", err)
+			}
+			gcsConfig.CredentialsJSON = []byte(credentials)
+		}
+		return storage.NewGCSClient(ctx, gcsConfig)
+	default:
+		return nil, fmt.Errorf("no storage backend is specified")
+	}
+}
+
+// newBackupEncryptor creates the encryptor for the backup encryption spec. It returns nil if encryption is not configured.
+func newBackupEncryptor(ctx context.Context, c client.Client, namespace string, enc *talosv1alpha1.BackupEncryption) (storage.Encryptor, error) {
+	if enc == nil {
+		return nil, nil
+	}
+	key, err := getSecretValue(ctx, c, namespace, enc.KeySecretRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	return storage.NewEncryptor(enc.Type, []byte(key))
+}
+
+// getS3Config retrieves S3 configuration from the S3 storage spec and resolves credentials from secrets
+func getS3Config(ctx context.Context, c client.Client, namespace string, s3Spec *talosv1alpha1.S3Storage) (*storage.S3Config, error) {
+	// Retrieve access key ID from secret
+	accessKeyID, err := getSecretValue(ctx, c, namespace, s3Spec.AccessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access key ID: %w", err)
+	}
+
+	// Retrieve secret access key from secret
+	secretAccessKey, err := getSecretValue(ctx, c, namespace, s3Spec.SecretAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret access key: %w", err)
+	}
+
+	return &storage.S3Config{
+		Bucket:             s3Spec.Bucket,
+		Region:             s3Spec.Region,
+		Endpoint:           s3Spec.Endpoint,
+		AccessKeyID:        accessKeyID,
+		SecretAccessKey:    secretAccessKey,
+		InsecureSkipVerify: s3Spec.InsecureSkipTLSVerify,
+	}, nil
+}
+
+// getSecretValue retrieves a value from a Kubernetes secret
+func getSecretValue(ctx context.Context, c client.Client, namespace string, selector *corev1.SecretKeySelector) (string, error) {
+	if selector == nil {
+		return "", fmt.Errorf("secret selector is nil")
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      selector.Name,
+	}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", selector.Name, err)
+	}
+
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", selector.Key, selector.Name)
+	}
+
+	return string(value), nil
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// defaultPreUpgradeBackupKeep is how many pre-upgrade backups are kept if keep is not set
+const defaultPreUpgradeBackupKeep = 3
+
+// preUpgradeBackupName returns the name of the TalosEtcdBackup taken before upgrading the control plane
+// to its desired versions. The name is keyed on the target versions and a hash of the per-machine
+// versions, so edits of the spec that do not change the versions reuse the backup of the upgrade.
+func preUpgradeBackupName(tcp *talosv1alpha1.TalosControlPlane) string {
+	name := fmt.Sprintf("%s-pre-upgrade-%s-%s", tcp.Name, tcp.Spec.Version, tcp.Spec.KubeVersion)
+	var pins []string
+	for _, machine := range tcp.Spec.MetalSpec.Machines {
+		if machine.Version == "" {
+			continue
+		}
+		switch {
+		case machine.Address != nil:
+			pins = append(pins, fmt.Sprintf("%s=%s", *machine.Address, machine.Version))
+		case machine.MachineRef != nil:
+			pins = append(pins, fmt.Sprintf("%s/%s/%s=%s", machine.MachineRef.Kind, machine.MachineRef.Namespace, machine.MachineRef.Name, machine.Version))
+		}
+	}
+	if len(pins) > 0 {
+		sort.Strings(pins)
+		name = fmt.Sprintf("%s-%s", name, configHash([]byte(strings.Join(pins, ",")))[:8])
+	}
+	return strings.ToLower(name)
+}
+
+// ensurePreUpgradeBackup creates the pre-upgrade etcd backup of the control plane if it is enabled and
+// returns true once the upgrade may proceed, i.e. the backup is ready or no backup is required.
+// A failed backup blocks the upgrade until it is deleted, which makes the operator take a new one.
+func (r *TalosControlPlaneReconciler) ensurePreUpgradeBackup(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if tcp.Spec.PreUpgradeBackup == nil {
+		return true, nil
+	}
+	name := preUpgradeBackupName(tcp)
+	if isDryRun(tcp) {
+		logger.Info("DryRun: would take a pre-upgrade etcd backup", "backup", name)
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, fmt.Sprintf("Would create TalosEtcdBackup %s before upgrading", name))
+		return true, nil
+	}
+
+	backup := &talosv1alpha1.TalosEtcdBackup{}
+	err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: tcp.Namespace}, backup)
+	if kerrors.IsNotFound(err) {
+		// The backup is not owned by the control plane so that deleting the control plane
+		// does not delete the snapshot it may have to be restored from.
+		backup = &talosv1alpha1.TalosEtcdBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: tcp.Namespace,
+				Labels: map[string]string{
+					talosv1alpha1.TalosEtcdBackupPreUpgradeLabelKey: tcp.Name,
+				},
+			},
+			Spec: talosv1alpha1.TalosEtcdBackupSpec{
+				TalosControlPlaneRef: &corev1.LocalObjectReference{Name: tcp.Name},
+				BackupStorage:        tcp.Spec.PreUpgradeBackup.BackupStorage,
+				Encryption:           tcp.Spec.PreUpgradeBackup.Encryption,
+				Verify:               tcp.Spec.PreUpgradeBackup.Verify,
+			},
+		}
+		logger.Info("Creating pre-upgrade etcd backup", "backup", name)
+		if err := r.Create(ctx, backup); err != nil && !kerrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("failed to create pre-upgrade backup %s: %w", name, err)
+		}
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeNormal, "PreUpgradeBackup", "PreUpgradeBackup", fmt.Sprintf("Taking etcd backup %s before upgrading", name))
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get pre-upgrade backup %s: %w", name, err)
+	}
+
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionPreUpgradeBackupReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "BackupInProgress",
+		Message: fmt.Sprintf("Waiting for etcd backup %s before upgrading", name),
+	}
+	switch {
+	case meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionReady):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BackupReady"
+		condition.Message = fmt.Sprintf("Etcd backup %s is ready", name)
+	case meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionFailed):
+		condition.Reason = "BackupFailed"
+		condition.Message = fmt.Sprintf("Etcd backup %s failed, delete it to retry the upgrade", name)
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "PreUpgradeBackupFailed", "PreUpgradeBackupFailed", condition.Message)
+	}
+
+	if condition.Status == metav1.ConditionTrue {
+		if err := r.prunePreUpgradeBackups(ctx, tcp, name); err != nil {
+			return false, err
+		}
+	}
+
+	changed := meta.SetStatusCondition(&tcp.Status.Conditions, condition)
+	if tcp.Status.PreUpgradeBackupName != name {
+		tcp.Status.PreUpgradeBackupName = name
+		changed = true
+	}
+	if changed {
+		if err := r.Status().Update(ctx, tcp); err != nil {
+			return false, fmt.Errorf("failed to update pre-upgrade backup status: %w", err)
+		}
+	}
+	return condition.Status == metav1.ConditionTrue, nil
+}
+
+// prunePreUpgradeBackups deletes the oldest pre-upgrade backups of the control plane above its keep
+// count. The backup of the current upgrade is always kept.
+func (r *TalosControlPlaneReconciler) prunePreUpgradeBackups(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, current string) error {
+	keep := int(tcp.Spec.PreUpgradeBackup.Keep)
+	if keep <= 0 {
+		keep = defaultPreUpgradeBackupKeep
+	}
+	list := &talosv1alpha1.TalosEtcdBackupList{}
+	if err := r.List(ctx, list, client.InNamespace(tcp.Namespace),
+		client.MatchingLabels{talosv1alpha1.TalosEtcdBackupPreUpgradeLabelKey: tcp.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list pre-upgrade backups: %w", err)
+	}
+	var older []talosv1alpha1.TalosEtcdBackup
+	for _, backup := range list.Items {
+		if backup.Name != current && backup.DeletionTimestamp.IsZero() {
+			older = append(older, backup)
+		}
+	}
+	if len(older) < keep {
+		return nil
+	}
+	sortBackupsNewestFirst(older)
+	for _, backup := range older[keep-1:] {
+		log.FromContext(ctx).Info("Deleting superseded pre-upgrade etcd backup", "backup", backup.Name)
+		if err := r.Delete(ctx, &backup); err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pre-upgrade backup %s: %w", backup.Name, err)
+		}
+	}
+	return nil
+}
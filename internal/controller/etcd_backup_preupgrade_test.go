@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newPreUpgradeTestControlPlane() *talosv1alpha1.TalosControlPlane {
+	return &talosv1alpha1.TalosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cp",
+			Namespace: DefaultNamespace,
+		},
+		Spec: talosv1alpha1.TalosControlPlaneSpec{
+			Version:     "v1.13.0",
+			KubeVersion: "v1.35.0",
+			PreUpgradeBackup: &talosv1alpha1.PreUpgradeBackup{
+				BackupStorage: talosv1alpha1.BackupStorage{
+					Filesystem: &talosv1alpha1.FilesystemStorage{Path: "/backups"},
+				},
+				Verify: true,
+			},
+		},
+	}
+}
+
+func TestPreUpgradeBackupName(t *testing.T) {
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.Version = "v1.13.0-Beta.1"
+	tcp.Generation = 4
+	name := preUpgradeBackupName(tcp)
+	if name != "test-cp-pre-upgrade-v1.13.0-beta.1-v1.35.0" {
+		t.Errorf("unexpected backup name %q", name)
+	}
+	// Edits that do not change the versions keep the backup
+	tcp.Generation = 6
+	tcp.Spec.RolloutStrategy = &talosv1alpha1.RolloutStrategy{}
+	if got := preUpgradeBackupName(tcp); got != name {
+		t.Errorf("expected the backup to be kept for an unrelated change, got %q", got)
+	}
+	// A per-machine version is a new upgrade
+	tcp.Spec.MetalSpec.Machines = []talosv1alpha1.Machine{
+		{Address: ptr.To("10.0.0.1"), Version: "v1.13.1"},
+		{Address: ptr.To("10.0.0.2")},
+	}
+	pinned := preUpgradeBackupName(tcp)
+	if !strings.HasPrefix(pinned, name+"-") || len(pinned) != len(name)+9 {
+		t.Errorf("expected a new backup for a per-machine version, got %q", pinned)
+	}
+	tcp.Spec.MetalSpec.Machines[0].Version = "v1.13.2"
+	if got := preUpgradeBackupName(tcp); got == pinned {
+		t.Errorf("expected a new backup for a changed per-machine version, got %q", got)
+	}
+}
+
+func TestPrunePreUpgradeBackups(t *testing.T) {
+	ctx := context.Background()
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.PreUpgradeBackup.Keep = 2
+	c := newTestClient(t, tcp)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"test-cp-pre-upgrade-1", "test-cp-pre-upgrade-2", "test-cp-pre-upgrade-3"} {
+		backup := &talosv1alpha1.TalosEtcdBackup{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         DefaultNamespace,
+			Labels:            map[string]string{talosv1alpha1.TalosEtcdBackupPreUpgradeLabelKey: tcp.Name},
+			CreationTimestamp: metav1.NewTime(base.Add(time.Duration(i) * time.Minute)),
+		}}
+		if err := r.Create(ctx, backup); err != nil {
+			t.Fatalf("failed to create backup: %v", err)
+		}
+	}
+
+	if err := r.prunePreUpgradeBackups(ctx, tcp, "test-cp-pre-upgrade-4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var backups talosv1alpha1.TalosEtcdBackupList
+	if err := r.List(ctx, &backups); err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	// The current backup and the newest older one are kept
+	if len(backups.Items) != 1 || backups.Items[0].Name != "test-cp-pre-upgrade-3" {
+		t.Errorf("expected only test-cp-pre-upgrade-3 to be kept next to the current backup, got %v", backups.Items)
+	}
+}
+
+func TestEnsurePreUpgradeBackup_Disabled(t *testing.T) {
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.PreUpgradeBackup = nil
+	c := newTestClient(t, tcp)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	ready, err := r.ensurePreUpgradeBackup(context.Background(), tcp)
+	if err != nil || !ready {
+		t.Fatalf("expected upgrade to proceed without a backup, got %v, %v", ready, err)
+	}
+	var backups talosv1alpha1.TalosEtcdBackupList
+	if err := r.List(context.Background(), &backups); err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(backups.Items) != 0 {
+		t.Errorf("expected no backups, got %d", len(backups.Items))
+	}
+}
+
+func TestEnsurePreUpgradeBackup_BlocksUntilReady(t *testing.T) {
+	ctx := context.Background()
+	tcp := newPreUpgradeTestControlPlane()
+	c := newTestClient(t, tcp)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	ready, err := r.ensurePreUpgradeBackup(ctx, tcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ready {
+		t.Fatal("expected the upgrade to be held while the backup is running")
+	}
+	name := preUpgradeBackupName(tcp)
+	if tcp.Status.PreUpgradeBackupName != name {
+		t.Errorf("expected status to record backup %q, got %q", name, tcp.Status.PreUpgradeBackupName)
+	}
+
+	var backup talosv1alpha1.TalosEtcdBackup
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: tcp.Namespace}, &backup); err != nil {
+		t.Fatalf("expected pre-upgrade backup to be created: %v", err)
+	}
+	if backup.Labels[talosv1alpha1.TalosEtcdBackupPreUpgradeLabelKey] != tcp.Name || len(backup.OwnerReferences) != 0 {
+		t.Errorf("unexpected backup metadata %v", backup.ObjectMeta)
+	}
+	if backup.Spec.BackupStorage.Filesystem == nil || !backup.Spec.Verify || backup.Spec.TalosControlPlaneRef.Name != tcp.Name {
+		t.Errorf("backup spec does not match the control plane: %+v", backup.Spec)
+	}
+
+	// A failed backup keeps the upgrade blocked
+	meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{Type: talosv1alpha1.ConditionFailed, Status: metav1.ConditionTrue, Reason: "BackupFailed"})
+	if err := r.Status().Update(ctx, &backup); err != nil {
+		t.Fatalf("failed to update backup status: %v", err)
+	}
+	if ready, err := r.ensurePreUpgradeBackup(ctx, tcp); err != nil || ready {
+		t.Fatalf("expected a failed backup to hold the upgrade, got %v, %v", ready, err)
+	}
+	if cond := meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionPreUpgradeBackupReady); cond == nil || cond.Reason != "BackupFailed" {
+		t.Errorf("expected BackupFailed condition, got %+v", cond)
+	}
+
+	meta.RemoveStatusCondition(&backup.Status.Conditions, talosv1alpha1.ConditionFailed)
+	meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{Type: talosv1alpha1.ConditionReady, Status: metav1.ConditionTrue, Reason: "BackupCompleted"})
+	if err := r.Status().Update(ctx, &backup); err != nil {
+		t.Fatalf("failed to update backup status: %v", err)
+	}
+	if ready, err := r.ensurePreUpgradeBackup(ctx, tcp); err != nil || !ready {
+		t.Fatalf("expected the upgrade to proceed once the backup is ready, got %v, %v", ready, err)
+	}
+	if !meta.IsStatusConditionTrue(tcp.Status.Conditions, talosv1alpha1.ConditionPreUpgradeBackupReady) {
+		t.Errorf("expected %s condition to be true", talosv1alpha1.ConditionPreUpgradeBackupReady)
+	}
+}
+
+func TestEnsurePreUpgradeBackup_DryRun(t *testing.T) {
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Annotations = map[string]string{ReconcileModeAnnotation: ReconcileModeDryRun}
+	c := newTestClient(t, tcp)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	ready, err := r.ensurePreUpgradeBackup(context.Background(), tcp)
+	if err != nil || !ready {
+		t.Fatalf("expected DryRun to not block on the backup, got %v, %v", ready, err)
+	}
+	if tcp.Status.PreUpgradeBackupName != "" {
+		t.Errorf("expected no status change in DryRun mode, got %q", tcp.Status.PreUpgradeBackupName)
+	}
+}
+
+func TestHandleTalosMachines_HoldsUpgradeForPreUpgradeBackup(t *testing.T) {
+	ctx := context.Background()
+	address := "10.0.0.1"
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.Version = "v1.13.1"
+	tcp.Spec.MetalSpec.Machines = []talosv1alpha1.Machine{{Address: &address}}
+	existing := &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cp-" + address, Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosMachineSpec{
+			Version:         "v1.13.0",
+			ControlPlaneRef: &corev1.ObjectReference{Name: tcp.Name},
+		},
+	}
+	c := newTestClient(t, tcp, existing)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	held, err := r.handleTalosMachines(ctx, tcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !held {
+		t.Error("expected the upgrade to be held until the pre-upgrade backup is ready")
+	}
+	var tm talosv1alpha1.TalosMachine
+	if err := c.Get(ctx, client.ObjectKeyFromObject(existing), &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.Spec.Version != "v1.13.0" {
+		t.Errorf("expected machine to stay on v1.13.0, got %s", tm.Spec.Version)
+	}
+}
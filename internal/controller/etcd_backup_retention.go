@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// defaultBackupRetention is the number of successful backups kept if no retention is configured
+const defaultBackupRetention = int32(5)
+
+// effectiveRetentionPolicy returns the retention policy of the schedule. The legacy retention count
+// is converted to a policy that keeps the last N successful backups and all failed ones.
+func effectiveRetentionPolicy(schedule *talosv1alpha1.TalosEtcdBackupSchedule) *talosv1alpha1.BackupRetentionPolicy {
+	if schedule.Spec.RetentionPolicy != nil {
+		return schedule.Spec.RetentionPolicy
+	}
+	retention := defaultBackupRetention
+	if schedule.Spec.Retention != nil {
+		retention = *schedule.Spec.Retention
+	}
+	return &talosv1alpha1.BackupRetentionPolicy{
+		KeepLastSuccessful: &retention,
+	}
+}
+
+// selectBackupsToPrune returns the backups the retention policy does not keep. Backups that are
+// still in progress are never pruned. The periods are calculated in the time zone of now.
+func selectBackupsToPrune(backups []talosv1alpha1.TalosEtcdBackup, policy *talosv1alpha1.BackupRetentionPolicy, now time.Time) []talosv1alpha1.TalosEtcdBackup {
+	var successful, failed []talosv1alpha1.TalosEtcdBackup
+	for _, backup := range backups {
+		switch {
+		case meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionReady):
+			successful = append(successful, backup)
+		case meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionFailed):
+			failed = append(failed, backup)
+		}
+	}
+	sortBackupsNewestFirst(successful)
+	sortBackupsNewestFirst(failed)
+
+	var prune []talosv1alpha1.TalosEtcdBackup
+	expired := func(backup talosv1alpha1.TalosEtcdBackup) bool {
+		return policy.MaxAge != nil && now.Sub(backupTime(backup)) > policy.MaxAge.Duration
+	}
+
+	// Successful backups are kept if any rule selects them. Without any rule all of them are kept.
+	keepSuccessful := map[string]bool{}
+	hasKeepRule := false
+	if policy.KeepLastSuccessful != nil {
+		hasKeepRule = true
+		for i := 0; i < len(successful) && i < int(*policy.KeepLastSuccessful); i++ {
+			keepSuccessful[successful[i].Name] = true
+		}
+	}
+	periods := []struct {
+		keep   *int32
+		bucket func(time.Time) string
+	}{
+		{policy.KeepHourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{policy.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{policy.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{policy.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, period := range periods {
+		if period.keep == nil {
+			continue
+		}
+		hasKeepRule = true
+		// Backups are sorted newest first, so the first backup of a bucket is the one to keep
+		seen := map[string]bool{}
+		for _, backup := range successful {
+			if len(seen) >= int(*period.keep) {
+				break
+			}
+			bucket := period.bucket(backupTime(backup).In(now.Location()))
+			if seen[bucket] {
+				continue
+			}
+			seen[bucket] = true
+			keepSuccessful[backup.Name] = true
+		}
+	}
+	for _, backup := range successful {
+		if expired(backup) || (hasKeepRule && !keepSuccessful[backup.Name]) {
+			prune = append(prune, backup)
+		}
+	}
+
+	for i, backup := range failed {
+		if expired(backup) || (policy.KeepLastFailed != nil && i >= int(*policy.KeepLastFailed)) {
+			prune = append(prune, backup)
+		}
+	}
+	return prune
+}
+
+// backupTime returns the time a backup was taken, falling back to its creation time
+func backupTime(backup talosv1alpha1.TalosEtcdBackup) time.Time {
+	if backup.Status.StartTime != nil {
+		return backup.Status.StartTime.Time
+	}
+	return backup.CreationTimestamp.Time
+}
+
+// sortBackupsNewestFirst sorts backups by the time they were taken, newest first
+func sortBackupsNewestFirst(backups []talosv1alpha1.TalosEtcdBackup) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backupTime(backups[i]).After(backupTime(backups[j]))
+	})
+}
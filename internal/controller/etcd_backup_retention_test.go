@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var retentionTestNow = time.Date(2025, 6, 15, 12, 30, 0, 0, time.UTC)
+
+func newRetentionTestBackup(name string, startTime time.Time, conditionType string) talosv1alpha1.TalosEtcdBackup {
+	start := metav1.NewTime(startTime)
+	backup := talosv1alpha1.TalosEtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: DefaultNamespace,
+		},
+		Status: talosv1alpha1.TalosEtcdBackupStatus{
+			StartTime: &start,
+		},
+	}
+	if conditionType != "" {
+		backup.Status.Conditions = []metav1.Condition{{
+			Type:   conditionType,
+			Status: metav1.ConditionTrue,
+		}}
+	}
+	return backup
+}
+
+// hourlyBackups returns successful backups taken every hour for the given number of hours before now
+func hourlyBackups(hours int) []talosv1alpha1.TalosEtcdBackup {
+	var backups []talosv1alpha1.TalosEtcdBackup
+	for i := 0; i < hours; i++ {
+		backups = append(backups, newRetentionTestBackup(fmt.Sprintf("backup-%04d", i), retentionTestNow.Add(-time.Duration(i)*time.Hour), talosv1alpha1.ConditionReady))
+	}
+	return backups
+}
+
+func prunedNames(backups []talosv1alpha1.TalosEtcdBackup) []string {
+	names := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSelectBackupsToPrune_KeepLast(t *testing.T) {
+	backups := []talosv1alpha1.TalosEtcdBackup{
+		newRetentionTestBackup("ok-1", retentionTestNow.Add(-1*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("ok-2", retentionTestNow.Add(-2*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("ok-3", retentionTestNow.Add(-3*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("failed-1", retentionTestNow.Add(-90*time.Minute), talosv1alpha1.ConditionFailed),
+		newRetentionTestBackup("failed-2", retentionTestNow.Add(-150*time.Minute), talosv1alpha1.ConditionFailed),
+		newRetentionTestBackup("running", retentionTestNow.Add(-100*time.Hour), ""),
+	}
+	policy := &talosv1alpha1.BackupRetentionPolicy{
+		KeepLastSuccessful: ptr.To[int32](2),
+		KeepLastFailed:     ptr.To[int32](1),
+	}
+	got := prunedNames(selectBackupsToPrune(backups, policy, retentionTestNow))
+	expected := []string{"failed-2", "ok-3"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected pruned backups %v, got %v", expected, got)
+	}
+}
+
+func TestSelectBackupsToPrune_FailedKeptByDefault(t *testing.T) {
+	backups := []talosv1alpha1.TalosEtcdBackup{
+		newRetentionTestBackup("ok-1", retentionTestNow.Add(-1*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("failed-1", retentionTestNow.Add(-2*time.Hour), talosv1alpha1.ConditionFailed),
+	}
+	policy := &talosv1alpha1.BackupRetentionPolicy{KeepLastSuccessful: ptr.To[int32](0)}
+	got := prunedNames(selectBackupsToPrune(backups, policy, retentionTestNow))
+	expected := []string{"ok-1"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected pruned backups %v, got %v", expected, got)
+	}
+}
+
+func TestSelectBackupsToPrune_MaxAge(t *testing.T) {
+	backups := []talosv1alpha1.TalosEtcdBackup{
+		newRetentionTestBackup("ok-new", retentionTestNow.Add(-1*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("ok-old", retentionTestNow.Add(-48*time.Hour), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("failed-old", retentionTestNow.Add(-48*time.Hour), talosv1alpha1.ConditionFailed),
+	}
+	// maxAge wins over the keep rules
+	policy := &talosv1alpha1.BackupRetentionPolicy{
+		MaxAge:             &metav1.Duration{Duration: 24 * time.Hour},
+		KeepLastSuccessful: ptr.To[int32](10),
+	}
+	got := prunedNames(selectBackupsToPrune(backups, policy, retentionTestNow))
+	expected := []string{"failed-old", "ok-old"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected pruned backups %v, got %v", expected, got)
+	}
+}
+
+func TestSelectBackupsToPrune_GFS(t *testing.T) {
+	// A year of hourly backups
+	backups := hourlyBackups(365 * 24)
+	policy := &talosv1alpha1.BackupRetentionPolicy{
+		KeepHourly:  ptr.To[int32](6),
+		KeepDaily:   ptr.To[int32](30),
+		KeepMonthly: ptr.To[int32](12),
+	}
+	pruned := selectBackupsToPrune(backups, policy, retentionTestNow)
+	prunedSet := map[string]bool{}
+	for _, backup := range pruned {
+		prunedSet[backup.Name] = true
+	}
+	var kept []talosv1alpha1.TalosEtcdBackup
+	for _, backup := range backups {
+		if !prunedSet[backup.Name] {
+			kept = append(kept, backup)
+		}
+	}
+
+	days := map[string]bool{}
+	months := map[string]bool{}
+	for _, backup := range kept {
+		days[backup.Status.StartTime.Format("2006-01-02")] = true
+		months[backup.Status.StartTime.Format("2006-01")] = true
+	}
+	if len(days) < 30 {
+		t.Errorf("expected at least 30 daily backups, got %d", len(days))
+	}
+	if len(months) != 12 {
+		t.Errorf("expected 12 monthly backups, got %d", len(months))
+	}
+	// Backups selected by more than one rule are only kept once
+	if len(kept) > 6+30+12 {
+		t.Errorf("expected at most %d backups to be kept, got %d", 6+30+12, len(kept))
+	}
+	for i := 0; i < 6; i++ {
+		if prunedSet[backups[i].Name] {
+			t.Errorf("expected hourly backup %s to be kept", backups[i].Name)
+		}
+	}
+}
+
+func TestEffectiveRetentionPolicy(t *testing.T) {
+	schedule := &talosv1alpha1.TalosEtcdBackupSchedule{}
+	policy := effectiveRetentionPolicy(schedule)
+	if policy.KeepLastSuccessful == nil || *policy.KeepLastSuccessful != defaultBackupRetention {
+		t.Errorf("expected default retention of %d successful backups, got %v", defaultBackupRetention, policy.KeepLastSuccessful)
+	}
+
+	schedule.Spec.Retention = ptr.To[int32](3)
+	policy = effectiveRetentionPolicy(schedule)
+	if policy.KeepLastSuccessful == nil || *policy.KeepLastSuccessful != 3 {
+		t.Errorf("expected legacy retention of 3 successful backups, got %v", policy.KeepLastSuccessful)
+	}
+	if policy.KeepLastFailed != nil {
+		t.Error("expected failed backups to be kept with the legacy retention")
+	}
+
+	schedule.Spec.RetentionPolicy = &talosv1alpha1.BackupRetentionPolicy{KeepDaily: ptr.To[int32](30)}
+	if effectiveRetentionPolicy(schedule) != schedule.Spec.RetentionPolicy {
+		t.Error("expected retentionPolicy to take precedence over retention")
+	}
+}
+
+func TestSelectBackupsToPrune_TimeZone(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	// Midnight in New York is 04:00 UTC, so the backups from 02:00 and 03:00 UTC are of the previous day there
+	backups := []talosv1alpha1.TalosEtcdBackup{
+		newRetentionTestBackup("same-day", retentionTestNow.Add(-(7*time.Hour + 30*time.Minute)), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("local-day-late", retentionTestNow.Add(-(9*time.Hour + 30*time.Minute)), talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("local-day-early", retentionTestNow.Add(-(10*time.Hour + 30*time.Minute)), talosv1alpha1.ConditionReady),
+	}
+	policy := &talosv1alpha1.BackupRetentionPolicy{KeepDaily: ptr.To[int32](2)}
+
+	got := prunedNames(selectBackupsToPrune(backups, policy, retentionTestNow))
+	if expected := []string{"local-day-early", "local-day-late"}; fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected pruned backups %v in UTC, got %v", expected, got)
+	}
+	got = prunedNames(selectBackupsToPrune(backups, policy, retentionTestNow.In(location)))
+	if expected := []string{"local-day-early"}; fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected pruned backups %v in New York, got %v", expected, got)
+	}
+}
@@ -0,0 +1,322 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// machineReachableTimeout bounds the probe whether a machine that is removed from etcd still
+// answers on the Talos API
+const machineReachableTimeout = 15 * time.Second
+
+// etcdMemberHealthy returns true if the machine counts towards the etcd quorum, i.e. it is
+// available and passes its health checks
+func etcdMemberHealthy(m *talosv1alpha1.TalosMachine) bool {
+	return m.DeletionTimestamp.IsZero() && m.Status.State == talosv1alpha1.StateAvailable && machineReady(m, 0, time.Now())
+}
+
+// checkEtcdMemberRemoval returns an error if removing the etcd member of the machine with the given
+// address breaks the quorum. healthy holds the addresses of the healthy control plane machines,
+// members that belong to no healthy machine are counted as down.
+func checkEtcdMemberRemoval(members []*machineapi.EtcdMember, address string, healthy map[string]bool) error {
+	member := talos.EtcdMemberByAddress(members, address)
+	if member == nil || member.IsLearner {
+		// Learners do not vote, so they can always be removed
+		return nil
+	}
+	voters, healthyVoters := 0, 0
+	for _, m := range members {
+		if m.IsLearner {
+			continue
+		}
+		voters++
+		for addr := range healthy {
+			if talos.EtcdMemberByAddress([]*machineapi.EtcdMember{m}, addr) != nil {
+				healthyVoters++
+				break
+			}
+		}
+	}
+	if voters <= 1 {
+		return fmt.Errorf("%s is the last etcd member", address)
+	}
+	if quorum := voters/2 + 1; healthyVoters < quorum {
+		return fmt.Errorf("only %d of %d etcd members are healthy, the quorum of %d is lost", healthyVoters, voters, quorum)
+	}
+	remaining := healthyVoters
+	if healthy[address] {
+		remaining--
+	}
+	if quorum := (voters-1)/2 + 1; remaining < quorum {
+		return fmt.Errorf("removing %s leaves %d of %d etcd members healthy, below the quorum of %d", address, remaining, voters-1, quorum)
+	}
+	return nil
+}
+
+// healthyEtcdMembers returns the addresses of the control plane machines that count towards the
+// etcd quorum
+func healthyEtcdMembers(items []talosv1alpha1.TalosMachine) map[string]bool {
+	healthy := make(map[string]bool)
+	for i := range items {
+		if etcdMemberHealthy(&items[i]) {
+			healthy[items[i].Spec.Endpoint] = true
+		}
+	}
+	return healthy
+}
+
+// scaleDownControlPlane deletes the TalosMachines that were removed from the control plane one at a
+// time. A machine that joined etcd is only deleted if etcd keeps its quorum without it, and leaves
+// etcd as it is deleted. It returns true while machines are waiting to be deleted.
+func (r *TalosControlPlaneReconciler) scaleDownControlPlane(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, items []talosv1alpha1.TalosMachine, desired map[string]bool) (bool, error) {
+	var orphans []*talosv1alpha1.TalosMachine
+	for i := range items {
+		m := &items[i]
+		if desired[m.Name] {
+			continue
+		}
+		if !m.DeletionTimestamp.IsZero() {
+			// Wait for the machine to leave etcd before the next one is removed
+			return true, nil
+		}
+		orphans = append(orphans, m)
+	}
+	if len(orphans) == 0 {
+		return false, r.updateScaleDownBlocked(ctx, tcp, "", nil)
+	}
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return scaleDownPriority(orphans[i]) < scaleDownPriority(orphans[j])
+	})
+	m := orphans[0]
+
+	// Machines that were never installed did not join etcd
+	if m.Status.ObservedVersion != "" {
+		members, err := r.etcdMembers(ctx, tcp, items)
+		if err != nil {
+			return true, r.updateScaleDownBlocked(ctx, tcp, "EtcdUnavailable", fmt.Errorf("failed to list etcd members: %w", err))
+		}
+		if err := checkEtcdMemberRemoval(members, m.Spec.Endpoint, healthyEtcdMembers(items)); err != nil {
+			return true, r.updateScaleDownBlocked(ctx, tcp, "EtcdQuorum", fmt.Errorf("refusing to remove TalosMachine %s: %w", m.Name, err))
+		}
+	}
+	if err := r.updateScaleDownBlocked(ctx, tcp, "", nil); err != nil {
+		return true, err
+	}
+	if isDryRun(tcp) {
+		log.FromContext(ctx).Info("DryRun: would delete TalosMachine", "name", m.Name)
+		return true, nil
+	}
+	if err := deleteChild(ctx, r.Client, m); err != nil {
+		return true, fmt.Errorf("failed to delete orphaned TalosMachine %s: %w", m.Name, err)
+	}
+	r.Recorder.Eventf(tcp, nil, corev1.EventTypeNormal, "ScalingDown", "ScalingDown", fmt.Sprintf("Removing TalosMachine %s from the control plane", m.Name))
+	return true, nil
+}
+
+// scaleDownPriority orders the machines that are removed from a control plane: machines that never
+// joined etcd go first, then machines that are down, so the quorum only improves along the way
+func scaleDownPriority(m *talosv1alpha1.TalosMachine) int {
+	switch {
+	case m.Status.ObservedVersion == "":
+		return 0
+	case !etcdMemberHealthy(m):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// etcdMembers returns the etcd members as seen by the available control plane machines
+func (r *TalosControlPlaneReconciler) etcdMembers(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, items []talosv1alpha1.TalosMachine) ([]*machineapi.EtcdMember, error) {
+	var endpoints []string
+	for i := range items {
+		if etcdMemberHealthy(&items[i]) {
+			endpoints = append(endpoints, items[i].Spec.Endpoint)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no control plane machine is available")
+	}
+	bc, err := controlPlaneBundleConfig(ctx, r.Client, tcp)
+	if err != nil {
+		return nil, err
+	}
+	bc.ClientEndpoint = &endpoints
+	tc, err := talos.NewClient(ctx, bc, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Talos client for TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	defer tc.Close() //nolint:errcheck
+	return tc.EtcdMembers(ctx)
+}
+
+// controlPlaneBundleConfig returns the bundle config of the control plane together with its secrets
+// bundle, which is what a Talos client for its machines needs
+func controlPlaneBundleConfig(ctx context.Context, c client.Client, tcp *talosv1alpha1.TalosControlPlane) (*talos.BundleConfig, error) {
+	if tcp.Status.BundleConfig == "" {
+		return nil, fmt.Errorf("TalosControlPlane %s bundleConfig is empty", tcp.Name)
+	}
+	bc, err := talos.ParseBundleConfig(tcp.Status.BundleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle config: %w", err)
+	}
+	sb, err := getSecretBundle(ctx, c, tcp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret bundle: %w", err)
+	}
+	sb.Clock = talos.NewClock()
+	bc.SecretsBundle = sb
+	return bc, nil
+}
+
+// updateScaleDownBlocked records on the conditions of the control plane why the scale-down is
+// blocked, or that it is not blocked anymore once the condition was added
+func (r *TalosControlPlaneReconciler) updateScaleDownBlocked(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, reason string, blockErr error) error {
+	if blockErr == nil && meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionScaleDownBlocked) == nil {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionScaleDownBlocked,
+		Status:  metav1.ConditionFalse,
+		Reason:  "QuorumSafe",
+		Message: "Removed machines can leave etcd without breaking the quorum",
+	}
+	if blockErr != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = blockErr.Error()
+	}
+	if !meta.SetStatusCondition(&tcp.Status.Conditions, condition) || isDryRun(tcp) {
+		return nil
+	}
+	if blockErr != nil {
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "ScaleDownBlocked", "ScaleDownBlocked", blockErr.Error())
+	}
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return fmt.Errorf("failed to update scale-down status of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return nil
+}
+
+// leavesEtcd returns true if the control plane machine is removed from a control plane that keeps
+// running, so it has to leave etcd. Machines of a control plane that is deleted as a whole keep
+// their membership, as etcd is torn down with them.
+func (r *TalosMachineReconciler) leavesEtcd(ctx context.Context, tm *talosv1alpha1.TalosMachine) (bool, error) {
+	if tm.Spec.ControlPlaneRef == nil || tm.Status.ObservedVersion == "" {
+		return false, nil
+	}
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tm.Namespace, Name: tm.Spec.ControlPlaneRef.Name}, tcp); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get TalosControlPlane %s: %w", tm.Spec.ControlPlaneRef.Name, err)
+	}
+	return tcp.DeletionTimestamp.IsZero(), nil
+}
+
+// controlPlaneTornDown returns true if the machine belongs to a control plane that is deleted as a
+// whole, so etcd is torn down with its machines
+func (r *TalosMachineReconciler) controlPlaneTornDown(ctx context.Context, tm *talosv1alpha1.TalosMachine) (bool, error) {
+	if tm.Spec.ControlPlaneRef == nil {
+		return false, nil
+	}
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tm.Namespace, Name: tm.Spec.ControlPlaneRef.Name}, tcp); err != nil {
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get TalosControlPlane %s: %w", tm.Spec.ControlPlaneRef.Name, err)
+	}
+	return !tcp.DeletionTimestamp.IsZero(), nil
+}
+
+// machineReachable returns true if the machine answers on the Talos API
+func machineReachable(ctx context.Context, tc *talos.TalosClient) bool {
+	ctx, cancel := context.WithTimeout(ctx, machineReachableTimeout)
+	defer cancel()
+	_, err := tc.Version(ctx)
+	return err == nil
+}
+
+// checkMachineLeavesEtcd returns an error if the reachable control plane machine cannot leave etcd
+// without breaking the quorum, the same check a scale-down runs before it removes a machine
+func (r *TalosMachineReconciler) checkMachineLeavesEtcd(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient) error {
+	machines := &talosv1alpha1.TalosMachineList{}
+	if err := r.List(ctx, machines, client.InNamespace(tm.Namespace),
+		client.MatchingFields{IndexControlPlaneRefName: tm.Spec.ControlPlaneRef.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list TalosMachines: %w", err)
+	}
+	members, err := tc.EtcdMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list etcd members: %w", err)
+	}
+	return checkEtcdMemberRemoval(members, tm.Spec.Endpoint, healthyEtcdMembers(machines.Items))
+}
+
+// removeEtcdMember removes the member of the machine from etcd. A reachable machine leaves etcd on
+// its own, the member of a machine that cannot be reached anymore is removed through a healthy peer.
+func (r *TalosMachineReconciler) removeEtcdMember(ctx context.Context, tm *talosv1alpha1.TalosMachine, bc *talos.BundleConfig, tc *talos.TalosClient, reachable bool) error {
+	logger := log.FromContext(ctx)
+	if reachable {
+		err := tc.LeaveEtcd(ctx)
+		if err == nil {
+			r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "EtcdMemberLeft", "EtcdMemberLeft", "Machine left the etcd cluster")
+			return nil
+		}
+		// The machine may have left already or its etcd is down, check the membership through a peer
+		logger.Info("Machine failed to leave etcd, removing its member through a peer", "name", tm.Name, "error", err.Error())
+	}
+
+	machines := &talosv1alpha1.TalosMachineList{}
+	if err := r.List(ctx, machines, client.InNamespace(tm.Namespace),
+		client.MatchingFields{IndexControlPlaneRefName: tm.Spec.ControlPlaneRef.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list TalosMachines: %w", err)
+	}
+	var peers []string
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if m.Name != tm.Name && etcdMemberHealthy(m) {
+			peers = append(peers, m.Spec.Endpoint)
+		}
+	}
+	if len(peers) == 0 {
+		return fmt.Errorf("no healthy control plane machine to remove the etcd member of TalosMachine %s through", tm.Name)
+	}
+	peerConfig := *bc
+	peerConfig.ClientEndpoint = &peers
+	peer, err := talos.NewClient(ctx, &peerConfig, false)
+	if err != nil {
+		return fmt.Errorf("failed to create Talos client for the peers of TalosMachine %s: %w", tm.Name, err)
+	}
+	defer peer.Close() //nolint:errcheck
+	members, err := peer.EtcdMembers(ctx)
+	if err != nil {
+		return err
+	}
+	member := talos.EtcdMemberByAddress(members, tm.Spec.Endpoint)
+	if member == nil {
+		return nil
+	}
+	if err := peer.RemoveEtcdMember(ctx, member.Id); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "EtcdMemberRemoved", "EtcdMemberRemoved",
+		fmt.Sprintf("Removed etcd member %s of the unreachable machine through its peers", member.Hostname))
+	return nil
+}
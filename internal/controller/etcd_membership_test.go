@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newEtcdTestMembers(addresses ...string) []*machineapi.EtcdMember {
+	members := make([]*machineapi.EtcdMember, len(addresses))
+	for i, address := range addresses {
+		members[i] = &machineapi.EtcdMember{
+			Id:       uint64(i + 1),
+			Hostname: fmt.Sprintf("cp-%d", i),
+			PeerUrls: []string{fmt.Sprintf("https://%s:2380", address)},
+		}
+	}
+	return members
+}
+
+func TestCheckEtcdMemberRemoval(t *testing.T) {
+	three := newEtcdTestMembers("10.0.0.1", "10.0.0.2", "10.0.0.3")
+	learner := newEtcdTestMembers("10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4")
+	learner[3].IsLearner = true
+	tests := []struct {
+		name    string
+		members []*machineapi.EtcdMember
+		address string
+		healthy []string
+		wantErr bool
+	}{
+		{name: "healthy cluster", members: three, address: "10.0.0.3", healthy: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{name: "dead member", members: three, address: "10.0.0.3", healthy: []string{"10.0.0.1", "10.0.0.2"}},
+		{name: "healthy member next to a dead one", members: three, address: "10.0.0.3", healthy: []string{"10.0.0.2", "10.0.0.3"}, wantErr: true},
+		{name: "quorum lost", members: three, address: "10.0.0.3", healthy: []string{"10.0.0.1"}, wantErr: true},
+		{name: "last member", members: newEtcdTestMembers("10.0.0.1"), address: "10.0.0.1", healthy: []string{"10.0.0.1"}, wantErr: true},
+		{name: "two members", members: newEtcdTestMembers("10.0.0.1", "10.0.0.2"), address: "10.0.0.2", healthy: []string{"10.0.0.1", "10.0.0.2"}},
+		{name: "learner", members: learner, address: "10.0.0.4", healthy: []string{"10.0.0.1"}},
+		{name: "not a member", members: three, address: "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy := make(map[string]bool)
+			for _, address := range tt.healthy {
+				healthy[address] = true
+			}
+			if err := checkEtcdMemberRemoval(tt.members, tt.address, healthy); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScaleDownControlPlane(t *testing.T) {
+	ctx := context.Background()
+	tcp := newPreUpgradeTestControlPlane()
+	var objects []client.Object
+	var items []talosv1alpha1.TalosMachine
+	for i := range 3 {
+		machine := newRolloutTestMachine(fmt.Sprintf("test-cp-10.0.0.%d", i+1), "v1.13.0", nil)
+		machine.Spec.Endpoint = fmt.Sprintf("10.0.0.%d", i+1)
+		machine.Finalizers = []string{talosv1alpha1.TalosMachineFinalizer}
+		items = append(items, machine)
+	}
+	// The second machine was never installed
+	items[1].Status.ObservedVersion = ""
+	for i := range items {
+		objects = append(objects, &items[i])
+	}
+	c := newTestClient(t, append(objects, tcp)...)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	desired := map[string]bool{"test-cp-10.0.0.1": true}
+
+	if _, err := r.scaleDownControlPlane(ctx, tcp, items, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	remaining := &talosv1alpha1.TalosMachineList{}
+	if err := c.List(ctx, remaining); err != nil {
+		t.Fatalf("failed to list machines: %v", err)
+	}
+	for _, m := range remaining.Items {
+		if deleting := !m.DeletionTimestamp.IsZero(); deleting != (m.Name == "test-cp-10.0.0.2") {
+			t.Errorf("expected only the machine that never joined etcd to be deleted, %s deleting: %v", m.Name, deleting)
+		}
+	}
+
+	// The next machine waits for the deletion to finish
+	held, err := r.scaleDownControlPlane(ctx, tcp, remaining.Items, desired)
+	if err != nil || !held {
+		t.Fatalf("expected the scale-down to wait, got %v, %v", held, err)
+	}
+
+	// Without a reachable etcd the installed machine is kept
+	items = []talosv1alpha1.TalosMachine{items[0], items[2]}
+	held, err = r.scaleDownControlPlane(ctx, tcp, items, desired)
+	if err != nil || !held {
+		t.Fatalf("expected the scale-down to be blocked, got %v, %v", held, err)
+	}
+	condition := meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionScaleDownBlocked)
+	if condition == nil || condition.Reason != "EtcdUnavailable" {
+		t.Fatalf("expected the scale-down to be blocked, got %+v", condition)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&items[1]), &items[1]); err != nil || !items[1].DeletionTimestamp.IsZero() {
+		t.Errorf("expected the installed machine to be kept, got %v", err)
+	}
+}
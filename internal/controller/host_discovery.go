@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+const (
+	// discoveryProbeTimeout is how long an address is probed before it counts as not answering
+	discoveryProbeTimeout = 5 * time.Second
+	// discoveryConcurrency is how many addresses are probed at once
+	discoveryConcurrency = 32
+	// discoveryMaxAddresses is how many addresses a single network may have
+	discoveryMaxAddresses = 4096
+	// discoveryBatchSize is how many addresses are probed by a single reconcile, which bounds a
+	// reconcile to discoveryBatchSize / discoveryConcurrency * discoveryProbeTimeout
+	discoveryBatchSize = 256
+)
+
+// probeMaintenanceHost probes the Talos API of a machine in maintenance mode. It is a variable so
+// tests can replace the calls to the Talos API.
+var probeMaintenanceHost = talos.ProbeMaintenance
+
+// cidrAddresses returns the host addresses of the network, without its network and broadcast address
+func cidrAddresses(cidr string) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", cidr, err)
+	}
+	if !prefix.Addr().Is4() {
+		return nil, fmt.Errorf("invalid CIDR %s: only IPv4 networks are supported", cidr)
+	}
+	prefix = prefix.Masked()
+	size := 1 << (32 - prefix.Bits())
+	if size > discoveryMaxAddresses {
+		return nil, fmt.Errorf("CIDR %s has %d addresses, at most %d are scanned", cidr, size, discoveryMaxAddresses)
+	}
+	var addresses []string
+	for addr, i := prefix.Addr(), 0; i < size; addr, i = addr.Next(), i+1 {
+		// Point-to-point networks have no network and broadcast address
+		if size > 2 && (i == 0 || i == size-1) {
+			continue
+		}
+		addresses = append(addresses, addr.String())
+	}
+	return addresses, nil
+}
+
+// nextScanBatch returns the addresses probed by the next reconcile of a scan that continues at start,
+// and where the scan continues afterwards. The scan is complete once next is 0.
+func nextScanBatch(addresses []string, start int) ([]string, int) {
+	if start >= len(addresses) {
+		start = 0
+	}
+	end := min(start+discoveryBatchSize, len(addresses))
+	if end == len(addresses) {
+		return addresses[start:end], 0
+	}
+	return addresses[start:end], end
+}
+
+// probeAddresses probes the addresses concurrently and returns the facts of the machines in
+// maintenance mode by their address. Addresses that do not answer are left out.
+func probeAddresses(ctx context.Context, addresses []string, port int) map[string]*talos.HostFacts {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	found := make(map[string]*talos.HostFacts)
+	sem := make(chan struct{}, discoveryConcurrency)
+	for _, address := range addresses {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			probeCtx, cancel := context.WithTimeout(ctx, discoveryProbeTimeout)
+			defer cancel()
+			facts, err := probeMaintenanceHost(probeCtx, address, port)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			found[address] = facts
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return found
+}
+
+// discoveredHostName returns the name of a discovered host, derived from its MAC address so the host
+// keeps its name when its address changes
+func discoveredHostName(mac, address string) string {
+	if mac != "" {
+		return "host-" + strings.ReplaceAll(strings.ToLower(mac), ":", "")
+	}
+	return "host-" + strings.ReplaceAll(address, ".", "-")
+}
+
+// newDiscoveredHost returns an unclaimed TalosHost with the facts the machine reported
+func newDiscoveredHost(thd *talosv1alpha1.TalosHostDiscovery, address, mac string, facts *talos.HostFacts) *talosv1alpha1.TalosHost {
+	labels := make(map[string]string, len(thd.Spec.HostLabels)+1)
+	for k, v := range thd.Spec.HostLabels {
+		labels[k] = v
+	}
+	labels[talosv1alpha1.TalosHostDiscoveryLabelKey] = thd.Name
+	host := &talosv1alpha1.TalosHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      discoveredHostName(mac, address),
+			Namespace: thd.Namespace,
+			Labels:    labels,
+		},
+		Spec: talosv1alpha1.TalosHostSpec{
+			Address:      address,
+			MACAddress:   mac,
+			Architecture: facts.Architecture,
+			Hardware:     hardwareFromFacts(facts),
+		},
+	}
+	if host.Spec.Architecture != "arm64" {
+		host.Spec.Architecture = "amd64"
+	}
+	return host
+}
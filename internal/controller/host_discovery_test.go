@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+func TestCIDRAddresses(t *testing.T) {
+	addresses, err := cidrAddresses("10.0.0.0/30")
+	if err != nil || !slices.Equal(addresses, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("expected the host addresses of the network, got %v, %v", addresses, err)
+	}
+	if addresses, err = cidrAddresses("10.0.0.8/31"); err != nil || len(addresses) != 2 {
+		t.Fatalf("expected both addresses of a point-to-point network, got %v, %v", addresses, err)
+	}
+	if _, err = cidrAddresses("10.0.0.0/16"); err == nil {
+		t.Errorf("expected an error for a network above %d addresses", discoveryMaxAddresses)
+	}
+	if _, err = cidrAddresses("fd00::/120"); err == nil {
+		t.Errorf("expected an error for an IPv6 network")
+	}
+}
+
+func TestTalosHostDiscoveryReconcile(t *testing.T) {
+	ctx := context.Background()
+	orig := probeMaintenanceHost
+	probeMaintenanceHost = func(_ context.Context, address string, _ int) (*talos.HostFacts, error) {
+		switch address {
+		case "10.0.0.1":
+			return &talos.HostFacts{Architecture: "amd64", MACAddress: "52:54:00:00:00:01", CPUs: 4, MemoryBytes: 8 << 30,
+				Disks: []talos.DiskFacts{{Name: "/dev/nvme0n1", Size: 512 << 30}}}, nil
+		case "10.0.0.2", "10.0.0.3":
+			return &talos.HostFacts{Architecture: "arm64", MACAddress: "52:54:00:00:00:0" + address[len(address)-1:]}, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	t.Cleanup(func() { probeMaintenanceHost = orig })
+
+	// 10.0.0.2 is in the inventory already, 10.0.0.3 runs a TalosMachine
+	known := newTestHost("rack-b-2", "10.0.0.2", "rack-b")
+	running := newRolloutTestMachine("test-10.0.0.3", "v1.13.0", nil)
+	running.Spec.Endpoint = "10.0.0.3"
+	thd := &talosv1alpha1.TalosHostDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Name: "rack-b", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosHostDiscoverySpec{
+			CIDRs:      []string{"10.0.0.0/29"},
+			Port:       talos.DefaultAPIPort,
+			HostLabels: map[string]string{"pool": "rack-b"},
+		},
+	}
+	c := newTestClient(t, thd, known, &running)
+	r := &TalosHostDiscoveryReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	key := client.ObjectKeyFromObject(thd)
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hosts := &talosv1alpha1.TalosHostList{}
+	if err := c.List(ctx, hosts, client.MatchingLabels{talosv1alpha1.TalosHostDiscoveryLabelKey: thd.Name}); err != nil {
+		t.Fatalf("failed to list hosts: %v", err)
+	}
+	if len(hosts.Items) != 1 {
+		t.Fatalf("expected only the unknown machine to be added, got %d hosts", len(hosts.Items))
+	}
+	host := hosts.Items[0]
+	if host.Name != "host-525400000001" || host.Spec.Address != "10.0.0.1" || host.Labels["pool"] != "rack-b" ||
+		host.Spec.Hardware.CPUs != 4 || host.Spec.Hardware.Memory.String() != "8Gi" || len(host.Spec.Hardware.Disks) != 1 {
+		t.Errorf("unexpected host %s: %+v", host.Name, host.Spec)
+	}
+
+	if err := c.Get(ctx, key, thd); err != nil {
+		t.Fatalf("failed to get discovery: %v", err)
+	}
+	cond := meta.FindStatusCondition(thd.Status.Conditions, talosv1alpha1.ConditionReady)
+	if cond == nil || cond.Status != metav1.ConditionTrue || thd.Status.ScannedAddresses != 6 || thd.Status.DiscoveredHosts != 1 {
+		t.Errorf("unexpected status: %+v", thd.Status)
+	}
+
+	// The machine got a new address from DHCP, the host follows it
+	probeMaintenanceHost = func(_ context.Context, address string, _ int) (*talos.HostFacts, error) {
+		if address == "10.0.0.5" {
+			return &talos.HostFacts{MACAddress: "52:54:00:00:00:01"}, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&host), &host); err != nil {
+		t.Fatalf("failed to get host: %v", err)
+	}
+	if host.Spec.Address != "10.0.0.5" {
+		t.Errorf("expected the host to move to 10.0.0.5, got %s", host.Spec.Address)
+	}
+}
+
+func TestNextScanBatch(t *testing.T) {
+	addresses, err := cidrAddresses("10.0.0.0/23")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	batch, next := nextScanBatch(addresses, 0)
+	if len(batch) != discoveryBatchSize || next != discoveryBatchSize {
+		t.Fatalf("expected the first batch of %d addresses, got %d, next %d", discoveryBatchSize, len(batch), next)
+	}
+	batch, next = nextScanBatch(addresses, next)
+	if len(batch) != len(addresses)-discoveryBatchSize || next != 0 {
+		t.Fatalf("expected the rest of the addresses to complete the scan, got %d, next %d", len(batch), next)
+	}
+	// The addresses shrank since the scan started
+	if batch, next = nextScanBatch(addresses[:10], discoveryBatchSize); len(batch) != 10 || next != 0 {
+		t.Errorf("expected the scan to start over, got %d, next %d", len(batch), next)
+	}
+}
+
+func TestTalosHostDiscoveryScanBatches(t *testing.T) {
+	ctx := context.Background()
+	orig := probeMaintenanceHost
+	probeMaintenanceHost = func(_ context.Context, address string, _ int) (*talos.HostFacts, error) {
+		switch address {
+		case "10.0.0.1":
+			return &talos.HostFacts{MACAddress: "52:54:00:00:00:01"}, nil
+		case "10.0.1.1":
+			return &talos.HostFacts{MACAddress: "52:54:00:00:01:01"}, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	t.Cleanup(func() { probeMaintenanceHost = orig })
+
+	thd := &talosv1alpha1.TalosHostDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Name: "rack-c", Namespace: DefaultNamespace},
+		Spec:       talosv1alpha1.TalosHostDiscoverySpec{CIDRs: []string{"10.0.0.0/23"}},
+	}
+	// Another discovery of the network adds the host at 10.0.1.1 after the hosts were listed
+	c := interceptor.NewClient(newTestClient(t, thd), interceptor.Funcs{
+		Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if host, ok := obj.(*talosv1alpha1.TalosHost); ok && host.Spec.Address == "10.0.1.1" {
+				return kerrors.NewAlreadyExists(talosv1alpha1.GroupVersion.WithResource("taloshosts").GroupResource(), host.Name)
+			}
+			return cl.Create(ctx, obj, opts...)
+		},
+	})
+	recorder := events.NewFakeRecorder(10)
+	r := &TalosHostDiscoveryReconciler{Client: c, Scheme: c.Scheme(), Recorder: recorder}
+	key := client.ObjectKeyFromObject(thd)
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, key, thd); err != nil {
+		t.Fatalf("failed to get discovery: %v", err)
+	}
+	if res.RequeueAfter != time.Second || thd.Status.LastScanTime != nil || thd.Status.DiscoveredHosts != 1 {
+		t.Fatalf("expected the scan to continue with the next batch, got %+v, %+v", res, thd.Status)
+	}
+
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, key, thd); err != nil {
+		t.Fatalf("failed to get discovery: %v", err)
+	}
+	if res.RequeueAfter != defaultDiscoveryInterval || thd.Status.LastScanTime == nil || thd.Status.ScannedAddresses != 510 {
+		t.Fatalf("expected the scan to be complete, got %+v, %+v", res, thd.Status)
+	}
+	// The host that already existed is neither counted nor reported
+	if thd.Status.DiscoveredHosts != 1 {
+		t.Errorf("expected 1 discovered host, got %d", thd.Status.DiscoveredHosts)
+	}
+	if n := len(recorder.Events); n != 1 {
+		t.Errorf("expected a single HostDiscovered event, got %d", n)
+	}
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// hostClaimedBy returns true if the host is claimed by the control plane or worker
+func hostClaimedBy(host *talosv1alpha1.TalosHost, kind, name string) bool {
+	ref := host.Spec.ClaimedBy
+	return ref != nil && ref.Kind == kind && ref.Name == name
+}
+
+// listClaimedHosts returns the hosts claimed by the control plane or worker, sorted by name
+func listClaimedHosts(ctx context.Context, c client.Client, namespace, kind, name string) ([]talosv1alpha1.TalosHost, error) {
+	hosts := &talosv1alpha1.TalosHostList{}
+	if err := c.List(ctx, hosts, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list TalosHosts: %w", err)
+	}
+	var claimed []talosv1alpha1.TalosHost
+	for i := range hosts.Items {
+		if hostClaimedBy(&hosts.Items[i], kind, name) {
+			claimed = append(claimed, hosts.Items[i])
+		}
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].Name < claimed[j].Name })
+	return claimed, nil
+}
+
+// hostMachines returns the machines of the metal spec followed by a machine for each of the hosts,
+// managed through the BMC of the host if it has one
+func hostMachines(machines []talosv1alpha1.Machine, hosts []talosv1alpha1.TalosHost) []talosv1alpha1.Machine {
+	all := make([]talosv1alpha1.Machine, 0, len(machines)+len(hosts))
+	all = append(all, machines...)
+	for i := range hosts {
+		address := hosts[i].Spec.Address
+		all = append(all, talosv1alpha1.Machine{Address: &address, BMC: hosts[i].Spec.BMC})
+	}
+	return all
+}
+
+// metalMachines returns the machines of a metal control plane or worker: the machines that are listed
+// followed by the hosts it claimed from the inventory
+func metalMachines(ctx context.Context, c client.Client, kind string, owner client.Object, metal *talosv1alpha1.MetalSpec) ([]talosv1alpha1.Machine, error) {
+	if metal.HostSelector == nil {
+		return metal.Machines, nil
+	}
+	hosts, err := listClaimedHosts(ctx, c, owner.GetNamespace(), kind, owner.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return hostMachines(metal.Machines, hosts), nil
+}
+
+// claimHosts claims free hosts that match the host selector of the metal spec until replicas hosts
+// are claimed by the control plane or worker. Claimed hosts above replicas, or that do not match the
+// selector anymore, are released once no TalosMachine runs on them. Hosts claimed by a control plane or
+// worker that no longer exists are free. It returns the claimed hosts that
+// back machines, sorted by name, and how many hosts are missing.
+func claimHosts(ctx context.Context, c client.Client, kind string, owner client.Object, metal *talosv1alpha1.MetalSpec,
+	existing []talosv1alpha1.TalosMachine,
+) ([]talosv1alpha1.TalosHost, int, error) {
+	want := 0
+	selector := labels.Nothing()
+	if metal.HostSelector != nil {
+		s, err := metav1.LabelSelectorAsSelector(metal.HostSelector)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid hostSelector of %s %s: %w", kind, owner.GetName(), err)
+		}
+		selector = s
+		if metal.Replicas != nil {
+			want = int(*metal.Replicas)
+		}
+	}
+	hosts := &talosv1alpha1.TalosHostList{}
+	if err := c.List(ctx, hosts, client.InNamespace(owner.GetNamespace())); err != nil {
+		return nil, 0, fmt.Errorf("failed to list TalosHosts: %w", err)
+	}
+	sort.Slice(hosts.Items, func(i, j int) bool { return hosts.Items[i].Name < hosts.Items[j].Name })
+	running := make(map[string]bool, len(existing))
+	for i := range existing {
+		running[existing[i].Spec.Endpoint] = true
+	}
+
+	var claimed, free []*talosv1alpha1.TalosHost
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		matches := host.DeletionTimestamp.IsZero() && selector.Matches(labels.Set(host.Labels))
+		switch {
+		case hostClaimedBy(host, kind, owner.GetName()):
+			if matches && len(claimed) < want {
+				claimed = append(claimed, host)
+				continue
+			}
+			// Release the host once its machine is reset and deleted
+			if !running[host.Spec.Address] {
+				if err := setHostClaim(ctx, c, host, nil); err != nil {
+					return nil, 0, err
+				}
+			}
+		case host.Spec.ClaimedBy == nil && matches:
+			free = append(free, host)
+		case matches:
+			// A host whose owner was deleted without releasing it is free
+			gone, err := hostClaimOwnerGone(ctx, c, host)
+			if err != nil {
+				return nil, 0, err
+			}
+			if gone {
+				free = append(free, host)
+			}
+		}
+	}
+	for _, host := range free {
+		if len(claimed) >= want {
+			break
+		}
+		if err := setHostClaim(ctx, c, host, &corev1.ObjectReference{
+			Kind:       kind,
+			Name:       owner.GetName(),
+			Namespace:  owner.GetNamespace(),
+			APIVersion: talosv1alpha1.GroupVersion.String(),
+		}); err != nil {
+			return nil, 0, err
+		}
+		claimed = append(claimed, host)
+	}
+
+	result := make([]talosv1alpha1.TalosHost, 0, len(claimed))
+	for _, host := range claimed {
+		result = append(result, *host)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, want - len(result), nil
+}
+
+// hostClaimOwnerGone returns true if the TalosControlPlane or TalosWorker that claimed the host does
+// not exist anymore
+func hostClaimOwnerGone(ctx context.Context, c client.Client, host *talosv1alpha1.TalosHost) (bool, error) {
+	ref := host.Spec.ClaimedBy
+	var owner client.Object
+	switch ref.Kind {
+	case talosv1alpha1.GroupKindControlPlane:
+		owner = &talosv1alpha1.TalosControlPlane{}
+	case talosv1alpha1.GroupKindWorker:
+		owner = &talosv1alpha1.TalosWorker{}
+	default:
+		return false, nil
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = host.Namespace
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, owner); err != nil {
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get %s %s that claimed TalosHost %s: %w", ref.Kind, ref.Name, host.Name, err)
+	}
+	return false, nil
+}
+
+// releaseHosts releases all hosts claimed by the deleted control plane or worker once its machines are gone
+func releaseHosts(ctx context.Context, c client.Client, namespace, kind, name string) error {
+	hosts, err := listClaimedHosts(ctx, c, namespace, kind, name)
+	if err != nil {
+		return err
+	}
+	for i := range hosts {
+		if err := setHostClaim(ctx, c, &hosts[i], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setHostClaim sets or clears the claim of a host. The update fails if the host changed in the
+// meantime, so two owners never claim the same host.
+func setHostClaim(ctx context.Context, c client.Client, host *talosv1alpha1.TalosHost, ref *corev1.ObjectReference) error {
+	patch := client.MergeFromWithOptions(host.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	host.Spec.ClaimedBy = ref
+	if err := c.Patch(ctx, host, patch); err != nil {
+		return fmt.Errorf("failed to update the claim of TalosHost %s: %w", host.Name, err)
+	}
+	return nil
+}
+
+// hostNames returns the names of the hosts
+func hostNames(hosts []talosv1alpha1.TalosHost) []string {
+	var names []string
+	for i := range hosts {
+		names = append(names, hosts[i].Name)
+	}
+	return names
+}
+
+// setHostsClaimedCondition records on the conditions whether all hosts the host selector asks for
+// are claimed and returns true if the condition changed
+func setHostsClaimedCondition(conditions *[]metav1.Condition, metal *talosv1alpha1.MetalSpec, hosts []talosv1alpha1.TalosHost, missing int) bool {
+	if metal.HostSelector == nil {
+		return meta.RemoveStatusCondition(conditions, talosv1alpha1.ConditionHostsClaimed)
+	}
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionHostsClaimed,
+		Status:  metav1.ConditionTrue,
+		Reason:  "HostsClaimed",
+		Message: fmt.Sprintf("%d hosts are claimed", len(hosts)),
+	}
+	if missing > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InsufficientHosts"
+		condition.Message = fmt.Sprintf("%d of %d hosts are claimed, no free host matches the hostSelector", len(hosts), len(hosts)+missing)
+	}
+	return meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newTestHost(name, address, pool string) *talosv1alpha1.TalosHost {
+	return &talosv1alpha1.TalosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace, Labels: map[string]string{"pool": pool}},
+		Spec:       talosv1alpha1.TalosHostSpec{Address: address},
+	}
+}
+
+func TestClaimHosts(t *testing.T) {
+	ctx := context.Background()
+	taken := newTestHost("host-0", "10.0.0.10", "rack-b")
+	taken.Spec.ClaimedBy = &corev1.ObjectReference{Kind: talosv1alpha1.GroupKindWorker, Name: "other"}
+	c := newTestClient(t, taken,
+		&talosv1alpha1.TalosWorker{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: DefaultNamespace}},
+		newTestHost("host-1", "10.0.0.11", "rack-b"),
+		newTestHost("host-2", "10.0.0.12", "rack-b"),
+		newTestHost("host-3", "10.0.0.13", "rack-a"),
+	)
+	tw := &talosv1alpha1.TalosWorker{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace}}
+	tw.Spec.MetalSpec = talosv1alpha1.MetalSpec{
+		HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "rack-b"}},
+		Replicas:     ptr.To[int32](3),
+	}
+
+	// Free hosts of the pool are claimed, the one claimed by another worker is left alone
+	hosts, missing, err := claimHosts(ctx, c, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := hostNames(hosts); !slices.Equal(names, []string{"host-1", "host-2"}) || missing != 1 {
+		t.Fatalf("expected host-1 and host-2 to be claimed with one missing, got %v and %d missing", names, missing)
+	}
+	machines, err := metalMachines(ctx, c, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec)
+	if err != nil || len(machines) != 2 || *machines[0].Address != "10.0.0.11" {
+		t.Fatalf("expected the machines of the claimed hosts, got %v, %v", machines, err)
+	}
+
+	// On scale-down the host is kept until its machine is gone
+	tw.Spec.MetalSpec.Replicas = ptr.To[int32](1)
+	running := newRolloutTestMachine("test-10.0.0.12", "v1.13.0", nil)
+	running.Spec.Endpoint = "10.0.0.12"
+	if hosts, _, err = claimHosts(ctx, c, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec, []talosv1alpha1.TalosMachine{running}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := hostNames(hosts); !slices.Equal(names, []string{"host-1"}) {
+		t.Fatalf("expected only host-1 to back a machine, got %v", names)
+	}
+	host := &talosv1alpha1.TalosHost{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: "host-2"}, host); err != nil {
+		t.Fatalf("failed to get host: %v", err)
+	}
+	if !hostClaimedBy(host, talosv1alpha1.GroupKindWorker, tw.Name) {
+		t.Fatalf("expected host-2 to stay claimed while its machine runs")
+	}
+
+	if _, _, err = claimHosts(ctx, c, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: "host-2"}, host); err != nil {
+		t.Fatalf("failed to get host: %v", err)
+	}
+	if host.Spec.ClaimedBy != nil {
+		t.Errorf("expected host-2 to be released, got %+v", host.Spec.ClaimedBy)
+	}
+}
+
+func TestReleaseHostsOfDeletedOwner(t *testing.T) {
+	ctx := context.Background()
+	stale := newTestHost("host-0", "10.0.0.10", "rack-b")
+	stale.Spec.ClaimedBy = &corev1.ObjectReference{Kind: talosv1alpha1.GroupKindWorker, Name: "deleted", Namespace: DefaultNamespace}
+	c := newTestClient(t, stale, newTestHost("host-1", "10.0.0.11", "rack-b"))
+	tw := &talosv1alpha1.TalosWorker{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace}}
+	tw.Spec.MetalSpec = talosv1alpha1.MetalSpec{
+		HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "rack-b"}},
+		Replicas:     ptr.To[int32](2),
+	}
+
+	// The claim of a worker that no longer exists does not keep the host out of the pool
+	hosts, missing, err := claimHosts(ctx, c, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := hostNames(hosts); !slices.Equal(names, []string{"host-0", "host-1"}) || missing != 0 {
+		t.Fatalf("expected host-0 and host-1 to be claimed, got %v and %d missing", names, missing)
+	}
+
+	// Deleting the worker returns its hosts to the pool
+	if err := releaseHosts(ctx, c, DefaultNamespace, talosv1alpha1.GroupKindWorker, tw.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claimed, err := listClaimedHosts(ctx, c, DefaultNamespace, talosv1alpha1.GroupKindWorker, tw.Name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("expected all hosts to be released, got %v", hostNames(claimed))
+	}
+}
+
+func TestSetHostsClaimedCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	metal := &talosv1alpha1.MetalSpec{HostSelector: &metav1.LabelSelector{}}
+	if !setHostsClaimedCondition(&conditions, metal, nil, 2) || conditions[0].Reason != "InsufficientHosts" {
+		t.Fatalf("expected the InsufficientHosts condition, got %+v", conditions)
+	}
+	if setHostsClaimedCondition(&conditions, metal, nil, 2) {
+		t.Errorf("expected the condition to be unchanged")
+	}
+	metal.HostSelector = nil
+	if !setHostsClaimedCondition(&conditions, metal, nil, 0) || len(conditions) != 0 {
+		t.Errorf("expected the condition to be removed without a hostSelector, got %+v", conditions)
+	}
+}
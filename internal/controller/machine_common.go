@@ -176,9 +176,16 @@ func getMachinesIPAddresses(ctx context.Context, c client.Client, machines *[]ta
 	return ips, nil
 }
 
-// getMachinesResolved resolves the IP address for each machine and returns a map of IP to Machine
-func getMachinesResolved(ctx context.Context, c client.Client, machines *[]talosv1alpha1.Machine) (map[string]talosv1alpha1.Machine, error) {
-	resolved := make(map[string]talosv1alpha1.Machine)
+// resolvedMachine is a machine of a control plane or worker together with its IP address
+type resolvedMachine struct {
+	IP      string
+	Machine talosv1alpha1.Machine
+}
+
+// getMachinesResolved resolves the IP address for each machine. The machines are returned in the
+// order they are listed in, so that their index can be used as ordinal.
+func getMachinesResolved(ctx context.Context, c client.Client, machines *[]talosv1alpha1.Machine) ([]resolvedMachine, error) {
+	resolved := make([]resolvedMachine, 0, len(*machines))
 	for _, machine := range *machines {
 		ip, err := getMachineIPAddress(ctx, c, &machine)
 		if err != nil {
@@ -187,7 +194,7 @@ func getMachinesResolved(ctx context.Context, c client.Client, machines *[]talos
 		if ip == nil {
 			return nil, fmt.Errorf("could not determine IP address for machine %+v", machine)
 		}
-		resolved[*ip] = machine
+		resolved = append(resolved, resolvedMachine{IP: *ip, Machine: machine})
 	}
 	return resolved, nil
 }
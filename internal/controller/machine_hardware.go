@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// readMachineHardware reads the hardware facts of a machine through the Talos API. It is a variable
+// so tests can replace the calls to the Talos API.
+var readMachineHardware = func(ctx context.Context, tc *talos.TalosClient) (*talos.HostFacts, error) {
+	return tc.HardwareFacts(ctx)
+}
+
+// hardwareFromFacts returns the hardware of a TalosHost or TalosMachine status from the facts the
+// machine reported
+func hardwareFromFacts(facts *talos.HostFacts) *talosv1alpha1.Hardware {
+	hw := &talosv1alpha1.Hardware{
+		SystemUUID:   facts.SystemUUID,
+		Manufacturer: facts.Manufacturer,
+		ProductName:  facts.ProductName,
+		CPUModel:     facts.CPUModel,
+		CPUs:         facts.CPUs,
+		Threads:      facts.Threads,
+	}
+	if facts.MemoryBytes > 0 {
+		hw.Memory = resource.NewQuantity(int64(facts.MemoryBytes), resource.BinarySI)
+	}
+	for _, d := range facts.Disks {
+		hw.Disks = append(hw.Disks, talosv1alpha1.Disk{
+			Name:       d.Name,
+			Size:       resource.NewQuantity(int64(d.Size), resource.BinarySI),
+			Model:      d.Model,
+			Serial:     d.Serial,
+			Transport:  d.Transport,
+			Rotational: d.Rotational,
+			Type:       diskType(d),
+		})
+	}
+	for _, n := range facts.NICs {
+		hw.NICs = append(hw.NICs, talosv1alpha1.NetworkInterface{
+			Name:       n.Name,
+			MACAddress: n.MACAddress,
+			LinkUp:     n.LinkUp,
+			SpeedMbps:  n.SpeedMbps,
+		})
+	}
+	return hw
+}
+
+// diskType returns nvme for NVMe disks, hdd for rotational disks and ssd for the others
+func diskType(d talos.DiskFacts) talosv1alpha1.DiskType {
+	switch {
+	case d.Transport == "nvme":
+		return talosv1alpha1.DiskTypeNVMe
+	case d.Rotational:
+		return talosv1alpha1.DiskTypeHDD
+	default:
+		return talosv1alpha1.DiskTypeSSD
+	}
+}
+
+// diskMatches returns true if the disk matches all fields of the selector that are set
+func diskMatches(disk *talosv1alpha1.Disk, selector *talosv1alpha1.InstallDiskSelector) bool {
+	if selector.Type != "" && disk.Type != selector.Type {
+		return false
+	}
+	if selector.Serial != "" && disk.Serial != selector.Serial {
+		return false
+	}
+	if selector.Model != "" {
+		// The model is matched like a file name, an invalid pattern matches nothing
+		if ok, err := filepath.Match(selector.Model, strings.TrimSpace(disk.Model)); err != nil || !ok {
+			return false
+		}
+	}
+	if selector.MinSize != nil && (disk.Size == nil || disk.Size.Cmp(*selector.MinSize) < 0) {
+		return false
+	}
+	if selector.MaxSize != nil && (disk.Size == nil || disk.Size.Cmp(*selector.MaxSize) > 0) {
+		return false
+	}
+	return true
+}
+
+// selectInstallDisk returns the disk of the hardware that matches the selector. The disk resolved
+// before is kept while it still matches, found by its serial number since device paths can change
+// between boots, so the install disk does not move once Talos is installed. Otherwise the matching
+// disk with the lowest device path is used.
+func selectInstallDisk(hw *talosv1alpha1.Hardware, selector *talosv1alpha1.InstallDiskSelector, previous *talosv1alpha1.Disk) (string, error) {
+	var matches []*talosv1alpha1.Disk
+	for i := range hw.Disks {
+		if diskMatches(&hw.Disks[i], selector) {
+			matches = append(matches, &hw.Disks[i])
+		}
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("none of the %d disks of the machine matches the installDiskSelector", len(hw.Disks))
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	if previous != nil {
+		for _, disk := range matches {
+			if (previous.Serial != "" && disk.Serial == previous.Serial) || (previous.Serial == "" && disk.Name == previous.Name) {
+				return disk.Name, nil
+			}
+		}
+	}
+	return matches[0].Name, nil
+}
+
+// findDisk returns the disk of the hardware with the device path
+func findDisk(hw *talosv1alpha1.Hardware, name string) *talosv1alpha1.Disk {
+	if hw == nil {
+		return nil
+	}
+	for i := range hw.Disks {
+		if hw.Disks[i].Name == name {
+			return &hw.Disks[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// newTestFacts returns the facts of a machine with a small SATA SSD, a large NVMe disk and a HDD
+func newTestFacts() *talos.HostFacts {
+	return &talos.HostFacts{
+		SystemUUID:  "4c4c4544-0051-3510-8057-b7c04f4e4e32",
+		CPUs:        16,
+		Threads:     32,
+		MemoryBytes: 64 << 30,
+		Disks: []talos.DiskFacts{
+			{Name: "/dev/sda", Size: 240 << 30, Model: "INTEL SSDSC2KB24", Serial: "BTYF0001", Transport: "sata"},
+			{Name: "/dev/sdb", Size: 4 << 40, Model: "ST4000NM000A", Serial: "ZC10002", Transport: "sata", Rotational: true},
+			{Name: "/dev/nvme0n1", Size: 1 << 40, Model: "Samsung SSD 980 PRO 1TB", Serial: "S5GXNF0003", Transport: "nvme"},
+		},
+		NICs: []talos.NICFacts{{Name: "enp1s0", MACAddress: "52:54:00:12:34:56", LinkUp: true, SpeedMbps: 10000}},
+	}
+}
+
+func TestSelectInstallDisk(t *testing.T) {
+	hw := hardwareFromFacts(newTestFacts())
+	if hw.Disks[0].Type != talosv1alpha1.DiskTypeSSD || hw.Disks[1].Type != talosv1alpha1.DiskTypeHDD || hw.Disks[2].Type != talosv1alpha1.DiskTypeNVMe {
+		t.Fatalf("unexpected disk types: %+v", hw.Disks)
+	}
+	tests := []struct {
+		name     string
+		selector talosv1alpha1.InstallDiskSelector
+		previous *talosv1alpha1.Disk
+		want     string
+	}{
+		{name: "type", selector: talosv1alpha1.InstallDiskSelector{Type: talosv1alpha1.DiskTypeNVMe}, want: "/dev/nvme0n1"},
+		{name: "model glob", selector: talosv1alpha1.InstallDiskSelector{Model: "Samsung SSD 9*"}, want: "/dev/nvme0n1"},
+		{name: "serial", selector: talosv1alpha1.InstallDiskSelector{Serial: "BTYF0001"}, want: "/dev/sda"},
+		{name: "size range", selector: talosv1alpha1.InstallDiskSelector{MinSize: ptr.To(resource.MustParse("500Gi")), MaxSize: ptr.To(resource.MustParse("2Ti"))}, want: "/dev/nvme0n1"},
+		{name: "lowest device path", selector: talosv1alpha1.InstallDiskSelector{MinSize: ptr.To(resource.MustParse("500Gi"))}, want: "/dev/nvme0n1"},
+		{name: "previous disk is kept by serial", selector: talosv1alpha1.InstallDiskSelector{Type: talosv1alpha1.DiskTypeSSD}, previous: &talosv1alpha1.Disk{Name: "/dev/sdc", Serial: "BTYF0001"}, want: "/dev/sda"},
+		{name: "previous disk is kept", selector: talosv1alpha1.InstallDiskSelector{MinSize: ptr.To(resource.MustParse("500Gi"))}, previous: &talosv1alpha1.Disk{Name: "/dev/sdb"}, want: "/dev/sdb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectInstallDisk(hw, &tt.selector, tt.previous)
+			if err != nil || got != tt.want {
+				t.Errorf("expected %s, got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+	if _, err := selectInstallDisk(hw, &talosv1alpha1.InstallDiskSelector{Type: talosv1alpha1.DiskTypeHDD, MaxSize: ptr.To(resource.MustParse("1Ti"))}, nil); err == nil {
+		t.Errorf("expected an error when no disk matches")
+	}
+}
+
+func TestInstallDiskFromSelector(t *testing.T) {
+	ctx := context.Background()
+	orig := readMachineHardware
+	facts := newTestFacts()
+	readMachineHardware = func(_ context.Context, _ *talos.TalosClient) (*talos.HostFacts, error) {
+		return facts, nil
+	}
+	t.Cleanup(func() { readMachineHardware = orig })
+
+	tm := newRolloutTestMachine("test-10.0.0.2", "v1.13.0", nil)
+	tm.Spec.MachineSpec = &talosv1alpha1.MachineSpec{InstallDiskSelector: &talosv1alpha1.InstallDiskSelector{Type: talosv1alpha1.DiskTypeSSD}}
+	c := newTestClient(t, &tm)
+	r := &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	disk, err := r.installDisk(ctx, &tm, nil)
+	if err != nil || disk != "/dev/sda" {
+		t.Fatalf("expected /dev/sda, got %q, %v", disk, err)
+	}
+	stored := &talosv1alpha1.TalosMachine{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&tm), stored); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if stored.Status.InstallDisk != "/dev/sda" || stored.Status.Hardware == nil || stored.Status.Hardware.SystemUUID != facts.SystemUUID ||
+		len(stored.Status.Hardware.NICs) != 1 || stored.Status.Hardware.Memory.String() != "64Gi" {
+		t.Fatalf("expected the hardware and install disk in the status, got %+v", stored.Status)
+	}
+
+	// A second SSD shows up and the disks are renamed after a reboot, the install disk follows its serial
+	facts.Disks[0].Name, facts.Disks[1].Name = "/dev/sdc", "/dev/sda"
+	facts.Disks = append(facts.Disks, talos.DiskFacts{Name: "/dev/sdb", Size: 480 << 30, Serial: "BTYF0004", Transport: "sata"})
+	if disk, err = r.installDisk(ctx, stored, nil); err != nil || disk != "/dev/sdc" {
+		t.Errorf("expected /dev/sdc, got %q, %v", disk, err)
+	}
+}
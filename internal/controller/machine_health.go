@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// serviceStateRunning is the state Talos reports for a service that is up
+const serviceStateRunning = "Running"
+
+// probeMachineHealth runs the checks of the unhealthy conditions against the machine and returns the
+// failing conditions with a message. It is a variable so tests can replace the calls to the Talos and
+// Kubernetes APIs.
+var probeMachineHealth = probeMachine
+
+// probeMachine checks the Node of the machine through the Kubernetes API of its cluster and the
+// machine itself through the Talos API. An error is returned if the checks could not be run, e.g.
+// because the Kubernetes API is down, which must not count against the machine.
+func probeMachine(ctx context.Context, c client.Client, tm *talosv1alpha1.TalosMachine,
+	conditions []talosv1alpha1.UnhealthyCondition,
+) (map[talosv1alpha1.UnhealthyConditionType]string, error) {
+	tcpName, err := machineControlPlaneName(ctx, c, tm)
+	if err != nil || tcpName == "" {
+		return nil, err
+	}
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: tm.Namespace, Name: tcpName}, tcp); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get TalosControlPlane %s: %w", tcpName, err)
+	}
+
+	failing := make(map[talosv1alpha1.UnhealthyConditionType]string)
+	var tc *talos.TalosClient
+	reachable := false
+	for _, condition := range conditions {
+		switch condition.Type {
+		case talosv1alpha1.NodeNotReadyCondition:
+			kc, err := workloadClusterClient(ctx, c, tcp)
+			if err != nil || kc == nil {
+				return nil, err
+			}
+			nodeName, err := kc.FindNode(ctx, tm.Spec.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if nodeName == "" {
+				failing[condition.Type] = fmt.Sprintf("No Node with address %s is registered", tm.Spec.Endpoint)
+				continue
+			}
+			ready, err := kc.IsNodeReady(ctx, nodeName)
+			if err != nil {
+				return nil, err
+			}
+			if !ready {
+				failing[condition.Type] = fmt.Sprintf("Node %s is not Ready", nodeName)
+			}
+		case talosv1alpha1.TalosAPIUnreachableCondition, talosv1alpha1.ServiceNotRunningCondition:
+			if tc == nil {
+				bc, err := controlPlaneBundleConfig(ctx, c, tcp)
+				if err != nil {
+					return nil, err
+				}
+				bc.ClientEndpoint = &[]string{tm.Spec.Endpoint}
+				tc, err = talos.NewClient(ctx, bc, false)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
+				}
+				defer tc.Close() //nolint:errcheck
+				reachable = machineReachable(ctx, tc)
+			}
+			if !reachable {
+				failing[condition.Type] = fmt.Sprintf("Talos API on %s is not reachable", tm.Spec.Endpoint)
+				continue
+			}
+			if condition.Type == talosv1alpha1.ServiceNotRunningCondition {
+				if message := servicesNotRunning(ctx, tc, condition.Services); message != "" {
+					failing[condition.Type] = message
+				}
+			}
+		}
+	}
+	return failing, nil
+}
+
+// servicesNotRunning returns a message naming the first of the services that is not Running
+func servicesNotRunning(ctx context.Context, tc *talos.TalosClient, services []string) string {
+	for _, svc := range services {
+		state, err := tc.GetServiceStatus(ctx, svc)
+		switch {
+		case err != nil:
+			return err.Error()
+		case state == nil:
+			return fmt.Sprintf("Service %s is not present", svc)
+		case *state != serviceStateRunning:
+			return fmt.Sprintf("Service %s is %s", svc, *state)
+		}
+	}
+	return ""
+}
+
+// updateMachineHealth records the failing conditions of a machine on its health status. A failure
+// keeps the time it was first observed. The machine is unhealthy once a failure outlasted the timeout
+// of its condition, and a remediated machine stays unhealthy while it keeps failing. It returns true
+// if the machine needs to be remediated.
+func updateMachineHealth(status *talosv1alpha1.MachineHealthStatus, conditions []talosv1alpha1.UnhealthyCondition,
+	failing map[talosv1alpha1.UnhealthyConditionType]string, now time.Time,
+) bool {
+	previous := make(map[talosv1alpha1.UnhealthyConditionType]metav1.Time, len(status.Failures))
+	for _, f := range status.Failures {
+		previous[f.Type] = f.Since
+	}
+	status.Failures = nil
+	status.Healthy = true
+	remediate := false
+	handled := make(map[talosv1alpha1.UnhealthyConditionType]bool)
+	for _, condition := range conditions {
+		message, ok := failing[condition.Type]
+		if !ok || handled[condition.Type] {
+			// The first condition of a type sets its timeout
+			continue
+		}
+		handled[condition.Type] = true
+		since, seen := previous[condition.Type]
+		if !seen {
+			since = metav1.NewTime(now)
+		}
+		status.Failures = append(status.Failures, talosv1alpha1.HealthCheckFailure{Type: condition.Type, Since: since, Message: message})
+		// The timeout starts over once the machine was remediated
+		start := since.Time
+		if last := status.LastRemediationTime; last != nil {
+			if !since.After(last.Time) {
+				status.Healthy = false
+			}
+			if last.After(start) {
+				start = last.Time
+			}
+		}
+		if now.Sub(start) > condition.Timeout.Duration {
+			status.Healthy = false
+			remediate = true
+		}
+	}
+	return remediate
+}
+
+// resolveMaxUnhealthy returns how many of the expected machines may be unhealthy before the
+// remediation stops, 1 if the health check does not set it
+func resolveMaxUnhealthy(hc *talosv1alpha1.TalosMachineHealthCheck, expected int) int {
+	if hc.Spec.MaxUnhealthy == nil {
+		return 1
+	}
+	v, err := intstr.GetScaledValueFromIntOrPercent(hc.Spec.MaxUnhealthy, expected, false)
+	if err != nil || v < 0 {
+		return 1
+	}
+	return v
+}
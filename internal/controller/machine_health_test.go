@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var nodeNotReadyConditions = []talosv1alpha1.UnhealthyCondition{{
+	Type:    talosv1alpha1.NodeNotReadyCondition,
+	Timeout: metav1.Duration{Duration: 5 * time.Minute},
+}}
+
+func TestUpdateMachineHealth(t *testing.T) {
+	now := time.Now()
+	failing := map[talosv1alpha1.UnhealthyConditionType]string{talosv1alpha1.NodeNotReadyCondition: "Node is not Ready"}
+	status := &talosv1alpha1.MachineHealthStatus{Name: "test-cp-0", Healthy: true}
+
+	if updateMachineHealth(status, nodeNotReadyConditions, failing, now) || !status.Healthy {
+		t.Fatalf("expected a new failure to be within its timeout, got %+v", status)
+	}
+	since := status.Failures[0].Since
+	if updateMachineHealth(status, nodeNotReadyConditions, failing, now.Add(3*time.Minute)) || status.Failures[0].Since != since {
+		t.Fatalf("expected the failure to keep the time it was first observed, got %+v", status)
+	}
+	if !updateMachineHealth(status, nodeNotReadyConditions, failing, now.Add(6*time.Minute)) || status.Healthy {
+		t.Fatalf("expected the machine to be remediated after the timeout, got %+v", status)
+	}
+
+	// A remediated machine stays unhealthy and its timeout starts over
+	status.LastRemediationTime = &metav1.Time{Time: now.Add(6 * time.Minute)}
+	if updateMachineHealth(status, nodeNotReadyConditions, failing, now.Add(8*time.Minute)) || status.Healthy {
+		t.Fatalf("expected the remediated machine to wait for the timeout, got %+v", status)
+	}
+	if !updateMachineHealth(status, nodeNotReadyConditions, failing, now.Add(12*time.Minute)) {
+		t.Fatalf("expected the machine to be remediated again, got %+v", status)
+	}
+
+	if updateMachineHealth(status, nodeNotReadyConditions, nil, now.Add(13*time.Minute)) || !status.Healthy || len(status.Failures) != 0 {
+		t.Errorf("expected the recovered machine to be healthy, got %+v", status)
+	}
+}
+
+func TestResolveMaxUnhealthy(t *testing.T) {
+	hc := &talosv1alpha1.TalosMachineHealthCheck{}
+	if v := resolveMaxUnhealthy(hc, 5); v != 1 {
+		t.Errorf("expected 1 unhealthy machine by default, got %d", v)
+	}
+	percent := intstr.FromString("40%")
+	hc.Spec.MaxUnhealthy = &percent
+	if v := resolveMaxUnhealthy(hc, 5); v != 2 {
+		t.Errorf("expected 2 of 5 machines, got %d", v)
+	}
+	zero := intstr.FromInt32(0)
+	hc.Spec.MaxUnhealthy = &zero
+	if v := resolveMaxUnhealthy(hc, 5); v != 0 {
+		t.Errorf("expected remediation to be turned off, got %d", v)
+	}
+}
+
+func TestTalosMachineHealthCheckReconcile(t *testing.T) {
+	ctx := context.Background()
+	failing := map[string]bool{}
+	orig := probeMachineHealth
+	probeErrors := map[string]error{}
+	probeMachineHealth = func(_ context.Context, _ client.Client, tm *talosv1alpha1.TalosMachine, _ []talosv1alpha1.UnhealthyCondition) (map[talosv1alpha1.UnhealthyConditionType]string, error) {
+		if err := probeErrors[tm.Name]; err != nil {
+			return nil, err
+		}
+		if failing[tm.Name] {
+			return map[talosv1alpha1.UnhealthyConditionType]string{talosv1alpha1.NodeNotReadyCondition: "Node is not Ready"}, nil
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { probeMachineHealth = orig })
+
+	objects := []client.Object{&talosv1alpha1.TalosMachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-hc", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosMachineHealthCheckSpec{
+			OwnerRef:            &talosv1alpha1.HealthCheckOwnerReference{Kind: talosv1alpha1.GroupKindControlPlane, Name: "test-cp"},
+			UnhealthyConditions: nodeNotReadyConditions,
+			Remediation:         talosv1alpha1.MachineRemediation{Strategy: talosv1alpha1.ResetRemediation},
+		},
+	}}
+	for i := range 3 {
+		machine := newRolloutTestMachine(fmt.Sprintf("test-cp-%d", i), "v1.13.0", nil)
+		objects = append(objects, &machine)
+	}
+	other := newRolloutTestMachine("other-cp-0", "v1.13.0", nil)
+	other.Spec.ControlPlaneRef.Name = "other-cp"
+	c := newTestClient(t, append(objects, &other)...)
+	r := &TalosMachineHealthCheckReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	key := client.ObjectKey{Namespace: DefaultNamespace, Name: "test-hc"}
+
+	reconcile := func() *talosv1alpha1.TalosMachineHealthCheck {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		hc := &talosv1alpha1.TalosMachineHealthCheck{}
+		if err := c.Get(ctx, key, hc); err != nil {
+			t.Fatalf("failed to get health check: %v", err)
+		}
+		return hc
+	}
+	// ageFailures moves the failures of the machines back in time, as if they were failing for long
+	ageFailures := func(hc *talosv1alpha1.TalosMachineHealthCheck) {
+		t.Helper()
+		for i := range hc.Status.Machines {
+			for j := range hc.Status.Machines[i].Failures {
+				hc.Status.Machines[i].Failures[j].Since = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+			}
+		}
+		if err := c.Status().Update(ctx, hc); err != nil {
+			t.Fatalf("failed to update health check: %v", err)
+		}
+	}
+	remediation := func(name string) string {
+		t.Helper()
+		tm := &talosv1alpha1.TalosMachine{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: name}, tm); err != nil {
+			t.Fatalf("failed to get machine: %v", err)
+		}
+		return tm.Annotations[talosv1alpha1.RemediationAnnotation]
+	}
+
+	hc := reconcile()
+	if hc.Status.ExpectedMachines != 3 || hc.Status.CurrentHealthy != 3 {
+		t.Fatalf("expected the 3 machines of the control plane to be healthy, got %+v", hc.Status)
+	}
+
+	failing["test-cp-0"] = true
+	hc = reconcile()
+	if len(hc.Status.Machines[0].Failures) != 1 || remediation("test-cp-0") != "" {
+		t.Fatalf("expected the failure to be recorded and wait for its timeout, got %+v", hc.Status.Machines[0])
+	}
+	ageFailures(hc)
+
+	// A machine that cannot be probed keeps its health and does not stop the other machines from being checked
+	probeErrors["test-cp-0"] = errors.New("kubeconfig is not available")
+	failing["test-cp-2"] = true
+	hc = reconcile()
+	if status := hc.Status.Machines[0]; status.ProbeError == "" || len(status.Failures) != 1 || remediation("test-cp-0") != "" {
+		t.Fatalf("expected the probe error to be recorded without a remediation, got %+v", status)
+	}
+	if len(hc.Status.Machines[2].Failures) != 1 {
+		t.Fatalf("expected the other machines to be checked, got %+v", hc.Status.Machines[2])
+	}
+	delete(probeErrors, "test-cp-0")
+	delete(failing, "test-cp-2")
+	hc = reconcile()
+	if got := remediation("test-cp-0"); got != string(talosv1alpha1.ResetRemediation) {
+		t.Fatalf("expected the machine to be reset, got %q", got)
+	}
+	if status := hc.Status.Machines[0]; status.Healthy || status.Remediations != 1 || status.LastRemediationTime == nil || status.ProbeError != "" {
+		t.Errorf("expected the remediation to be recorded, got %+v", status)
+	}
+
+	// Remediation stops once more machines than maxUnhealthy are unhealthy
+	failing["test-cp-1"] = true
+	hc = reconcile()
+	ageFailures(hc)
+	hc = reconcile()
+	if got := remediation("test-cp-1"); got != "" {
+		t.Errorf("expected the second machine not to be remediated, got %q", got)
+	}
+	condition := meta.FindStatusCondition(hc.Status.Conditions, talosv1alpha1.ConditionRemediationAllowed)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "TooManyUnhealthy" {
+		t.Errorf("expected the remediation to be blocked, got %+v", condition)
+	}
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/bmc"
+)
+
+// Power actions the operator takes through the BMC of a machine
+const (
+	PowerActionOn    = "PowerOn"
+	PowerActionCycle = "PowerCycle"
+	PowerActionOff   = "PowerOff"
+)
+
+const (
+	// defaultBMCBootTimeout is how long a machine may take to boot into Talos without a bootTimeout
+	defaultBMCBootTimeout = 10 * time.Minute
+	// bmcTimeout is how long the BMC of a machine may take to answer
+	bmcTimeout = 30 * time.Second
+	// Keys of the BMC credentials Secret
+	bmcUsernameKey = "username"
+	bmcPasswordKey = "password"
+)
+
+// newBMCClient returns the client of the BMC of a machine. It is a variable so tests can replace the BMC.
+var newBMCClient = bmc.New
+
+// bmcClient returns the client of the BMC of the machine with the credentials of its Secret
+func (r *TalosMachineReconciler) bmcClient(ctx context.Context, tm *talosv1alpha1.TalosMachine) (bmc.Client, error) {
+	spec := tm.Spec.BMC
+	username, err := readSecretKey(ctx, r.Client, tm.Namespace, &corev1.SecretKeySelector{LocalObjectReference: spec.CredentialsSecretRef, Key: bmcUsernameKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BMC credentials: %w", err)
+	}
+	password, err := readSecretKey(ctx, r.Client, tm.Namespace, &corev1.SecretKeySelector{LocalObjectReference: spec.CredentialsSecretRef, Key: bmcPasswordKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BMC credentials: %w", err)
+	}
+	return newBMCClient(bmc.Config{
+		Protocol:           spec.Protocol,
+		Address:            spec.Address,
+		Username:           string(username),
+		Password:           string(password),
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+		SystemID:           spec.SystemID,
+	})
+}
+
+// bmcBootTimeout returns how long the machine may take to boot into Talos after a power action
+func bmcBootTimeout(tm *talosv1alpha1.TalosMachine) time.Duration {
+	if tm.Spec.BMC.BootTimeout != nil && tm.Spec.BMC.BootTimeout.Duration > 0 {
+		return tm.Spec.BMC.BootTimeout.Duration
+	}
+	return defaultBMCBootTimeout
+}
+
+// powerAction returns the power action a machine in the power state needs and whether it has to
+// boot from the network. A machine that is off is powered on. A PXE booted machine that did not boot
+// into Talos within the boot timeout, counted from the last power action or else its creation, hangs
+// and is power cycled. Machines that are not installed yet boot from the network once.
+func powerAction(tm *talosv1alpha1.TalosMachine, state bmc.PowerState, now time.Time) (string, bool) {
+	pxe := tm.Spec.PxeClientSpec != nil &&
+		(tm.Status.State == "" || tm.Status.State == talosv1alpha1.StateBooting)
+	switch state {
+	case bmc.PowerOff:
+		return PowerActionOn, pxe
+	case bmc.PowerOn:
+		if !pxe || tm.Status.State != talosv1alpha1.StateBooting {
+			return "", false
+		}
+		since := tm.CreationTimestamp.Time
+		if tm.Status.BMC != nil && tm.Status.BMC.LastPowerActionTime != nil {
+			since = tm.Status.BMC.LastPowerActionTime.Time
+		}
+		if now.Sub(since) > bmcBootTimeout(tm) {
+			return PowerActionCycle, true
+		}
+	}
+	return "", false
+}
+
+// reconcilePower reads the power state of the machine from its BMC and powers the machine on, or
+// power cycles it when it hangs while booting. A BMC that cannot be reached is reported but does not
+// stop the reconciliation of the machine.
+func (r *TalosMachineReconciler) reconcilePower(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	logger := log.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, bmcTimeout)
+	defer cancel()
+	c, err := r.bmcClient(ctx, tm)
+	if err != nil {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "BMCUnavailable", "BMCUnavailable", err.Error())
+		return r.updatePowerStatus(ctx, tm, bmc.PowerUnknown, "")
+	}
+	state, err := c.PowerState(ctx)
+	if err != nil {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "BMCUnavailable", "BMCUnavailable",
+			fmt.Sprintf("Failed to read the power state of the machine: %v", err))
+		return r.updatePowerStatus(ctx, tm, bmc.PowerUnknown, "")
+	}
+	action, pxe := powerAction(tm, state, time.Now())
+	if action == "" {
+		return r.updatePowerStatus(ctx, tm, state, "")
+	}
+	if r.isDryRun(tm) {
+		logger.Info("DryRun: would change the power of TalosMachine", "name", tm.Name, "action", action, "pxe", pxe)
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, fmt.Sprintf("Would %s the machine through its BMC", action))
+		return nil
+	}
+	if pxe {
+		if err := c.SetPXEBootOnce(ctx); err != nil {
+			r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "PowerActionFailed", "PowerActionFailed",
+				fmt.Sprintf("Failed to make the machine boot from the network: %v", err))
+			return r.updatePowerStatus(ctx, tm, state, "")
+		}
+	}
+	if err := runPowerAction(ctx, c, action); err != nil {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "PowerActionFailed", "PowerActionFailed",
+			fmt.Sprintf("Failed to %s the machine: %v", action, err))
+		return r.updatePowerStatus(ctx, tm, state, "")
+	}
+	msg := fmt.Sprintf("Ran %s on the machine through its BMC", action)
+	if pxe {
+		msg += ", it boots from the network once"
+	}
+	logger.Info("Changed the power of TalosMachine", "name", tm.Name, "action", action, "pxe", pxe)
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, action, action, msg)
+	return r.updatePowerStatus(ctx, tm, bmc.PowerOn, action)
+}
+
+// powerCycle power cycles the machine through its BMC, e.g. when it hangs and cannot be reached
+func (r *TalosMachineReconciler) powerCycle(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	return r.runBMCAction(ctx, tm, PowerActionCycle, bmc.PowerOn)
+}
+
+// powerOff powers the machine off through its BMC
+func (r *TalosMachineReconciler) powerOff(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	return r.runBMCAction(ctx, tm, PowerActionOff, bmc.PowerOff)
+}
+
+// runBMCAction runs the power action through the BMC of the machine and records it in the status
+func (r *TalosMachineReconciler) runBMCAction(ctx context.Context, tm *talosv1alpha1.TalosMachine, action string, state bmc.PowerState) error {
+	ctx, cancel := context.WithTimeout(ctx, bmcTimeout)
+	defer cancel()
+	c, err := r.bmcClient(ctx, tm)
+	if err != nil {
+		return err
+	}
+	if err := runPowerAction(ctx, c, action); err != nil {
+		return fmt.Errorf("failed to %s TalosMachine %s: %w", action, tm.Name, err)
+	}
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, action, action, fmt.Sprintf("Ran %s on the machine through its BMC", action))
+	return r.updatePowerStatus(ctx, tm, state, action)
+}
+
+// runPowerAction runs the power action on the BMC
+func runPowerAction(ctx context.Context, c bmc.Client, action string) error {
+	switch action {
+	case PowerActionOn:
+		return c.PowerOn(ctx)
+	case PowerActionCycle:
+		return c.PowerCycle(ctx)
+	case PowerActionOff:
+		return c.PowerOff(ctx)
+	default:
+		return fmt.Errorf("unknown power action %s", action)
+	}
+}
+
+// updatePowerStatus records the power state of the machine and the power action that was taken, if
+// any, in its status
+func (r *TalosMachineReconciler) updatePowerStatus(ctx context.Context, tm *talosv1alpha1.TalosMachine, state bmc.PowerState, action string) error {
+	if r.isDryRun(tm) {
+		return nil
+	}
+	status := &talosv1alpha1.BMCStatus{PowerState: string(state)}
+	if tm.Status.BMC != nil {
+		status.LastPowerAction = tm.Status.BMC.LastPowerAction
+		status.LastPowerActionTime = tm.Status.BMC.LastPowerActionTime
+	}
+	if action != "" {
+		status.LastPowerAction = action
+		status.LastPowerActionTime = &metav1.Time{Time: time.Now()}
+	}
+	if equality.Semantic.DeepEqual(tm.Status.BMC, status) {
+		return nil
+	}
+	orig := tm.DeepCopy()
+	tm.Status.BMC = status
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to update power state of TalosMachine %s: %w", tm.Name, err)
+	}
+	return nil
+}
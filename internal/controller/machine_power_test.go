@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/bmc"
+	"github.com/alperencelik/talos-operator/pkg/bmc/bmctest"
+)
+
+func newPowerTestMachine(address string) talosv1alpha1.TalosMachine {
+	tm := newRolloutTestMachine("test-10.0.0.2", "v1.13.0", nil)
+	tm.CreationTimestamp = metav1.Now()
+	tm.Status = talosv1alpha1.TalosMachineStatus{}
+	tm.Spec.PxeClientSpec = &talosv1alpha1.PxeClientSpec{MacAddress: ptr.To("52:54:00:00:00:02"), CpuArchitecture: ptr.To("amd64")}
+	tm.Spec.BMC = &talosv1alpha1.BMCSpec{
+		Protocol:             bmc.ProtocolRedfish,
+		Address:              address,
+		CredentialsSecretRef: corev1.LocalObjectReference{Name: "bmc-credentials"},
+	}
+	return tm
+}
+
+func TestPowerAction(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		state  string
+		power  bmc.PowerState
+		last   time.Duration
+		pxe    bool
+		action string
+		boot   bool
+	}{
+		{name: "off machine boots from the network", power: bmc.PowerOff, pxe: true, action: PowerActionOn, boot: true},
+		{name: "off machine without PXE is powered on", power: bmc.PowerOff, action: PowerActionOn},
+		{name: "installed machine boots from disk", state: talosv1alpha1.StateAvailable, power: bmc.PowerOff, pxe: true, action: PowerActionOn},
+		{name: "booting machine gets time to boot", state: talosv1alpha1.StateBooting, power: bmc.PowerOn, last: 5 * time.Minute, pxe: true},
+		{name: "hung machine is power cycled", state: talosv1alpha1.StateBooting, power: bmc.PowerOn, last: 11 * time.Minute, pxe: true, action: PowerActionCycle, boot: true},
+		{name: "running machine is left alone", state: talosv1alpha1.StateAvailable, power: bmc.PowerOn, last: time.Hour, pxe: true},
+		{name: "unknown power state is left alone", state: talosv1alpha1.StateBooting, power: bmc.PowerUnknown, last: time.Hour, pxe: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newPowerTestMachine("https://10.0.1.2")
+			tm.Status.State = tt.state
+			if !tt.pxe {
+				tm.Spec.PxeClientSpec = nil
+			}
+			tm.Status.BMC = &talosv1alpha1.BMCStatus{LastPowerAction: PowerActionOn, LastPowerActionTime: &metav1.Time{Time: now.Add(-tt.last)}}
+			action, boot := powerAction(&tm, tt.power, now)
+			if action != tt.action || boot != tt.boot {
+				t.Errorf("expected %q with PXE %t, got %q with PXE %t", tt.action, tt.boot, action, boot)
+			}
+		})
+	}
+}
+
+func TestReconcilePower(t *testing.T) {
+	ctx := context.Background()
+	server := bmctest.NewRedfishServer("admin", "secret")
+	defer server.Close()
+
+	tm := newPowerTestMachine(server.URL)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-credentials", Namespace: DefaultNamespace},
+		Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("secret")},
+	}
+	c := newTestClient(t, &tm, secret)
+	r := &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	// A new machine that is off is powered on and boots from the network
+	if err := r.reconcilePower(ctx, &tm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.PowerState() != "On" || server.BootedFrom() != "Pxe" {
+		t.Fatalf("expected the machine to boot from PXE, got %s booted from %q", server.PowerState(), server.BootedFrom())
+	}
+	stored := &talosv1alpha1.TalosMachine{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&tm), stored); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if stored.Status.BMC == nil || stored.Status.BMC.PowerState != string(bmc.PowerOn) || stored.Status.BMC.LastPowerAction != PowerActionOn {
+		t.Fatalf("expected the power on in the status, got %+v", stored.Status.BMC)
+	}
+
+	// The machine hangs while booting and is power cycled into PXE once the boot timeout expired
+	stored.Status.State = talosv1alpha1.StateBooting
+	stored.Status.BMC.LastPowerActionTime = &metav1.Time{Time: time.Now().Add(-defaultBMCBootTimeout - time.Minute)}
+	if err := r.reconcilePower(ctx, stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"On", "PowerCycle"}; !slices.Equal(server.Resets(), want) || server.BootedFrom() != "Pxe" {
+		t.Fatalf("expected resets %v with a PXE boot, got %v booted from %q", want, server.Resets(), server.BootedFrom())
+	}
+
+	// A machine that is released without a reset is powered off
+	r.powerOffReleased(ctx, stored)
+	if server.PowerState() != "Off" || stored.Status.BMC.PowerState != string(bmc.PowerOff) || stored.Status.BMC.LastPowerAction != PowerActionOff {
+		t.Errorf("expected the machine to be powered off, got %s and %+v", server.PowerState(), stored.Status.BMC)
+	}
+
+	// Without credentials the power state is unknown, the machine is still reconciled
+	if err := c.Delete(ctx, secret); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	if err := r.reconcilePower(ctx, stored); err != nil || stored.Status.BMC.PowerState != string(bmc.PowerUnknown) {
+		t.Errorf("expected an unknown power state, got %+v, %v", stored.Status.BMC, err)
+	}
+}
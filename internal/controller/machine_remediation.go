@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// remediationRequested returns the remediation a TalosMachineHealthCheck requested for the machine.
+// Machines marked for replacement are left to the user, so only Reboot and Reset are returned.
+func remediationRequested(tm *talosv1alpha1.TalosMachine) (talosv1alpha1.RemediationStrategy, bool) {
+	strategy := talosv1alpha1.RemediationStrategy(tm.Annotations[talosv1alpha1.RemediationAnnotation])
+	switch strategy {
+	case talosv1alpha1.RebootRemediation, talosv1alpha1.ResetRemediation:
+		return strategy, true
+	default:
+		return "", false
+	}
+}
+
+// remediate reboots or resets the machine as requested by its remediation annotation and removes the
+// annotation afterwards. A reset machine leaves etcd first if it is a control plane machine, and is
+// installed again with its config once it is back in maintenance mode. A reset that would break the
+// etcd quorum is skipped.
+func (r *TalosMachineReconciler) remediate(ctx context.Context, tm *talosv1alpha1.TalosMachine, strategy talosv1alpha1.RemediationStrategy) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if r.isDryRun(tm) {
+		logger.Info("DryRun: would remediate TalosMachine", "name", tm.Name, "strategy", strategy)
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, fmt.Sprintf("Would remediate the machine with %s", strategy))
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	}
+	bc, err := r.GetBundleConfig(ctx, tm)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get BundleConfig for TalosMachine %s: %w", tm.Name, err)
+	}
+	if bc == nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	machineConfig := *bc
+	machineConfig.ClientEndpoint = &[]string{tm.Spec.Endpoint}
+	tc, err := talos.NewClient(ctx, &machineConfig, false)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
+	}
+	defer tc.Close() //nolint:errcheck
+
+	if !machineReachable(ctx, tc) {
+		// A hung machine is power cycled through its BMC
+		if tm.Spec.BMC != nil {
+			if err := r.powerCycle(ctx, tm); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, r.clearRemediation(ctx, tm)
+		}
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "RemediationFailed", "RemediationFailed",
+			fmt.Sprintf("Machine is unreachable and cannot be remediated with %s, it has to be replaced", strategy))
+		return ctrl.Result{}, r.clearRemediation(ctx, tm)
+	}
+	switch strategy {
+	case talosv1alpha1.RebootRemediation:
+		if err := tc.Reboot(ctx); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to reboot TalosMachine %s: %w", tm.Name, err)
+		}
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Rebooted", "Rebooted", "Rebooted the machine to remediate it")
+	case talosv1alpha1.ResetRemediation:
+		leaveEtcd, err := r.leavesEtcd(ctx, tm)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if leaveEtcd {
+			// The machine may still be a healthy voter, so it only leaves if etcd keeps its quorum
+			if err := r.checkMachineLeavesEtcd(ctx, tm, tc); err != nil {
+				r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "RemediationBlocked", "RemediationBlocked",
+					fmt.Sprintf("Skipping the reset, the machine cannot leave etcd: %v", err))
+				return ctrl.Result{}, r.clearRemediation(ctx, tm)
+			}
+			// The machine joins etcd again as a new member once it is installed
+			if err := r.removeEtcdMember(ctx, tm, &machineConfig, tc, true); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to remove etcd member of TalosMachine %s: %w", tm.Name, err)
+			}
+		}
+		if err := tc.Reset(ctx, false, true); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to reset TalosMachine %s: %w", tm.Name, err)
+		}
+		// Forget the applied config, so it is applied again in maintenance mode
+		orig := tm.DeepCopy()
+		tm.Status.State = talosv1alpha1.StatePending
+		tm.Status.Config = ""
+		tm.Status.ConfigSecretRef = nil
+		if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to patch TalosMachine %s status after reset: %w", tm.Name, err)
+		}
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Reset", "Reset", "Reset the machine to remediate it, it is installed again")
+	}
+	if err := r.clearRemediation(ctx, tm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// clearRemediation removes the remediation annotation once the remediation was run
+func (r *TalosMachineReconciler) clearRemediation(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	patch := client.MergeFrom(tm.DeepCopy())
+	delete(tm.Annotations, talosv1alpha1.RemediationAnnotation)
+	if err := r.Patch(ctx, tm, patch); err != nil {
+		return fmt.Errorf("failed to remove remediation annotation of TalosMachine %s: %w", tm.Name, err)
+	}
+	return nil
+}
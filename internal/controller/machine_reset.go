@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// resetTimedOut returns true if the reset timeout of the deleted machine expired
+func resetTimedOut(tm *talosv1alpha1.TalosMachine, now time.Time) bool {
+	if tm.Spec.Reset == nil || tm.Spec.Reset.Timeout == nil || tm.DeletionTimestamp.IsZero() {
+		return false
+	}
+	return now.Sub(tm.DeletionTimestamp.Time) > tm.Spec.Reset.Timeout.Duration
+}
+
+// resetMachine resets the deleted machine as configured by its reset spec. A machine that already
+// left etcd or whose control plane is torn down is reset forcefully, see resetRequest. Once the reset
+// timeout expired, a graceful reset is retried forcefully and the machine is released without a reset
+// if that fails as well.
+func (r *TalosMachineReconciler) resetMachine(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient, leftEtcd bool) error {
+	tornDown, err := r.controlPlaneTornDown(ctx, tm)
+	if err != nil {
+		return err
+	}
+	expired := resetTimedOut(tm, time.Now())
+	req := resetRequest(tm, leftEtcd || tornDown || expired)
+	err = tc.ResetMachine(ctx, req)
+	if err == nil {
+		return nil
+	}
+	if !expired {
+		return fmt.Errorf("failed to reset TalosMachine %s: %w", tm.Name, err)
+	}
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "ResetTimedOut", "ResetTimedOut",
+		fmt.Sprintf("Machine was not reset within %s, releasing it without a reset: %v", tm.Spec.Reset.Timeout.Duration, err))
+	r.powerOffReleased(ctx, tm)
+	return nil
+}
+
+// resetRequest returns the reset request of the deleted machine. A graceful reset leaves etcd first,
+// which fails for a machine that left etcd already and for the last etcd member of a control plane
+// that is torn down, so those are reset forcefully.
+func resetRequest(tm *talosv1alpha1.TalosMachine, forceful bool) *machineapi.ResetRequest {
+	req := talos.NewResetRequest(tm.Spec.Reset)
+	if tm.Spec.BMC != nil {
+		// The BMC powers the machine on again when it is reused
+		req.Reboot = false
+	}
+	if forceful {
+		req.Graceful = false
+	}
+	return req
+}
+
+// powerOffReleased powers off a machine that is released without a reset through its BMC, so it
+// does not keep running with the config of the cluster. A failure is reported but does not block the
+// deletion.
+func (r *TalosMachineReconciler) powerOffReleased(ctx context.Context, tm *talosv1alpha1.TalosMachine) {
+	if tm.Spec.BMC == nil {
+		return
+	}
+	if err := r.powerOff(ctx, tm); err != nil {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "PowerActionFailed", "PowerActionFailed",
+			fmt.Sprintf("Failed to power off the machine: %v", err))
+	}
+}
+
+// nextControlPlaneTeardown returns the machine of a deleted control plane that is torn down next, or
+// nil while a machine is still being deleted. The machines are torn down one after the other with the
+// machine the cluster was bootstrapped on last, so etcd loses one member at a time.
+func nextControlPlaneTeardown(items []talosv1alpha1.TalosMachine, bootstrapEndpoint string) *talosv1alpha1.TalosMachine {
+	machines := make([]*talosv1alpha1.TalosMachine, 0, len(items))
+	for i := range items {
+		if !items[i].DeletionTimestamp.IsZero() {
+			return nil
+		}
+		machines = append(machines, &items[i])
+	}
+	sort.SliceStable(machines, func(i, j int) bool {
+		bi, bj := machines[i].Spec.Endpoint == bootstrapEndpoint, machines[j].Spec.Endpoint == bootstrapEndpoint
+		if bi != bj {
+			return bj
+		}
+		return machines[i].Name < machines[j].Name
+	})
+	if len(machines) == 0 {
+		return nil
+	}
+	return machines[0]
+}
+
+// bootstrapEndpoint returns the address of the machine a metal control plane was bootstrapped on,
+// which is the first machine of its spec or else the first host it claimed
+func bootstrapEndpoint(ctx context.Context, c client.Client, tcp *talosv1alpha1.TalosControlPlane) string {
+	machines, err := metalMachines(ctx, c, talosv1alpha1.GroupKindControlPlane, tcp, &tcp.Spec.MetalSpec)
+	if err != nil || len(machines) == 0 {
+		return ""
+	}
+	ip, err := getMachineIPAddress(ctx, c, &machines[0])
+	if err != nil || ip == nil {
+		return ""
+	}
+	return *ip
+}
+
+// deleteAndWait deletes the object and returns true until it is gone
+func deleteAndWait(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s: %w", obj.GetName(), err)
+	}
+	if err := deleteChild(ctx, c, obj); err != nil {
+		return false, fmt.Errorf("failed to delete %s: %w", obj.GetName(), err)
+	}
+	return true, nil
+}
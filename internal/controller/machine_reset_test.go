@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestResetTimedOut(t *testing.T) {
+	now := time.Now()
+	tm := &talosv1alpha1.TalosMachine{}
+	if resetTimedOut(tm, now) {
+		t.Errorf("expected a machine without a reset timeout never to time out")
+	}
+	tm.Spec.Reset = &talosv1alpha1.ResetSpec{Timeout: &metav1.Duration{Duration: 10 * time.Minute}}
+	if resetTimedOut(tm, now) {
+		t.Errorf("expected a machine that is not deleted not to time out")
+	}
+	tm.DeletionTimestamp = &metav1.Time{Time: now.Add(-5 * time.Minute)}
+	if resetTimedOut(tm, now) {
+		t.Errorf("expected the reset to be within its timeout")
+	}
+	if !resetTimedOut(tm, now.Add(6*time.Minute)) {
+		t.Errorf("expected the reset to time out")
+	}
+}
+
+func TestNextControlPlaneTeardown(t *testing.T) {
+	machines := make([]talosv1alpha1.TalosMachine, 3)
+	for i := range machines {
+		machines[i] = newRolloutTestMachine(fmt.Sprintf("test-cp-%d", i), "v1.13.0", nil)
+		machines[i].Spec.Endpoint = fmt.Sprintf("10.0.0.%d", i+1)
+	}
+
+	if next := nextControlPlaneTeardown(machines, "10.0.0.1"); next == nil || next.Name != "test-cp-1" {
+		t.Fatalf("expected test-cp-1 to be torn down first, got %v", next)
+	}
+	if next := nextControlPlaneTeardown(machines[:1], "10.0.0.1"); next == nil || next.Name != "test-cp-0" {
+		t.Fatalf("expected the bootstrap machine to be torn down last, got %v", next)
+	}
+	machines[2].DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	if next := nextControlPlaneTeardown(machines, "10.0.0.1"); next != nil {
+		t.Errorf("expected no machine while test-cp-2 is being deleted, got %s", next.Name)
+	}
+}
+
+func TestTalosClusterTeardownOrder(t *testing.T) {
+	ctx := context.Background()
+	finalizers := []string{talosv1alpha1.TalosControlPlaneFinalizer}
+	tc := &talosv1alpha1.TalosCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace}}
+	meta.SetStatusCondition(&tc.Status.Conditions, metav1.Condition{
+		Type:   talosv1alpha1.ConditionDeleting,
+		Status: metav1.ConditionUnknown,
+		Reason: ConditionReasonDeleting,
+	})
+	c := newTestClient(t, tc,
+		&talosv1alpha1.TalosWorker{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace, Finalizers: finalizers}},
+		&talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace, Finalizers: finalizers}},
+	)
+	r := &TalosClusterReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	key := client.ObjectKey{Namespace: DefaultNamespace, Name: "test"}
+
+	handleDelete := func() ctrl.Result {
+		t.Helper()
+		res, err := r.handleDelete(ctx, *tc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+	// release removes the finalizer of the object, as its controller does once it is torn down
+	release := func(obj client.Object) {
+		t.Helper()
+		if err := c.Get(ctx, key, obj); err != nil {
+			t.Fatalf("failed to get %T: %v", obj, err)
+		}
+		obj.SetFinalizers(nil)
+		if err := c.Update(ctx, obj); err != nil {
+			t.Fatalf("failed to update %T: %v", obj, err)
+		}
+	}
+
+	if res := handleDelete(); res.RequeueAfter == 0 {
+		t.Fatalf("expected to wait for the TalosWorker to be deleted")
+	}
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := c.Get(ctx, key, tcp); err != nil || !tcp.DeletionTimestamp.IsZero() {
+		t.Fatalf("expected the TalosControlPlane to be kept until the TalosWorker is gone, got %v", err)
+	}
+
+	release(&talosv1alpha1.TalosWorker{})
+	if res := handleDelete(); res.RequeueAfter == 0 {
+		t.Fatalf("expected to wait for the TalosControlPlane to be deleted")
+	}
+	if err := c.Get(ctx, key, tcp); err != nil || tcp.DeletionTimestamp.IsZero() {
+		t.Fatalf("expected the TalosControlPlane to be deleted, got %v", err)
+	}
+
+	release(&talosv1alpha1.TalosControlPlane{})
+	if res := handleDelete(); res != (ctrl.Result{}) {
+		t.Errorf("expected the teardown to be done, got %+v", res)
+	}
+}
+
+func TestResetRequestOnControlPlaneTeardown(t *testing.T) {
+	ctx := context.Background()
+	tm := newRolloutTestMachine("test-cp-0", "v1.13.0", nil)
+	tm.Spec.ControlPlaneRef = &corev1.ObjectReference{Name: "test"}
+	tm.Spec.Reset = &talosv1alpha1.ResetSpec{Graceful: true}
+	tcp := &talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: DefaultNamespace, Finalizers: []string{talosv1alpha1.TalosControlPlaneFinalizer}}}
+	c := newTestClient(t, tcp)
+	r := &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	tornDown, err := r.controlPlaneTornDown(ctx, &tm)
+	if err != nil || tornDown {
+		t.Fatalf("expected a running control plane not to be torn down, got %v, %v", tornDown, err)
+	}
+	if req := resetRequest(&tm, tornDown); !req.Graceful {
+		t.Errorf("expected a graceful reset while the control plane keeps running")
+	}
+
+	// The bootstrap machine is the last etcd member once the control plane is deleted
+	if err := c.Delete(ctx, tcp); err != nil {
+		t.Fatalf("failed to delete TalosControlPlane: %v", err)
+	}
+	tornDown, err = r.controlPlaneTornDown(ctx, &tm)
+	if err != nil || !tornDown {
+		t.Fatalf("expected a deleted control plane to be torn down, got %v, %v", tornDown, err)
+	}
+	if req := resetRequest(&tm, tornDown); req.Graceful {
+		t.Errorf("expected a forced reset of a machine of a deleted control plane")
+	}
+}
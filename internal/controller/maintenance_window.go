@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// maintenanceWindowOpen returns true if now is inside one of the maintenance windows. Otherwise it
+// returns when the next window opens, or a zero time if no window opens anymore. Without windows
+// upgrades may start at any time.
+func maintenanceWindowOpen(windows []talosv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+	var next time.Time
+	for i := range windows {
+		window := &windows[i]
+		schedule, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid schedule %q of maintenance window: %w", window.Schedule, err)
+		}
+		location, err := maintenanceWindowLocation(window)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid time zone of maintenance window: %w", err)
+		}
+		local := now.In(location)
+		// The first start after now-duration is either inside the window or the next time it opens
+		start := schedule.Next(local.Add(-window.Duration.Duration))
+		if start.IsZero() {
+			// The schedule never matches
+			continue
+		}
+		if !start.After(local) {
+			return true, time.Time{}, nil
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return false, next, nil
+}
+
+// maintenanceWindowLocation returns the time zone the maintenance window is interpreted in
+func maintenanceWindowLocation(window *talosv1alpha1.MaintenanceWindow) (*time.Location, error) {
+	if window.TimeZone == nil {
+		return time.Local, nil
+	}
+	return time.LoadLocation(*window.TimeZone)
+}
+
+// setMaintenanceWindowCondition records on the status of a control plane or worker whether an
+// upgrade waits for a maintenance window and when the next one opens. A zero next while waiting means
+// no window opens anymore. The condition is only added once an upgrade waited. It returns true if the
+// status changed.
+func setMaintenanceWindowCondition(conditions *[]metav1.Condition, nextWindow **metav1.Time, waiting bool, next time.Time) bool {
+	if !waiting {
+		changed := *nextWindow != nil
+		*nextWindow = nil
+		if meta.FindStatusCondition(*conditions, talosv1alpha1.ConditionWaitingForMaintenanceWindow) == nil {
+			return changed
+		}
+		return meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionWaitingForMaintenanceWindow,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NoUpgradeWaiting",
+			Message: "No upgrade is waiting for a maintenance window",
+		}) || changed
+	}
+	if next.IsZero() {
+		changed := *nextWindow != nil
+		*nextWindow = nil
+		return meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionWaitingForMaintenanceWindow,
+			Status:  metav1.ConditionTrue,
+			Reason:  "NoMaintenanceWindow",
+			Message: "Waiting for a maintenance window, none of the schedules opens a window",
+		}) || changed
+	}
+	changed := *nextWindow == nil || !(*nextWindow).Time.Equal(next)
+	*nextWindow = &metav1.Time{Time: next}
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionWaitingForMaintenanceWindow,
+		Status:  metav1.ConditionTrue,
+		Reason:  "OutsideMaintenanceWindow",
+		Message: fmt.Sprintf("Waiting for the maintenance window opening at %s", next.Format(time.RFC3339)),
+	}) || changed
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func nightlyWindow(timeZone string) talosv1alpha1.MaintenanceWindow {
+	return talosv1alpha1.MaintenanceWindow{
+		Schedule: "0 22 * * *",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: ptr.To(timeZone),
+	}
+}
+
+func TestMaintenanceWindowOpen(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	windows := []talosv1alpha1.MaintenanceWindow{nightlyWindow("Europe/Berlin")}
+
+	if open, _, err := maintenanceWindowOpen(nil, time.Now()); err != nil || !open {
+		t.Errorf("expected upgrades to be allowed without windows, got %v, %v", open, err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantOpen bool
+		wantNext time.Time
+	}{
+		{name: "inside", now: time.Date(2026, 3, 10, 23, 30, 0, 0, berlin), wantOpen: true},
+		{name: "after midnight", now: time.Date(2026, 3, 11, 1, 59, 0, 0, berlin), wantOpen: true},
+		{name: "closed", now: time.Date(2026, 3, 11, 2, 0, 0, 0, berlin), wantNext: time.Date(2026, 3, 11, 22, 0, 0, 0, berlin)},
+		{name: "during the day", now: time.Date(2026, 3, 11, 12, 0, 0, 0, berlin), wantNext: time.Date(2026, 3, 11, 22, 0, 0, 0, berlin)},
+		// The same instant in UTC is evaluated in the time zone of the window
+		{name: "utc", now: time.Date(2026, 3, 11, 21, 30, 0, 0, time.UTC), wantOpen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, next, err := maintenanceWindowOpen(windows, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if open != tt.wantOpen {
+				t.Errorf("expected open to be %v", tt.wantOpen)
+			}
+			if !tt.wantOpen && !next.Equal(tt.wantNext) {
+				t.Errorf("expected next window at %s, got %s", tt.wantNext, next)
+			}
+		})
+	}
+
+	// The earliest of several windows is reported
+	windows = append(windows, talosv1alpha1.MaintenanceWindow{
+		Schedule: "0 14 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+		TimeZone: ptr.To("Europe/Berlin"),
+	})
+	_, next, _ := maintenanceWindowOpen(windows, time.Date(2026, 3, 11, 12, 0, 0, 0, berlin))
+	if want := time.Date(2026, 3, 11, 14, 0, 0, 0, berlin); !next.Equal(want) {
+		t.Errorf("expected next window at %s, got %s", want, next)
+	}
+
+	// A schedule that never fires keeps the windows closed
+	open, next, err := maintenanceWindowOpen([]talosv1alpha1.MaintenanceWindow{{Schedule: "0 22 30 2 *", Duration: metav1.Duration{Duration: time.Hour}}}, time.Now())
+	if err != nil || open || !next.IsZero() {
+		t.Errorf("expected no window to open, got %v, %s, %v", open, next, err)
+	}
+
+	if _, _, err := maintenanceWindowOpen([]talosv1alpha1.MaintenanceWindow{{Schedule: "nightly"}}, time.Now()); err == nil {
+		t.Error("expected an invalid schedule to be rejected")
+	}
+}
+
+func TestSetMaintenanceWindowCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	var nextWindow *metav1.Time
+	if setMaintenanceWindowCondition(&conditions, &nextWindow, false, time.Time{}) || len(conditions) != 0 {
+		t.Fatalf("expected no condition while nothing waits, got %v", conditions)
+	}
+
+	next := time.Now().Add(time.Hour).Truncate(time.Second)
+	if !setMaintenanceWindowCondition(&conditions, &nextWindow, true, next) {
+		t.Fatal("expected the status to change")
+	}
+	if !meta.IsStatusConditionTrue(conditions, talosv1alpha1.ConditionWaitingForMaintenanceWindow) || nextWindow == nil || !nextWindow.Time.Equal(next) {
+		t.Fatalf("expected upgrade to wait for %s, got %v and %v", next, conditions, nextWindow)
+	}
+	if setMaintenanceWindowCondition(&conditions, &nextWindow, true, next) {
+		t.Error("expected no change for the same window")
+	}
+
+	// Without a window opening the upgrade keeps waiting
+	if !setMaintenanceWindowCondition(&conditions, &nextWindow, true, time.Time{}) {
+		t.Fatal("expected the status to change")
+	}
+	if condition := meta.FindStatusCondition(conditions, talosv1alpha1.ConditionWaitingForMaintenanceWindow); condition.Status != metav1.ConditionTrue || condition.Reason != "NoMaintenanceWindow" || nextWindow != nil {
+		t.Fatalf("expected upgrade to wait without a next window, got %v and %v", conditions, nextWindow)
+	}
+
+	if !setMaintenanceWindowCondition(&conditions, &nextWindow, false, time.Time{}) {
+		t.Fatal("expected the status to change once nothing waits")
+	}
+	if !meta.IsStatusConditionFalse(conditions, talosv1alpha1.ConditionWaitingForMaintenanceWindow) || nextWindow != nil {
+		t.Errorf("expected upgrade not to wait anymore, got %v and %v", conditions, nextWindow)
+	}
+}
+
+func TestHandleTalosMachines_WaitsForMaintenanceWindow(t *testing.T) {
+	ctx := context.Background()
+	address := "10.0.0.1"
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.Version = "v1.13.1"
+	tcp.Spec.MetalSpec.Machines = []talosv1alpha1.Machine{{Address: &address}}
+	// A one minute window that opened an hour ago is closed already. The layout formats the minute
+	// and hour of the opening time into a daily cron expression.
+	opened := time.Now().Add(-time.Hour)
+	tcp.Spec.MaintenanceWindows = []talosv1alpha1.MaintenanceWindow{{
+		Schedule: opened.UTC().Format("4 15 * * *"),
+		Duration: metav1.Duration{Duration: time.Minute},
+		TimeZone: ptr.To("UTC"),
+	}}
+	machine := newRolloutTestMachine("test-cp-10.0.0.1", "v1.13.0", nil)
+
+	c := newTestClient(t, tcp, &machine)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	held, err := r.handleTalosMachines(ctx, tcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !held {
+		t.Error("expected the upgrade to be held until the maintenance window opens")
+	}
+	var tm talosv1alpha1.TalosMachine
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&machine), &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.Spec.Version != "v1.13.0" {
+		t.Errorf("expected machine to stay on v1.13.0, got %s", tm.Spec.Version)
+	}
+	updated := &talosv1alpha1.TalosControlPlane{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tcp), updated); err != nil {
+		t.Fatalf("failed to get control plane: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionWaitingForMaintenanceWindow) {
+		t.Errorf("expected WaitingForMaintenanceWindow condition, got %v", updated.Status.Conditions)
+	}
+	if updated.Status.NextMaintenanceWindow == nil || !updated.Status.NextMaintenanceWindow.After(time.Now()) {
+		t.Errorf("expected the next window to be in the future, got %v", updated.Status.NextMaintenanceWindow)
+	}
+	if updated.Status.PreUpgradeBackupName != "" {
+		t.Error("expected no pre-upgrade backup outside of the maintenance window")
+	}
+}
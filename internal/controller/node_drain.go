@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/kube"
+)
+
+const (
+	// defaultDrainTimeout is used when the drain spec of a machine does not set a timeout
+	defaultDrainTimeout = 10 * time.Minute
+	// drainAttemptTimeout bounds a single drain attempt so that evictions blocked by a
+	// PodDisruptionBudget do not hold the reconcile worker. Attempts are repeated until the drain
+	// timeout of the machine expired.
+	drainAttemptTimeout = 20 * time.Second
+)
+
+// newWorkloadClusterClient creates a client for the Kubernetes API of a cluster from its kubeconfig
+var newWorkloadClusterClient = kube.NewClient
+
+// kubeconfigSecretName returns the name of the Secret WriteKubeconfig stores the kubeconfig of the
+// cluster in. It is named after the owning TalosCluster if there is one.
+func kubeconfigSecretName(tcp *talosv1alpha1.TalosControlPlane) string {
+	name := tcp.Name
+	if len(tcp.GetOwnerReferences()) > 0 {
+		name = tcp.OwnerReferences[0].Name
+	}
+	return fmt.Sprintf("%s-kubeconfig", name)
+}
+
+// workloadClusterClient returns a client for the Kubernetes API of the cluster of the control plane,
+// or nil if the kubeconfig was not written yet.
+func workloadClusterClient(ctx context.Context, c client.Client, tcp *talosv1alpha1.TalosControlPlane) (*kube.Client, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: kubeconfigSecretName(tcp), Namespace: tcp.Namespace}, secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get kubeconfig Secret of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	kubeconfig := secret.Data["kubeconfig"]
+	if len(kubeconfig) == 0 {
+		return nil, nil
+	}
+	kc, err := newWorkloadClusterClient(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client for TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return kc, nil
+}
+
+// drainEnabled returns true unless draining was turned off for the machine
+func drainEnabled(tm *talosv1alpha1.TalosMachine) bool {
+	return tm.Spec.Drain == nil || tm.Spec.Drain.Enabled == nil || *tm.Spec.Drain.Enabled
+}
+
+func drainTimeout(tm *talosv1alpha1.TalosMachine) time.Duration {
+	if tm.Spec.Drain == nil || tm.Spec.Drain.Timeout == nil {
+		return defaultDrainTimeout
+	}
+	return tm.Spec.Drain.Timeout.Duration
+}
+
+func drainForce(tm *talosv1alpha1.TalosMachine) bool {
+	return tm.Spec.Drain != nil && tm.Spec.Drain.Force
+}
+
+// machineNode returns a client for the cluster of the machine and the name of its Node. The client is
+// nil if the cluster has no kubeconfig yet or its control plane is being deleted, and the name is
+// empty if no Node with the address of the machine is registered.
+func (r *TalosMachineReconciler) machineNode(ctx context.Context, tm *talosv1alpha1.TalosMachine) (*kube.Client, string, error) {
+	tcp, err := r.GetControlPlaneRef(ctx, tm)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get TalosControlPlane of TalosMachine %s: %w", tm.Name, err)
+	}
+	if tcp == nil || !tcp.DeletionTimestamp.IsZero() {
+		return nil, "", nil
+	}
+	kc, err := workloadClusterClient(ctx, r.Client, tcp)
+	if err != nil || kc == nil {
+		return nil, "", err
+	}
+	if tm.Status.NodeName != "" {
+		return kc, tm.Status.NodeName, nil
+	}
+	nodeName, err := kc.FindNode(ctx, tm.Spec.Endpoint)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find Node of TalosMachine %s: %w", tm.Name, err)
+	}
+	return kc, nodeName, nil
+}
+
+// drainNode cordons the Node of the machine and evicts its pods before the machine is upgraded or
+// reset. It returns true once the machine may go ahead: the Node is drained, the drain timed out and
+// force is set, draining is disabled or the machine has no Node. Every call makes one bounded drain
+// attempt, the caller requeues while it returns false.
+func (r *TalosMachineReconciler) drainNode(ctx context.Context, tm *talosv1alpha1.TalosMachine) (bool, error) {
+	logger := log.FromContext(ctx)
+	if !drainEnabled(tm) {
+		return true, nil
+	}
+	kc, nodeName, err := r.machineNode(ctx, tm)
+	if err != nil {
+		return false, err
+	}
+	if kc == nil || nodeName == "" {
+		logger.Info("No Kubernetes Node found for TalosMachine, skipping drain", "name", tm.Name)
+		return true, nil
+	}
+	if tm.Status.DrainStartTime == nil || tm.Status.NodeName != nodeName {
+		orig := tm.DeepCopy()
+		now := metav1.Now()
+		tm.Status.NodeName = nodeName
+		tm.Status.DrainStartTime = &now
+		meta.RemoveStatusCondition(&tm.Status.Conditions, talosv1alpha1.ConditionDrained)
+		if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+			return false, fmt.Errorf("failed to patch TalosMachine %s status with drain start time: %w", tm.Name, err)
+		}
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Draining", "Draining", fmt.Sprintf("Cordoning and draining Node %s", nodeName))
+	}
+	if err := kc.CordonNode(ctx, nodeName); err != nil {
+		return false, err
+	}
+	drainErr := kc.DrainNode(ctx, nodeName, kube.DrainOptions{Timeout: drainAttemptTimeout, Force: drainForce(tm)})
+	if drainErr == nil {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Drained", "Drained", fmt.Sprintf("Drained Node %s", nodeName))
+		return true, nil
+	}
+	timeout := drainTimeout(tm)
+	if time.Since(tm.Status.DrainStartTime.Time) < timeout {
+		logger.Info("Node is not drained yet", "name", tm.Name, "node", nodeName, "reason", drainErr.Error())
+		return false, nil
+	}
+	if drainForce(tm) {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "DrainTimeout", "DrainTimeout", fmt.Sprintf("Node %s was not drained within %s, proceeding since force is set: %v", nodeName, timeout, drainErr))
+		return true, nil
+	}
+	// The timeout is recorded once on the condition, the drain is retried on every requeue
+	logger.Info("Node was not drained within the drain timeout", "name", tm.Name, "node", nodeName, "reason", drainErr.Error())
+	orig := tm.DeepCopy()
+	if !meta.SetStatusCondition(&tm.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionDrained,
+		Status:  metav1.ConditionFalse,
+		Reason:  "DrainTimeout",
+		Message: fmt.Sprintf("Node %s was not drained within %s, waiting for the remaining pods to be evicted", nodeName, timeout),
+	}) {
+		return false, nil
+	}
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return false, fmt.Errorf("failed to patch TalosMachine %s status with drain timeout: %w", tm.Name, err)
+	}
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "DrainTimeout", "DrainTimeout", fmt.Sprintf("Node %s was not drained within %s, waiting for the remaining pods to be evicted: %v", nodeName, timeout, drainErr))
+	return false, nil
+}
+
+// uncordonNode makes the Node that was drained for an upgrade schedulable again once it is Ready. It
+// returns false while the Node is not Ready yet.
+func (r *TalosMachineReconciler) uncordonNode(ctx context.Context, tm *talosv1alpha1.TalosMachine) (bool, error) {
+	if tm.Status.DrainStartTime == nil {
+		return true, nil
+	}
+	kc, nodeName, err := r.machineNode(ctx, tm)
+	if err != nil {
+		return false, err
+	}
+	if kc != nil && nodeName != "" {
+		ready, err := kc.IsNodeReady(ctx, nodeName)
+		if err != nil {
+			return false, err
+		}
+		if !ready {
+			log.FromContext(ctx).Info("Waiting for Node to become Ready before uncordoning it", "name", tm.Name, "node", nodeName)
+			return false, nil
+		}
+		if err := kc.UncordonNode(ctx, nodeName); err != nil {
+			return false, err
+		}
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Uncordoned", "Uncordoned", fmt.Sprintf("Node %s is Ready and schedulable again", nodeName))
+	}
+	orig := tm.DeepCopy()
+	tm.Status.DrainStartTime = nil
+	meta.RemoveStatusCondition(&tm.Status.Conditions, talosv1alpha1.ConditionDrained)
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return false, fmt.Errorf("failed to patch TalosMachine %s status after uncordoning: %w", tm.Name, err)
+	}
+	return true, nil
+}
+
+// deleteNode removes the Node of a machine that was reset from the cluster. Failures are only
+// reported, since the machine is already wiped and the reset cannot be repeated.
+func (r *TalosMachineReconciler) deleteNode(ctx context.Context, tm *talosv1alpha1.TalosMachine) {
+	logger := log.FromContext(ctx)
+	kc, nodeName, err := r.machineNode(ctx, tm)
+	if err == nil && kc != nil && nodeName != "" {
+		err = kc.DeleteNode(ctx, nodeName)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to delete Node of TalosMachine", "name", tm.Name)
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "NodeDeleteFailed", "NodeDeleteFailed", fmt.Sprintf("Failed to delete Node of the machine: %v", err))
+		return
+	}
+	if nodeName != "" {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "NodeDeleted", "NodeDeleted", fmt.Sprintf("Deleted Node %s", nodeName))
+	}
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/kube"
+)
+
+const drainTestNode = "worker-1"
+
+// newDrainTestReconciler returns a reconciler for a worker machine whose cluster has a kubeconfig
+// Secret. The workload cluster is served by a fake clientset with a Ready Node for the machine.
+func newDrainTestReconciler(t *testing.T, tm *talosv1alpha1.TalosMachine, workloadObjs ...runtime.Object) (*TalosMachineReconciler, *kubefake.Clientset) {
+	t.Helper()
+	tcp := &talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-cp", Namespace: DefaultNamespace}}
+	tw := &talosv1alpha1.TalosWorker{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-workers", Namespace: DefaultNamespace},
+		Spec:       talosv1alpha1.TalosWorkerSpec{ControlPlaneRef: corev1.LocalObjectReference{Name: tcp.Name}},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cp-kubeconfig", Namespace: DefaultNamespace},
+		Data:       map[string][]byte{"kubeconfig": []byte("kubeconfig")},
+	}
+	c := newTestClient(t, tcp, tw, secret, tm)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: drainTestNode},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: tm.Spec.Endpoint}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	clientset := kubefake.NewClientset(append([]runtime.Object{node}, workloadObjs...)...)
+	orig := newWorkloadClusterClient
+	newWorkloadClusterClient = func([]byte) (*kube.Client, error) {
+		return &kube.Client{Interface: clientset}, nil
+	}
+	t.Cleanup(func() { newWorkloadClusterClient = orig })
+
+	return &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}, clientset
+}
+
+func newDrainTestMachine() *talosv1alpha1.TalosMachine {
+	return &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-worker-0", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosMachineSpec{
+			Endpoint:  "10.0.0.11",
+			WorkerRef: &corev1.ObjectReference{Name: "test-workers"},
+		},
+	}
+}
+
+func newUnmanagedPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: DefaultNamespace},
+		Spec:       corev1.PodSpec{NodeName: drainTestNode},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestKubeconfigSecretName(t *testing.T) {
+	tcp := &talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-cp"}}
+	if name := kubeconfigSecretName(tcp); name != "test-cp-kubeconfig" {
+		t.Errorf("unexpected Secret name %q", name)
+	}
+	tcp.OwnerReferences = []metav1.OwnerReference{{Kind: "TalosCluster", Name: "test-cluster"}}
+	if name := kubeconfigSecretName(tcp); name != "test-cluster-kubeconfig" {
+		t.Errorf("unexpected Secret name %q for owned control plane", name)
+	}
+}
+
+func TestDrainNode_WithoutKubeconfig(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	r, _ := newDrainTestReconciler(t, tm)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-cp-kubeconfig", Namespace: DefaultNamespace}}
+	if err := r.Delete(ctx, secret); err != nil {
+		t.Fatalf("failed to delete kubeconfig Secret: %v", err)
+	}
+
+	drained, err := r.drainNode(ctx, tm)
+	if err != nil || !drained {
+		t.Fatalf("expected machine without kubeconfig to proceed, got %v, %v", drained, err)
+	}
+	if tm.Status.DrainStartTime != nil {
+		t.Error("expected no drain to be started")
+	}
+}
+
+func TestDrainNode_Disabled(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	disabled := false
+	tm.Spec.Drain = &talosv1alpha1.DrainSpec{Enabled: &disabled}
+	r, clientset := newDrainTestReconciler(t, tm)
+
+	drained, err := r.drainNode(ctx, tm)
+	if err != nil || !drained {
+		t.Fatalf("expected machine with drain disabled to proceed, got %v, %v", drained, err)
+	}
+	node, _ := clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{})
+	if node.Spec.Unschedulable {
+		t.Error("expected Node not to be cordoned")
+	}
+}
+
+func TestDrainNode_CordonsAndDrains(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	r, clientset := newDrainTestReconciler(t, tm)
+
+	drained, err := r.drainNode(ctx, tm)
+	if err != nil || !drained {
+		t.Fatalf("expected Node to be drained, got %v, %v", drained, err)
+	}
+	node, _ := clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Error("expected Node to be cordoned")
+	}
+	updated := &talosv1alpha1.TalosMachine{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(tm), updated); err != nil {
+		t.Fatalf("failed to get TalosMachine: %v", err)
+	}
+	if updated.Status.NodeName != drainTestNode || updated.Status.DrainStartTime == nil {
+		t.Errorf("expected drain to be recorded in status, got node %q and start time %v", updated.Status.NodeName, updated.Status.DrainStartTime)
+	}
+
+	// The machine is back and its Node is Ready, so it is made schedulable again
+	done, err := r.uncordonNode(ctx, updated)
+	if err != nil || !done {
+		t.Fatalf("expected Node to be uncordoned, got %v, %v", done, err)
+	}
+	node, _ = clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{})
+	if node.Spec.Unschedulable {
+		t.Error("expected Node to be schedulable after uncordoning it")
+	}
+	if updated.Status.DrainStartTime != nil {
+		t.Error("expected drain start time to be cleared")
+	}
+}
+
+func TestDrainNode_Timeout(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	tm.Spec.Drain = &talosv1alpha1.DrainSpec{Timeout: &metav1.Duration{Duration: time.Minute}}
+	r, _ := newDrainTestReconciler(t, tm, newUnmanagedPod())
+
+	// A pod without controller is not evicted without force, so the drain does not complete
+	drained, err := r.drainNode(ctx, tm)
+	if err != nil || drained {
+		t.Fatalf("expected drain to block, got %v, %v", drained, err)
+	}
+
+	// Even after the timeout the machine waits for the pods unless force is set
+	started := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	tm.Status.DrainStartTime = &started
+	if err := r.Status().Update(ctx, tm); err != nil {
+		t.Fatalf("failed to update drain start time: %v", err)
+	}
+	drained, err = r.drainNode(ctx, tm)
+	if err != nil || drained {
+		t.Fatalf("expected drain to block after the timeout without force, got %v, %v", drained, err)
+	}
+	if condition := meta.FindStatusCondition(tm.Status.Conditions, talosv1alpha1.ConditionDrained); condition == nil || condition.Reason != "DrainTimeout" {
+		t.Fatalf("expected the drain timeout to be recorded, got %v", tm.Status.Conditions)
+	}
+	// The timeout is only reported once while the drain keeps waiting
+	recorder := r.Recorder.(*events.FakeRecorder)
+	reported := len(recorder.Events)
+	if drained, err = r.drainNode(ctx, tm); err != nil || drained {
+		t.Fatalf("expected drain to keep blocking, got %v, %v", drained, err)
+	}
+	if len(recorder.Events) != reported {
+		t.Errorf("expected no repeated DrainTimeout event, got %d events instead of %d", len(recorder.Events), reported)
+	}
+
+	tm.Spec.Drain.Force = true
+	drained, err = r.drainNode(ctx, tm)
+	if err != nil || !drained {
+		t.Fatalf("expected drain with force to proceed, got %v, %v", drained, err)
+	}
+}
+
+func TestUncordonNode_WaitsForReady(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	started := metav1.Now()
+	tm.Status.NodeName = drainTestNode
+	tm.Status.DrainStartTime = &started
+	r, clientset := newDrainTestReconciler(t, tm)
+	node, _ := clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{})
+	node.Spec.Unschedulable = true
+	node.Status.Conditions[0].Status = corev1.ConditionFalse
+	if _, err := clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update Node: %v", err)
+	}
+
+	done, err := r.uncordonNode(ctx, tm)
+	if err != nil || done {
+		t.Fatalf("expected uncordon to wait for the Node to be Ready, got %v, %v", done, err)
+	}
+	node, _ = clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Error("expected Node to stay cordoned while it is not Ready")
+	}
+}
+
+func TestDeleteNode(t *testing.T) {
+	ctx := context.Background()
+	tm := newDrainTestMachine()
+	r, clientset := newDrainTestReconciler(t, tm)
+
+	r.deleteNode(ctx, tm)
+	if _, err := clientset.CoreV1().Nodes().Get(ctx, drainTestNode, metav1.GetOptions{}); err == nil {
+		t.Error("expected Node to be deleted")
+	}
+}
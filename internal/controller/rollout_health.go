@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// defaultHealthTimeout is used when the rollout strategy does not set a health timeout
+const defaultHealthTimeout = 10 * time.Minute
+
+// resolveMinReady returns how long an upgraded machine has to be healthy before the next one is released
+func resolveMinReady(rs *talosv1alpha1.RolloutStrategy) time.Duration {
+	if rs == nil || rs.RollingUpdate == nil {
+		return 0
+	}
+	return time.Duration(rs.RollingUpdate.MinReadySeconds) * time.Second
+}
+
+// resolveHealthTimeout returns how long a machine may fail its health checks before the rollout is paused
+func resolveHealthTimeout(rs *talosv1alpha1.RolloutStrategy) time.Duration {
+	if rs == nil || rs.RollingUpdate == nil || rs.RollingUpdate.HealthTimeout == nil {
+		return defaultHealthTimeout
+	}
+	return rs.RollingUpdate.HealthTimeout.Duration
+}
+
+// machineReady returns true if the machine passed its health checks at least minReady ago. Machines
+// that were never health checked, e.g. because they were provisioned by an older version of the
+// operator, count as ready.
+func machineReady(m *talosv1alpha1.TalosMachine, minReady time.Duration, now time.Time) bool {
+	condition := meta.FindStatusCondition(m.Status.Conditions, talosv1alpha1.ConditionHealthy)
+	if condition == nil {
+		return true
+	}
+	return condition.Status == metav1.ConditionTrue && now.Sub(condition.LastTransitionTime.Time) >= minReady
+}
+
+// healthTimedOutMachines returns the names of the desired machines that have been failing their
+// health checks for longer than the timeout
+func healthTimedOutMachines(items []talosv1alpha1.TalosMachine, desired map[string]bool, timeout time.Duration, now time.Time) []string {
+	var names []string
+	for i := range items {
+		m := &items[i]
+		if !desired[m.Name] {
+			continue
+		}
+		condition := meta.FindStatusCondition(m.Status.Conditions, talosv1alpha1.ConditionHealthy)
+		if condition != nil && condition.Status == metav1.ConditionFalse && now.Sub(condition.LastTransitionTime.Time) > timeout {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setRolloutPausedCondition records on the conditions of a control plane or worker whether the
+// rollout is paused because machines failed their upgrade or did not become healthy in time. The
+// condition is only added once a rollout was paused. It returns true if the condition changed and
+// whether the rollout is paused.
+func setRolloutPausedCondition(conditions *[]metav1.Condition, failed, timedOut []string, timeout time.Duration) (bool, bool) {
+	paused := len(failed) > 0 || len(timedOut) > 0
+	if !paused && meta.FindStatusCondition(*conditions, talosv1alpha1.ConditionRolloutPaused) == nil {
+		return false, false
+	}
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionRolloutPaused,
+		Status:  metav1.ConditionFalse,
+		Reason:  "MachinesHealthy",
+		Message: "No machine failed its upgrade or exceeded its health timeout",
+	}
+	switch {
+	case len(failed) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "UpgradeFailed"
+		condition.Message = fmt.Sprintf("Upgrade of machines %s failed", strings.Join(failed, ", "))
+	case len(timedOut) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "HealthCheckTimeout"
+		condition.Message = fmt.Sprintf("Machines %s did not pass their health checks within %s", strings.Join(timedOut, ", "), timeout)
+	}
+	return meta.SetStatusCondition(conditions, condition), paused
+}
+
+// machineHealthChecks returns the health checks an upgraded machine has to pass before
+// it is Available: its Talos services, etcd on control plane machines and its Kubernetes Node.
+func (r *TalosMachineReconciler) machineHealthChecks(tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient) []talos.HealthCheck {
+	checks := tc.MachineHealthChecks(tm.Spec.ControlPlaneRef != nil)
+	return append(checks, talos.NewHealthCheck("node", func(ctx context.Context) error {
+		kc, nodeName, err := r.machineNode(ctx, tm)
+		if err != nil || kc == nil || nodeName == "" {
+			// Without a kubeconfig or a registered Node there is nothing to check yet
+			return err
+		}
+		ready, err := kc.IsNodeReady(ctx, nodeName)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return fmt.Errorf("node %s is not Ready", nodeName)
+		}
+		return nil
+	}))
+}
+
+// setHealthyCondition records the result of the health checks of the machine
+func (r *TalosMachineReconciler) setHealthyCondition(ctx context.Context, tm *talosv1alpha1.TalosMachine, healthErr error) error {
+	if r.isDryRun(tm) {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  "HealthChecksPassed",
+		Message: "All health checks passed",
+	}
+	if healthErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "HealthCheckFailed"
+		condition.Message = healthErr.Error()
+	}
+	orig := tm.DeepCopy()
+	if !meta.SetStatusCondition(&tm.Status.Conditions, condition) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to patch TalosMachine %s status with health: %w", tm.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newRolloutTestMachine(name, version string, healthy *metav1.Condition) talosv1alpha1.TalosMachine {
+	tm := talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosMachineSpec{
+			Version:         version,
+			ControlPlaneRef: &corev1.ObjectReference{Name: "test-cp"},
+		},
+	}
+	tm.Status.State = talosv1alpha1.StateAvailable
+	tm.Status.ObservedVersion = version
+	if healthy != nil {
+		tm.Status.Conditions = []metav1.Condition{*healthy}
+	}
+	return tm
+}
+
+func healthyCondition(status metav1.ConditionStatus, since time.Duration) *metav1.Condition {
+	return &metav1.Condition{
+		Type:               talosv1alpha1.ConditionHealthy,
+		Status:             status,
+		Reason:             "Test",
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+	}
+}
+
+func TestResolveRolloutHealth(t *testing.T) {
+	if minReady := resolveMinReady(nil); minReady != 0 {
+		t.Errorf("expected no minReady by default, got %s", minReady)
+	}
+	if timeout := resolveHealthTimeout(nil); timeout != defaultHealthTimeout {
+		t.Errorf("expected default health timeout, got %s", timeout)
+	}
+	rs := &talosv1alpha1.RolloutStrategy{RollingUpdate: &talosv1alpha1.RollingUpdateRolloutStrategy{
+		MinReadySeconds: 90,
+		HealthTimeout:   &metav1.Duration{Duration: 5 * time.Minute},
+	}}
+	if minReady := resolveMinReady(rs); minReady != 90*time.Second {
+		t.Errorf("unexpected minReady %s", minReady)
+	}
+	if timeout := resolveHealthTimeout(rs); timeout != 5*time.Minute {
+		t.Errorf("unexpected health timeout %s", timeout)
+	}
+}
+
+func TestCountInFlightUpgrades_MinReady(t *testing.T) {
+	items := []talosv1alpha1.TalosMachine{
+		// Never health checked
+		newRolloutTestMachine("cp-1", "v1.13.0", nil),
+		// Healthy for long enough
+		newRolloutTestMachine("cp-2", "v1.13.0", healthyCondition(metav1.ConditionTrue, 10*time.Minute)),
+		// Healthy, but only since a few seconds
+		newRolloutTestMachine("cp-3", "v1.13.0", healthyCondition(metav1.ConditionTrue, 5*time.Second)),
+		// Failing its health checks
+		newRolloutTestMachine("cp-4", "v1.13.0", healthyCondition(metav1.ConditionFalse, time.Minute)),
+	}
+	desired := map[string]bool{"cp-1": true, "cp-2": true, "cp-3": true, "cp-4": true}
+
+	if count := countInFlightUpgrades(items, desired, 0); count != 1 {
+		t.Errorf("expected only the unhealthy machine to be in flight without minReady, got %d", count)
+	}
+	if count := countInFlightUpgrades(items, desired, time.Minute); count != 2 {
+		t.Errorf("expected the recently healthy machine to be in flight with minReady, got %d", count)
+	}
+}
+
+func TestHealthTimedOutMachines(t *testing.T) {
+	items := []talosv1alpha1.TalosMachine{
+		newRolloutTestMachine("cp-1", "v1.13.0", healthyCondition(metav1.ConditionFalse, 20*time.Minute)),
+		newRolloutTestMachine("cp-2", "v1.13.0", healthyCondition(metav1.ConditionFalse, time.Minute)),
+		newRolloutTestMachine("cp-3", "v1.13.0", healthyCondition(metav1.ConditionTrue, 20*time.Minute)),
+		newRolloutTestMachine("orphan", "v1.13.0", healthyCondition(metav1.ConditionFalse, 20*time.Minute)),
+	}
+	desired := map[string]bool{"cp-1": true, "cp-2": true, "cp-3": true}
+
+	timedOut := healthTimedOutMachines(items, desired, 10*time.Minute, time.Now())
+	if len(timedOut) != 1 || timedOut[0] != "cp-1" {
+		t.Errorf("expected only cp-1 to exceed the health timeout, got %v", timedOut)
+	}
+}
+
+func TestSetRolloutPausedCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	if changed, paused := setRolloutPausedCondition(&conditions, nil, nil, time.Minute); changed || paused || len(conditions) != 0 {
+		t.Fatalf("expected no condition without a paused rollout, got %v", conditions)
+	}
+
+	changed, paused := setRolloutPausedCondition(&conditions, nil, []string{"cp-1"}, time.Minute)
+	if !changed || !paused || !meta.IsStatusConditionTrue(conditions, talosv1alpha1.ConditionRolloutPaused) {
+		t.Fatalf("expected rollout to be paused, got %v", conditions)
+	}
+
+	// A failed upgrade takes precedence over a health timeout
+	changed, paused = setRolloutPausedCondition(&conditions, []string{"cp-2"}, []string{"cp-1"}, time.Minute)
+	condition := meta.FindStatusCondition(conditions, talosv1alpha1.ConditionRolloutPaused)
+	if !changed || !paused || condition.Reason != "UpgradeFailed" {
+		t.Fatalf("expected rollout to be paused by the failed upgrade, got %v", conditions)
+	}
+
+	changed, paused = setRolloutPausedCondition(&conditions, nil, nil, time.Minute)
+	if !changed || paused || !meta.IsStatusConditionFalse(conditions, talosv1alpha1.ConditionRolloutPaused) {
+		t.Errorf("expected rollout to resume, got %v", conditions)
+	}
+}
+
+func TestHandleTalosMachines_PausesRolloutOnHealthTimeout(t *testing.T) {
+	ctx := context.Background()
+	addresses := []string{"10.0.0.1", "10.0.0.2"}
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.Version = "v1.13.1"
+	tcp.Spec.PreUpgradeBackup = nil
+	tcp.Spec.RolloutStrategy = &talosv1alpha1.RolloutStrategy{RollingUpdate: &talosv1alpha1.RollingUpdateRolloutStrategy{
+		HealthTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+	}}
+	for i := range addresses {
+		tcp.Spec.MetalSpec.Machines = append(tcp.Spec.MetalSpec.Machines, talosv1alpha1.Machine{Address: &addresses[i]})
+	}
+	// The first machine was upgraded already but does not become healthy
+	upgraded := newRolloutTestMachine("test-cp-10.0.0.1", "v1.13.1", healthyCondition(metav1.ConditionFalse, 10*time.Minute))
+	pending := newRolloutTestMachine("test-cp-10.0.0.2", "v1.13.0", nil)
+	// Allow both machines to upgrade at once, so only the pause holds the second one back
+	tcp.Spec.RolloutStrategy.RollingUpdate.MaxUnavailable = ptr.To(intstr.FromInt32(2))
+
+	c := newTestClient(t, tcp, &upgraded, &pending)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	held, err := r.handleTalosMachines(ctx, tcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !held {
+		t.Error("expected the upgrade to be held while the rollout is paused")
+	}
+	var tm talosv1alpha1.TalosMachine
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&pending), &tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.Spec.Version != "v1.13.0" {
+		t.Errorf("expected machine to stay on v1.13.0, got %s", tm.Spec.Version)
+	}
+	updated := &talosv1alpha1.TalosControlPlane{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tcp), updated); err != nil {
+		t.Fatalf("failed to get control plane: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionRolloutPaused) {
+		t.Errorf("expected RolloutPaused condition, got %v", updated.Status.Conditions)
+	}
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// rollout decides which Talos version each machine of a control plane or worker is set to and
+// records the rollout progress of the machines
+type rollout struct {
+	strategyType   talosv1alpha1.RolloutStrategyType
+	partition      int
+	selector       labels.Selector
+	maxUnavailable int
+	inFlight       int
+	// paused holds the upgrades gated by the rollout budget while machines fail their health checks
+	paused bool
+	// blocked holds every upgrade, e.g. outside of a maintenance window
+	blocked bool
+	// plans are the TalosUpgradePlans of the cluster, which hold the upgrades they do not approve
+	plans []talosv1alpha1.TalosUpgradePlan
+	// held is set once an upgrade was held that has to be retried later
+	held   bool
+	status talosv1alpha1.RolloutStatus
+}
+
+// newRollout returns the rollout of the given strategy across replicas machines, inFlight of which are
+// currently unavailable
+func newRollout(rs *talosv1alpha1.RolloutStrategy, replicas, inFlight int, paused bool) (*rollout, error) {
+	ro := &rollout{
+		strategyType:   talosv1alpha1.RollingUpdateStrategyType,
+		maxUnavailable: resolveMaxUnavailable(rs, replicas),
+		inFlight:       inFlight,
+		paused:         paused,
+	}
+	if rs == nil {
+		return ro, nil
+	}
+	if rs.Type != "" {
+		ro.strategyType = rs.Type
+	}
+	if ro.strategyType == talosv1alpha1.PartitionStrategyType && rs.Partition != nil {
+		ro.partition = int(rs.Partition.Partition)
+		if rs.Partition.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(rs.Partition.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid partition selector: %w", err)
+			}
+			ro.selector = selector
+		}
+	}
+	return ro, nil
+}
+
+// partitioned returns true if the machine with the given ordinal may get a new version. Only the
+// Partition strategy keeps machines out, namely those below the partition whose TalosMachine does
+// not match the selector.
+func (ro *rollout) partitioned(ordinal int, existing *talosv1alpha1.TalosMachine) bool {
+	if ro.strategyType != talosv1alpha1.PartitionStrategyType || ordinal >= ro.partition {
+		return true
+	}
+	return ro.selector != nil && existing != nil && ro.selector.Matches(labels.Set(existing.Labels))
+}
+
+// upgradePending returns true if the existing machine is to be upgraded to desiredVersion. Per-machine
+// pins are upgraded regardless of the partition.
+func (ro *rollout) upgradePending(ordinal int, machine *talosv1alpha1.Machine, existing *talosv1alpha1.TalosMachine, desiredVersion string) bool {
+	if existing == nil || existing.Spec.Version == "" || existing.Spec.Version == desiredVersion {
+		return false
+	}
+	return machine.Version != "" || ro.partitioned(ordinal, existing)
+}
+
+// approved returns true if the TalosUpgradePlans of the cluster approve upgrading the machine
+func (ro *rollout) approved(name, version string) bool {
+	return plansApprove(ro.plans, talosv1alpha1.PlannedActionTalosUpgrade, name, version)
+}
+
+// version returns the Talos version the machine is set to and records its rollout phase
+func (ro *rollout) version(name string, ordinal int, machine *talosv1alpha1.Machine, existing *talosv1alpha1.TalosMachine, desiredVersion string) string {
+	version := desiredVersion
+	if existing != nil && existing.Spec.Version != "" && existing.Spec.Version != desiredVersion {
+		switch {
+		case !ro.upgradePending(ordinal, machine, existing, desiredVersion):
+			// The machine is outside of the partition and keeps its version until the partition moves
+			version = existing.Spec.Version
+		case ro.blocked || !ro.approved(name, desiredVersion):
+			// Hold the upgrade, e.g. until the maintenance window opens or the upgrade plan is approved
+			version = existing.Spec.Version
+			ro.held = true
+		case machine.Version != "" || ro.strategyType == talosv1alpha1.RecreateStrategyType:
+			// Per-machine pins and the Recreate strategy are not gated by the rollout budget
+		case ro.paused || ro.inFlight >= ro.maxUnavailable:
+			// Gate the version bump so we don't fan out an upgrade to all machines at once
+			version = existing.Spec.Version
+			ro.held = true
+		default:
+			ro.inFlight++
+		}
+	}
+
+	phase := talosv1alpha1.MachineRolloutPending
+	switch {
+	case version != desiredVersion:
+		phase = talosv1alpha1.MachineRolloutHeld
+		ro.status.HeldMachines++
+	case existing != nil && existing.Spec.Version == version && existing.Status.ObservedVersion == version &&
+		existing.Status.State == talosv1alpha1.StateAvailable:
+		phase = talosv1alpha1.MachineRolloutUpdated
+		ro.status.UpdatedMachines++
+	default:
+		ro.status.PendingMachines++
+	}
+	ro.status.Machines = append(ro.status.Machines, talosv1alpha1.MachineRolloutStatus{Name: name, Version: version, Phase: phase})
+	return version
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestRollout_Partition(t *testing.T) {
+	rs := &talosv1alpha1.RolloutStrategy{
+		Type: talosv1alpha1.PartitionStrategyType,
+		Partition: &talosv1alpha1.PartitionRolloutStrategy{
+			Partition: 2,
+			Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+		},
+	}
+	ro, err := newRollout(rs, 3, 0, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	canary := newRolloutTestMachine("cp-0", "v1.13.0", nil)
+	canary.Labels = map[string]string{"canary": "true"}
+	held := newRolloutTestMachine("cp-1", "v1.13.0", nil)
+	partitioned := newRolloutTestMachine("cp-2", "v1.13.0", nil)
+	machine := talosv1alpha1.Machine{}
+
+	if !ro.upgradePending(0, &machine, &canary, "v1.13.1") || ro.upgradePending(1, &machine, &held, "v1.13.1") {
+		t.Error("expected only machines inside the partition to be pending an upgrade")
+	}
+	pinned := talosv1alpha1.Machine{Version: "v1.13.1"}
+	if !ro.upgradePending(1, &pinned, &held, "v1.13.1") {
+		t.Error("expected a pinned machine to be upgraded regardless of the partition")
+	}
+
+	// The labelled canary takes the only slot of the rollout budget
+	if version := ro.version("cp-0", 0, &machine, &canary, "v1.13.1"); version != "v1.13.1" {
+		t.Errorf("expected the labelled machine to be upgraded, got %s", version)
+	}
+	if version := ro.version("cp-1", 1, &machine, &held, "v1.13.1"); version != "v1.13.0" {
+		t.Errorf("expected the machine below the partition to be held, got %s", version)
+	}
+	if ro.held {
+		t.Error("expected a machine outside of the partition not to be retried")
+	}
+	if version := ro.version("cp-2", 2, &machine, &partitioned, "v1.13.1"); version != "v1.13.0" || !ro.held {
+		t.Errorf("expected the machine inside the partition to wait for the rollout budget, got %s", version)
+	}
+	if ro.status.PendingMachines != 1 || ro.status.HeldMachines != 2 || len(ro.status.Machines) != 3 {
+		t.Errorf("unexpected rollout status %+v", ro.status)
+	}
+}
+
+func TestRollout_Recreate(t *testing.T) {
+	ro, err := newRollout(&talosv1alpha1.RolloutStrategy{Type: talosv1alpha1.RecreateStrategyType}, 3, 1, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	machine := talosv1alpha1.Machine{}
+	for i := range 3 {
+		existing := newRolloutTestMachine(fmt.Sprintf("cp-%d", i), "v1.13.0", nil)
+		if version := ro.version(existing.Name, i, &machine, &existing, "v1.13.1"); version != "v1.13.1" {
+			t.Errorf("expected %s to be upgraded at once, got %s", existing.Name, version)
+		}
+	}
+	if ro.held || ro.status.PendingMachines != 3 {
+		t.Errorf("expected all machines to be upgraded, got %+v", ro.status)
+	}
+
+	// A closed maintenance window holds the Recreate strategy as well
+	ro.blocked = true
+	existing := newRolloutTestMachine("cp-0", "v1.13.0", nil)
+	if version := ro.version(existing.Name, 0, &machine, &existing, "v1.13.1"); version != "v1.13.0" || !ro.held {
+		t.Errorf("expected the upgrade to be held, got %s", version)
+	}
+
+	updated := newRolloutTestMachine("cp-1", "v1.13.1", nil)
+	ro.version(updated.Name, 1, &machine, &updated, "v1.13.1")
+	if last := ro.status.Machines[len(ro.status.Machines)-1]; last.Phase != talosv1alpha1.MachineRolloutUpdated {
+		t.Errorf("expected an available machine on its version to be updated, got %s", last.Phase)
+	}
+}
+
+func TestHandleTalosMachines_Partition(t *testing.T) {
+	ctx := context.Background()
+	addresses := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	tw := &talosv1alpha1.TalosWorker{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-worker", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosWorkerSpec{
+			Version: "v1.13.1",
+			RolloutStrategy: &talosv1alpha1.RolloutStrategy{
+				Type:      talosv1alpha1.PartitionStrategyType,
+				Partition: &talosv1alpha1.PartitionRolloutStrategy{Partition: 2},
+			},
+		},
+	}
+	objects := []client.Object{tw}
+	for _, address := range addresses {
+		tw.Spec.MetalSpec.Machines = append(tw.Spec.MetalSpec.Machines, talosv1alpha1.Machine{Address: &address})
+		machine := newRolloutTestMachine(fmt.Sprintf("test-worker-%s", address), "v1.13.0", nil)
+		machine.Spec.ControlPlaneRef = nil
+		machine.Spec.WorkerRef = &corev1.ObjectReference{Name: tw.Name}
+		objects = append(objects, &machine)
+	}
+
+	c := newTestClient(t, objects...)
+	r := &TalosWorkerReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	held, err := r.handleTalosMachines(ctx, tw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if held {
+		t.Error("expected machines outside of the partition not to requeue the rollout")
+	}
+	for i, address := range addresses {
+		var tm talosv1alpha1.TalosMachine
+		if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: fmt.Sprintf("test-worker-%s", address)}, &tm); err != nil {
+			t.Fatalf("failed to get machine: %v", err)
+		}
+		want := "v1.13.0"
+		if i >= 2 {
+			want = "v1.13.1"
+		}
+		if tm.Spec.Version != want {
+			t.Errorf("expected %s to be set to %s, got %s", tm.Name, want, tm.Spec.Version)
+		}
+	}
+
+	updated := &talosv1alpha1.TalosWorker{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tw), updated); err != nil {
+		t.Fatalf("failed to get worker: %v", err)
+	}
+	rollout := updated.Status.Rollout
+	if rollout == nil || rollout.HeldMachines != 2 || rollout.PendingMachines != 1 || len(rollout.Machines) != 3 {
+		t.Fatalf("unexpected rollout status %+v", rollout)
+	}
+	if machine := rollout.Machines[2]; machine.Phase != talosv1alpha1.MachineRolloutPending || machine.Version != "v1.13.1" {
+		t.Errorf("expected the machine inside the partition to be pending on v1.13.1, got %+v", machine)
+	}
+}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/utils"
+)
+
+// Keys of the Secrets holding the secrets bundle and the machine configs. They match the keys of the
+// state secret so its data can be written back as is.
+const (
+	secretBundleSecretKey  = "secretBundle"
+	machineConfigSecretKey = "config"
+)
+
+func secretBundleSecretName(tcpName string) string {
+	return fmt.Sprintf("%s-secret-bundle", tcpName)
+}
+
+func controlPlaneConfigSecretName(tcpName string) string {
+	return fmt.Sprintf("%s-controlplane-config", tcpName)
+}
+
+func workerConfigSecretName(twName string) string {
+	return fmt.Sprintf("%s-worker-config", twName)
+}
+
+func machineConfigSecretName(tmName string) string {
+	return fmt.Sprintf("%s-machine-config", tmName)
+}
+
+// writeOwnedSecret creates or updates a Secret owned by owner that holds data under key. It returns a
+// reference to the key and whether the stored data changed.
+func writeOwnedSecret(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, name, secretType, key string, data []byte) (*corev1.SecretKeySelector, bool, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		if err := controllerutil.SetControllerReference(owner, secret, scheme); err != nil {
+			return err
+		}
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[talosv1alpha1.StateSecretLabelKey] = secretType
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[key] = data
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create or update Secret %s: %w", name, err)
+	}
+	ref := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+	return ref, op != controllerutil.OperationResultNone, nil
+}
+
+// readSecretKey returns the data stored under the Secret key referenced by ref
+func readSecretKey(ctx context.Context, c client.Client, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", ref.Name, err)
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in Secret %s", ref.Key, ref.Name)
+	}
+	return data, nil
+}
+
+// hasSecretBundle returns true if a secrets bundle was generated for the control plane
+func hasSecretBundle(tcp *talosv1alpha1.TalosControlPlane) bool {
+	return tcp.Status.SecretBundleRef != nil || tcp.Status.SecretBundle != ""
+}
+
+// secretBundleData returns the serialized secrets bundle of the control plane, or an empty string if
+// none was generated yet. Objects that were not migrated yet still carry it in the deprecated status field.
+func secretBundleData(ctx context.Context, c client.Client, tcp *talosv1alpha1.TalosControlPlane) (string, error) {
+	if tcp.Status.SecretBundleRef == nil {
+		return tcp.Status.SecretBundle, nil
+	}
+	data, err := readSecretKey(ctx, c, tcp.Namespace, tcp.Status.SecretBundleRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret bundle of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return string(data), nil
+}
+
+// getSecretBundle returns the decoded secrets bundle of the control plane
+func getSecretBundle(ctx context.Context, c client.Client, tcp *talosv1alpha1.TalosControlPlane) (*secrets.Bundle, error) {
+	data, err := secretBundleData(ctx, c, tcp)
+	if err != nil {
+		return nil, err
+	}
+	if data == "" {
+		return nil, fmt.Errorf("TalosControlPlane %s does not have a secret bundle yet", tcp.Name)
+	}
+	sb, err := utils.SecretBundleDecoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret bundle of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return sb, nil
+}
+
+// machineConfigData returns the machine config stored in the Secret referenced by ref. It falls back to
+// the deprecated status field for objects that were not migrated yet and returns an empty string if the
+// Secret is gone, so the config is treated as not applied.
+func machineConfigData(ctx context.Context, c client.Client, namespace string, ref *corev1.SecretKeySelector, legacy string) (string, error) {
+	if ref == nil {
+		return legacy, nil
+	}
+	data, err := readSecretKey(ctx, c, namespace, ref)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// migrateControlPlaneStatusSecrets moves the secrets bundle and the config of a control plane out of the
+// deprecated status fields into owned Secrets. It returns true if the status has to be updated.
+func migrateControlPlaneStatusSecrets(ctx context.Context, c client.Client, scheme *runtime.Scheme, tcp *talosv1alpha1.TalosControlPlane) (bool, error) {
+	if tcp.Status.SecretBundle == "" && tcp.Status.Config == "" {
+		return false, nil
+	}
+	if tcp.Status.SecretBundle != "" {
+		ref, _, err := writeOwnedSecret(ctx, c, scheme, tcp, secretBundleSecretName(tcp.Name), talosv1alpha1.SecretBundleSecretLabelValue, secretBundleSecretKey, []byte(tcp.Status.SecretBundle))
+		if err != nil {
+			return false, err
+		}
+		tcp.Status.SecretBundleRef = ref
+		tcp.Status.SecretBundle = ""
+	}
+	if tcp.Status.Config != "" {
+		ref, _, err := writeOwnedSecret(ctx, c, scheme, tcp, controlPlaneConfigSecretName(tcp.Name), talosv1alpha1.MachineConfigSecretLabelValue, machineConfigSecretKey, []byte(tcp.Status.Config))
+		if err != nil {
+			return false, err
+		}
+		tcp.Status.ConfigSecretRef = ref
+		tcp.Status.Config = ""
+	}
+	return true, nil
+}
+
+// migrateWorkerStatusSecrets moves the config of a worker out of the deprecated status field into an
+// owned Secret. It returns true if the status has to be updated.
+func migrateWorkerStatusSecrets(ctx context.Context, c client.Client, scheme *runtime.Scheme, tw *talosv1alpha1.TalosWorker) (bool, error) {
+	if tw.Status.Config == "" {
+		return false, nil
+	}
+	ref, _, err := writeOwnedSecret(ctx, c, scheme, tw, workerConfigSecretName(tw.Name), talosv1alpha1.MachineConfigSecretLabelValue, machineConfigSecretKey, []byte(tw.Status.Config))
+	if err != nil {
+		return false, err
+	}
+	tw.Status.ConfigSecretRef = ref
+	tw.Status.Config = ""
+	return true, nil
+}
+
+// migrateMachineStatusSecrets moves the applied config of a machine out of the deprecated status field
+// into an owned Secret. It returns true if the status has to be updated.
+func migrateMachineStatusSecrets(ctx context.Context, c client.Client, scheme *runtime.Scheme, tm *talosv1alpha1.TalosMachine) (bool, error) {
+	if tm.Status.Config == "" {
+		return false, nil
+	}
+	ref, _, err := writeOwnedSecret(ctx, c, scheme, tm, machineConfigSecretName(tm.Name), talosv1alpha1.MachineConfigSecretLabelValue, machineConfigSecretKey, []byte(tm.Status.Config))
+	if err != nil {
+		return false, err
+	}
+	tm.Status.ConfigSecretRef = ref
+	tm.Status.Config = ""
+	return true, nil
+}
+
+// applyStateSecretData restores the status of a control plane from the data of its state secret. The
+// secrets bundle and the config are written to owned Secrets, in DryRun mode they are kept in memory
+// since the Secrets would not be persisted. The caller persists the status.
+func applyStateSecretData(ctx context.Context, c client.Client, scheme *runtime.Scheme, tcp *talosv1alpha1.TalosControlPlane, data map[string][]byte) error {
+	tcp.Status.BundleConfig = string(data["bundleConfig"])
+	tcp.Status.State = string(data["state"])
+	tcp.Status.ObservedKubeVersion = string(data["observedKubeVersion"])
+	if isDryRun(tcp) {
+		tcp.Status.SecretBundle = string(data[secretBundleSecretKey])
+		tcp.Status.Config = string(data[machineConfigSecretKey])
+		return nil
+	}
+	if len(data[secretBundleSecretKey]) > 0 {
+		ref, _, err := writeOwnedSecret(ctx, c, scheme, tcp, secretBundleSecretName(tcp.Name), talosv1alpha1.SecretBundleSecretLabelValue, secretBundleSecretKey, data[secretBundleSecretKey])
+		if err != nil {
+			return err
+		}
+		tcp.Status.SecretBundleRef = ref
+		tcp.Status.SecretBundle = ""
+	}
+	if len(data[machineConfigSecretKey]) > 0 {
+		ref, _, err := writeOwnedSecret(ctx, c, scheme, tcp, controlPlaneConfigSecretName(tcp.Name), talosv1alpha1.MachineConfigSecretLabelValue, machineConfigSecretKey, data[machineConfigSecretKey])
+		if err != nil {
+			return err
+		}
+		tcp.Status.ConfigSecretRef = ref
+		tcp.Status.Config = ""
+	}
+	return nil
+}
+
+// storeMachineConfig writes config to the Secret called name and points ref at it, clearing the
+// deprecated legacy status field. In DryRun mode the Secret would not be persisted, so the config is
+// kept in legacy for this pass instead. It returns true if the stored config or the reference changed.
+func storeMachineConfig(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, name string, ref **corev1.SecretKeySelector, legacy *string, config []byte) (bool, error) {
+	if isDryRun(owner) {
+		current, err := machineConfigData(ctx, c, owner.GetNamespace(), *ref, *legacy)
+		if err != nil {
+			return false, err
+		}
+		if current == string(config) {
+			return false, nil
+		}
+		*legacy = string(config)
+		return true, nil
+	}
+	newRef, changed, err := writeOwnedSecret(ctx, c, scheme, owner, name, talosv1alpha1.MachineConfigSecretLabelValue, machineConfigSecretKey, config)
+	if err != nil {
+		return false, err
+	}
+	if *ref == nil || *legacy != "" {
+		changed = true
+	}
+	*ref = newRef
+	*legacy = ""
+	return changed, nil
+}
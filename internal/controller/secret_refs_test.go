@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newSecretRefsTestControlPlane() *talosv1alpha1.TalosControlPlane {
+	return &talosv1alpha1.TalosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cp",
+			Namespace: DefaultNamespace,
+			UID:       "test-cp-uid",
+		},
+	}
+}
+
+func getTestSecret(t *testing.T, c client.Client, name string) *corev1.Secret {
+	t.Helper()
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: name, Namespace: DefaultNamespace}, secret); err != nil {
+		t.Fatalf("failed to get Secret %s: %v", name, err)
+	}
+	return secret
+}
+
+func TestMigrateControlPlaneStatusSecrets(t *testing.T) {
+	ctx := context.Background()
+	tcp := newSecretRefsTestControlPlane()
+	tcp.Status.SecretBundle = "bundle"
+	tcp.Status.Config = "config"
+	c := newTestClient(t, tcp)
+	scheme := c.Scheme()
+
+	migrated, err := migrateControlPlaneStatusSecrets(ctx, c, scheme, tcp)
+	if err != nil || !migrated {
+		t.Fatalf("expected status to be migrated, got %v, %v", migrated, err)
+	}
+	if tcp.Status.SecretBundle != "" || tcp.Status.Config != "" {
+		t.Errorf("expected deprecated status fields to be cleared")
+	}
+	if tcp.Status.SecretBundleRef == nil || tcp.Status.SecretBundleRef.Name != "test-cp-secret-bundle" {
+		t.Fatalf("unexpected secretBundleRef %v", tcp.Status.SecretBundleRef)
+	}
+	if tcp.Status.ConfigSecretRef == nil || tcp.Status.ConfigSecretRef.Name != "test-cp-controlplane-config" {
+		t.Fatalf("unexpected configSecretRef %v", tcp.Status.ConfigSecretRef)
+	}
+
+	secret := getTestSecret(t, c, "test-cp-secret-bundle")
+	if string(secret.Data[secretBundleSecretKey]) != "bundle" {
+		t.Errorf("unexpected secret bundle %q", secret.Data[secretBundleSecretKey])
+	}
+	if secret.Labels[talosv1alpha1.StateSecretLabelKey] != talosv1alpha1.SecretBundleSecretLabelValue {
+		t.Errorf("unexpected labels %v", secret.Labels)
+	}
+	if !metav1.IsControlledBy(secret, tcp) {
+		t.Errorf("expected the secret bundle Secret to be owned by the TalosControlPlane")
+	}
+	data, err := secretBundleData(ctx, c, tcp)
+	if err != nil || data != "bundle" {
+		t.Errorf("expected to read the secret bundle through the reference, got %q, %v", data, err)
+	}
+	config, err := machineConfigData(ctx, c, tcp.Namespace, tcp.Status.ConfigSecretRef, tcp.Status.Config)
+	if err != nil || config != "config" {
+		t.Errorf("expected to read the config through the reference, got %q, %v", config, err)
+	}
+
+	migrated, err = migrateControlPlaneStatusSecrets(ctx, c, scheme, tcp)
+	if err != nil || migrated {
+		t.Errorf("expected nothing left to migrate, got %v, %v", migrated, err)
+	}
+}
+
+func TestSecretBundleData_LegacyStatus(t *testing.T) {
+	tcp := newSecretRefsTestControlPlane()
+	c := newTestClient(t, tcp)
+	if hasSecretBundle(tcp) {
+		t.Errorf("expected no secret bundle")
+	}
+	if _, err := getSecretBundle(context.Background(), c, tcp); err == nil {
+		t.Errorf("expected an error without a secret bundle")
+	}
+
+	tcp.Status.SecretBundle = "legacy"
+	if !hasSecretBundle(tcp) {
+		t.Errorf("expected the legacy secret bundle to be found")
+	}
+	data, err := secretBundleData(context.Background(), c, tcp)
+	if err != nil || data != "legacy" {
+		t.Errorf("expected the legacy secret bundle, got %q, %v", data, err)
+	}
+}
+
+func TestMachineConfigData_MissingSecret(t *testing.T) {
+	c := newTestClient(t)
+	ref := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+		Key:                  machineConfigSecretKey,
+	}
+	config, err := machineConfigData(context.Background(), c, DefaultNamespace, ref, "")
+	if err != nil || config != "" {
+		t.Errorf("expected a missing Secret to read as no config, got %q, %v", config, err)
+	}
+}
+
+func TestStoreMachineConfig(t *testing.T) {
+	ctx := context.Background()
+	tm := &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: DefaultNamespace, UID: "test-machine-uid"},
+	}
+	tm.Status.Config = "legacy"
+	c := newTestClient(t, tm)
+	scheme := c.Scheme()
+	name := machineConfigSecretName(tm.Name)
+
+	changed, err := storeMachineConfig(ctx, c, scheme, tm, name, &tm.Status.ConfigSecretRef, &tm.Status.Config, []byte("v1"))
+	if err != nil || !changed {
+		t.Fatalf("expected the first store to change the config, got %v, %v", changed, err)
+	}
+	if tm.Status.Config != "" || tm.Status.ConfigSecretRef == nil || tm.Status.ConfigSecretRef.Name != name {
+		t.Fatalf("unexpected status after storing the config: %+v", tm.Status)
+	}
+	changed, err = storeMachineConfig(ctx, c, scheme, tm, name, &tm.Status.ConfigSecretRef, &tm.Status.Config, []byte("v1"))
+	if err != nil || changed {
+		t.Errorf("expected storing the same config to be a no-op, got %v, %v", changed, err)
+	}
+	changed, err = storeMachineConfig(ctx, c, scheme, tm, name, &tm.Status.ConfigSecretRef, &tm.Status.Config, []byte("v2"))
+	if err != nil || !changed {
+		t.Errorf("expected a new config to be stored, got %v, %v", changed, err)
+	}
+	if got := string(getTestSecret(t, c, name).Data[machineConfigSecretKey]); got != "v2" {
+		t.Errorf("unexpected stored config %q", got)
+	}
+}
+
+func TestStoreMachineConfig_DryRun(t *testing.T) {
+	ctx := context.Background()
+	tw := &talosv1alpha1.TalosWorker{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-worker",
+			Namespace:   DefaultNamespace,
+			Annotations: map[string]string{ReconcileModeAnnotation: ReconcileModeDryRun},
+		},
+	}
+	c := newTestClient(t, tw)
+	scheme := c.Scheme()
+
+	changed, err := storeMachineConfig(ctx, c, scheme, tw, workerConfigSecretName(tw.Name), &tw.Status.ConfigSecretRef, &tw.Status.Config, []byte("v1"))
+	if err != nil || !changed {
+		t.Fatalf("expected the config to change, got %v, %v", changed, err)
+	}
+	if tw.Status.Config != "v1" || tw.Status.ConfigSecretRef != nil {
+		t.Errorf("expected the config to be kept in memory in DryRun mode, got %+v", tw.Status)
+	}
+	var secrets corev1.SecretList
+	if err := c.List(ctx, &secrets); err != nil {
+		t.Fatalf("failed to list Secrets: %v", err)
+	}
+	if len(secrets.Items) != 0 {
+		t.Errorf("expected no Secrets in DryRun mode, got %d", len(secrets.Items))
+	}
+}
+
+func TestApplyStateSecretData(t *testing.T) {
+	tcp := newSecretRefsTestControlPlane()
+	c := newTestClient(t, tcp)
+	scheme := c.Scheme()
+	data := map[string][]byte{
+		"secretBundle":        []byte("bundle"),
+		"bundleConfig":        []byte("bundle-config"),
+		"state":               []byte(talosv1alpha1.StateReady),
+		"config":              []byte("config"),
+		"observedKubeVersion": []byte("v1.35.0"),
+	}
+
+	if err := applyStateSecretData(context.Background(), c, scheme, tcp, data); err != nil {
+		t.Fatalf("failed to apply state secret data: %v", err)
+	}
+	if tcp.Status.BundleConfig != "bundle-config" || tcp.Status.State != talosv1alpha1.StateReady || tcp.Status.ObservedKubeVersion != "v1.35.0" {
+		t.Errorf("unexpected restored status %+v", tcp.Status)
+	}
+	if tcp.Status.SecretBundle != "" || tcp.Status.SecretBundleRef == nil || tcp.Status.ConfigSecretRef == nil {
+		t.Fatalf("expected the secrets to be restored into Secrets, got %+v", tcp.Status)
+	}
+	if got := string(getTestSecret(t, c, "test-cp-secret-bundle").Data[secretBundleSecretKey]); got != "bundle" {
+		t.Errorf("unexpected restored secret bundle %q", got)
+	}
+}
@@ -19,7 +19,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -38,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
 )
 
 // TalosClusterReconciler reconciles a TalosCluster object
@@ -45,6 +45,8 @@ type TalosClusterReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
+	// BootServer is the PXE boot service of the operator, nil when the PXE boot stack is not enabled
+	BootServer *pxe.Server
 }
 
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosclusters,verbs=get;list;watch;create;update;patch;delete
@@ -74,8 +76,13 @@ func (r *TalosClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	} else {
 		// Object is being deleted
 		if controllerutil.ContainsFinalizer(&tc, talosv1alpha1.TalosClusterFinalizer) {
+			// Keep the cluster and its children while it is protected from deletion
+			blocked, err := holdProtectedDeletion(ctx, r.Client, r.Recorder, &tc, &tc.Status.Conditions)
+			if err != nil || blocked {
+				return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, err
+			}
 			// Remove TalosCluster from PXE boot stack configuration
-			if os.Getenv("ENABLE_PXE_BOOT_STACK") == PxeBootStackEnabled {
+			if r.BootServer != nil {
 				if isDryRun(&tc) {
 					logger.Info("DryRun: would remove TalosCluster from PXE boot stack configuration", "name", tc.Name)
 					r.Recorder.Eventf(&tc, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, "Would remove cluster from PXE boot stack configuration")
@@ -88,6 +95,10 @@ func (r *TalosClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			if err != nil {
 				return res, fmt.Errorf("failed to handle delete for TalosCluster %s: %w", tc.Name, err)
 			}
+			if res != (ctrl.Result{}) {
+				// Deletion is waiting for the workers or the control plane to be torn down
+				return res, nil
+			}
 			// Remove finalizer and update
 			controllerutil.RemoveFinalizer(&tc, talosv1alpha1.TalosClusterFinalizer)
 			if err := r.Update(ctx, &tc); err != nil {
@@ -110,15 +121,15 @@ func (r *TalosClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		logger.Info("Reconciling TalosCluster in DryRun mode; no mutating operations will be performed", "name", tc.Name, "namespace", tc.Namespace)
 		r.Recorder.Eventf(&tc, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, "Reconciling in DryRun mode; no mutating operations will be performed")
 		// Route all Kubernetes writes through server-side dry-run for the rest of the reconciliation
-		r = &TalosClusterReconciler{Client: client.NewDryRunClient(r.Client), Scheme: r.Scheme, Recorder: r.Recorder}
+		r = &TalosClusterReconciler{Client: client.NewDryRunClient(r.Client), Scheme: r.Scheme, Recorder: r.Recorder, BootServer: r.BootServer}
 	case ReconcileModeNormal:
 		// Do nothing, proceed with reconciliation
 	}
 
 	// PXE boot stack
-	if os.Getenv("ENABLE_PXE_BOOT_STACK") == PxeBootStackEnabled {
+	if r.BootServer != nil {
 		if isDryRun(&tc) {
-			// PXE boot stack performs file-system and process operations that have no dry-run support
+			// The PXE boot service answers machines right away, its configuration has no dry-run support
 			logger.Info("DryRun: would update PXE boot stack configuration", "name", tc.Name)
 			r.Recorder.Eventf(&tc, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, "Would update PXE boot stack configuration")
 		} else if err := r.handlePxeBootStack(ctx, tc); err != nil {
@@ -171,21 +182,27 @@ func (r *TalosClusterReconciler) reconcileControlPlane(ctx context.Context, tc *
 			if err := controllerutil.SetOwnerReference(tc, tcp, r.Scheme); err != nil {
 				return err
 			}
+			inheritDeletionProtection(tc, tcp)
 			// set desired spec
 			tcp.Spec = talosv1alpha1.TalosControlPlaneSpec{
-				Version:          tc.Spec.ControlPlane.Version,
-				Mode:             tc.Spec.ControlPlane.Mode,
-				Replicas:         tc.Spec.ControlPlane.Replicas,
-				Endpoint:         tc.Spec.ControlPlane.Endpoint,
-				MetalSpec:        tc.Spec.ControlPlane.MetalSpec,
-				KubeVersion:      tc.Spec.ControlPlane.KubeVersion,
-				ClusterDomain:    tc.Spec.ControlPlane.ClusterDomain,
-				StorageClassName: tc.Spec.ControlPlane.StorageClassName,
-				PodCIDR:          tc.Spec.ControlPlane.PodCIDR,
-				ServiceCIDR:      tc.Spec.ControlPlane.ServiceCIDR,
-				DeletionPolicy:   tc.Spec.ControlPlane.DeletionPolicy,
-				RolloutStrategy:  tc.Spec.ControlPlane.RolloutStrategy,
-				CNI:              tc.Spec.ControlPlane.CNI,
+				Version:            tc.Spec.ControlPlane.Version,
+				Mode:               tc.Spec.ControlPlane.Mode,
+				Replicas:           tc.Spec.ControlPlane.Replicas,
+				Endpoint:           tc.Spec.ControlPlane.Endpoint,
+				MetalSpec:          tc.Spec.ControlPlane.MetalSpec,
+				KubeVersion:        tc.Spec.ControlPlane.KubeVersion,
+				ClusterDomain:      tc.Spec.ControlPlane.ClusterDomain,
+				StorageClassName:   tc.Spec.ControlPlane.StorageClassName,
+				PodCIDR:            tc.Spec.ControlPlane.PodCIDR,
+				ServiceCIDR:        tc.Spec.ControlPlane.ServiceCIDR,
+				DeletionPolicy:     tc.Spec.ControlPlane.DeletionPolicy,
+				Reset:              tc.Spec.ControlPlane.Reset,
+				RolloutStrategy:    tc.Spec.ControlPlane.RolloutStrategy,
+				CNI:                tc.Spec.ControlPlane.CNI,
+				PreUpgradeBackup:   tc.Spec.ControlPlane.PreUpgradeBackup,
+				Drain:              tc.Spec.ControlPlane.Drain,
+				Upgrade:            tc.Spec.ControlPlane.Upgrade,
+				MaintenanceWindows: tc.Spec.ControlPlane.MaintenanceWindows,
 			}
 			// Optionally set ConfigRef if provided
 			if tc.Spec.ControlPlane.ConfigRef != nil {
@@ -267,16 +284,21 @@ func (r *TalosClusterReconciler) reconcileWorker(ctx context.Context, tc *talosv
 		if err := controllerutil.SetOwnerReference(tc, tw, r.Scheme); err != nil {
 			return err
 		}
+		inheritDeletionProtection(tc, tw)
 
 		// set desired spec
 		tw.Spec = talosv1alpha1.TalosWorkerSpec{
-			Version:          tc.Spec.Worker.Version,
-			Mode:             tc.Spec.Worker.Mode,
-			Replicas:         tc.Spec.Worker.Replicas,
-			MetalSpec:        tc.Spec.Worker.MetalSpec,
-			KubeVersion:      tc.Spec.Worker.KubeVersion,
-			StorageClassName: tc.Spec.Worker.StorageClassName,
-			DeletionPolicy:   tc.Spec.Worker.DeletionPolicy,
+			Version:            tc.Spec.Worker.Version,
+			Mode:               tc.Spec.Worker.Mode,
+			Replicas:           tc.Spec.Worker.Replicas,
+			MetalSpec:          tc.Spec.Worker.MetalSpec,
+			KubeVersion:        tc.Spec.Worker.KubeVersion,
+			StorageClassName:   tc.Spec.Worker.StorageClassName,
+			DeletionPolicy:     tc.Spec.Worker.DeletionPolicy,
+			Reset:              tc.Spec.Worker.Reset,
+			Drain:              tc.Spec.Worker.Drain,
+			Upgrade:            tc.Spec.Worker.Upgrade,
+			MaintenanceWindows: tc.Spec.Worker.MaintenanceWindows,
 			ControlPlaneRef: corev1.LocalObjectReference{
 				Name: controlPlaneRefName,
 			},
@@ -318,7 +340,7 @@ func (r *TalosClusterReconciler) reconcileWorker(ctx context.Context, tc *talosv
 // SetupWithManager sets up the controller with the Manager.
 func (r *TalosClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&talosv1alpha1.TalosCluster{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&talosv1alpha1.TalosCluster{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, deletionProtectionPredicate))).
 		Owns(&talosv1alpha1.TalosControlPlane{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&talosv1alpha1.TalosWorker{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Named("taloscluster").
@@ -336,34 +358,22 @@ func (r *TalosClusterReconciler) handleFinalizer(ctx context.Context, tc *talosv
 	return nil
 }
 
+// handlePxeBootStack reconfigures the PXE boot service for the TalosClusters and downloads the boot
+// images of their machines. A cluster that is being deleted is removed from the configuration.
 func (r *TalosClusterReconciler) handlePxeBootStack(ctx context.Context, tc talosv1alpha1.TalosCluster) error {
-	// Retrieving all TalosCluster resources
-	var tcList talosv1alpha1.TalosClusterList
-	if err := r.List(ctx, &tcList, client.InNamespace(tc.Namespace)); err != nil {
+	invalid, err := updateBootServer(ctx, r.Client, r.BootServer)
+	if err != nil {
 		return err
 	}
-
-	// If this cluster is being deleted, setting PxeServerSpec to "nil" to ignore it when updating configuration
-	if tc.DeletionTimestamp != nil {
-		for i, tcli := range tcList.Items {
-			if tcli.Name == tc.Name {
-				tcList.Items[i].Spec.PxeServerSpec = nil
-			}
-		}
+	// The other clusters are served regardless, only this cluster's machines are not answered
+	if err := invalid[tc.Namespace+"/"+tc.Name]; err != nil {
+		log.FromContext(ctx).Error(err, "invalid PXE configuration, the machines of the TalosCluster are not served", "name", tc.Name)
+		r.Recorder.Eventf(&tc, nil, corev1.EventTypeWarning, "InvalidPxeConfig", "InvalidPxeConfig", fmt.Sprintf("Machines are not served by the PXE boot service: %v", err))
 	}
-
-	clusters := getClustersPxeSpecs(tcList)
-
-	// Update PXE boot stack configuration
-	if err := updatePxeBootStackConfig(clusters); err != nil {
-		return err
+	// Download iPXE and Talos boot images to the assets directory of the boot service
+	if err := r.BootServer.SyncAssets(ctx); err != nil {
+		return fmt.Errorf("failed to download boot images for TalosCluster %s: %w", tc.Name, err)
 	}
-
-	// Download iPXE and Talos boot images to TFTP and Matchbox assets directory
-	if err := downloadBootImages(clusters); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -399,26 +409,35 @@ func (r *TalosClusterReconciler) handleDelete(ctx context.Context, tc talosv1alp
 			return ctrl.Result{Requeue: true}, err
 		}
 	}
-	// Delete the child resources if they exist
-	if err := r.Delete(ctx, &talosv1alpha1.TalosWorker{
+	// Delete the TalosWorker first and wait for it to be gone, its machines need the bundle config of
+	// the control plane to be reset
+	deleting, err := deleteAndWait(ctx, r.Client, &talosv1alpha1.TalosWorker{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      tc.Name,
 			Namespace: tc.Namespace,
 		},
-	}); err != nil && !kerrors.IsNotFound(err) {
+	})
+	if err != nil {
 		logger.Error(err, "failed to delete TalosWorker during TalosCluster deletion", "name", tc.Name)
 		return ctrl.Result{}, err
 	}
-	// Delete the TalosControlPlane
-	if err := r.Delete(ctx, &talosv1alpha1.TalosControlPlane{
+	if deleting {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	// Delete the TalosControlPlane, which tears its machines down with the bootstrap machine last
+	deleting, err = deleteAndWait(ctx, r.Client, &talosv1alpha1.TalosControlPlane{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      tc.Name,
 			Namespace: tc.Namespace,
 		},
-	}); err != nil && !kerrors.IsNotFound(err) {
+	})
+	if err != nil {
 		logger.Error(err, "failed to delete TalosControlPlane during TalosCluster deletion", "name", tc.Name)
 		return ctrl.Result{}, err
 	}
+	if deleting {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
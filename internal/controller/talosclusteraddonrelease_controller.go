@@ -35,6 +35,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/internal/metrics"
 	"github.com/alperencelik/talos-operator/pkg/helm"
 )
 
@@ -85,6 +86,7 @@ func (r *TalosClusterAddonReleaseReconciler) Reconcile(ctx context.Context, req
 				logger.Error(err, "failed to remove finalizer for TalosClusterAddonRelease", "name", tcAddonRelease.Name)
 				return ctrl.Result{}, err
 			}
+			metrics.DeleteAddonReleaseStatus(tcAddonRelease.Namespace, tcAddonRelease.Name)
 		}
 		// Object is being deleted, no further reconciliation needed
 		return ctrl.Result{}, client.IgnoreNotFound(delErr)
@@ -101,6 +103,7 @@ func (r *TalosClusterAddonReleaseReconciler) Reconcile(ctx context.Context, req
 			Reason:  "KubeconfigFailed",
 			Message: err.Error(),
 		})
+		metrics.SetAddonReleaseStatus(tcAddonRelease.Namespace, tcAddonRelease.Name, metrics.AddonReleaseStatusUnknown)
 		if updateErr := r.Status().Update(ctx, &tcAddonRelease); updateErr != nil {
 			logger.Error(updateErr, "failed to update TalosClusterAddonRelease status")
 		}
@@ -116,6 +119,7 @@ func (r *TalosClusterAddonReleaseReconciler) Reconcile(ctx context.Context, req
 			Reason:  "HelmClientFailed",
 			Message: err.Error(),
 		})
+		metrics.SetAddonReleaseStatus(tcAddonRelease.Namespace, tcAddonRelease.Name, metrics.AddonReleaseStatusUnknown)
 		if updateErr := r.Status().Update(ctx, &tcAddonRelease); updateErr != nil {
 			logger.Error(updateErr, "failed to update TalosClusterAddonRelease status")
 		}
@@ -131,12 +135,14 @@ func (r *TalosClusterAddonReleaseReconciler) Reconcile(ctx context.Context, req
 			Reason:  "HelmInstallFailed",
 			Message: err.Error(),
 		})
+		metrics.SetAddonReleaseStatus(tcAddonRelease.Namespace, tcAddonRelease.Name, metrics.AddonReleaseStatusFailed)
 		if updateErr := r.Status().Update(ctx, &tcAddonRelease); updateErr != nil {
 			logger.Error(updateErr, "failed to update TalosClusterAddonRelease status")
 		}
 		return ctrl.Result{}, err
 	}
 
+	metrics.SetAddonReleaseStatus(tcAddonRelease.Namespace, tcAddonRelease.Name, helmRelease.Info.Status.String())
 	if helmRelease.Info.Status == "deployed" {
 		logger.Info("Helm chart deployed successfully", "releaseName", tcAddonRelease.Spec.HelmSpec.ReleaseName)
 	}
@@ -22,6 +22,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"strings"
@@ -30,6 +31,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,6 +52,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/internal/metrics"
 	"github.com/alperencelik/talos-operator/pkg/talos"
 	"github.com/alperencelik/talos-operator/pkg/utils"
 )
@@ -68,9 +71,11 @@ const (
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshosts,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosetcdbackups,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
@@ -90,11 +95,27 @@ func (r *TalosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 	// If status was lost (e.g. CR was deleted and re-applied), attempt to restore from
 	// the state secret before any other logic runs.
-	if tcp.Status.SecretBundle == "" {
+	if !hasSecretBundle(&tcp) {
 		if err := r.restoreFromStateSecret(ctx, &tcp); err != nil {
 			logger.Error(err, "failed to restore status from state secret, will re-provision", "name", tcp.Name)
 		}
 	}
+	// Move the secret bundle and the config out of the status of objects created by older versions.
+	// In DryRun mode the Secrets would not be persisted, so the migration waits for a normal pass.
+	if !isDryRun(&tcp) && tcp.DeletionTimestamp.IsZero() {
+		migrated, err := migrateControlPlaneStatusSecrets(ctx, r.Client, r.Scheme, &tcp)
+		if err != nil {
+			logger.Error(err, "failed to migrate status secrets", "name", tcp.Name)
+			return ctrl.Result{}, err
+		}
+		if migrated {
+			if err := r.Status().Update(ctx, &tcp); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update TalosControlPlane %s status after migrating secrets: %w", tcp.Name, err)
+			}
+			r.Recorder.Eventf(&tcp, nil, corev1.EventTypeNormal, "StatusSecretsMigrated", "StatusSecretsMigrated", "Moved secret bundle and config from status into Secrets")
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
 	// Initialize ObservedKubeVersion if it's empty
 	if tcp.Status.ObservedKubeVersion == "" && tcp.Spec.KubeVersion != "" {
 		tcp.Status.ObservedKubeVersion = tcp.Spec.KubeVersion
@@ -121,6 +142,11 @@ func (r *TalosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	} else {
 		// Handle deletion logic here
 		if controllerutil.ContainsFinalizer(&tcp, talosv1alpha1.TalosControlPlaneFinalizer) {
+			// Keep the control plane and its machines while it is protected from deletion
+			blocked, err := holdProtectedDeletion(ctx, r.Client, r.Recorder, &tcp, &tcp.Status.Conditions)
+			if err != nil || blocked {
+				return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, err
+			}
 			// Run delete operations
 			var res ctrl.Result
 			res, delErr = r.handleDelete(ctx, &tcp)
@@ -202,7 +228,11 @@ func (r *TalosControlPlaneReconciler) reconcileKubeVersion(ctx context.Context,
 	logger := log.FromContext(ctx)
 
 	if tcp.Spec.KubeVersion == "" || tcp.Spec.KubeVersion == tcp.Status.ObservedKubeVersion {
-		return ctrl.Result{}, nil
+		// Talos upgrades are done at this point, so nothing waits for a maintenance window anymore
+		if _, err := r.waitForMaintenanceWindow(ctx, tcp, false); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.finishKubeUpgrade(ctx, tcp)
 	}
 
 	logger.Info("KubeVersion changed, starting upgrade", "old", tcp.Status.ObservedKubeVersion, "new", tcp.Spec.KubeVersion)
@@ -220,6 +250,37 @@ func (r *TalosControlPlaneReconciler) reconcileKubeVersion(ctx context.Context,
 	err := r.Get(ctx, client.ObjectKey{Name: jobName, Namespace: tcp.Namespace}, job)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
+			// Clusters under change control wait for the TalosUpgradePlan to be approved
+			approved, planErr := upgradePlanApproves(ctx, r.Client, tcp.Namespace, tcp.Name, talosv1alpha1.PlannedActionKubernetesUpgrade, tcp.Name, tcp.Spec.KubeVersion)
+			if planErr != nil {
+				return ctrl.Result{}, planErr
+			}
+			if !approved {
+				logger.Info("waiting for the upgrade plan to be approved", "kubeVersion", tcp.Spec.KubeVersion)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			}
+			// The upgrade job is only started inside a maintenance window
+			waiting, windowErr := r.waitForMaintenanceWindow(ctx, tcp, true)
+			if windowErr != nil {
+				return ctrl.Result{}, windowErr
+			}
+			if waiting {
+				logger.Info("waiting for maintenance window", "next", tcp.Status.NextMaintenanceWindow)
+				if tcp.Status.NextMaintenanceWindow == nil {
+					// No window opens anymore, a change of the windows triggers the next reconcile
+					return ctrl.Result{}, nil
+				}
+				return ctrl.Result{RequeueAfter: time.Until(tcp.Status.NextMaintenanceWindow.Time)}, nil
+			}
+			// Take the pre-upgrade etcd backup before the upgrade job is started
+			ready, backupErr := r.ensurePreUpgradeBackup(ctx, tcp)
+			if backupErr != nil {
+				return ctrl.Result{}, backupErr
+			}
+			if !ready {
+				logger.Info("waiting for pre-upgrade etcd backup", "backup", tcp.Status.PreUpgradeBackupName)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			}
 			// Job does not exist, create it.
 			logger.Info("creating upgrade job", "job", jobName)
 			job = &batchv1.Job{
@@ -246,6 +307,12 @@ func (r *TalosControlPlaneReconciler) reconcileKubeVersion(ctx context.Context,
 				return ctrl.Result{}, nil
 			}
 			tcp.Status.State = talosv1alpha1.StateUpgradingKubernetes
+			meta.SetStatusCondition(&tcp.Status.Conditions, metav1.Condition{
+				Type:    talosv1alpha1.ConditionKubernetesUpgradeInProgress,
+				Status:  metav1.ConditionTrue,
+				Reason:  "UpgradeStarted",
+				Message: fmt.Sprintf("Upgrading Kubernetes from %s to %s", tcp.Status.ObservedKubeVersion, tcp.Spec.KubeVersion),
+			})
 			if err := r.Status().Update(ctx, tcp); err != nil {
 				logger.Error(err, "failed to update TalosControlPlane status after creating upgrade job")
 			}
@@ -257,6 +324,14 @@ func (r *TalosControlPlaneReconciler) reconcileKubeVersion(ctx context.Context,
 	if job.Status.Failed > 0 {
 		logger.Error(nil, "upgrade job failed")
 		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "UpgradeJobFailed", "UpgradeJobFailed", "Upgrade job failed")
+		if meta.IsStatusConditionTrue(tcp.Status.Conditions, talosv1alpha1.ConditionKubernetesUpgradeInProgress) {
+			metrics.RecordUpgradeFailure(metrics.UpgradeTypeKubernetes, tcp.Namespace, tcp.Name)
+			meta.SetStatusCondition(&tcp.Status.Conditions, metav1.Condition{
+				Type:   talosv1alpha1.ConditionKubernetesUpgradeInProgress,
+				Status: metav1.ConditionFalse,
+				Reason: "UpgradeFailed",
+			})
+		}
 		tcp.Status.State = talosv1alpha1.StateKubernetesUpgradeFailed
 		if err := r.Status().Update(ctx, tcp); err != nil {
 			logger.Error(err, "failed to update TalosControlPlane status after upgrade job failed")
@@ -272,6 +347,27 @@ func (r *TalosControlPlaneReconciler) reconcileKubeVersion(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// finishKubeUpgrade records the duration of a Kubernetes upgrade once the upgrade job reported the
+// new version, and clears the in-progress condition.
+func (r *TalosControlPlaneReconciler) finishKubeUpgrade(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) error {
+	inProgress := meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionKubernetesUpgradeInProgress)
+	if inProgress == nil || inProgress.Status != metav1.ConditionTrue || isDryRun(tcp) {
+		return nil
+	}
+	started := inProgress.LastTransitionTime.Time
+	meta.SetStatusCondition(&tcp.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionKubernetesUpgradeInProgress,
+		Status:  metav1.ConditionFalse,
+		Reason:  "UpgradeSucceeded",
+		Message: fmt.Sprintf("Upgraded Kubernetes to %s", tcp.Status.ObservedKubeVersion),
+	})
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return fmt.Errorf("failed to update TalosControlPlane %s status after Kubernetes upgrade: %w", tcp.Name, err)
+	}
+	metrics.ObserveUpgrade(metrics.UpgradeTypeKubernetes, time.Since(started))
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TalosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := mgr.GetFieldIndexer().IndexField(
@@ -300,11 +396,16 @@ func (r *TalosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&batchv1.Job{}, builder.WithPredicates(jobPredicate)).
 		// Watch ConfigMaps so that changes to a referenced configRef trigger reconciliation.
 		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.configMapToTalosControlPlanes)).
+		// Watch TalosHosts so that hosts added to or removed from the inventory are claimed or replaced.
+		Watches(&talosv1alpha1.TalosHost{}, handler.EnqueueRequestsFromMapFunc(r.hostToTalosControlPlanes)).
 		WithEventFilter(predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				if _, ok := e.ObjectNew.(*corev1.ConfigMap); ok {
 					return true
 				}
+				if _, ok := e.ObjectNew.(*talosv1alpha1.TalosHost); ok {
+					return true
+				}
 				oldTcp, ok1 := e.ObjectOld.(*talosv1alpha1.TalosControlPlane)
 				newTcp, ok2 := e.ObjectNew.(*talosv1alpha1.TalosControlPlane)
 				if !ok1 || !ok2 {
@@ -314,7 +415,9 @@ func (r *TalosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				condition1 := oldTcp.GetGeneration() != newTcp.GetGeneration()
 				// Check if the observed kubeVersion has changed
 				condition2 := oldTcp.Status.ObservedKubeVersion != newTcp.Status.ObservedKubeVersion
-				return condition1 || condition2
+				// Check if the deletion protection has changed
+				condition3 := deletionProtectionChanged(oldTcp, newTcp)
+				return condition1 || condition2 || condition3
 			},
 		}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
@@ -342,6 +445,27 @@ func (r *TalosControlPlaneReconciler) configMapToTalosControlPlanes(ctx context.
 	return requests
 }
 
+// hostToTalosControlPlanes maps a TalosHost change event to the TalosControlPlanes of its namespace that claim hosts
+// through a hostSelector.
+func (r *TalosControlPlaneReconciler) hostToTalosControlPlanes(ctx context.Context, obj client.Object) []reconcile.Request {
+	var tcpList talosv1alpha1.TalosControlPlaneList
+	if err := r.List(ctx, &tcpList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tcp := range tcpList.Items {
+		if tcp.Spec.MetalSpec.HostSelector != nil {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      tcp.Name,
+					Namespace: tcp.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
+
 func (r *TalosControlPlaneReconciler) reconcileContainerMode(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
@@ -458,10 +582,6 @@ func (r *TalosControlPlaneReconciler) reconcileMetalMode(ctx context.Context, tc
 func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	resolvedMachines, err := getMachinesResolved(ctx, r.Client, &tcp.Spec.MetalSpec.Machines)
-	if err != nil {
-		return false, fmt.Errorf("failed to get machine IP addresses for TalosControlPlane %s: %w", tcp.Name, err)
-	}
 	// List existing ones
 	existing := &talosv1alpha1.TalosMachineList{}
 	if err := r.List(ctx, existing, client.InNamespace(tcp.Namespace),
@@ -469,10 +589,23 @@ func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, t
 	); err != nil {
 		return false, fmt.Errorf("failed to list TalosMachines: %w", err)
 	}
+	// Claim the hosts of the inventory that back the machines besides the listed ones
+	hosts, missing, err := claimHosts(ctx, r.Client, talosv1alpha1.GroupKindControlPlane, tcp, &tcp.Spec.MetalSpec, existing.Items)
+	if err != nil {
+		return false, err
+	}
+	if err := r.updateClaimedHosts(ctx, tcp, hosts, missing); err != nil {
+		return false, err
+	}
+	machines := hostMachines(tcp.Spec.MetalSpec.Machines, hosts)
+	resolvedMachines, err := getMachinesResolved(ctx, r.Client, &machines)
+	if err != nil {
+		return false, fmt.Errorf("failed to get machine IP addresses for TalosControlPlane %s: %w", tcp.Name, err)
+	}
 	// Desired state
 	desired := make(map[string]bool)
-	for ip := range resolvedMachines {
-		desired[fmt.Sprintf("%s-%s", tcp.Name, ip)] = true
+	for _, resolved := range resolvedMachines {
+		desired[fmt.Sprintf("%s-%s", tcp.Name, resolved.IP)] = true
 	}
 	// Index existing machines by name for quick lookup during version decisions.
 	existingByName := make(map[string]*talosv1alpha1.TalosMachine, len(existing.Items))
@@ -480,24 +613,51 @@ func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, t
 		m := &existing.Items[i]
 		existingByName[m.Name] = m
 	}
-	// Delete orphaned machines
-	for _, m := range existing.Items {
-		if m.Spec.ControlPlaneRef != nil && m.Spec.ControlPlaneRef.Name == tcp.Name {
-			if !desired[m.Name] {
-				if err := r.Delete(ctx, &m); err != nil && !kerrors.IsNotFound(err) {
-					logger.Error(err, "Failed to delete orphaned TalosMachine", "name", m.Name)
-					return false, fmt.Errorf("failed to delete orphaned TalosMachine %s: %w", m.Name, err)
+	// Delete orphaned machines one at a time without breaking the etcd quorum
+	scalingDown, err := r.scaleDownControlPlane(ctx, tcp, existing.Items, desired)
+	if err != nil {
+		logger.Error(err, "Failed to scale down TalosControlPlane", "name", tcp.Name)
+		return false, err
+	}
+
+	inFlight := countInFlightUpgrades(existing.Items, desired, resolveMinReady(tcp.Spec.RolloutStrategy))
+
+	// Pause the rollout while machines fail their health checks for longer than the health timeout
+	paused, err := r.updateRolloutPaused(ctx, tcp, existing.Items, desired)
+	if err != nil {
+		return false, err
+	}
+	ro, err := newRollout(tcp.Spec.RolloutStrategy, len(resolvedMachines), inFlight, paused)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve rollout strategy of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	if ro.plans, err = upgradePlansFor(ctx, r.Client, tcp.Namespace, tcp.Name); err != nil {
+		return false, err
+	}
+
+	// Approved upgrades only start inside a maintenance window and after the pre-upgrade etcd backup is ready
+	for ordinal, resolved := range resolvedMachines {
+		name := fmt.Sprintf("%s-%s", tcp.Name, resolved.IP)
+		desiredVersion := machineVersion(tcp, &resolved.Machine)
+		if ro.upgradePending(ordinal, &resolved.Machine, existingByName[name], desiredVersion) && ro.approved(name, desiredVersion) {
+			waiting, err := r.waitForMaintenanceWindow(ctx, tcp, true)
+			if err != nil {
+				return false, err
+			}
+			upgradeAllowed := false
+			if !waiting {
+				if upgradeAllowed, err = r.ensurePreUpgradeBackup(ctx, tcp); err != nil {
+					return false, err
 				}
 			}
+			ro.blocked = !upgradeAllowed
+			break
 		}
 	}
 
-	maxUnavailable := resolveMaxUnavailable(tcp.Spec.RolloutStrategy, len(resolvedMachines))
-	inFlight := countInFlightUpgrades(existing.Items, desired)
-	heldUpgrades := false
-
 	// Create or update TalosMachines
-	for ip, machine := range resolvedMachines {
+	for ordinal, resolved := range resolvedMachines {
+		ip, machine := resolved.IP, resolved.Machine
 		name := fmt.Sprintf("%s-%s", tcp.Name, ip)
 		// If the talosContolPlane is imported then add an annotation to the TalosMachine to indicate that it's imported
 		var annotations map[string]string
@@ -515,24 +675,9 @@ func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, t
 			if err := controllerutil.SetControllerReference(tcp, tm, r.Scheme); err != nil {
 				return fmt.Errorf("failed to set controller reference for TalosMachine %s: %w", tm.Name, err)
 			}
-			desiredVersion := tcp.Spec.Version
-			if machine.Version != "" {
-				// Per-machine pin overrides both the parent version and rollout gating.
-				desiredVersion = machine.Version
-			}
-			version := desiredVersion
-			// For existing machines without an explicit per-machine pin, gate the version bump
-			// behind the rollout strategy so we don't fan out an upgrade to all machines at once.
-			if existingTM, ok := existingByName[name]; ok && machine.Version == "" {
-				if existingTM.Spec.Version != "" && existingTM.Spec.Version != desiredVersion {
-					if inFlight >= maxUnavailable {
-						version = existingTM.Spec.Version
-						heldUpgrades = true
-					} else {
-						inFlight++
-					}
-				}
-			}
+			inheritDeletionProtection(tcp, tm)
+			// Per-machine pin overrides both the parent version and rollout gating.
+			version := ro.version(name, ordinal, &machine, existingByName[name], machineVersion(tcp, &machine))
 			tm.Spec = talosv1alpha1.TalosMachineSpec{
 				ControlPlaneRef: &corev1.ObjectReference{
 					Kind:       talosv1alpha1.GroupKindControlPlane,
@@ -545,7 +690,11 @@ func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, t
 				MachineSpec:    mergeMachineSpec(tcp.Spec.MetalSpec.MachineSpec, &machine),
 				ConfigRef:      tcp.Spec.ConfigRef,
 				DeletionPolicy: tcp.Spec.DeletionPolicy,
+				Reset:          tcp.Spec.Reset,
 				PxeClientSpec:  machine.PxeClientSpec,
+				BMC:            machine.BMC,
+				Drain:          tcp.Spec.Drain,
+				Upgrade:        tcp.Spec.Upgrade,
 			}
 			return nil
 		})
@@ -554,7 +703,18 @@ func (r *TalosControlPlaneReconciler) handleTalosMachines(ctx context.Context, t
 			return false, fmt.Errorf("failed to create or update TalosMachine %s: %w", tm.Name, err)
 		}
 	}
-	return heldUpgrades, nil
+	if err := r.updateRolloutStatus(ctx, tcp, &ro.status); err != nil {
+		return false, err
+	}
+	return ro.held || scalingDown, nil
+}
+
+// machineVersion returns the Talos version a control plane machine should run
+func machineVersion(tcp *talosv1alpha1.TalosControlPlane, machine *talosv1alpha1.Machine) string {
+	if machine.Version != "" {
+		return machine.Version
+	}
+	return tcp.Spec.Version
 }
 
 // resolveMaxUnavailable returns the effective maxUnavailable count for the rollout
@@ -569,23 +729,99 @@ func resolveMaxUnavailable(rs *talosv1alpha1.RolloutStrategy, replicas int) int
 	return v
 }
 
-// countInFlightUpgrades returns how many of the desired machines currently have an upgrade in
-// progress: either explicitly in StateUpgrading, or with an observed version that lags the spec.
-func countInFlightUpgrades(items []talosv1alpha1.TalosMachine, desired map[string]bool) int {
+// countInFlightUpgrades returns how many of the desired machines are unavailable for the rollout:
+// either explicitly in StateUpgrading, with an observed version that lags the spec, or upgraded but
+// not passing their health checks for minReady yet.
+func countInFlightUpgrades(items []talosv1alpha1.TalosMachine, desired map[string]bool, minReady time.Duration) int {
 	count := 0
+	now := time.Now()
 	for i := range items {
 		m := &items[i]
 		if !desired[m.Name] {
 			continue
 		}
 		if m.Status.State == talosv1alpha1.StateUpgrading ||
-			(m.Status.ObservedVersion != "" && m.Status.ObservedVersion != m.Spec.Version) {
+			(m.Status.ObservedVersion != "" && m.Status.ObservedVersion != m.Spec.Version) ||
+			!machineReady(m, minReady, now) {
 			count++
 		}
 	}
 	return count
 }
 
+// updateRolloutPaused sets the RolloutPaused condition of the control plane and returns true if the
+// rollout is paused
+func (r *TalosControlPlaneReconciler) updateRolloutPaused(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, items []talosv1alpha1.TalosMachine, desired map[string]bool) (bool, error) {
+	timeout := resolveHealthTimeout(tcp.Spec.RolloutStrategy)
+	timedOut := healthTimedOutMachines(items, desired, timeout, time.Now())
+	changed, paused := setRolloutPausedCondition(&tcp.Status.Conditions, upgradeFailedMachines(items, desired), timedOut, timeout)
+	if !changed || isDryRun(tcp) {
+		return paused, nil
+	}
+	if paused {
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "RolloutPaused", "RolloutPaused", fmt.Sprintf("Rollout paused: %s", meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionRolloutPaused).Message))
+	}
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return false, fmt.Errorf("failed to update rollout status of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return paused, nil
+}
+
+// updateRolloutStatus records the rollout progress of the control plane machines in the status
+func (r *TalosControlPlaneReconciler) updateRolloutStatus(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, status *talosv1alpha1.RolloutStatus) error {
+	if equality.Semantic.DeepEqual(tcp.Status.Rollout, status) || isDryRun(tcp) {
+		return nil
+	}
+	tcp.Status.Rollout = status
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return fmt.Errorf("failed to update rollout status of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return nil
+}
+
+// updateClaimedHosts records the hosts claimed by the control plane in the status
+func (r *TalosControlPlaneReconciler) updateClaimedHosts(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, hosts []talosv1alpha1.TalosHost, missing int) error {
+	names := hostNames(hosts)
+	changed := setHostsClaimedCondition(&tcp.Status.Conditions, &tcp.Spec.MetalSpec, hosts, missing)
+	if (!changed && slices.Equal(tcp.Status.ClaimedHosts, names)) || isDryRun(tcp) {
+		return nil
+	}
+	if changed && missing > 0 {
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "InsufficientHosts", "InsufficientHosts", meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionHostsClaimed).Message)
+	}
+	tcp.Status.ClaimedHosts = names
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return fmt.Errorf("failed to update claimed hosts of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return nil
+}
+
+// waitForMaintenanceWindow returns true if a pending upgrade has to wait for the next maintenance
+// window of the control plane and records the next window in the status
+func (r *TalosControlPlaneReconciler) waitForMaintenanceWindow(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, pending bool) (bool, error) {
+	var waiting bool
+	var next time.Time
+	if pending {
+		open, nextWindow, err := maintenanceWindowOpen(tcp.Spec.MaintenanceWindows, time.Now())
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate maintenance windows of TalosControlPlane %s: %w", tcp.Name, err)
+		}
+		waiting, next = !open, nextWindow
+	}
+	if !setMaintenanceWindowCondition(&tcp.Status.Conditions, &tcp.Status.NextMaintenanceWindow, waiting, next) || isDryRun(tcp) {
+		return waiting, nil
+	}
+	if waiting && next.IsZero() {
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeWarning, "NoMaintenanceWindow", "NoMaintenanceWindow", "Upgrade is waiting, none of the maintenance window schedules opens a window")
+	} else if waiting {
+		r.Recorder.Eventf(tcp, nil, corev1.EventTypeNormal, "WaitingForMaintenanceWindow", "WaitingForMaintenanceWindow", fmt.Sprintf("Upgrade is waiting for the maintenance window opening at %s", next.Format(time.RFC3339)))
+	}
+	if err := r.Status().Update(ctx, tcp); err != nil {
+		return false, fmt.Errorf("failed to update maintenance window status of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	return waiting, nil
+}
+
 func (r *TalosControlPlaneReconciler) CheckControlPlaneReady(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) (bool, error) {
 	// Check if all replicas of the StatefulSet are ready
 	switch tcp.Spec.Mode {
@@ -798,10 +1034,13 @@ func (r *TalosControlPlaneReconciler) GenerateConfig(ctx context.Context, tcp *t
 	if err != nil {
 		return fmt.Errorf("failed to marshal BundleConfig for %s: %w", tcp.Name, err)
 	}
-	if tcp.Status.Config == string(*cpConfig) && tcp.Status.BundleConfig == string(bcBytes) {
+	configChanged, err := storeMachineConfig(ctx, r.Client, r.Scheme, tcp, controlPlaneConfigSecretName(tcp.Name), &tcp.Status.ConfigSecretRef, &tcp.Status.Config, *cpConfig)
+	if err != nil {
+		return fmt.Errorf("failed to store Talos ControlPlane config for %s: %w", tcp.Name, err)
+	}
+	if !configChanged && tcp.Status.BundleConfig == string(bcBytes) {
 		return nil // No changes in the config, skip update
 	}
-	tcp.Status.Config = string(*cpConfig)
 	// store it in the status
 	tcp.Status.BundleConfig = string(bcBytes)
 	// Update the TalosControlPlane status with the config
@@ -930,12 +1169,12 @@ func (r *TalosControlPlaneReconciler) BootstrapCluster(ctx context.Context, tcp
 	// If the mode is metal tweak the config to use the metal-specific endpoint to bootstrap
 	if tcp.Spec.Mode == TalosModeMetal {
 		// Use the first machine's endpoint for bootstrapping
-		ip, err := getMachineIPAddress(ctx, r.Client, &tcp.Spec.MetalSpec.Machines[0])
-		if err != nil {
-			return fmt.Errorf("failed to get machine IP address for bootstrapping TalosControlPlane %s: %w", tcp.Name, err)
+		ip := bootstrapEndpoint(ctx, r.Client, tcp)
+		if ip == "" {
+			return fmt.Errorf("failed to get machine IP address for bootstrapping TalosControlPlane %s", tcp.Name)
 		}
-		config.Endpoint = *ip
-		config.ClientEndpoint = &[]string{*ip}
+		config.Endpoint = ip
+		config.ClientEndpoint = &[]string{ip}
 	}
 
 	// Create a Talos client
@@ -957,6 +1196,7 @@ func (r *TalosControlPlaneReconciler) BootstrapCluster(ctx context.Context, tcp
 	if err := r.updateState(ctx, tcp, talosv1alpha1.StateBootstrapped); err != nil {
 		return fmt.Errorf("failed to update TalosControlPlane %s status to Bootstrapped: %w", tcp.Name, err)
 	}
+	metrics.ObserveBootstrap(time.Since(tcp.CreationTimestamp.Time))
 	return nil
 }
 
@@ -979,18 +1219,10 @@ func (r *TalosControlPlaneReconciler) WriteKubeconfig(ctx context.Context, tcp *
 	if err != nil {
 		return fmt.Errorf("failed to generate kubeconfig for TalosControlPlane %s: %w", tcp.Name, err)
 	}
-	// If the controlplane is owned by a TalosCluster use it's name
-	var secretName string
-	if tcp.GetOwnerReferences() != nil && len(tcp.GetOwnerReferences()) > 0 {
-		secretName = tcp.ObjectMeta.OwnerReferences[0].Name
-	} else {
-		// Fallback to the TalosControlPlane name
-		secretName = tcp.Name
-	}
 	// Write the kubeconfig to a secret
 	kubeconfigSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-kubeconfig", secretName),
+			Name:      kubeconfigSecretName(tcp),
 			Namespace: tcp.Namespace,
 		},
 	}
@@ -1042,7 +1274,11 @@ func (r *TalosControlPlaneReconciler) SetConfig(ctx context.Context, tcp *talosv
 	}
 	var ClientEndpoint []string
 	if tcp.Spec.Mode == "metal" {
-		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &tcp.Spec.MetalSpec.Machines)
+		machines, err := metalMachines(ctx, r.Client, talosv1alpha1.GroupKindControlPlane, tcp, &tcp.Spec.MetalSpec)
+		if err != nil {
+			return nil, err
+		}
+		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &machines)
 		if err != nil {
 			logger.Error(err, "Failed to get machine IP addresses for TalosControlPlane", "name", tcp.Name)
 			return nil, fmt.Errorf("failed to get machine IP addresses for TalosControlPlane %s: %w", tcp.Name, err)
@@ -1077,8 +1313,8 @@ func (r *TalosControlPlaneReconciler) SecretBundle(ctx context.Context, tcp *tal
 	logger := log.FromContext(ctx)
 	var secretBundle talos.SecretBundle
 	var err error
-	// Get the secret bundle for the TalosControlPlane from .status.SecretBundle
-	if tcp.Status.SecretBundle == "" {
+	// Get the secret bundle for the TalosControlPlane from the Secret referenced in .status.secretBundleRef
+	if !hasSecretBundle(tcp) {
 		// Check if the configRef is set
 		if tcp.Spec.ConfigRef != nil {
 			// Get the config from the ConfigMap
@@ -1110,8 +1346,16 @@ func (r *TalosControlPlaneReconciler) SecretBundle(ctx context.Context, tcp *tal
 			}
 		}
 
-		// Converts bytes to a string and sets it in the status
-		tcp.Status.SecretBundle = string(secretBundleBytes)
+		if isDryRun(tcp) {
+			// The Secret would not be persisted, keep the bundle in memory for this pass
+			tcp.Status.SecretBundle = string(secretBundleBytes)
+		} else {
+			ref, _, err := writeOwnedSecret(ctx, r.Client, r.Scheme, tcp, secretBundleSecretName(tcp.Name), talosv1alpha1.SecretBundleSecretLabelValue, secretBundleSecretKey, secretBundleBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store SecretBundle for TalosControlPlane %s: %w", tcp.Name, err)
+			}
+			tcp.Status.SecretBundleRef = ref
+		}
 		if err := r.Status().Update(ctx, tcp); err != nil {
 			return nil, fmt.Errorf("failed to update TalosControlPlane %s status with SecretBundle: %w", tcp.Name, err)
 		}
@@ -1119,11 +1363,10 @@ func (r *TalosControlPlaneReconciler) SecretBundle(ctx context.Context, tcp *tal
 			return nil, fmt.Errorf("failed to ensure state secret for TalosControlPlane %s: %w", tcp.Name, err)
 		}
 	} else {
-		// Get the existing SecretBundle from the status
-		// logger.Info("Using existing SecretBundle from status")
-		secretBundle, err = utils.SecretBundleDecoder(tcp.Status.SecretBundle)
+		// Get the existing SecretBundle
+		secretBundle, err = getSecretBundle(ctx, r.Client, tcp)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode SecretBundle for TalosControlPlane %s: %w", tcp.Name, err)
+			return nil, fmt.Errorf("failed to get SecretBundle for TalosControlPlane %s: %w", tcp.Name, err)
 		}
 	}
 	// DEBUG: SET Clock forcefully -- investigate later
@@ -1191,22 +1434,27 @@ func (r *TalosControlPlaneReconciler) handleDelete(ctx context.Context, tcp *tal
 			logger.Error(err, "Failed to list TalosMachines for TalosControlPlane", "name", tcp.Name)
 			return ctrl.Result{}, fmt.Errorf("failed to list TalosMachines for TalosControlPlane %s: %w", tcp.Name, err)
 		}
-		// Delete each TalosMachine associated with the TalosControlPlane
-		for _, machine := range machines.Items {
-			if err := r.Delete(ctx, &machine); err != nil && !kerrors.IsNotFound(err) {
-				logger.Error(err, "Failed to delete TalosMachine", "name", machine.Name)
-				return ctrl.Result{}, fmt.Errorf("failed to delete TalosMachine %s for TalosControlPlane %s: %w", machine.Name, tcp.Name, err)
+		// Delete the TalosMachines one after the other with the bootstrap machine last and wait for them
+		// to be deleted
+		if len(machines.Items) > 0 {
+			for i := range machines.Items {
+				if err := releaseInheritedProtection(ctx, r.Client, &machines.Items[i]); err != nil {
+					return ctrl.Result{}, fmt.Errorf("failed to release TalosMachine %s: %w", machines.Items[i].Name, err)
+				}
+			}
+			if machine := nextControlPlaneTeardown(machines.Items, bootstrapEndpoint(ctx, r.Client, tcp)); machine != nil {
+				if err := deleteChild(ctx, r.Client, machine); err != nil {
+					logger.Error(err, "Failed to delete TalosMachine", "name", machine.Name)
+					return ctrl.Result{}, fmt.Errorf("failed to delete TalosMachine %s for TalosControlPlane %s: %w", machine.Name, tcp.Name, err)
+				}
+				r.Recorder.Eventf(tcp, nil, corev1.EventTypeNormal, "TearingDown", "TearingDown", fmt.Sprintf("Deleting TalosMachine %s", machine.Name))
 			}
-		}
-		// Wait for the TalosMachines to be deleted
-		remaining := &talosv1alpha1.TalosMachineList{}
-		if err := r.List(ctx, remaining, opts...); err != nil {
-			logger.Error(err, "Failed to list remaining TalosMachines for TalosControlPlane", "name", tcp.Name)
-			return ctrl.Result{}, fmt.Errorf("failed to list remaining TalosMachines for TalosControlPlane %s: %w", tcp.Name, err)
-		}
-		if len(remaining.Items) > 0 {
 			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
 		}
+		// The machines are reset, return the claimed hosts to the inventory
+		if err := releaseHosts(ctx, r.Client, tcp.Namespace, talosv1alpha1.GroupKindControlPlane, tcp.Name); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to release the hosts of TalosControlPlane %s: %w", tcp.Name, err)
+		}
 	default:
 		logger.Info("Unsupported mode for TalosControlPlane during deletion, finalizer will be removed", "mode", tcp.Spec.Mode)
 	}
@@ -1241,22 +1489,30 @@ func (r *TalosControlPlaneReconciler) updateState(ctx context.Context, tcp *talo
 
 // ensureStateSecret creates or updates the {name}-state Secret with the critical info.
 func (r *TalosControlPlaneReconciler) ensureStateSecret(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) error {
+	secretBundle, err := secretBundleData(ctx, r.Client, tcp)
+	if err != nil {
+		return err
+	}
+	config, err := machineConfigData(ctx, r.Client, tcp.Namespace, tcp.Status.ConfigSecretRef, tcp.Status.Config)
+	if err != nil {
+		return fmt.Errorf("failed to read config of TalosControlPlane %s: %w", tcp.Name, err)
+	}
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("%s-state", tcp.Name),
 			Namespace: tcp.Namespace,
 		},
 	}
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
 		if secret.Labels == nil {
 			secret.Labels = map[string]string{}
 		}
 		secret.Labels[talosv1alpha1.StateSecretLabelKey] = talosv1alpha1.StateSecretLabelValue
 		secret.Data = map[string][]byte{
-			"secretBundle":        []byte(tcp.Status.SecretBundle),
+			"secretBundle":        []byte(secretBundle),
 			"bundleConfig":        []byte(tcp.Status.BundleConfig),
 			"state":               []byte(tcp.Status.State),
-			"config":              []byte(tcp.Status.Config),
+			"config":              []byte(config),
 			"observedKubeVersion": []byte(tcp.Status.ObservedKubeVersion),
 		}
 		return nil
@@ -1279,11 +1535,9 @@ func (r *TalosControlPlaneReconciler) restoreFromStateSecret(ctx context.Context
 	}
 	logger.Info("Restoring TalosControlPlane status from state secret", "name", tcp.Name)
 	orig := tcp.DeepCopy()
-	tcp.Status.SecretBundle = string(secret.Data["secretBundle"])
-	tcp.Status.BundleConfig = string(secret.Data["bundleConfig"])
-	tcp.Status.State = string(secret.Data["state"])
-	tcp.Status.Config = string(secret.Data["config"])
-	tcp.Status.ObservedKubeVersion = string(secret.Data["observedKubeVersion"])
+	if err := applyStateSecretData(ctx, r.Client, r.Scheme, tcp, secret.Data); err != nil {
+		return fmt.Errorf("failed to restore secrets of TalosControlPlane %s from state secret: %w", tcp.Name, err)
+	}
 	if isDryRun(tcp) {
 		// Keep the restored status in memory only; it stays authoritative for this pass
 		logger.Info("DryRun: restored status from state secret in memory only", "name", tcp.Name)
@@ -139,6 +139,8 @@ var _ = Describe("TalosControlPlane Controller", func() {
 				g.Expect(fetched.Status.State).To(BeEmpty())
 				g.Expect(fetched.Status.Config).To(BeEmpty())
 				g.Expect(fetched.Status.SecretBundle).To(BeEmpty())
+				g.Expect(fetched.Status.ConfigSecretRef).To(BeNil())
+				g.Expect(fetched.Status.SecretBundleRef).To(BeNil())
 			}, time.Second*3, interval).Should(Succeed())
 		})
 
@@ -190,6 +192,8 @@ var _ = Describe("TalosControlPlane Controller", func() {
 				g.Expect(fetched.Status.State).To(BeEmpty())
 				g.Expect(fetched.Status.Config).To(BeEmpty())
 				g.Expect(fetched.Status.SecretBundle).To(BeEmpty())
+				g.Expect(fetched.Status.ConfigSecretRef).To(BeNil())
+				g.Expect(fetched.Status.SecretBundleRef).To(BeNil())
 			}, time.Second*3, interval).Should(Succeed())
 		})
 	})
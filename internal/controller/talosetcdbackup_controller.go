@@ -20,16 +20,18 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"time"
 
+	"github.com/alperencelik/talos-operator/internal/metrics"
 	"github.com/alperencelik/talos-operator/pkg/storage"
 	"github.com/alperencelik/talos-operator/pkg/talos"
-	"github.com/alperencelik/talos-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -116,6 +118,7 @@ func (r *TalosEtcdBackupReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	// Perform the backup
 	if err := r.performBackup(ctx, &teb); err != nil {
 		logger.Error(err, "Failed to perform backup")
+		metrics.RecordEtcdBackupFailure(teb.Namespace, teb.Spec.TalosControlPlaneRef.Name)
 
 		// Set failed condition
 		meta.SetStatusCondition(&teb.Status.Conditions, metav1.Condition{
@@ -143,6 +146,11 @@ func (r *TalosEtcdBackupReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		logger.Error(err, "Failed to update status to ready")
 		return ctrl.Result{}, err
 	}
+	var duration time.Duration
+	if teb.Status.StartTime != nil && teb.Status.CompletionTime != nil {
+		duration = teb.Status.CompletionTime.Sub(teb.Status.StartTime.Time)
+	}
+	metrics.RecordEtcdBackupSuccess(teb.Namespace, teb.Spec.TalosControlPlaneRef.Name, duration, teb.Status.Size)
 
 	logger.Info("Successfully completed etcd backup", "TalosEtcdBackup", req.NamespacedName)
 	return ctrl.Result{}, nil
@@ -176,23 +184,25 @@ func (r *TalosEtcdBackupReconciler) handleDelete(ctx context.Context, teb *talos
 		// Nothing to delete
 		return nil
 	}
-	s3Config, err := r.getS3Config(ctx, teb)
+	// Create the storage backend client
+	backend, err := newStorageBackend(ctx, r.Client, teb.Namespace, &teb.Spec.BackupStorage)
 	if err != nil {
-		return fmt.Errorf("failed to get S3 configuration: %w", err)
+		return fmt.Errorf("failed to create backup storage client: %w", err)
 	}
-	// Create S3 client
-	s3Client, err := storage.NewS3Client(ctx, s3Config)
-	if err != nil {
-		return fmt.Errorf("failed to create S3 client: %w", err)
+	defer backend.Close() //nolint:errcheck
+	// Delete the backup and its state secret from the storage backend
+	if err := backend.Delete(ctx, teb.Status.Filename); err != nil {
+		return fmt.Errorf("failed to delete backup from storage: %w", err)
 	}
-	// Delete the backup from S3
-	if err := s3Client.Delete(ctx, teb.Status.Filename); err != nil {
-		return fmt.Errorf("failed to delete backup from S3: %w", err)
+	if teb.Status.StateFilename != "" {
+		if err := backend.Delete(ctx, teb.Status.StateFilename); err != nil {
+			return fmt.Errorf("failed to delete state secret from storage: %w", err)
+		}
 	}
 	return nil
 }
 
-// performBackup executes the etcd backup by streaming from Talos to S3
+// performBackup executes the etcd backup by streaming from Talos to the storage backend
 func (r *TalosEtcdBackupReconciler) performBackup(ctx context.Context, teb *talosv1alpha1.TalosEtcdBackup) error {
 	logger := logf.FromContext(ctx)
 
@@ -217,9 +227,9 @@ func (r *TalosEtcdBackupReconciler) performBackup(ctx context.Context, teb *talo
 		return fmt.Errorf("failed to parse bundle config: %w", err)
 	}
 	// Get the secretBundle
-	sb, err := utils.SecretBundleDecoder(tcp.Status.SecretBundle)
+	sb, err := getSecretBundle(ctx, r.Client, &tcp)
 	if err != nil {
-		return fmt.Errorf("failed to decode secret bundle: %w", err)
+		return fmt.Errorf("failed to get secret bundle: %w", err)
 	}
 	sb.Clock = talos.NewClock()
 	bc.SecretsBundle = sb
@@ -231,16 +241,34 @@ func (r *TalosEtcdBackupReconciler) performBackup(ctx context.Context, teb *talo
 	}
 	defer talosClient.Close() //nolint:errcheck
 
-	// Get S3 configuration and credentials from secrets
-	s3Config, err := r.getS3Config(ctx, teb)
+	// Create the storage backend client, credentials are resolved from secrets
+	backend, err := newStorageBackend(ctx, r.Client, teb.Namespace, &teb.Spec.BackupStorage)
 	if err != nil {
-		return fmt.Errorf("failed to get S3 configuration: %w", err)
+		return fmt.Errorf("failed to create backup storage client: %w", err)
 	}
-
-	// Create S3 client
-	s3Client, err := storage.NewS3Client(ctx, s3Config)
+	defer backend.Close() //nolint:errcheck
+	// Resolve the encryption key before anything is uploaded
+	encryptor, err := newBackupEncryptor(ctx, r.Client, teb.Namespace, teb.Spec.Encryption)
 	if err != nil {
-		return fmt.Errorf("failed to create S3 client: %w", err)
+		return fmt.Errorf("failed to create backup encryptor: %w", err)
+	}
+
+	// Record where the snapshot comes from, these are informational so failures are not fatal
+	startTime := metav1.Now()
+	teb.Status.StartTime = &startTime
+	teb.Status.KubernetesVersion = tcp.Status.ObservedKubeVersion
+	if teb.Status.KubernetesVersion == "" {
+		teb.Status.KubernetesVersion = tcp.Spec.KubeVersion
+	}
+	if talosVersion, err := talosClient.GetTalosVersion(ctx); err != nil {
+		logger.Error(err, "Failed to get Talos version for the backup metadata")
+	} else {
+		teb.Status.TalosVersion = talosVersion
+	}
+	if revision, err := talosClient.EtcdRevision(ctx); err != nil {
+		logger.Error(err, "Failed to get etcd revision for the backup metadata")
+	} else {
+		teb.Status.EtcdRevision = revision
 	}
 
 	// Get etcd snapshot reader (streaming)
@@ -250,40 +278,156 @@ func (r *TalosEtcdBackupReconciler) performBackup(ctx context.Context, teb *talo
 		return fmt.Errorf("failed to get etcd snapshot reader: %w", err)
 	}
 	defer snapshotReader.Close() // nolint:errcheck
+	// Compute the checksum of the plaintext snapshot while it is streamed
+	checksumReader := storage.NewChecksumReader(snapshotReader)
+	var uploadReader io.Reader = checksumReader
+	if encryptor != nil {
+		encryptedReader, err := encryptor.EncryptReader(checksumReader)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt etcd snapshot: %w", err)
+		}
+		defer encryptedReader.Close() //nolint:errcheck
+		uploadReader = encryptedReader
+	}
 
 	// Generate backup key
 	backupKey := storage.GenerateBackupKey(tcp.Name)
-	logger.Info("Uploading etcd snapshot to S3", "bucket", s3Config.Bucket, "key", backupKey)
+	logger.Info("Uploading etcd snapshot to backup storage", "key", backupKey)
 	// Write that key to status so I can refer it later
 	teb.Status.Filename = backupKey
+	teb.Status.Encrypted = encryptor != nil
 	// Update status with the file path
 	if err := r.Status().Update(ctx, teb); err != nil {
 		return fmt.Errorf("failed to update status with file path: %w", err)
 	}
-	// Stream directly to S3
-	if err := s3Client.Upload(ctx, backupKey, snapshotReader); err != nil {
-		return fmt.Errorf("failed to upload snapshot to S3: %w", err)
+	// Stream directly to the storage backend
+	if err := backend.Upload(ctx, backupKey, uploadReader); err != nil {
+		return fmt.Errorf("failed to upload snapshot to storage: %w", err)
 	}
+	teb.Status.SHA256 = checksumReader.SHA256()
+	teb.Status.Size = checksumReader.Size()
 
-	logger.Info("Successfully uploaded etcd snapshot to S3", "bucket", s3Config.Bucket, "key", backupKey)
+	logger.Info("Successfully uploaded etcd snapshot to backup storage", "key", backupKey, "size", teb.Status.Size, "sha256", teb.Status.SHA256)
 
 	// Upload the state secret as well
-	stateKey, err := r.uploadStateSecret(ctx, &tcp, s3Client, backupKey)
+	stateKey, err := r.uploadStateSecret(ctx, &tcp, backend, encryptor, backupKey)
 	if err != nil {
 		return fmt.Errorf("failed to upload state secret: %w", err)
 	}
 	if stateKey != nil {
 		teb.Status.StateFilename = *stateKey
-		if err := r.Status().Update(ctx, teb); err != nil {
-			return fmt.Errorf("failed to update status with state filename: %w", err)
+		logger.Info("Successfully uploaded state secret to backup storage", "key", stateKey)
+	}
+	completionTime := metav1.Now()
+	teb.Status.CompletionTime = &completionTime
+	if err := r.Status().Update(ctx, teb); err != nil {
+		return fmt.Errorf("failed to update status with backup metadata: %w", err)
+	}
+
+	// Attach the metadata to the object so the backup can be identified without the TalosEtcdBackup
+	if err := backend.SetMetadata(ctx, backupKey, backupObjectMetadata(teb)); err != nil {
+		logger.Error(err, "Failed to set metadata on the etcd snapshot object", "key", backupKey)
+	}
+
+	if teb.Spec.Verify {
+		if err := r.verifyBackup(ctx, teb, backend, encryptor); err != nil {
+			// The retry uploads a new snapshot under a new key, the unverified one would be orphaned
+			discardBackup(ctx, teb, backend)
+			return fmt.Errorf("failed to verify backup: %w", err)
 		}
-		logger.Info("Successfully uploaded state secret to S3", "bucket", s3Config.Bucket, "key", stateKey)
 	}
 	return nil
 }
 
+// verifyBackup downloads the uploaded snapshot and validates its checksum and size against the status
+func (r *TalosEtcdBackupReconciler) verifyBackup(ctx context.Context, teb *talosv1alpha1.TalosEtcdBackup, backend storage.Backend, encryptor storage.Encryptor) error {
+	logger := logf.FromContext(ctx)
+	logger.Info("Verifying etcd snapshot", "key", teb.Status.Filename)
+
+	reader, err := backend.Download(ctx, teb.Status.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to download etcd snapshot: %w", err)
+	}
+	defer reader.Close() //nolint:errcheck
+	var snapshotReader io.Reader = reader
+	if encryptor != nil {
+		snapshotReader, err = encryptor.DecryptReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt etcd snapshot: %w", err)
+		}
+	}
+	sum, size, err := storage.Checksum(snapshotReader)
+	if err != nil {
+		return fmt.Errorf("failed to read etcd snapshot: %w", err)
+	}
+
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionVerified,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ChecksumMatched",
+		Message: "Downloaded etcd snapshot matches the recorded checksum and size",
+	}
+	var verifyErr error
+	if sum != teb.Status.SHA256 || size != teb.Status.Size {
+		verifyErr = fmt.Errorf("downloaded snapshot has checksum %s and size %d, expected %s and %d", sum, size, teb.Status.SHA256, teb.Status.Size)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ChecksumMismatch"
+		condition.Message = verifyErr.Error()
+	} else {
+		verificationTime := metav1.Now()
+		teb.Status.VerificationTime = &verificationTime
+	}
+	meta.SetStatusCondition(&teb.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, teb); err != nil {
+		return fmt.Errorf("failed to update status with verification result: %w", err)
+	}
+	return verifyErr
+}
+
+// discardBackup deletes the snapshot and state secret of the backup from the storage backend and clears
+// their keys from the status. Failures are only logged, the backup fails either way.
+func discardBackup(ctx context.Context, teb *talosv1alpha1.TalosEtcdBackup, backend storage.Backend) {
+	logger := logf.FromContext(ctx)
+	for _, key := range []string{teb.Status.Filename, teb.Status.StateFilename} {
+		if key == "" {
+			continue
+		}
+		if err := backend.Delete(ctx, key); err != nil {
+			logger.Error(err, "Failed to delete unverified backup from storage", "key", key)
+			return
+		}
+	}
+	teb.Status.Filename = ""
+	teb.Status.StateFilename = ""
+}
+
+// backupObjectMetadata returns the object metadata describing the etcd snapshot of the backup
+func backupObjectMetadata(teb *talosv1alpha1.TalosEtcdBackup) map[string]string {
+	metadata := map[string]string{
+		storage.MetadataSHA256: teb.Status.SHA256,
+		storage.MetadataSize:   strconv.FormatInt(teb.Status.Size, 10),
+	}
+	if teb.Status.EtcdRevision != 0 {
+		metadata[storage.MetadataEtcdRevision] = strconv.FormatInt(teb.Status.EtcdRevision, 10)
+	}
+	if teb.Status.TalosVersion != "" {
+		metadata[storage.MetadataTalosVersion] = teb.Status.TalosVersion
+	}
+	if teb.Status.KubernetesVersion != "" {
+		metadata[storage.MetadataKubernetesVersion] = teb.Status.KubernetesVersion
+	}
+	if teb.Status.CompletionTime != nil {
+		metadata[storage.MetadataCompletionTime] = teb.Status.CompletionTime.UTC().Format(time.RFC3339)
+	}
+	if teb.Spec.Encryption != nil && teb.Status.Encrypted {
+		metadata[storage.MetadataEncryption] = teb.Spec.Encryption.Type
+	}
+	return metadata
+}
+
 // uploadStateSecret fetches the {tcp.Name}-state Secret, strips runtime metadata so it
-func (r *TalosEtcdBackupReconciler) uploadStateSecret(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, s3Client *storage.S3Client, backupKey string) (*string, error) {
+// can be re-applied on restore and uploads it next to the snapshot, encrypted if an encryptor is given.
+func (r *TalosEtcdBackupReconciler) uploadStateSecret(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, backend storage.Backend, encryptor storage.Encryptor, backupKey string) (*string, error) {
 	stateSecret := &corev1.Secret{}
 	if err := r.Get(ctx, client.ObjectKey{
 		Namespace: tcp.Namespace,
@@ -311,61 +455,18 @@ func (r *TalosEtcdBackupReconciler) uploadStateSecret(ctx context.Context, tcp *
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal state secret: %w", err)
 	}
+	var stateReader io.Reader = bytes.NewReader(yamlBytes)
+	if encryptor != nil {
+		encryptedReader, err := encryptor.EncryptReader(stateReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt state secret: %w", err)
+		}
+		defer encryptedReader.Close() //nolint:errcheck
+		stateReader = encryptedReader
+	}
 	stateKey := storage.StateKeyForBackupKey(backupKey)
-	if err := s3Client.Upload(ctx, stateKey, bytes.NewReader(yamlBytes)); err != nil {
-		return nil, fmt.Errorf("failed to upload state secret to S3: %w", err)
+	if err := backend.Upload(ctx, stateKey, stateReader); err != nil {
+		return nil, fmt.Errorf("failed to upload state secret to storage: %w", err)
 	}
 	return &stateKey, nil
 }
-
-// getS3Config retrieves S3 configuration from the TalosEtcdBackup spec and resolves credentials from secrets
-func (r *TalosEtcdBackupReconciler) getS3Config(ctx context.Context, teb *talosv1alpha1.TalosEtcdBackup) (*storage.S3Config, error) {
-	if teb.Spec.BackupStorage.S3 == nil {
-		return nil, fmt.Errorf("S3 storage configuration is not specified")
-	}
-
-	s3Spec := teb.Spec.BackupStorage.S3
-
-	// Retrieve access key ID from secret
-	accessKeyID, err := r.getSecretValue(ctx, teb.Namespace, s3Spec.AccessKeyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get access key ID: %w", err)
-	}
-
-	// Retrieve secret access key from secret
-	secretAccessKey, err := r.getSecretValue(ctx, teb.Namespace, s3Spec.SecretAccessKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get secret access key: %w", err)
-	}
-
-	return &storage.S3Config{
-		Bucket:             s3Spec.Bucket,
-		Region:             s3Spec.Region,
-		Endpoint:           s3Spec.Endpoint,
-		AccessKeyID:        accessKeyID,
-		SecretAccessKey:    secretAccessKey,
-		InsecureSkipVerify: s3Spec.InsecureSkipTLSVerify,
-	}, nil
-}
-
-// getSecretValue retrieves a value from a Kubernetes secret
-func (r *TalosEtcdBackupReconciler) getSecretValue(ctx context.Context, namespace string, selector *corev1.SecretKeySelector) (string, error) {
-	if selector == nil {
-		return "", fmt.Errorf("secret selector is nil")
-	}
-
-	var secret corev1.Secret
-	if err := r.Get(ctx, types.NamespacedName{
-		Namespace: namespace,
-		Name:      selector.Name,
-	}, &secret); err != nil {
-		return "", fmt.Errorf("failed to get secret %s: %w", selector.Name, err)
-	}
-
-	value, ok := secret.Data[selector.Key]
-	if !ok {
-		return "", fmt.Errorf("key %s not found in secret %s", selector.Key, selector.Name)
-	}
-
-	return string(value), nil
-}
@@ -133,6 +133,16 @@ var _ = Describe("TalosEtcdBackup Controller", func() {
 				return k8sClient.Get(ctx, types.NamespacedName{Name: talosEtcdBackupName, Namespace: namespace}, talosEtcdBackup)
 			}, timeout, interval).ShouldNot(Succeed())
 		})
+		It("Should reject more than one storage backend", func() {
+			talosEtcdBackup.Spec.BackupStorage.Filesystem = &talosv1alpha1.FilesystemStorage{
+				Path: "/var/lib/talos-operator/backups",
+			}
+			Expect(k8sClient.Create(ctx, talosEtcdBackup)).NotTo(Succeed())
+		})
+		It("Should reject an empty storage configuration", func() {
+			talosEtcdBackup.Spec.BackupStorage = talosv1alpha1.BackupStorage{}
+			Expect(k8sClient.Create(ctx, talosEtcdBackup)).NotTo(Succeed())
+		})
 	})
 
 	// TODO: Find a way to mock S3 interactions to test backup process
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/storage"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testSnapshotData = "etcd-snapshot-data"
+
+func newVerifyTestBackup(t *testing.T, backend storage.Backend, encryptor storage.Encryptor) *talosv1alpha1.TalosEtcdBackup {
+	t.Helper()
+	ctx := context.Background()
+	checksumReader := storage.NewChecksumReader(strings.NewReader(testSnapshotData))
+	var uploadErr error
+	if encryptor != nil {
+		encrypted, err := encryptor.EncryptReader(checksumReader)
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+		uploadErr = backend.Upload(ctx, "backup.db", encrypted)
+	} else {
+		uploadErr = backend.Upload(ctx, "backup.db", checksumReader)
+	}
+	if uploadErr != nil {
+		t.Fatalf("failed to upload: %v", uploadErr)
+	}
+	return &talosv1alpha1.TalosEtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backup",
+			Namespace: DefaultNamespace,
+		},
+		Status: talosv1alpha1.TalosEtcdBackupStatus{
+			Filename: "backup.db",
+			SHA256:   checksumReader.SHA256(),
+			Size:     checksumReader.Size(),
+		},
+	}
+}
+
+func TestVerifyBackup(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	aesEncryptor, err := storage.NewEncryptor(storage.EncryptionTypeAESGCM, []byte(base64.StdEncoding.EncodeToString(key)))
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		encryptor   storage.Encryptor
+		corrupt     bool
+		expectError bool
+	}{
+		{name: "plaintext snapshot matches"},
+		{name: "encrypted snapshot matches", encryptor: aesEncryptor},
+		{name: "checksum mismatch", corrupt: true, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend, err := storage.NewFilesystemClient(&storage.FilesystemConfig{Path: t.TempDir()})
+			if err != nil {
+				t.Fatalf("failed to create backend: %v", err)
+			}
+			teb := newVerifyTestBackup(t, backend, tt.encryptor)
+			if tt.corrupt {
+				teb.Status.SHA256 = strings.Repeat("0", 64)
+			}
+			c := newTestClient(t, teb)
+			r := &TalosEtcdBackupReconciler{Client: c, Scheme: c.Scheme()}
+
+			err = r.verifyBackup(ctx, teb, backend, tt.encryptor)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("verifyBackup() error = %v, expectError %v", err, tt.expectError)
+			}
+			condition := meta.FindStatusCondition(teb.Status.Conditions, talosv1alpha1.ConditionVerified)
+			if condition == nil {
+				t.Fatal("expected Verified condition to be set")
+			}
+			if tt.expectError {
+				if condition.Status != metav1.ConditionFalse || teb.Status.VerificationTime != nil {
+					t.Errorf("expected failed verification, got condition %s and verification time %v", condition.Status, teb.Status.VerificationTime)
+				}
+				return
+			}
+			if condition.Status != metav1.ConditionTrue || teb.Status.VerificationTime == nil {
+				t.Errorf("expected successful verification, got condition %s and verification time %v", condition.Status, teb.Status.VerificationTime)
+			}
+		})
+	}
+}
+
+func TestDiscardBackup(t *testing.T) {
+	ctx := context.Background()
+	backend, err := storage.NewFilesystemClient(&storage.FilesystemConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	teb := newVerifyTestBackup(t, backend, nil)
+	if err := backend.Upload(ctx, "state.yaml", strings.NewReader("state")); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	teb.Status.StateFilename = "state.yaml"
+
+	discardBackup(ctx, teb, backend)
+	if keys, err := backend.List(ctx, ""); err != nil || len(keys) != 0 {
+		t.Errorf("expected the unverified backup to be deleted, got %v, %v", keys, err)
+	}
+	if teb.Status.Filename != "" || teb.Status.StateFilename != "" {
+		t.Errorf("expected the keys to be cleared, got %q and %q", teb.Status.Filename, teb.Status.StateFilename)
+	}
+}
+
+func TestBackupObjectMetadata(t *testing.T) {
+	completionTime := metav1.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	teb := &talosv1alpha1.TalosEtcdBackup{
+		Spec: talosv1alpha1.TalosEtcdBackupSpec{
+			Encryption: &talosv1alpha1.BackupEncryption{Type: storage.EncryptionTypeAge},
+		},
+		Status: talosv1alpha1.TalosEtcdBackupStatus{
+			Encrypted:         true,
+			SHA256:            "abc",
+			Size:              42,
+			EtcdRevision:      1234,
+			TalosVersion:      testTalosVersion,
+			KubernetesVersion: testKubeVersion,
+			CompletionTime:    &completionTime,
+		},
+	}
+	metadata := backupObjectMetadata(teb)
+	expected := map[string]string{
+		storage.MetadataSHA256:            "abc",
+		storage.MetadataSize:              "42",
+		storage.MetadataEtcdRevision:      "1234",
+		storage.MetadataTalosVersion:      testTalosVersion,
+		storage.MetadataKubernetesVersion: testKubeVersion,
+		storage.MetadataCompletionTime:    completionTime.UTC().Format(time.RFC3339),
+		storage.MetadataEncryption:        storage.EncryptionTypeAge,
+	}
+	for k, v := range expected {
+		if metadata[k] != v {
+			t.Errorf("expected metadata %s=%s, got %s", k, v, metadata[k])
+		}
+	}
+	if len(metadata) != len(expected) {
+		t.Errorf("expected %d metadata entries, got %d", len(expected), len(metadata))
+	}
+}
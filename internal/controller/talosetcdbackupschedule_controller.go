@@ -19,7 +19,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -36,6 +35,18 @@ import (
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
 )
 
+const (
+	// maxMissedSchedules is the number of missed schedules kept in the status history
+	maxMissedSchedules = 10
+	// maxMissedStartTimes is the number of missed schedules after which the schedule starts over from
+	// the current time, like a CronJob does
+	maxMissedStartTimes = 100
+
+	missedScheduleReasonSuperseded           = "Superseded"
+	missedScheduleReasonDeadlineExceeded     = "StartingDeadlineExceeded"
+	missedScheduleReasonConcurrencyForbidden = "ConcurrencyForbidden"
+)
+
 // TalosEtcdBackupScheduleReconciler reconciles a TalosEtcdBackupSchedule object
 type TalosEtcdBackupScheduleReconciler struct {
 	client.Client
@@ -103,30 +114,67 @@ func (r *TalosEtcdBackupScheduleReconciler) Reconcile(ctx context.Context, req c
 		return ctrl.Result{}, statusErr
 	}
 
+	// Interpret the schedule in its time zone
+	location, err := scheduleLocation(&schedule)
+	if err != nil {
+		logger.Error(err, "Invalid time zone", "timeZone", schedule.Spec.TimeZone)
+		meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionFailed,
+			Status:  metav1.ConditionTrue,
+			Reason:  "InvalidTimeZone",
+			Message: fmt.Sprintf("Invalid time zone: %v", err),
+		})
+		if statusErr = r.Status().Update(ctx, &schedule); statusErr != nil {
+			logger.Error(statusErr, "Failed to update status")
+		}
+		return ctrl.Result{}, statusErr
+	}
+
 	// Get the next scheduled time
-	now := time.Now()
+	now := time.Now().In(location)
 	nextSchedule := cronSchedule.Next(now)
 
-	// Determine if we should create a backup
-	shouldCreateBackup := false
-	lastScheduleTime := schedule.Status.LastScheduleTime
+	// Track the backups of this schedule that are still running
+	var backupList talosv1alpha1.TalosEtcdBackupList
+	if err := r.List(ctx, &backupList, client.InNamespace(schedule.Namespace), client.MatchingLabels{
+		talosv1alpha1.TalosEtcdBackupScheduleLabelKey: schedule.Name,
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list backups: %w", err)
+	}
+	active := updateScheduleBackupStatus(&schedule, backupList.Items)
 
-	if lastScheduleTime == nil {
+	// Determine which scheduled time, if any, is due
+	var scheduledTime time.Time
+	if schedule.Status.LastScheduleTime == nil {
 		// First time running, create a backup
-		shouldCreateBackup = true
+		scheduledTime = now
 	} else {
-		// Calculate when the next backup should have been created after the last one
-		lastScheduledNext := cronSchedule.Next(lastScheduleTime.Time)
-		// If we're past the next scheduled time, create a backup
-		if now.After(lastScheduledNext) || now.Equal(lastScheduledNext) {
-			shouldCreateBackup = true
+		var missed []time.Time
+		var tooMany bool
+		scheduledTime, missed, tooMany = mostRecentScheduleTime(cronSchedule, schedule.Status.LastScheduleTime.In(location), now)
+		switch {
+		case tooMany:
+			// Start over from now instead of catching up on the missed runs
+			logger.Info("Too many missed start times, scheduling from now", "lastScheduleTime", schedule.Status.LastScheduleTime)
+			scheduledTime = now
+			meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+				Type:   talosv1alpha1.ConditionTooManyMissedStarts,
+				Status: metav1.ConditionTrue,
+				Reason: "TooManyMissedStartTimes",
+				Message: fmt.Sprintf("More than %d scheduled backups were missed since %s, scheduling from %s",
+					maxMissedStartTimes, schedule.Status.LastScheduleTime.Format(time.RFC3339), now.Format(time.RFC3339)),
+			})
+		case !scheduledTime.IsZero():
+			meta.RemoveStatusCondition(&schedule.Status.Conditions, talosv1alpha1.ConditionTooManyMissedStarts)
+		}
+		// Only the most recent run is started after an outage, older ones are skipped
+		for _, t := range missed {
+			recordMissedSchedule(&schedule, t, missedScheduleReasonSuperseded)
 		}
 	}
 
-	// Check if we need to create a new backup
-	if shouldCreateBackup {
-		// Time to create a new backup
-		if err := r.createBackup(ctx, &schedule); err != nil {
+	if !scheduledTime.IsZero() {
+		if err := r.runScheduledBackup(ctx, &schedule, scheduledTime, now, active); err != nil {
 			logger.Error(err, "Failed to create backup")
 			meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
 				Type:    talosv1alpha1.ConditionFailed,
@@ -139,13 +187,13 @@ func (r *TalosEtcdBackupScheduleReconciler) Reconcile(ctx context.Context, req c
 			}
 			return ctrl.Result{}, err
 		}
-
-		// Update last schedule time
-		schedule.Status.LastScheduleTime = &metav1.Time{Time: now}
+		// Update last schedule time, skipped runs count as handled as well
+		schedule.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		meta.RemoveStatusCondition(&schedule.Status.Conditions, talosv1alpha1.ConditionFailed)
 	}
 
 	// Clean up old backups based on retention policy
-	if err := r.cleanupOldBackups(ctx, &schedule); err != nil {
+	if err := r.cleanupOldBackups(ctx, &schedule, now); err != nil {
 		logger.Error(err, "Failed to cleanup old backups")
 		// Don't fail the reconciliation for cleanup errors
 	}
@@ -218,12 +266,43 @@ func (r *TalosEtcdBackupScheduleReconciler) handleDelete(ctx context.Context, sc
 	return nil
 }
 
+// runScheduledBackup starts the backup scheduled at scheduledTime, honouring the starting deadline
+// and the concurrency policy. Runs that are skipped are recorded in the missed schedules.
+func (r *TalosEtcdBackupScheduleReconciler) runScheduledBackup(ctx context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule, scheduledTime, now time.Time, active []talosv1alpha1.TalosEtcdBackup) error {
+	logger := logf.FromContext(ctx)
+
+	if deadline := schedule.Spec.StartingDeadlineSeconds; deadline != nil && now.Sub(scheduledTime) > time.Duration(*deadline)*time.Second {
+		logger.Info("Skipping backup that missed its starting deadline", "scheduledTime", scheduledTime)
+		recordMissedSchedule(schedule, scheduledTime, missedScheduleReasonDeadlineExceeded)
+		return nil
+	}
+
+	if len(active) > 0 {
+		switch schedule.Spec.ConcurrencyPolicy {
+		case talosv1alpha1.ForbidConcurrent:
+			logger.Info("Skipping backup while the previous one is still running", "scheduledTime", scheduledTime, "active", schedule.Status.ActiveBackups)
+			recordMissedSchedule(schedule, scheduledTime, missedScheduleReasonConcurrencyForbidden)
+			return nil
+		case talosv1alpha1.ReplaceConcurrent:
+			for _, backup := range active {
+				logger.Info("Deleting running backup to replace it", "backup", backup.Name)
+				if err := r.Delete(ctx, &backup); err != nil && !errors.IsNotFound(err) {
+					return fmt.Errorf("failed to delete running backup %s: %w", backup.Name, err)
+				}
+			}
+			schedule.Status.ActiveBackups = nil
+		}
+	}
+
+	return r.createBackup(ctx, schedule, scheduledTime)
+}
+
 // createBackup creates a new TalosEtcdBackup from the schedule template
-func (r *TalosEtcdBackupScheduleReconciler) createBackup(ctx context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule) error {
+func (r *TalosEtcdBackupScheduleReconciler) createBackup(ctx context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule, scheduledTime time.Time) error {
 	logger := logf.FromContext(ctx)
 
-	// Generate backup name with timestamp
-	backupName := fmt.Sprintf("%s-%d", schedule.Name, time.Now().Unix())
+	// Name the backup after its scheduled time so a run is never created twice
+	backupName := fmt.Sprintf("%s-%d", schedule.Name, scheduledTime.Unix())
 
 	backup := &talosv1alpha1.TalosEtcdBackup{
 		ObjectMeta: metav1.ObjectMeta{
@@ -242,21 +321,86 @@ func (r *TalosEtcdBackupScheduleReconciler) createBackup(ctx context.Context, sc
 	}
 
 	logger.Info("Creating backup", "backup", backupName)
-	if err := r.Create(ctx, backup); err != nil {
+	if err := r.Create(ctx, backup); err != nil && !errors.IsAlreadyExists(err) {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
+	schedule.Status.ActiveBackups = append(schedule.Status.ActiveBackups, backupName)
 
 	return nil
 }
 
-// cleanupOldBackups removes backups that exceed the retention policy
-func (r *TalosEtcdBackupScheduleReconciler) cleanupOldBackups(ctx context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule) error {
-	logger := logf.FromContext(ctx)
+// scheduleLocation returns the time zone the schedule is interpreted in
+func scheduleLocation(schedule *talosv1alpha1.TalosEtcdBackupSchedule) (*time.Location, error) {
+	if schedule.Spec.TimeZone == nil {
+		return time.Local, nil
+	}
+	return time.LoadLocation(*schedule.Spec.TimeZone)
+}
+
+// mostRecentScheduleTime returns the most recent scheduled time after lastScheduleTime that is due,
+// or the zero time if none is due, and the older scheduled times that were missed in between.
+// It stops and returns true if more than maxMissedStartTimes scheduled times were missed.
+func mostRecentScheduleTime(schedule cron.Schedule, lastScheduleTime, now time.Time) (time.Time, []time.Time, bool) {
+	var mostRecent time.Time
+	var missed []time.Time
+	starts := 0
+	for t := schedule.Next(lastScheduleTime); !t.After(now); t = schedule.Next(t) {
+		if starts++; starts > maxMissedStartTimes {
+			return time.Time{}, nil, true
+		}
+		if !mostRecent.IsZero() {
+			missed = append(missed, mostRecent)
+			// Only the latest entries end up in the status history
+			if len(missed) > maxMissedSchedules {
+				missed = missed[1:]
+			}
+		}
+		mostRecent = t
+	}
+	return mostRecent, missed, false
+}
 
-	retention := int32(5) // default
-	if schedule.Spec.Retention != nil {
-		retention = *schedule.Spec.Retention
+// recordMissedSchedule appends a skipped run to the missed schedule history of the schedule
+func recordMissedSchedule(schedule *talosv1alpha1.TalosEtcdBackupSchedule, scheduledTime time.Time, reason string) {
+	schedule.Status.MissedSchedules = append(schedule.Status.MissedSchedules, talosv1alpha1.MissedSchedule{
+		ScheduledTime: metav1.Time{Time: scheduledTime},
+		Reason:        reason,
+	})
+	if overflow := len(schedule.Status.MissedSchedules) - maxMissedSchedules; overflow > 0 {
+		schedule.Status.MissedSchedules = schedule.Status.MissedSchedules[overflow:]
 	}
+}
+
+// updateScheduleBackupStatus records the running backups and the time of the last successful backup
+// in the schedule status and returns the running backups
+func updateScheduleBackupStatus(schedule *talosv1alpha1.TalosEtcdBackupSchedule, backups []talosv1alpha1.TalosEtcdBackup) []talosv1alpha1.TalosEtcdBackup {
+	var active []talosv1alpha1.TalosEtcdBackup
+	schedule.Status.ActiveBackups = nil
+	for _, backup := range backups {
+		if meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionReady) {
+			completed := backup.CreationTimestamp
+			if backup.Status.CompletionTime != nil {
+				completed = *backup.Status.CompletionTime
+			}
+			if schedule.Status.LastSuccessfulBackupTime == nil || completed.After(schedule.Status.LastSuccessfulBackupTime.Time) {
+				schedule.Status.LastSuccessfulBackupTime = &completed
+			}
+			continue
+		}
+		if meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionFailed) || !backup.DeletionTimestamp.IsZero() {
+			continue
+		}
+		active = append(active, backup)
+		schedule.Status.ActiveBackups = append(schedule.Status.ActiveBackups, backup.Name)
+	}
+	return active
+}
+
+// cleanupOldBackups removes backups that are not kept by the retention policy, whose periods follow
+// the time zone of now. The storage objects of a deleted backup are removed by the TalosEtcdBackup
+// finalizer.
+func (r *TalosEtcdBackupScheduleReconciler) cleanupOldBackups(ctx context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule, now time.Time) error {
+	logger := logf.FromContext(ctx)
 
 	// List all backups owned by this schedule
 	var backupList talosv1alpha1.TalosEtcdBackupList
@@ -266,27 +410,14 @@ func (r *TalosEtcdBackupScheduleReconciler) cleanupOldBackups(ctx context.Contex
 		return fmt.Errorf("failed to list backups: %w", err)
 	}
 
-	// Filter successful backups
-	var successfulBackups []talosv1alpha1.TalosEtcdBackup
-	for _, backup := range backupList.Items {
-		if meta.IsStatusConditionTrue(backup.Status.Conditions, talosv1alpha1.ConditionReady) {
-			successfulBackups = append(successfulBackups, backup)
+	policy := effectiveRetentionPolicy(schedule)
+	for _, backup := range selectBackupsToPrune(backupList.Items, policy, now) {
+		if !backup.DeletionTimestamp.IsZero() {
+			continue
 		}
-	}
-
-	// Sort by creation timestamp (newest first)
-	sort.Slice(successfulBackups, func(i, j int) bool {
-		return successfulBackups[i].CreationTimestamp.After(successfulBackups[j].CreationTimestamp.Time)
-	})
-
-	// Delete backups exceeding retention
-	if len(successfulBackups) > int(retention) {
-		backupsToDelete := successfulBackups[retention:]
-		for _, backup := range backupsToDelete {
-			logger.Info("Deleting old backup due to retention policy", "backup", backup.Name)
-			if err := r.Delete(ctx, &backup); err != nil && !errors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
-			}
+		logger.Info("Deleting old backup due to retention policy", "backup", backup.Name)
+		if err := r.Delete(ctx, &backup); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
 		}
 	}
 
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	hourly, err := cron.ParseStandard("0 * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	last := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("nothing due", func(t *testing.T) {
+		scheduled, missed, tooMany := mostRecentScheduleTime(hourly, last, last.Add(59*time.Minute))
+		if !scheduled.IsZero() || len(missed) != 0 || tooMany {
+			t.Errorf("expected nothing due, got %v and %d missed", scheduled, len(missed))
+		}
+	})
+
+	t.Run("one run due", func(t *testing.T) {
+		scheduled, missed, tooMany := mostRecentScheduleTime(hourly, last, last.Add(65*time.Minute))
+		if want := last.Add(time.Hour); !scheduled.Equal(want) {
+			t.Errorf("expected %v, got %v", want, scheduled)
+		}
+		if len(missed) != 0 || tooMany {
+			t.Errorf("expected no missed runs, got %d", len(missed))
+		}
+	})
+
+	t.Run("outage", func(t *testing.T) {
+		scheduled, missed, tooMany := mostRecentScheduleTime(hourly, last, last.Add(3*time.Hour+time.Minute))
+		if want := last.Add(3 * time.Hour); !scheduled.Equal(want) {
+			t.Errorf("expected %v, got %v", want, scheduled)
+		}
+		if tooMany || len(missed) != 2 || !missed[0].Equal(last.Add(time.Hour)) || !missed[1].Equal(last.Add(2*time.Hour)) {
+			t.Errorf("unexpected missed runs %v", missed)
+		}
+	})
+
+	t.Run("long outage keeps the latest missed runs", func(t *testing.T) {
+		scheduled, missed, tooMany := mostRecentScheduleTime(hourly, last, last.Add(48*time.Hour))
+		if want := last.Add(48 * time.Hour); !scheduled.Equal(want) {
+			t.Errorf("expected %v, got %v", want, scheduled)
+		}
+		if tooMany || len(missed) != maxMissedSchedules {
+			t.Fatalf("expected %d missed runs, got %d", maxMissedSchedules, len(missed))
+		}
+		if want := last.Add(47 * time.Hour); !missed[len(missed)-1].Equal(want) {
+			t.Errorf("expected latest missed run %v, got %v", want, missed[len(missed)-1])
+		}
+	})
+
+	t.Run("too many missed start times", func(t *testing.T) {
+		scheduled, _, tooMany := mostRecentScheduleTime(hourly, last, last.Add(maxMissedStartTimes*time.Hour))
+		if tooMany || !scheduled.Equal(last.Add(maxMissedStartTimes*time.Hour)) {
+			t.Errorf("expected %d start times to be caught up on, got %v, %v", maxMissedStartTimes, scheduled, tooMany)
+		}
+		scheduled, missed, tooMany := mostRecentScheduleTime(hourly, last, last.Add((maxMissedStartTimes+1)*time.Hour))
+		if !tooMany || !scheduled.IsZero() || len(missed) != 0 {
+			t.Errorf("expected the walk to stop after %d start times, got %v, %d missed, %v", maxMissedStartTimes, scheduled, len(missed), tooMany)
+		}
+	})
+}
+
+func TestMostRecentScheduleTime_TimeZone(t *testing.T) {
+	daily, err := cron.ParseStandard("0 2 * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	last := time.Date(2025, 6, 14, 2, 0, 0, 0, berlin)
+	scheduled, _, _ := mostRecentScheduleTime(daily, last, time.Date(2025, 6, 15, 0, 30, 0, 0, time.UTC).In(berlin))
+	// 02:00 in Berlin is 00:00 UTC during summer time
+	if want := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC); !scheduled.Equal(want) {
+		t.Errorf("expected %v, got %v", want, scheduled)
+	}
+}
+
+func TestRecordMissedSchedule(t *testing.T) {
+	schedule := &talosv1alpha1.TalosEtcdBackupSchedule{}
+	start := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxMissedSchedules+3; i++ {
+		recordMissedSchedule(schedule, start.Add(time.Duration(i)*time.Hour), missedScheduleReasonConcurrencyForbidden)
+	}
+	if len(schedule.Status.MissedSchedules) != maxMissedSchedules {
+		t.Fatalf("expected %d missed schedules, got %d", maxMissedSchedules, len(schedule.Status.MissedSchedules))
+	}
+	if oldest := schedule.Status.MissedSchedules[0].ScheduledTime.Time; !oldest.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("expected oldest entries to be dropped, oldest is %v", oldest)
+	}
+}
+
+func TestUpdateScheduleBackupStatus(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2025, 6, 15, 10, 5, 0, 0, time.UTC))
+	older := metav1.NewTime(time.Date(2025, 6, 15, 9, 5, 0, 0, time.UTC))
+	deleting := metav1.Now()
+	backups := []talosv1alpha1.TalosEtcdBackup{
+		newRetentionTestBackup("ready", completed.Time, talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("ready-older", older.Time, talosv1alpha1.ConditionReady),
+		newRetentionTestBackup("failed", completed.Time, talosv1alpha1.ConditionFailed),
+		newRetentionTestBackup("running", completed.Time, ""),
+		newRetentionTestBackup("deleting", completed.Time, ""),
+	}
+	backups[0].Status.CompletionTime = &completed
+	backups[1].Status.CompletionTime = &older
+	backups[4].DeletionTimestamp = &deleting
+
+	schedule := &talosv1alpha1.TalosEtcdBackupSchedule{
+		Status: talosv1alpha1.TalosEtcdBackupScheduleStatus{ActiveBackups: []string{"stale"}},
+	}
+	active := updateScheduleBackupStatus(schedule, backups)
+	if len(active) != 1 || active[0].Name != "running" {
+		t.Errorf("expected only the running backup to be active, got %v", schedule.Status.ActiveBackups)
+	}
+	if len(schedule.Status.ActiveBackups) != 1 || schedule.Status.ActiveBackups[0] != "running" {
+		t.Errorf("unexpected active backups in status %v", schedule.Status.ActiveBackups)
+	}
+	if schedule.Status.LastSuccessfulBackupTime == nil || !schedule.Status.LastSuccessfulBackupTime.Equal(&completed) {
+		t.Errorf("expected last successful backup time %v, got %v", completed, schedule.Status.LastSuccessfulBackupTime)
+	}
+}
+
+func TestScheduleLocation(t *testing.T) {
+	schedule := &talosv1alpha1.TalosEtcdBackupSchedule{}
+	if loc, err := scheduleLocation(schedule); err != nil || loc != time.Local {
+		t.Errorf("expected the local time zone by default, got %v, %v", loc, err)
+	}
+	schedule.Spec.TimeZone = ptr.To("America/New_York")
+	if loc, err := scheduleLocation(schedule); err != nil || loc.String() != "America/New_York" {
+		t.Errorf("expected America/New_York, got %v, %v", loc, err)
+	}
+	schedule.Spec.TimeZone = ptr.To("Mars/Olympus_Mons")
+	if _, err := scheduleLocation(schedule); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}
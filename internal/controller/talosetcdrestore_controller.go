@@ -0,0 +1,517 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alperencelik/talos-operator/pkg/storage"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/yaml"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// errBackupNotReady is returned when the referenced TalosEtcdBackup has not completed yet
+var errBackupNotReady = errors.New("referenced TalosEtcdBackup is not ready")
+
+// errRecoveryFailed is returned when Talos failed to recover etcd from the snapshot, etcd may be recovered partially
+var errRecoveryFailed = errors.New("failed to recover etcd")
+
+// errRestoreRejected is returned for restores that cannot succeed as they are specified, e.g. a missing snapshot
+var errRestoreRejected = errors.New("restore rejected")
+
+// checkEtcdWaitingForBootstrap reports whether etcd on the node waits for the bootstrap and returns the node. It
+// is a variable so tests can replace the calls to the Talos API.
+var checkEtcdWaitingForBootstrap = (*TalosEtcdRestoreReconciler).etcdWaitingForBootstrap
+
+// TalosEtcdRestoreReconciler reconciles a TalosEtcdRestore object
+type TalosEtcdRestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosetcdrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosetcdrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosetcdrestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosetcdbackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+
+// Reconcile restores the etcd data of a TalosControlPlane from a snapshot. Once etcd on the first control
+// plane node is found waiting for the bootstrap, the paired state secret is restored so the control plane
+// uses the PKI the snapshot was taken with, then etcd is bootstrapped from the snapshot on that node. A
+// restore runs only once. A restore that is rejected, or fails during the etcd recovery, is not retried
+// since etcd may be recovered partially, it is retried by recreating it. Other failures are retried.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *TalosEtcdRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var ter talosv1alpha1.TalosEtcdRestore
+	if err := r.Get(ctx, req.NamespacedName, &ter); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !ter.DeletionTimestamp.IsZero() {
+		// Nothing to clean up, the restore does not own any external resources
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Reconciling TalosEtcdRestore", "TalosEtcdRestore", req.NamespacedName)
+
+	// Check if the restore is already completed
+	if meta.IsStatusConditionTrue(ter.Status.Conditions, talosv1alpha1.ConditionReady) {
+		logger.Info("Restore is already completed. Skipping reconciliation.")
+		return ctrl.Result{}, nil
+	}
+	if meta.IsStatusConditionTrue(ter.Status.Conditions, talosv1alpha1.ConditionFailed) {
+		logger.Info("Restore failed. Skipping reconciliation, recreate the TalosEtcdRestore to retry.")
+		return ctrl.Result{}, nil
+	}
+
+	// Set progressing condition
+	meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionProgressing,
+		Status:  metav1.ConditionTrue,
+		Reason:  "RestoreInProgress",
+		Message: "Etcd restore is in progress",
+	})
+	if err := r.Status().Update(ctx, &ter); err != nil {
+		logger.Error(err, "Failed to update status to progressing")
+	}
+
+	// Perform the restore
+	if err := r.performRestore(ctx, &ter); err != nil {
+		if errors.Is(err, errBackupNotReady) {
+			logger.Info("Waiting for the referenced TalosEtcdBackup to become ready", "backup", ter.Spec.BackupRef.Name)
+			meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+				Type:    talosv1alpha1.ConditionProgressing,
+				Status:  metav1.ConditionTrue,
+				Reason:  "WaitingForBackup",
+				Message: fmt.Sprintf("Waiting for TalosEtcdBackup %s to become ready", ter.Spec.BackupRef.Name),
+			})
+			if statusErr := r.Status().Update(ctx, &ter); statusErr != nil {
+				logger.Error(statusErr, "Failed to update status while waiting for backup")
+			}
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if !errors.Is(err, errRestoreRejected) && !errors.Is(err, errRecoveryFailed) {
+			// etcd is either untouched or recovered and the steps after the recovery are idempotent, retry
+			logger.Error(err, "Failed to perform restore, retrying")
+			return ctrl.Result{}, err
+		}
+		logger.Error(err, "Failed to perform restore")
+		r.Recorder.Eventf(&ter, nil, corev1.EventTypeWarning, "RestoreFailed", "RestoreFailed", "Failed to restore etcd: %v", err)
+
+		// The failure is terminal, a rejected restore fails again and a retried recovery would recover etcd
+		// again on a partially recovered cluster
+		meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionFailed,
+			Status:  metav1.ConditionTrue,
+			Reason:  "RestoreFailed",
+			Message: fmt.Sprintf("Failed to restore etcd: %v", err),
+		})
+		meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionProgressing,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RestoreFailed",
+			Message: "Etcd restore failed, recreate the TalosEtcdRestore to retry",
+		})
+		if statusErr := r.Status().Update(ctx, &ter); statusErr != nil {
+			logger.Error(statusErr, "Failed to update status after restore failure")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Set ready condition and clear the progressing and failed ones
+	meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "RestoreSucceeded",
+		Message: "Etcd restore completed successfully",
+	})
+	meta.SetStatusCondition(&ter.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  "RestoreSucceeded",
+		Message: "Etcd restore completed successfully",
+	})
+	meta.RemoveStatusCondition(&ter.Status.Conditions, talosv1alpha1.ConditionFailed)
+	if err := r.Status().Update(ctx, &ter); err != nil {
+		logger.Error(err, "Failed to update status to ready")
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(&ter, nil, corev1.EventTypeNormal, "RestoreSucceeded", "RestoreSucceeded", "Restored etcd from snapshot %s on node %s", ter.Status.SnapshotKey, ter.Status.Node)
+
+	logger.Info("Successfully completed etcd restore", "TalosEtcdRestore", req.NamespacedName)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TalosEtcdRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&talosv1alpha1.TalosEtcdRestore{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("talosetcdrestore").
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		Complete(r)
+}
+
+// performRestore restores the state secret and recovers etcd by streaming the snapshot from the storage backend to Talos.
+// Once the recovery is started only the steps after it are repeated, they are idempotent.
+func (r *TalosEtcdRestoreReconciler) performRestore(ctx context.Context, ter *talosv1alpha1.TalosEtcdRestore) error {
+	switch {
+	case ter.Status.RecoveryStartTime == nil:
+		if err := r.recoverEtcd(ctx, ter); err != nil {
+			return err
+		}
+	case ter.Status.RecoveryTime == nil:
+		// The recovery was started but its result was not recorded
+		if err := r.confirmRecovery(ctx, ter); err != nil {
+			return err
+		}
+	}
+
+	// The cluster is bootstrapped from the snapshot, make sure the control plane does not bootstrap it again
+	var tcp talosv1alpha1.TalosControlPlane
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: ter.Namespace,
+		Name:      ter.Spec.TalosControlPlaneRef.Name,
+	}, &tcp); err != nil {
+		return fmt.Errorf("failed to get TalosControlPlane: %w", err)
+	}
+	if tcp.Status.State != talosv1alpha1.StateBootstrapped && tcp.Status.State != talosv1alpha1.StateReady {
+		orig := tcp.DeepCopy()
+		tcp.Status.State = talosv1alpha1.StateBootstrapped
+		if err := r.Status().Patch(ctx, &tcp, client.MergeFrom(orig)); err != nil {
+			return fmt.Errorf("failed to update TalosControlPlane %s status to Bootstrapped: %w", tcp.Name, err)
+		}
+	}
+	return nil
+}
+
+// recoverEtcd checks that etcd waits for the bootstrap, restores the state secret, streams the snapshot from
+// the storage backend to the node and records the start and the end of the recovery
+func (r *TalosEtcdRestoreReconciler) recoverEtcd(ctx context.Context, ter *talosv1alpha1.TalosEtcdRestore) error {
+	logger := logf.FromContext(ctx)
+
+	// Resolve where the snapshot and its state are stored
+	source, err := r.resolveSource(ctx, ter)
+	if err != nil {
+		return err
+	}
+	snapshotKey, stateKey := source.snapshotKey, source.stateKey
+	ter.Status.SnapshotKey = snapshotKey
+	ter.Status.StateKey = stateKey
+	if err := r.Status().Update(ctx, ter); err != nil {
+		return fmt.Errorf("failed to update status with restore keys: %w", err)
+	}
+
+	// Create the storage backend client, credentials are resolved from secrets
+	backend, err := newStorageBackend(ctx, r.Client, ter.Namespace, source.backupStorage)
+	if err != nil {
+		return fmt.Errorf("failed to create backup storage client: %w", err)
+	}
+	defer backend.Close() //nolint:errcheck
+	encryptor, err := newBackupEncryptor(ctx, r.Client, ter.Namespace, source.encryption)
+	if err != nil {
+		return fmt.Errorf("failed to create backup encryptor: %w", err)
+	}
+
+	// Get the TalosControlPlane
+	var tcp talosv1alpha1.TalosControlPlane
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: ter.Namespace,
+		Name:      ter.Spec.TalosControlPlaneRef.Name,
+	}, &tcp); err != nil {
+		return fmt.Errorf("failed to get TalosControlPlane: %w", err)
+	}
+
+	// A control plane without a state runs no cluster, the state is restored first to reach its nodes
+	stateRestored := false
+	if tcp.Status.BundleConfig == "" && stateKey != "" {
+		if err := r.restoreStateSecret(ctx, &tcp, backend, encryptor, stateKey); err != nil {
+			return fmt.Errorf("failed to restore state secret: %w", err)
+		}
+		stateRestored = true
+	}
+
+	// Talos recovers etcd only while it waits for the bootstrap, refuse to touch a running cluster and its
+	// state. etcd has to be recovered on a single node, use the first control plane machine like the bootstrap
+	// does.
+	waiting, node, err := checkEtcdWaitingForBootstrap(r, ctx, &tcp, "")
+	if err != nil {
+		return err
+	}
+	if !waiting {
+		return fmt.Errorf("%w: etcd on node %s is not waiting for the bootstrap, reset the control plane nodes before restoring",
+			errRestoreRejected, node)
+	}
+	ter.Status.Node = node
+	if err := r.Status().Update(ctx, ter); err != nil {
+		return fmt.Errorf("failed to update status with node: %w", err)
+	}
+
+	// Stream the snapshot directly from the storage backend to the node
+	logger.Info("Downloading etcd snapshot from backup storage", "key", snapshotKey)
+	snapshotReader, err := backend.Download(ctx, snapshotKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("%w: etcd snapshot %s does not exist", errRestoreRejected, snapshotKey)
+		}
+		return fmt.Errorf("failed to download etcd snapshot: %w", err)
+	}
+	defer snapshotReader.Close() //nolint:errcheck
+	var recoverReader io.Reader = snapshotReader
+	if encryptor != nil {
+		recoverReader, err = encryptor.DecryptReader(snapshotReader)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt etcd snapshot: %w", err)
+		}
+	}
+
+	// Restore the state secret so the control plane uses the PKI the snapshot was taken with
+	if stateKey != "" && !stateRestored {
+		if err := r.restoreStateSecret(ctx, &tcp, backend, encryptor, stateKey); err != nil {
+			return fmt.Errorf("failed to restore state secret: %w", err)
+		}
+	}
+	talosClient, _, err := r.restoreTalosClient(ctx, &tcp, node)
+	if err != nil {
+		return err
+	}
+	defer talosClient.Close() //nolint:errcheck
+
+	// Record the start before the upload, etcd may be recovered even if the result is never recorded
+	ter.Status.RecoveryStartTime = &metav1.Time{Time: time.Now()}
+	if err := r.Status().Update(ctx, ter); err != nil {
+		ter.Status.RecoveryStartTime = nil
+		return fmt.Errorf("failed to update status with recovery start time: %w", err)
+	}
+	logger.Info("Recovering etcd from snapshot", "node", node)
+	if err := talosClient.RecoverEtcdFromSnapshot(ctx, recoverReader, ter.Spec.SkipHashCheck); err != nil {
+		return fmt.Errorf("%w on node %s: %w", errRecoveryFailed, node, err)
+	}
+	ter.Status.RecoveryTime = &metav1.Time{Time: time.Now()}
+	if err := r.Status().Update(ctx, ter); err != nil {
+		return fmt.Errorf("failed to update status with recovery time: %w", err)
+	}
+	return nil
+}
+
+// confirmRecovery records the recovery time of a recovery whose result was not recorded. etcd is recovered if it
+// no longer waits for the bootstrap, otherwise the recovery failed.
+func (r *TalosEtcdRestoreReconciler) confirmRecovery(ctx context.Context, ter *talosv1alpha1.TalosEtcdRestore) error {
+	var tcp talosv1alpha1.TalosControlPlane
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: ter.Namespace,
+		Name:      ter.Spec.TalosControlPlaneRef.Name,
+	}, &tcp); err != nil {
+		return fmt.Errorf("failed to get TalosControlPlane: %w", err)
+	}
+	waiting, node, err := checkEtcdWaitingForBootstrap(r, ctx, &tcp, ter.Status.Node)
+	if err != nil {
+		return err
+	}
+	if waiting {
+		return fmt.Errorf("%w on node %s: etcd is still waiting for the bootstrap", errRecoveryFailed, node)
+	}
+	ter.Status.RecoveryTime = &metav1.Time{Time: time.Now()}
+	if err := r.Status().Update(ctx, ter); err != nil {
+		return fmt.Errorf("failed to update status with recovery time: %w", err)
+	}
+	return nil
+}
+
+// etcdWaitingForBootstrap reports whether etcd on the node waits for the bootstrap and returns the node. If node
+// is empty the first control plane machine is checked.
+func (r *TalosEtcdRestoreReconciler) etcdWaitingForBootstrap(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, node string) (bool, string, error) {
+	talosClient, node, err := r.restoreTalosClient(ctx, tcp, node)
+	if err != nil {
+		return false, node, err
+	}
+	defer talosClient.Close() //nolint:errcheck
+	waiting, err := talosClient.EtcdWaitingForBootstrap(ctx)
+	if err != nil {
+		return false, node, fmt.Errorf("failed to get etcd state on node %s: %w", node, err)
+	}
+	return waiting, node, nil
+}
+
+// restoreTalosClient returns a Talos client for the node etcd is recovered on. If node is empty the first control
+// plane machine is used like the bootstrap does.
+func (r *TalosEtcdRestoreReconciler) restoreTalosClient(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, node string) (*talos.TalosClient, string, error) {
+	// Build the Talos client config from the (restored) control plane status
+	if tcp.Status.BundleConfig == "" {
+		return nil, "", fmt.Errorf("TalosControlPlane %s bundleConfig is empty", tcp.Name)
+	}
+	bc, err := talos.ParseBundleConfig(tcp.Status.BundleConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse bundle config: %w", err)
+	}
+	sb, err := getSecretBundle(ctx, r.Client, tcp)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get secret bundle: %w", err)
+	}
+	sb.Clock = talos.NewClock()
+	bc.SecretsBundle = sb
+	if node == "" {
+		node = tcp.Name
+		if tcp.Spec.Mode == TalosModeMetal {
+			node = bootstrapEndpoint(ctx, r.Client, tcp)
+			if node == "" {
+				return nil, "", fmt.Errorf("TalosControlPlane %s has no machines to restore etcd on", tcp.Name)
+			}
+		} else if bc.ClientEndpoint != nil && len(*bc.ClientEndpoint) > 0 {
+			node = (*bc.ClientEndpoint)[0]
+		}
+	}
+	if tcp.Spec.Mode == TalosModeMetal || (bc.ClientEndpoint != nil && len(*bc.ClientEndpoint) > 0) {
+		bc.ClientEndpoint = &[]string{node}
+	}
+
+	talosClient, err := talos.NewClient(ctx, bc, false)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create talos client: %w", err)
+	}
+	return talosClient, node, nil
+}
+
+// restoreSource describes where the snapshot and its state are stored and how they are encrypted
+type restoreSource struct {
+	backupStorage *talosv1alpha1.BackupStorage
+	encryption    *talosv1alpha1.BackupEncryption
+	snapshotKey   string
+	stateKey      string
+}
+
+// resolveSource returns the backup storage, encryption and the snapshot and state keys to restore from
+func (r *TalosEtcdRestoreReconciler) resolveSource(ctx context.Context, ter *talosv1alpha1.TalosEtcdRestore) (*restoreSource, error) {
+	if ter.Spec.Source != nil {
+		stateKey := ter.Spec.Source.StateKey
+		if stateKey == "" {
+			stateKey = storage.StateKeyForBackupKey(ter.Spec.Source.Key)
+		}
+		return &restoreSource{
+			backupStorage: &ter.Spec.Source.BackupStorage,
+			encryption:    ter.Spec.Source.Encryption,
+			snapshotKey:   ter.Spec.Source.Key,
+			stateKey:      stateKey,
+		}, nil
+	}
+	if ter.Spec.BackupRef == nil {
+		return nil, fmt.Errorf("%w: either backupRef or source must be specified", errRestoreRejected)
+	}
+	var teb talosv1alpha1.TalosEtcdBackup
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: ter.Namespace,
+		Name:      ter.Spec.BackupRef.Name,
+	}, &teb); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: TalosEtcdBackup %s does not exist", errRestoreRejected, ter.Spec.BackupRef.Name)
+		}
+		return nil, fmt.Errorf("failed to get TalosEtcdBackup %s: %w", ter.Spec.BackupRef.Name, err)
+	}
+	if !meta.IsStatusConditionTrue(teb.Status.Conditions, talosv1alpha1.ConditionReady) || teb.Status.Filename == "" {
+		return nil, errBackupNotReady
+	}
+	if teb.Status.Encrypted && teb.Spec.Encryption == nil {
+		return nil, fmt.Errorf("%w: TalosEtcdBackup %s is encrypted but has no encryption configured", errRestoreRejected, teb.Name)
+	}
+	return &restoreSource{
+		backupStorage: &teb.Spec.BackupStorage,
+		encryption:    teb.Spec.Encryption,
+		snapshotKey:   teb.Status.Filename,
+		stateKey:      teb.Status.StateFilename,
+	}, nil
+}
+
+// restoreStateSecret downloads the state secret uploaded next to the snapshot, writes it back as the
+// {tcp.Name}-state Secret and restores the TalosControlPlane status from it.
+func (r *TalosEtcdRestoreReconciler) restoreStateSecret(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane, backend storage.Backend, encryptor storage.Encryptor, stateKey string) error {
+	logger := logf.FromContext(ctx)
+	logger.Info("Restoring state secret from backup storage", "key", stateKey)
+	reader, err := backend.Download(ctx, stateKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logger.Info("State secret not found in backup storage, keeping the current state", "key", stateKey)
+			return nil
+		}
+		return err
+	}
+	defer reader.Close() //nolint:errcheck
+	var stateReader io.Reader = reader
+	if encryptor != nil {
+		stateReader, err = encryptor.DecryptReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt state secret: %w", err)
+		}
+	}
+	yamlBytes, err := io.ReadAll(stateReader)
+	if err != nil {
+		return fmt.Errorf("failed to read state secret: %w", err)
+	}
+	restored := &corev1.Secret{}
+	if err := yaml.Unmarshal(yamlBytes, restored); err != nil {
+		return fmt.Errorf("failed to unmarshal state secret: %w", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-state", tcp.Name),
+			Namespace: tcp.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[talosv1alpha1.StateSecretLabelKey] = talosv1alpha1.StateSecretLabelValue
+		secret.Data = restored.Data
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create or update state secret %s: %w", secret.Name, err)
+	}
+
+	orig := tcp.DeepCopy()
+	if err := applyStateSecretData(ctx, r.Client, r.Scheme, tcp, restored.Data); err != nil {
+		return fmt.Errorf("failed to restore secrets of TalosControlPlane %s: %w", tcp.Name, err)
+	}
+	if err := r.Status().Patch(ctx, tcp, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to restore TalosControlPlane %s status from state secret: %w", tcp.Name, err)
+	}
+	return nil
+}
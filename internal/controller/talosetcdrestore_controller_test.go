@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var _ = Describe("TalosEtcdRestore Controller", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var (
+		talosEtcdRestore     *talosv1alpha1.TalosEtcdRestore
+		talosEtcdRestoreName string
+		controlPlaneName     string
+		namespace            string
+		ctx                  context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = DefaultNamespace
+		talosEtcdRestoreName = "test-restore-" + RandStringRunes(5)
+		controlPlaneName = "test-cp-restore-" + RandStringRunes(5)
+
+		// Create dummy ControlPlane
+		cp := &talosv1alpha1.TalosControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      controlPlaneName,
+				Namespace: namespace,
+			},
+			Spec: talosv1alpha1.TalosControlPlaneSpec{
+				Replicas:       1,
+				Version:        testTalosVersion,
+				KubeVersion:    testKubeVersion,
+				Mode:           testModeCloud,
+				DeletionPolicy: testDeletionPolicyReset,
+			},
+		}
+		Expect(k8sClient.Create(ctx, cp)).To(Succeed())
+
+		talosEtcdRestore = &talosv1alpha1.TalosEtcdRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      talosEtcdRestoreName,
+				Namespace: namespace,
+			},
+			Spec: talosv1alpha1.TalosEtcdRestoreSpec{
+				TalosControlPlaneRef: &corev1.LocalObjectReference{
+					Name: controlPlaneName,
+				},
+			},
+		}
+	})
+
+	Context("When reconciling a TalosEtcdRestore", func() {
+		It("Should reject a spec with both backupRef and source", func() {
+			talosEtcdRestore.Spec.BackupRef = &corev1.LocalObjectReference{Name: "test-backup"}
+			talosEtcdRestore.Spec.Source = &talosv1alpha1.EtcdRestoreSource{
+				Key: "talos-operator-etcd-backups/test/etcd-snapshot-2025-01-01T00-00-00Z.db",
+				BackupStorage: talosv1alpha1.BackupStorage{
+					S3: &talosv1alpha1.S3Storage{
+						Bucket: "test-bucket",
+						Region: "us-east-1",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, talosEtcdRestore)).ToNot(Succeed())
+		})
+
+		It("Should set the Failed condition when the referenced backup does not exist", func() {
+			talosEtcdRestore.Spec.BackupRef = &corev1.LocalObjectReference{Name: "missing-backup-" + RandStringRunes(5)}
+			By("Creating the TalosEtcdRestore")
+			Expect(k8sClient.Create(ctx, talosEtcdRestore)).To(Succeed())
+
+			By("Checking for the Failed condition")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: talosEtcdRestoreName, Namespace: namespace}, talosEtcdRestore)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(talosEtcdRestore.Status.Conditions, talosv1alpha1.ConditionFailed)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(talosEtcdRestore.Status.Conditions, talosv1alpha1.ConditionReady)).To(BeFalse())
+				// The failed restore is not retried
+				g.Expect(meta.IsStatusConditionFalse(talosEtcdRestore.Status.Conditions, talosv1alpha1.ConditionProgressing)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should wait for the referenced backup to become ready", func() {
+			backupName := "test-backup-" + RandStringRunes(5)
+			backup := &talosv1alpha1.TalosEtcdBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      backupName,
+					Namespace: namespace,
+				},
+				Spec: talosv1alpha1.TalosEtcdBackupSpec{
+					TalosControlPlaneRef: &corev1.LocalObjectReference{
+						Name: controlPlaneName,
+					},
+					BackupStorage: talosv1alpha1.BackupStorage{
+						S3: &talosv1alpha1.S3Storage{
+							Bucket: "test-bucket",
+							Region: "us-east-1",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+
+			talosEtcdRestore.Spec.BackupRef = &corev1.LocalObjectReference{Name: backupName}
+			By("Creating the TalosEtcdRestore")
+			Expect(k8sClient.Create(ctx, talosEtcdRestore)).To(Succeed())
+
+			By("Checking the restore waits for the backup")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: talosEtcdRestoreName, Namespace: namespace}, talosEtcdRestore)).To(Succeed())
+				cond := meta.FindStatusCondition(talosEtcdRestore.Status.Conditions, talosv1alpha1.ConditionProgressing)
+				g.Expect(cond).ToNot(BeNil())
+				g.Expect(cond.Reason).To(Equal("WaitingForBackup"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/storage"
+)
+
+func TestReconcileRestore_FailureIsTerminal(t *testing.T) {
+	ctx := context.Background()
+	ter := &talosv1alpha1.TalosEtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-restore", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosEtcdRestoreSpec{
+			TalosControlPlaneRef: &corev1.LocalObjectReference{Name: "test-cp"},
+			BackupRef:            &corev1.LocalObjectReference{Name: "missing-backup"},
+		},
+	}
+	c := newTestClient(t, ter)
+	recorder := events.NewFakeRecorder(10)
+	r := &TalosEtcdRestoreReconciler{Client: c, Scheme: c.Scheme(), Recorder: recorder}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ter)}
+
+	// The failure is recorded instead of being returned, so the restore is not retried with backoff
+	for range 2 {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("expected the failure to be recorded on the status, got %v", err)
+		}
+	}
+	updated := &talosv1alpha1.TalosEtcdRestore{}
+	if err := c.Get(ctx, req.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get TalosEtcdRestore: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionFailed) ||
+		!meta.IsStatusConditionFalse(updated.Status.Conditions, talosv1alpha1.ConditionProgressing) {
+		t.Errorf("expected a failed restore, got %v", updated.Status.Conditions)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected the restore to run once, got %d events", len(recorder.Events))
+	}
+}
+
+func TestReconcileRestore_RetriesStepsAfterRecovery(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+	ter := &talosv1alpha1.TalosEtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-restore", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosEtcdRestoreSpec{
+			TalosControlPlaneRef: &corev1.LocalObjectReference{Name: "test-cp"},
+			BackupRef:            &corev1.LocalObjectReference{Name: "test-backup"},
+		},
+		Status: talosv1alpha1.TalosEtcdRestoreStatus{RecoveryStartTime: &now, RecoveryTime: &now},
+	}
+	c := newTestClient(t, ter)
+	r := &TalosEtcdRestoreReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ter)}
+
+	// etcd is recovered, a missing TalosControlPlane is retried instead of failing the restore
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("expected the error after the recovery to be returned")
+	}
+	updated := &talosv1alpha1.TalosEtcdRestore{}
+	if err := c.Get(ctx, req.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get TalosEtcdRestore: %v", err)
+	}
+	if meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionFailed) {
+		t.Fatalf("expected the restore not to fail after the recovery, got %v", updated.Status.Conditions)
+	}
+
+	tcp := &talosv1alpha1.TalosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-cp", Namespace: DefaultNamespace}}
+	if err := c.Create(ctx, tcp); err != nil {
+		t.Fatalf("failed to create TalosControlPlane: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("expected the restore to complete, got %v", err)
+	}
+	if err := c.Get(ctx, req.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get TalosEtcdRestore: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionReady) {
+		t.Errorf("expected a ready restore, got %v", updated.Status.Conditions)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tcp), tcp); err != nil {
+		t.Fatalf("failed to get TalosControlPlane: %v", err)
+	}
+	if tcp.Status.State != talosv1alpha1.StateBootstrapped {
+		t.Errorf("expected the TalosControlPlane to be Bootstrapped, got %q", tcp.Status.State)
+	}
+}
+
+func TestReconcileRestore_ChecksEtcdBeforeRestoringState(t *testing.T) {
+	ctx := context.Background()
+	const snapshotKey = "test-cp/etcd-snapshot.db"
+	dir := t.TempDir()
+	backend, err := storage.NewFilesystemClient(&storage.FilesystemConfig{Path: dir})
+	if err != nil {
+		t.Fatalf("failed to create filesystem backend: %v", err)
+	}
+	backup, err := yaml.Marshal(&corev1.Secret{Data: map[string][]byte{"bundle": []byte("backup")}})
+	if err != nil {
+		t.Fatalf("failed to marshal state secret: %v", err)
+	}
+	for key, data := range map[string][]byte{snapshotKey: []byte("snapshot"), storage.StateKeyForBackupKey(snapshotKey): backup} {
+		if err := backend.Upload(ctx, key, bytes.NewReader(data)); err != nil {
+			t.Fatalf("failed to upload %s: %v", key, err)
+		}
+	}
+
+	ter := &talosv1alpha1.TalosEtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-restore", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosEtcdRestoreSpec{
+			TalosControlPlaneRef: &corev1.LocalObjectReference{Name: "test-cp"},
+			Source: &talosv1alpha1.EtcdRestoreSource{
+				Key:           snapshotKey,
+				BackupStorage: talosv1alpha1.BackupStorage{Filesystem: &talosv1alpha1.FilesystemStorage{Path: dir}},
+			},
+		},
+	}
+	tcp := &talosv1alpha1.TalosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cp", Namespace: DefaultNamespace},
+		Status:     talosv1alpha1.TalosControlPlaneStatus{BundleConfig: "live", State: talosv1alpha1.StateReady},
+	}
+	state := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cp-state", Namespace: DefaultNamespace},
+		Data:       map[string][]byte{"bundle": []byte("live")},
+	}
+	c := newTestClient(t, ter, tcp, state)
+	r := &TalosEtcdRestoreReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ter)}
+
+	var waitingErr error
+	orig := checkEtcdWaitingForBootstrap
+	checkEtcdWaitingForBootstrap = func(*TalosEtcdRestoreReconciler, context.Context, *talosv1alpha1.TalosControlPlane, string) (bool, string, error) {
+		return false, "10.0.0.1", waitingErr
+	}
+	t.Cleanup(func() { checkEtcdWaitingForBootstrap = orig })
+
+	// The Talos API is not reachable, nothing touched etcd yet so the restore is retried
+	waitingErr = errors.New("connection refused")
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("expected the transient failure to be returned")
+	}
+	updated := &talosv1alpha1.TalosEtcdRestore{}
+	if err := c.Get(ctx, req.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get TalosEtcdRestore: %v", err)
+	}
+	if meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionFailed) {
+		t.Fatalf("expected the restore not to fail on a transient error, got %v", updated.Status.Conditions)
+	}
+
+	// etcd of the running cluster is not waiting for the bootstrap, the restore is rejected
+	waitingErr = nil
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("expected the rejected restore to be recorded on the status, got %v", err)
+	}
+	if err := c.Get(ctx, req.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get TalosEtcdRestore: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, talosv1alpha1.ConditionFailed) || updated.Status.RecoveryStartTime != nil {
+		t.Errorf("expected a failed restore that never started the recovery, got %+v", updated.Status)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(state), state); err != nil {
+		t.Fatalf("failed to get state secret: %v", err)
+	}
+	if string(state.Data["bundle"]) != "live" {
+		t.Errorf("expected the state secret of the running cluster to be kept, got %q", state.Data["bundle"])
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tcp), tcp); err != nil {
+		t.Fatalf("failed to get TalosControlPlane: %v", err)
+	}
+	if tcp.Status.BundleConfig != "live" || tcp.Status.State != talosv1alpha1.StateReady {
+		t.Errorf("expected the TalosControlPlane status to be kept, got %+v", tcp.Status)
+	}
+}
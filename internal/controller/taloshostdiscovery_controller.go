@@ -0,0 +1,281 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// defaultDiscoveryInterval is how often the networks of a discovery are scanned without an interval
+const defaultDiscoveryInterval = 10 * time.Minute
+
+// TalosHostDiscoveryReconciler reconciles a TalosHostDiscovery object
+type TalosHostDiscoveryReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+	// BootServer is the PXE boot service of the operator whose leases are probed, nil when the PXE
+	// boot stack is not enabled
+	BootServer *pxe.Server
+
+	// scanOffsets holds where the running scan of a discovery continues, scans start over after a restart
+	scanOffsets sync.Map
+}
+
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshostdiscoveries,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshostdiscoveries/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshostdiscoveries/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshosts,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachines,verbs=get;list;watch
+
+// Reconcile scans the networks and DHCP leases of the discovery for machines in maintenance mode and
+// adds the machines that are neither in the inventory nor used by a TalosMachine as unclaimed
+// TalosHosts. Every reconcile probes a batch of the addresses, so a scan of large networks is spread
+// over several reconciles. The scan is repeated every interval.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *TalosHostDiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var thd talosv1alpha1.TalosHostDiscovery
+	if err := r.Get(ctx, req.NamespacedName, &thd); err != nil {
+		r.scanOffsets.Delete(req.NamespacedName)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !thd.DeletionTimestamp.IsZero() {
+		r.scanOffsets.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Reconciling TalosHostDiscovery", "TalosHostDiscovery", req.NamespacedName)
+	orig := thd.DeepCopy()
+	interval := defaultDiscoveryInterval
+	if thd.Spec.Interval != nil && thd.Spec.Interval.Duration > 0 {
+		interval = thd.Spec.Interval.Duration
+	}
+
+	condition := metav1.Condition{
+		Type:   talosv1alpha1.ConditionReady,
+		Status: metav1.ConditionTrue,
+		Reason: "ScanSucceeded",
+	}
+	start, _ := r.scanOffsets.Load(req.NamespacedName)
+	offset, _ := start.(int)
+	next, err := r.scan(ctx, &thd, &condition, offset)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	meta.SetStatusCondition(&thd.Status.Conditions, condition)
+	if next > 0 {
+		// Continue the scan with the next batch
+		r.scanOffsets.Store(req.NamespacedName, next)
+		return ctrl.Result{RequeueAfter: time.Second}, r.updateStatus(ctx, orig, &thd)
+	}
+	r.scanOffsets.Delete(req.NamespacedName)
+	thd.Status.LastScanTime = &metav1.Time{Time: time.Now()}
+	return ctrl.Result{RequeueAfter: interval}, r.updateStatus(ctx, orig, &thd)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TalosHostDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&talosv1alpha1.TalosHostDiscovery{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("taloshostdiscovery").
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		Complete(r)
+}
+
+// scan probes the batch of the addresses of the discovery that starts at offset and creates the
+// TalosHosts of the machines it found. It returns the offset of the next batch, or 0 once all addresses
+// were probed. Problems with the spec are reported on the condition.
+func (r *TalosHostDiscoveryReconciler) scan(ctx context.Context, thd *talosv1alpha1.TalosHostDiscovery, condition *metav1.Condition, offset int) (int, error) {
+	// MAC addresses of the leased addresses, machines in maintenance mode report them too
+	leasedMACs := make(map[string]string)
+	var addresses []string
+	for _, cidr := range thd.Spec.CIDRs {
+		cidrAddrs, err := cidrAddresses(cidr)
+		if err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "InvalidCIDR"
+			condition.Message = err.Error()
+			return 0, nil
+		}
+		addresses = append(addresses, cidrAddrs...)
+	}
+	if thd.Spec.DHCPLeases {
+		if r.BootServer == nil {
+			r.Recorder.Eventf(thd, nil, corev1.EventTypeWarning, "LeasesUnavailable", "LeasesUnavailable",
+				"dhcpLeases is set but the PXE boot stack of the operator is not enabled")
+		} else {
+			for _, lease := range r.BootServer.Leases() {
+				leasedMACs[lease.Address.String()] = lease.MACAddress
+				addresses = append(addresses, lease.Address.String())
+			}
+		}
+	}
+	addresses = dedupAddresses(addresses)
+
+	// Machines that are in the inventory or used by a TalosMachine are known already
+	hosts := &talosv1alpha1.TalosHostList{}
+	if err := r.List(ctx, hosts); err != nil {
+		return 0, fmt.Errorf("failed to list TalosHosts: %w", err)
+	}
+	machines := &talosv1alpha1.TalosMachineList{}
+	if err := r.List(ctx, machines); err != nil {
+		return 0, fmt.Errorf("failed to list TalosMachines: %w", err)
+	}
+	knownAddresses := make(map[string]bool)
+	knownMACs := make(map[string]*talosv1alpha1.TalosHost)
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		knownAddresses[host.Spec.Address] = true
+		if host.Spec.MACAddress != "" {
+			knownMACs[strings.ToLower(host.Spec.MACAddress)] = host
+		}
+	}
+	for _, m := range machines.Items {
+		knownAddresses[m.Spec.Endpoint] = true
+	}
+
+	port := int(thd.Spec.Port)
+	if port == 0 {
+		port = talos.DefaultAPIPort
+	}
+	batch, next := nextScanBatch(addresses, offset)
+	found := probeAddresses(ctx, batch, port)
+	created := 0
+	for _, address := range sortedAddresses(found) {
+		facts := found[address]
+		mac := strings.ToLower(facts.MACAddress)
+		if mac == "" {
+			mac = leasedMACs[address]
+		}
+		if host, ok := knownMACs[mac]; ok && mac != "" {
+			if err := r.updateHostAddress(ctx, thd, host, address); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if knownAddresses[address] {
+			continue
+		}
+		host := newDiscoveredHost(thd, address, mac, facts)
+		if isDryRun(thd) {
+			r.Recorder.Eventf(thd, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun,
+				fmt.Sprintf("Would create TalosHost %s for the machine at %s", host.Name, address))
+			continue
+		}
+		if err := r.Create(ctx, host); err != nil {
+			if kerrors.IsAlreadyExists(err) {
+				// The host was added since the hosts were listed, e.g. by another discovery of the network
+				continue
+			}
+			return 0, fmt.Errorf("failed to create TalosHost %s: %w", host.Name, err)
+		}
+		created++
+		r.Recorder.Eventf(thd, nil, corev1.EventTypeNormal, "HostDiscovered", "HostDiscovered",
+			fmt.Sprintf("Created TalosHost %s for the machine in maintenance mode at %s", host.Name, address))
+	}
+
+	discovered := 0
+	for i := range hosts.Items {
+		if hosts.Items[i].Namespace == thd.Namespace && hosts.Items[i].Labels[talosv1alpha1.TalosHostDiscoveryLabelKey] == thd.Name {
+			discovered++
+		}
+	}
+	thd.Status.ScannedAddresses = int32(len(addresses))
+	thd.Status.DiscoveredHosts = int32(discovered + created)
+	probed := next
+	if next == 0 {
+		probed = len(addresses)
+	}
+	condition.Message = fmt.Sprintf("Probed %d of %d addresses, %d machines of the last batch are in maintenance mode",
+		probed, len(addresses), len(found))
+	return next, nil
+}
+
+// updateHostAddress updates the address of a host the discovery created when the machine got a new
+// address, e.g. a new DHCP lease. Hosts that are claimed, or were created by hand, are left alone.
+func (r *TalosHostDiscoveryReconciler) updateHostAddress(ctx context.Context, thd *talosv1alpha1.TalosHostDiscovery, host *talosv1alpha1.TalosHost, address string) error {
+	if host.Spec.Address == address || host.Spec.ClaimedBy != nil || host.Namespace != thd.Namespace ||
+		host.Labels[talosv1alpha1.TalosHostDiscoveryLabelKey] != thd.Name || isDryRun(thd) {
+		return nil
+	}
+	patch := client.MergeFrom(host.DeepCopy())
+	host.Spec.Address = address
+	if err := r.Patch(ctx, host, patch); err != nil {
+		return fmt.Errorf("failed to update the address of TalosHost %s: %w", host.Name, err)
+	}
+	return nil
+}
+
+// updateStatus updates the status of the discovery if it changed
+func (r *TalosHostDiscoveryReconciler) updateStatus(ctx context.Context, orig, thd *talosv1alpha1.TalosHostDiscovery) error {
+	if equality.Semantic.DeepEqual(orig.Status, thd.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, thd); err != nil {
+		return fmt.Errorf("failed to update TalosHostDiscovery %s status: %w", thd.Name, err)
+	}
+	return nil
+}
+
+// dedupAddresses returns the addresses without duplicates, in their order
+func dedupAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	var unique []string
+	for _, address := range addresses {
+		if !seen[address] {
+			seen[address] = true
+			unique = append(unique, address)
+		}
+	}
+	return unique
+}
+
+// sortedAddresses returns the addresses of the probed machines in order
+func sortedAddresses(found map[string]*talos.HostFacts) []string {
+	addresses := make([]string, 0, len(found))
+	for address := range found {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
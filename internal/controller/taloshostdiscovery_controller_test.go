@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var _ = Describe("TalosHostDiscovery Controller", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var (
+		discovery     *talosv1alpha1.TalosHostDiscovery
+		discoveryName string
+		namespace     string
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = DefaultNamespace
+		discoveryName = "test-thd-" + RandStringRunes(5)
+
+		discovery = &talosv1alpha1.TalosHostDiscovery{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      discoveryName,
+				Namespace: namespace,
+			},
+			Spec: talosv1alpha1.TalosHostDiscoverySpec{
+				// TEST-NET-1 is never routed, so no machine answers
+				CIDRs: []string{"192.0.2.0/30"},
+			},
+		}
+	})
+
+	Context("When reconciling a TalosHostDiscovery", func() {
+		It("Should record the scan in the status", func() {
+			By("Creating the TalosHostDiscovery")
+			Expect(k8sClient.Create(ctx, discovery)).To(Succeed())
+
+			By("Checking for the Ready condition")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: discoveryName, Namespace: namespace}, discovery)).To(Succeed())
+				cond := meta.FindStatusCondition(discovery.Status.Conditions, talosv1alpha1.ConditionReady)
+				g.Expect(cond).ToNot(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				g.Expect(discovery.Status.ScannedAddresses).To(Equal(int32(2)))
+				g.Expect(discovery.Status.DiscoveredHosts).To(BeZero())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should reject a discovery without CIDRs and DHCP leases", func() {
+			discovery.Spec.CIDRs = nil
+			Expect(k8sClient.Create(ctx, discovery)).ToNot(Succeed())
+		})
+
+		It("Should reject a network above /20", func() {
+			discovery.Spec.CIDRs = []string{"10.0.0.0/16"}
+			Expect(k8sClient.Create(ctx, discovery)).ToNot(Succeed())
+		})
+	})
+})
@@ -24,10 +24,15 @@ import (
 	"time"
 
 	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/internal/metrics"
+	"github.com/alperencelik/talos-operator/pkg/pxe"
 	"github.com/alperencelik/talos-operator/pkg/talos"
 	"github.com/alperencelik/talos-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
@@ -49,6 +54,8 @@ type TalosMachineReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
+	// BootServer is the PXE boot service of the operator, nil when the PXE boot stack is not enabled
+	BootServer *pxe.Server
 }
 
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachines,verbs=get;list;watch;create;update;patch;delete
@@ -83,6 +90,11 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	} else {
 		// The object is being deleted, so we handle the finalizer logic
 		if controllerutil.ContainsFinalizer(&talosMachine, talosv1alpha1.TalosMachineFinalizer) {
+			// Keep the machine as it is while it is protected from deletion
+			blocked, err := holdProtectedDeletion(ctx, r.Client, r.Recorder, &talosMachine, &talosMachine.Status.Conditions)
+			if err != nil || blocked {
+				return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, err
+			}
 			// Run delete operations
 			res, err := r.handleDelete(ctx, &talosMachine)
 			if err != nil {
@@ -90,6 +102,10 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				r.Recorder.Eventf(&talosMachine, nil, corev1.EventTypeWarning, "DeleteFailed", "DeleteFailed", "Failed to handle delete for TalosMachine")
 				return res, err
 			}
+			if res != (ctrl.Result{}) {
+				// Deletion is waiting for something, e.g. the Node to be drained
+				return res, nil
+			}
 			// Remove the finalizer
 			controllerutil.RemoveFinalizer(&talosMachine, talosv1alpha1.TalosMachineFinalizer)
 			if err := r.Update(ctx, &talosMachine); err != nil {
@@ -101,6 +117,22 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// Stop the reconciliation if the finalizer is not present
 		return ctrl.Result{}, client.IgnoreNotFound(nil)
 	}
+	// Move the applied config out of the status of objects created by older versions. In DryRun mode
+	// the Secret would not be persisted, so the migration waits for a normal pass.
+	if !r.isDryRun(&talosMachine) {
+		migrated, err := migrateMachineStatusSecrets(ctx, r.Client, r.Scheme, &talosMachine)
+		if err != nil {
+			logger.Error(err, "Failed to migrate status secrets for TalosMachine", "name", talosMachine.Name)
+			return ctrl.Result{}, err
+		}
+		if migrated {
+			if err := r.Status().Update(ctx, &talosMachine); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update TalosMachine %s status after migrating secrets: %w", talosMachine.Name, err)
+			}
+			r.Recorder.Eventf(&talosMachine, nil, corev1.EventTypeNormal, "StatusSecretsMigrated", "StatusSecretsMigrated", "Moved applied config from status into a Secret")
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
 	// Get the reconcile mode from the annotation
 	reconcileMode := r.getReconciliationMode(ctx, &talosMachine)
 	switch reconcileMode {
@@ -120,6 +152,11 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// Do nothing, proceed with reconciliation
 	}
 
+	// Remediate the machine if a TalosMachineHealthCheck found it unhealthy
+	if strategy, ok := remediationRequested(&talosMachine); ok {
+		return r.remediate(ctx, &talosMachine, strategy)
+	}
+
 	// If state is lost (e.g. CR was re-applied), probe the node to see if it's already
 	// provisioned.
 	if talosMachine.Status.State == "" {
@@ -142,7 +179,21 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	// Power the machine on, or power cycle it when it hangs while booting
+	if talosMachine.Spec.BMC != nil {
+		if err := r.reconcilePower(ctx, &talosMachine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if talosMachine.Spec.PxeClientSpec != nil {
+		// The machine boots the Talos version it has from the network
+		if r.BootServer != nil && !r.isDryRun(&talosMachine) {
+			// Invalid PXE configurations are reported on their TalosCluster
+			if _, err := updateBootServer(ctx, r.Client, r.BootServer); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update PXE boot service for TalosMachine %s: %w", talosMachine.Name, err)
+			}
+		}
 		// If the state is empty update it to Booting
 		if talosMachine.Status.State == "" {
 			// Update .status.state to Booting
@@ -167,6 +218,17 @@ func (r *TalosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	// A failed upgrade is not retried until the version of the machine is changed
+	if talosMachine.Status.FailedVersion != "" {
+		if upgradeFailed(&talosMachine) {
+			logger.Info("Talos upgrade of the machine failed, waiting for its version to be changed", "name", talosMachine.Name, "version", talosMachine.Status.FailedVersion)
+			return ctrl.Result{}, nil
+		}
+		if err := r.clearFailedUpgrade(ctx, &talosMachine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Check whether we should wait for machine to be ready
 	if talosMachine.Status.State == talosv1alpha1.StateInstalling || talosMachine.Status.State == talosv1alpha1.StateUpgrading {
 		// If the machine is in the installing state, we should wait for it to be ready
@@ -262,10 +324,14 @@ func (r *TalosMachineReconciler) handleControlPlaneMachine(ctx context.Context,
 			return ctrl.Result{}, fmt.Errorf("failed to append additionalConfig for TalosMachine %s: %w", tm.Name, err)
 		}
 	}
-	// Check if the current config is the same as the one in status
-	if tm.Status.Config == string(*cpConfig) && tm.Status.ObservedVersion == tm.Spec.Version {
+	// Check if the current config is the same as the applied one
+	appliedConfig, err := r.appliedConfig(ctx, tm)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if appliedConfig == string(*cpConfig) && tm.Status.ObservedVersion == tm.Spec.Version {
 		// Return since the machine is in desired state
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.clearPlannedConfig(ctx, tm)
 	}
 	// Ensure the client targets this specific machine, not the cluster name
 	bc.ClientEndpoint = &[]string{tm.Spec.Endpoint}
@@ -330,10 +396,14 @@ func (r *TalosMachineReconciler) handleWorkerMachine(ctx context.Context, tm *ta
 		}
 	}
 
-	// Check if the current config is the same as the one in status
-	if tm.Status.Config == string(*workerConfig) && tm.Status.ObservedVersion == tm.Spec.Version {
+	// Check if the current config is the same as the applied one
+	appliedConfig, err := r.appliedConfig(ctx, tm)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if appliedConfig == string(*workerConfig) && tm.Status.ObservedVersion == tm.Spec.Version {
 		// Return since the machine is in desired state
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.clearPlannedConfig(ctx, tm)
 	}
 	err = r.UpgradeOrApplyConfig(ctx, tm, bc, workerConfig)
 	if err != nil {
@@ -438,9 +508,9 @@ func (r *TalosMachineReconciler) GetBundleConfig(ctx context.Context, tm *talosv
 		logger.Error(err, "Failed to parse Talos bundle config", "name", tcp.Name)
 		return nil, fmt.Errorf("failed to parse Talos bundle config for Control Plane %s: %w", tcp.Name, err)
 	}
-	secretBundle, err := utils.SecretBundleDecoder(tcp.Status.SecretBundle)
+	secretBundle, err := getSecretBundle(ctx, r.Client, tcp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode secret bundle for Control Plane %s: %w", tcp.Name, err)
+		return nil, fmt.Errorf("failed to get secret bundle for Control Plane %s: %w", tcp.Name, err)
 	}
 	secretBundle.Clock = talos.NewClock()
 	bc.SecretsBundle = secretBundle
@@ -454,7 +524,11 @@ func (r *TalosMachineReconciler) GetBundleConfig(ctx context.Context, tm *talosv
 		}, tw); err != nil {
 			return nil, r.handleResourceNotFound(ctx, err)
 		}
-		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &tw.Spec.MetalSpec.Machines)
+		machines, err := metalMachines(ctx, r.Client, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec)
+		if err != nil {
+			return nil, err
+		}
+		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &machines)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get machine IP addresses for TalosControlPlane %s: %w", tcp.Name, err)
 		}
@@ -478,28 +552,66 @@ func (r *TalosMachineReconciler) handleDelete(ctx context.Context, tm *talosv1al
 	if tm.Status.State == talosv1alpha1.StateOrphaned {
 		return ctrl.Result{}, nil
 	}
-	// Only reset if requested by deletion policy:
-	if tm.Spec.DeletionPolicy == DeletionPolicyReset {
-		if r.isDryRun(tm) {
-			log.FromContext(ctx).Info("DryRun: would reset TalosMachine", "name", tm.Name)
-			r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, "Would reset machine")
-			return ctrl.Result{}, nil
-		}
-		// Run talosctl reset command to reset the machine
-		config, err := r.GetBundleConfig(ctx, tm)
+	// Control plane machines removed from a running control plane leave etcd
+	leaveEtcd, err := r.leavesEtcd(ctx, tm)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	reset := tm.Spec.DeletionPolicy == DeletionPolicyReset
+	if !reset && !leaveEtcd {
+		return ctrl.Result{}, nil
+	}
+	if r.isDryRun(tm) {
+		log.FromContext(ctx).Info("DryRun: would remove TalosMachine", "name", tm.Name, "reset", reset, "leaveEtcd", leaveEtcd)
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, "Would leave etcd and reset machine")
+		return ctrl.Result{}, nil
+	}
+	config, err := r.GetBundleConfig(ctx, tm)
+	if err != nil {
+		return ctrl.Result{Requeue: true}, fmt.Errorf("failed to get BundleConfig for TalosMachine %s: %w", tm.Name, err)
+	}
+	if config == nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	// Make the client for the machine
+	machineConfig := *config
+	machineConfig.ClientEndpoint = &[]string{tm.Spec.Endpoint}
+	tc, err := talos.NewClient(ctx, &machineConfig, false)
+	if err != nil {
+		return ctrl.Result{Requeue: true}, fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
+	}
+	defer tc.Close() //nolint:errcheck
+	// A dead machine that is replaced is neither drained nor reset, only its etcd member is removed
+	reachable := !leaveEtcd || machineReachable(ctx, tc)
+	if reset && reachable {
+		// Move the workloads off the Node before the machine is wiped
+		drained, err := r.drainNode(ctx, tm)
 		if err != nil {
-			return ctrl.Result{Requeue: true}, fmt.Errorf("failed to get BundleConfig for TalosMachine %s: %w", tm.Name, err)
+			return ctrl.Result{}, fmt.Errorf("failed to drain Node of TalosMachine %s: %w", tm.Name, err)
 		}
-		// Make the client for the machine
-		config.ClientEndpoint = &[]string{tm.Spec.Endpoint}
-		tc, err := talos.NewClient(ctx, config, false)
-		if err != nil {
-			return ctrl.Result{Requeue: true}, fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
+		if !drained {
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+	if leaveEtcd {
+		if err := r.removeEtcdMember(ctx, tm, config, tc, reachable); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to remove etcd member of TalosMachine %s: %w", tm.Name, err)
 		}
-		defer tc.Close() //nolint:errcheck
-		if err := tc.Reset(ctx, false, true); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to reset TalosMachine %s: %w", tm.Name, err)
+	}
+	if !reachable {
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "MachineUnreachable", "MachineUnreachable", "Machine is not reachable, skipping the reset")
+		if reset {
+			r.powerOffReleased(ctx, tm)
 		}
+		r.deleteNode(ctx, tm)
+		return ctrl.Result{}, nil
+	}
+	if reset {
+		if err := r.resetMachine(ctx, tm, tc, leaveEtcd); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The machine left the cluster for good, so remove its Node as well
+		r.deleteNode(ctx, tm)
 	}
 	return ctrl.Result{}, nil
 }
@@ -517,11 +629,10 @@ func (r *TalosMachineReconciler) metalConfigPatches(ctx context.Context, tm *tal
 	}
 	defer talosclient.Close() //nolint:errcheck
 	// Disk Patches
-	diskNamePtr, err := talosclient.GetInstallDisk(ctx, tm)
+	diskName, err := r.installDisk(ctx, tm, talosclient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get install disk for TalosMachine %s: %w", tm.Name, err)
 	}
-	diskName := utils.PtrToString(diskNamePtr)
 	diskPatch := fmt.Sprintf(talos.InstallDisk, diskName)
 
 	// Wipe Disk Patch
@@ -603,6 +714,65 @@ func (r *TalosMachineReconciler) metalConfigPatches(ctx context.Context, tm *tal
 	return &patches, nil
 }
 
+// installDisk returns the disk to install Talos on: the installDisk of the machine spec, the disk that
+// matches the installDiskSelector or the first writable disk. The hardware facts of the machine are
+// recorded in its status once, and read again on every reconcile while a selector is set so the
+// selector resolves against the current disks.
+func (r *TalosMachineReconciler) installDisk(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient) (string, error) {
+	logger := log.FromContext(ctx)
+	var selector *talosv1alpha1.InstallDiskSelector
+	if tm.Spec.MachineSpec != nil {
+		selector = tm.Spec.MachineSpec.InstallDiskSelector
+	}
+	hardware := tm.Status.Hardware
+	if hardware == nil || selector != nil {
+		facts, err := readMachineHardware(ctx, tc)
+		switch {
+		case err != nil && selector != nil:
+			return "", fmt.Errorf("failed to read hardware of TalosMachine %s: %w", tm.Name, err)
+		case err != nil:
+			// The hardware facts are informational without a selector
+			logger.Info("Failed to read hardware of TalosMachine", "name", tm.Name, "error", err.Error())
+		default:
+			hardware = hardwareFromFacts(facts)
+		}
+	}
+
+	var disk string
+	switch {
+	case tm.Spec.MachineSpec != nil && tm.Spec.MachineSpec.InstallDisk != nil:
+		disk = *tm.Spec.MachineSpec.InstallDisk
+	case selector != nil:
+		selected, err := selectInstallDisk(hardware, selector, findDisk(tm.Status.Hardware, tm.Status.InstallDisk))
+		if err != nil {
+			r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "InstallDiskNotFound", "InstallDiskNotFound", err.Error())
+			return "", err
+		}
+		disk = selected
+	default:
+		diskNamePtr, err := tc.GetInstallDisk(ctx, tm)
+		if err != nil {
+			return "", err
+		}
+		disk = utils.PtrToString(diskNamePtr)
+	}
+	return disk, r.updateHardware(ctx, tm, hardware, disk)
+}
+
+// updateHardware records the hardware facts and the install disk of the machine in its status
+func (r *TalosMachineReconciler) updateHardware(ctx context.Context, tm *talosv1alpha1.TalosMachine, hardware *talosv1alpha1.Hardware, disk string) error {
+	if r.isDryRun(tm) || (equality.Semantic.DeepEqual(tm.Status.Hardware, hardware) && tm.Status.InstallDisk == disk) {
+		return nil
+	}
+	orig := tm.DeepCopy()
+	tm.Status.Hardware = hardware
+	tm.Status.InstallDisk = disk
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to update hardware of TalosMachine %s: %w", tm.Name, err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TalosMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -615,7 +785,10 @@ func (r *TalosMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				if _, ok := e.ObjectNew.(*corev1.ConfigMap); ok {
 					return true
 				}
-				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+				// Remediations are requested and deletions are unblocked through annotations
+				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+					e.ObjectOld.GetAnnotations()[talosv1alpha1.RemediationAnnotation] != e.ObjectNew.GetAnnotations()[talosv1alpha1.RemediationAnnotation] ||
+					deletionProtectionChanged(e.ObjectOld, e.ObjectNew)
 			},
 		}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
@@ -708,6 +881,16 @@ func (r *TalosMachineReconciler) CheckMachineReady(ctx context.Context, tm *talo
 		return ctrl.Result{}, fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
 	}
 	defer tc.Close() //nolint:errcheck
+	// An upgraded machine has to come back on the new version within the upgrade timeout
+	if tm.Status.State == talosv1alpha1.StateUpgrading {
+		upgraded, err := r.verifyUpgrade(ctx, tm, tc)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !upgraded {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
 	// Check if the machine is ready
 	svcState, err := tc.GetServiceStatus(ctx, talos.KUBELET_SERVICE_NAME)
 	if err != nil {
@@ -721,11 +904,45 @@ func (r *TalosMachineReconciler) CheckMachineReady(ctx context.Context, tm *talo
 		logger.Info("Kubelet service is not running, requeuing reconciliation", "name", tm.Name, "state", svcState)
 		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
 	}
+	// An upgraded machine has to pass its health checks before the rollout moves on. Installing
+	// machines are not checked since etcd only starts once the cluster is bootstrapped.
+	if tm.Status.State == talosv1alpha1.StateUpgrading {
+		healthErr := talos.RunHealthChecks(ctx, r.machineHealthChecks(tm, tc)...)
+		if err := r.setHealthyCondition(ctx, tm, healthErr); err != nil {
+			return ctrl.Result{}, err
+		}
+		if healthErr != nil && upgradeTimedOut(tm, time.Now()) {
+			if err := r.failUpgrade(ctx, tm, tc, tm.Spec.Version, "UpgradeUnhealthy",
+				fmt.Sprintf("Machine did not become healthy within %s after upgrading to %s: %v", upgradeTimeout(tm), tm.Spec.Version, healthErr)); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if healthErr != nil {
+			logger.Info("Machine is not healthy yet, requeuing reconciliation", "name", tm.Name, "reason", healthErr.Error())
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
+	// Make the Node drained for an upgrade schedulable again
+	uncordoned, err := r.uncordonNode(ctx, tm)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to uncordon Node of TalosMachine %s: %w", tm.Name, err)
+	}
+	if !uncordoned {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
 	// If the machine is ready, update the state to Available
 	if tm.Status.State != talosv1alpha1.StateAvailable {
+		upgradeStartTime := tm.Status.UpgradeStartTime
+		if !r.isDryRun(tm) {
+			tm.Status.UpgradeStartTime = nil
+		}
 		if err := r.updateState(ctx, tm, talosv1alpha1.StateAvailable); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to update TalosMachine %s status to Available: %w", tm.Name, err)
 		}
+		if upgradeStartTime != nil && !r.isDryRun(tm) {
+			metrics.ObserveUpgrade(metrics.UpgradeTypeTalos, time.Since(upgradeStartTime.Time))
+		}
 	}
 	return ctrl.Result{}, nil
 }
@@ -741,7 +958,11 @@ func (r *TalosMachineReconciler) UpgradeOrApplyConfig(ctx context.Context, tm *t
 		return fmt.Errorf("failed to create Talos client for TalosMachine %s: %w", tm.Name, err)
 	}
 	defer tc.Close() //nolint:errcheck
-	configDrift := tm.Status.Config != string(*config)
+	appliedConfig, err := r.appliedConfig(ctx, tm)
+	if err != nil {
+		return err
+	}
+	configDrift := appliedConfig != string(*config)
 	applyConfigurationFunc := func() error {
 		diff, err := tc.ApplyConfig(ctx, *config, dryRun)
 		if err != nil {
@@ -754,8 +975,11 @@ func (r *TalosMachineReconciler) UpgradeOrApplyConfig(ctx context.Context, tm *t
 		}
 		// Prepare a merge patch to update only our status fields
 		orig := tm.DeepCopy()
-		tm.Status.Config = string(*config)
+		if _, err := storeMachineConfig(ctx, r.Client, r.Scheme, tm, machineConfigSecretName(tm.Name), &tm.Status.ConfigSecretRef, &tm.Status.Config, *config); err != nil {
+			return fmt.Errorf("failed to store applied config for TalosMachine %s: %w", tm.Name, err)
+		}
 		tm.Status.ObservedVersion = tm.Spec.Version
+		tm.Status.PlannedConfig = nil
 		if tm.Status.State != talosv1alpha1.StateInstalling {
 			tm.Status.State = talosv1alpha1.StateInstalling
 		}
@@ -783,6 +1007,11 @@ func (r *TalosMachineReconciler) UpgradeOrApplyConfig(ctx context.Context, tm *t
 	// If the version is the same, we can apply the config
 	if actualVersion == tm.Spec.Version {
 		if configDrift {
+			// Config changes of clusters under change control wait for the TalosUpgradePlan to be approved
+			approved, err := r.configChangeApproved(ctx, tm, tc, appliedConfig, *config)
+			if err != nil || !approved {
+				return err
+			}
 			// Apply the config
 			return applyConfigurationFunc()
 		}
@@ -807,14 +1036,29 @@ func (r *TalosMachineReconciler) UpgradeOrApplyConfig(ctx context.Context, tm *t
 			r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, fmt.Sprintf("Would upgrade Talos version from %s to %s using image %s", actualVersion, tm.Spec.Version, image))
 			return nil
 		}
+		// Move the workloads off the Node before the machine reboots into the new version
+		drained, err := r.drainNode(ctx, tm)
+		if err != nil {
+			return fmt.Errorf("failed to drain Node of TalosMachine %s: %w", tm.Name, err)
+		}
+		if !drained {
+			return nil
+		}
 		// Add an event
 		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "Upgrading", "Upgrading", fmt.Sprintf("Upgrading Talos version to %s using image %s", tm.Spec.Version, image))
 		if err := tc.UpgradeTalosVersion(ctx, actualVersion, image); err != nil {
+			metrics.RecordUpgradeFailure(metrics.UpgradeTypeTalos, tm.Namespace, tm.Name)
 			return fmt.Errorf("failed to upgrade Talos version for TalosMachine %s: %w", tm.Name, err)
 		}
 		// Update it to Upgrading state
 		orig := tm.DeepCopy()
+		tm.Status.PreviousVersion = actualVersion
 		tm.Status.ObservedVersion = tm.Spec.Version
+		now := metav1.Now()
+		tm.Status.UpgradeStartTime = &now
+		// The machine is health checked again once it is back
+		meta.RemoveStatusCondition(&tm.Status.Conditions, talosv1alpha1.ConditionHealthy)
+		meta.RemoveStatusCondition(&tm.Status.Conditions, talosv1alpha1.ConditionFailed)
 		if tm.Status.State != talosv1alpha1.StateUpgrading {
 			tm.Status.State = talosv1alpha1.StateUpgrading
 		}
@@ -825,6 +1069,15 @@ func (r *TalosMachineReconciler) UpgradeOrApplyConfig(ctx context.Context, tm *t
 	return nil
 }
 
+// appliedConfig returns the config that was last applied to the machine
+func (r *TalosMachineReconciler) appliedConfig(ctx context.Context, tm *talosv1alpha1.TalosMachine) (string, error) {
+	config, err := machineConfigData(ctx, r.Client, tm.Namespace, tm.Status.ConfigSecretRef, tm.Status.Config)
+	if err != nil {
+		return "", fmt.Errorf("failed to read applied config of TalosMachine %s: %w", tm.Name, err)
+	}
+	return config, nil
+}
+
 // isDryRun returns true if the TalosMachine is annotated with the DryRun reconciliation mode.
 func (r *TalosMachineReconciler) isDryRun(tm *talosv1alpha1.TalosMachine) bool {
 	return isDryRun(tm)
@@ -915,7 +1168,9 @@ func (r *TalosMachineReconciler) ImportExistingMachine(ctx context.Context, tm *
 	}
 	config := utils.StringToBytePtr(strings.TrimSpace(*data))
 	// Update the status fields with the imported config
-	tm.Status.Config = string(*config)
+	if _, err := storeMachineConfig(ctx, r.Client, r.Scheme, tm, machineConfigSecretName(tm.Name), &tm.Status.ConfigSecretRef, &tm.Status.Config, *config); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to store imported config for TalosMachine %s: %w", tm.Name, err)
+	}
 	tm.Status.ObservedVersion = tm.Spec.Version
 	tm.Status.Imported = ptr.To(true)
 	tm.Status.State = talosv1alpha1.StateAvailable
@@ -137,6 +137,7 @@ var _ = Describe("TalosMachine Controller", func() {
 				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: talosMachineName, Namespace: namespace}, fetched)).To(Succeed())
 				g.Expect(fetched.Status.State).To(BeEmpty())
 				g.Expect(fetched.Status.Config).To(BeEmpty())
+				g.Expect(fetched.Status.ConfigSecretRef).To(BeNil())
 			}, time.Second*2, interval).Should(Succeed())
 		})
 	})
@@ -0,0 +1,225 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// healthCheckInterval is how often the machines of a health check are probed
+const healthCheckInterval = 30 * time.Second
+
+// TalosMachineHealthCheckReconciler reconciles a TalosMachineHealthCheck object
+type TalosMachineHealthCheckReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachinehealthchecks,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachinehealthchecks/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachinehealthchecks/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachines,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosworkers,verbs=get;list;watch
+
+// Reconcile probes the machines selected by the health check and requests the remediation of those
+// that are unhealthy. The remediation itself is run by the TalosMachine controller, which picks it up
+// from the talos.alperen.cloud/remediation annotation. Nothing is remediated while more machines than
+// maxUnhealthy are unhealthy.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *TalosMachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var hc talosv1alpha1.TalosMachineHealthCheck
+	if err := r.Get(ctx, req.NamespacedName, &hc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !hc.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Reconciling TalosMachineHealthCheck", "TalosMachineHealthCheck", req.NamespacedName)
+	orig := hc.DeepCopy()
+
+	machines, err := r.selectMachines(ctx, &hc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	previous := make(map[string]talosv1alpha1.MachineHealthStatus, len(hc.Status.Machines))
+	for _, status := range hc.Status.Machines {
+		previous[status.Name] = status
+	}
+	now := time.Now()
+	var statuses []talosv1alpha1.MachineHealthStatus
+	var unhealthy []*talosv1alpha1.TalosMachine
+	healthy := 0
+	for i := range machines {
+		m := &machines[i]
+		status, ok := previous[m.Name]
+		if !ok {
+			status = talosv1alpha1.MachineHealthStatus{Name: m.Name, Healthy: true}
+		}
+		// Machines that are installed, upgraded or deleted are not probed and keep their health, so a
+		// machine that is reinstalled by a Reset stays unhealthy until it is available again
+		if m.DeletionTimestamp.IsZero() && m.Status.State == talosv1alpha1.StateAvailable {
+			failing, err := probeMachineHealth(ctx, r.Client, m, hc.Spec.UnhealthyConditions)
+			if err != nil {
+				// The machine keeps its health, the other machines are still checked
+				logger.Error(err, "Failed to check the health of TalosMachine", "name", m.Name)
+				status.ProbeError = err.Error()
+			} else {
+				status.ProbeError = ""
+				if updateMachineHealth(&status, hc.Spec.UnhealthyConditions, failing, now) {
+					unhealthy = append(unhealthy, m)
+				}
+			}
+		}
+		if status.Healthy {
+			healthy++
+		}
+		statuses = append(statuses, status)
+	}
+	hc.Status.Machines = statuses
+	hc.Status.ExpectedMachines = int32(len(machines))
+	hc.Status.CurrentHealthy = int32(healthy)
+
+	maxUnhealthy := resolveMaxUnhealthy(&hc, len(machines))
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionRemediationAllowed,
+		Status:  metav1.ConditionTrue,
+		Reason:  "WithinMaxUnhealthy",
+		Message: fmt.Sprintf("%d of %d machines are unhealthy, at most %d are remediated", len(machines)-healthy, len(machines), maxUnhealthy),
+	}
+	if len(machines)-healthy > maxUnhealthy {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "TooManyUnhealthy"
+		if meta.IsStatusConditionTrue(hc.Status.Conditions, talosv1alpha1.ConditionRemediationAllowed) {
+			r.Recorder.Eventf(&hc, nil, corev1.EventTypeWarning, "RemediationBlocked", "RemediationBlocked",
+				fmt.Sprintf("%d of %d machines are unhealthy, which is more than maxUnhealthy %d, remediation is stopped", len(machines)-healthy, len(machines), maxUnhealthy))
+		}
+		unhealthy = nil
+	}
+	meta.SetStatusCondition(&hc.Status.Conditions, condition)
+
+	for _, m := range unhealthy {
+		if err := r.remediate(ctx, &hc, m, now); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: healthCheckInterval}, r.updateStatus(ctx, orig, &hc)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TalosMachineHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&talosv1alpha1.TalosMachineHealthCheck{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("talosmachinehealthcheck").
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		Complete(r)
+}
+
+// selectMachines returns the TalosMachines selected by the label selector and owner of the health check
+func (r *TalosMachineHealthCheckReconciler) selectMachines(ctx context.Context, hc *talosv1alpha1.TalosMachineHealthCheck) ([]talosv1alpha1.TalosMachine, error) {
+	opts := []client.ListOption{client.InNamespace(hc.Namespace)}
+	if hc.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(hc.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of TalosMachineHealthCheck %s: %w", hc.Name, err)
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	if ref := hc.Spec.OwnerRef; ref != nil {
+		switch ref.Kind {
+		case talosv1alpha1.GroupKindControlPlane:
+			opts = append(opts, client.MatchingFields{IndexControlPlaneRefName: ref.Name})
+		case talosv1alpha1.GroupKindWorker:
+			opts = append(opts, client.MatchingFields{IndexWorkerRefName: ref.Name})
+		}
+	}
+	machines := &talosv1alpha1.TalosMachineList{}
+	if err := r.List(ctx, machines, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list TalosMachines: %w", err)
+	}
+	sort.Slice(machines.Items, func(i, j int) bool { return machines.Items[i].Name < machines.Items[j].Name })
+	return machines.Items, nil
+}
+
+// remediate requests the remediation of the machine through the remediation annotation. Machines
+// whose remediation is still pending, or that were marked for replacement, are left alone.
+func (r *TalosMachineHealthCheckReconciler) remediate(ctx context.Context, hc *talosv1alpha1.TalosMachineHealthCheck, tm *talosv1alpha1.TalosMachine, now time.Time) error {
+	if _, ok := tm.Annotations[talosv1alpha1.RemediationAnnotation]; ok {
+		return nil
+	}
+	strategy := hc.Spec.Remediation.Strategy
+	if strategy == "" {
+		strategy = talosv1alpha1.RebootRemediation
+	}
+	if isDryRun(hc) {
+		logf.FromContext(ctx).Info("DryRun: would remediate TalosMachine", "name", tm.Name, "strategy", strategy)
+		r.Recorder.Eventf(hc, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun,
+			fmt.Sprintf("Would remediate TalosMachine %s with %s", tm.Name, strategy))
+		return nil
+	}
+	patch := client.MergeFrom(tm.DeepCopy())
+	if tm.Annotations == nil {
+		tm.Annotations = make(map[string]string)
+	}
+	tm.Annotations[talosv1alpha1.RemediationAnnotation] = string(strategy)
+	if err := r.Patch(ctx, tm, patch); err != nil {
+		return fmt.Errorf("failed to request remediation of TalosMachine %s: %w", tm.Name, err)
+	}
+	for i := range hc.Status.Machines {
+		if status := &hc.Status.Machines[i]; status.Name == tm.Name {
+			status.LastRemediationTime = &metav1.Time{Time: now}
+			status.Remediations++
+		}
+	}
+	r.Recorder.Eventf(hc, nil, corev1.EventTypeWarning, "Remediating", "Remediating",
+		fmt.Sprintf("TalosMachine %s is unhealthy, remediating it with %s", tm.Name, strategy))
+	return nil
+}
+
+// updateStatus updates the status of the health check if it changed
+func (r *TalosMachineHealthCheckReconciler) updateStatus(ctx context.Context, orig, hc *talosv1alpha1.TalosMachineHealthCheck) error {
+	if equality.Semantic.DeepEqual(orig.Status, hc.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, hc); err != nil {
+		return fmt.Errorf("failed to update TalosMachineHealthCheck %s status: %w", hc.Name, err)
+	}
+	return nil
+}
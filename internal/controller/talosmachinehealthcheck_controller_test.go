@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var _ = Describe("TalosMachineHealthCheck Controller", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var (
+		healthCheck     *talosv1alpha1.TalosMachineHealthCheck
+		healthCheckName string
+		namespace       string
+		ctx             context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = DefaultNamespace
+		healthCheckName = "test-hc-" + RandStringRunes(5)
+
+		healthCheck = &talosv1alpha1.TalosMachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      healthCheckName,
+				Namespace: namespace,
+			},
+			Spec: talosv1alpha1.TalosMachineHealthCheckSpec{
+				OwnerRef: &talosv1alpha1.HealthCheckOwnerReference{
+					Kind: talosv1alpha1.GroupKindWorker,
+					Name: "missing-worker-" + RandStringRunes(5),
+				},
+				UnhealthyConditions: []talosv1alpha1.UnhealthyCondition{{
+					Type:    talosv1alpha1.NodeNotReadyCondition,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				}},
+			},
+		}
+	})
+
+	Context("When reconciling a TalosMachineHealthCheck", func() {
+		It("Should allow remediation when no machine is unhealthy", func() {
+			By("Creating the TalosMachineHealthCheck")
+			Expect(k8sClient.Create(ctx, healthCheck)).To(Succeed())
+
+			By("Checking for the RemediationAllowed condition")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: healthCheckName, Namespace: namespace}, healthCheck)).To(Succeed())
+				cond := meta.FindStatusCondition(healthCheck.Status.Conditions, talosv1alpha1.ConditionRemediationAllowed)
+				g.Expect(cond).ToNot(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				g.Expect(healthCheck.Status.ExpectedMachines).To(BeZero())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should reject a health check without selector and ownerRef", func() {
+			healthCheck.Spec.OwnerRef = nil
+			Expect(k8sClient.Create(ctx, healthCheck)).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// upgradePlanRequeueInterval is how often a plan is recomputed, as the machine status changes it
+// is planned from are not watched
+const upgradePlanRequeueInterval = 30 * time.Second
+
+// TalosUpgradePlanReconciler reconciles a TalosUpgradePlan object
+type TalosUpgradePlanReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosupgradeplans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosupgradeplans/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosupgradeplans/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosworkers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosmachines,verbs=get;list;watch
+
+// Reconcile plans the changes the operator is about to execute on the cluster of a control plane:
+// Talos upgrades and config changes of its machines and the Kubernetes upgrade. The changes are
+// held until the revision of the plan is approved. An approved plan is invalidated once the specs
+// it was planned from change or a change shows up that was not part of it.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
+func (r *TalosUpgradePlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var plan talosv1alpha1.TalosUpgradePlan
+	if err := r.Get(ctx, req.NamespacedName, &plan); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !plan.DeletionTimestamp.IsZero() {
+		// Deleting the plan lifts the change control of the cluster
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Reconciling TalosUpgradePlan", "TalosUpgradePlan", req.NamespacedName)
+	orig := plan.DeepCopy()
+
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: plan.Namespace, Name: plan.Spec.TalosControlPlaneRef.Name}, tcp); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get TalosControlPlane %s: %w", plan.Spec.TalosControlPlaneRef.Name, err)
+		}
+		meta.SetStatusCondition(&plan.Status.Conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionApproved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ControlPlaneNotFound",
+			Message: fmt.Sprintf("TalosControlPlane %s not found", plan.Spec.TalosControlPlaneRef.Name),
+		})
+		return ctrl.Result{RequeueAfter: upgradePlanRequeueInterval}, r.updateStatus(ctx, orig, &plan)
+	}
+
+	specHash, actions, err := r.planActions(ctx, tcp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	approved := approvedRevision(&plan) != "" && approvedRevision(&plan) == plan.Status.Revision
+	if approved && specHash == plan.Status.SpecHash && actionsApproved(plan.Status.Actions, actions) {
+		// The approved actions are kept as they are executed
+		plan.Status.Phase = talosv1alpha1.UpgradePlanPhaseApproved
+		if len(actions) == 0 {
+			plan.Status.Phase = talosv1alpha1.UpgradePlanPhaseApplied
+		}
+		meta.SetStatusCondition(&plan.Status.Conditions, metav1.Condition{
+			Type:    talosv1alpha1.ConditionApproved,
+			Status:  metav1.ConditionTrue,
+			Reason:  "RevisionApproved",
+			Message: fmt.Sprintf("Revision %s is approved", plan.Status.Revision),
+		})
+		return ctrl.Result{RequeueAfter: upgradePlanRequeueInterval}, r.updateStatus(ctx, orig, &plan)
+	}
+
+	revision, err := planRevision(specHash, actions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if approved && revision != plan.Status.Revision {
+		logger.Info("Specs or planned actions changed after the plan was approved", "revision", plan.Status.Revision)
+		r.Recorder.Eventf(&plan, nil, corev1.EventTypeWarning, "PlanInvalidated", "PlanInvalidated",
+			fmt.Sprintf("Approval of revision %s is invalidated because the planned changes changed", plan.Status.Revision))
+	}
+	plan.Status.Actions = actions
+	plan.Status.SpecHash = specHash
+	plan.Status.Revision = revision
+	condition := metav1.Condition{
+		Type:    talosv1alpha1.ConditionApproved,
+		Status:  metav1.ConditionFalse,
+		Reason:  "AwaitingApproval",
+		Message: fmt.Sprintf("Approve revision %s to execute the planned actions", revision),
+	}
+	switch {
+	case len(actions) == 0:
+		plan.Status.Phase = talosv1alpha1.UpgradePlanPhaseUpToDate
+		condition.Reason = "NoChanges"
+		condition.Message = "No changes are planned"
+	case approvedRevision(&plan) == revision:
+		// The spec was approved ahead of the status, e.g. when a plan is re-created with a known revision
+		plan.Status.Phase = talosv1alpha1.UpgradePlanPhaseApproved
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RevisionApproved"
+		condition.Message = fmt.Sprintf("Revision %s is approved", revision)
+	default:
+		plan.Status.Phase = talosv1alpha1.UpgradePlanPhaseAwaitingApproval
+		if orig.Status.Revision != revision {
+			r.Recorder.Eventf(&plan, nil, corev1.EventTypeNormal, "AwaitingApproval", "AwaitingApproval",
+				fmt.Sprintf("Revision %s with %d planned actions is awaiting approval", revision, len(actions)))
+		}
+	}
+	meta.SetStatusCondition(&plan.Status.Conditions, condition)
+	return ctrl.Result{RequeueAfter: upgradePlanRequeueInterval}, r.updateStatus(ctx, orig, &plan)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TalosUpgradePlanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&talosv1alpha1.TalosUpgradePlan{}).
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		Named("talosupgradeplan").
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		Complete(r)
+}
+
+// planActions returns the actions the operator is about to execute on the cluster of the control
+// plane together with the hash of the control plane and worker specs they are planned from
+func (r *TalosUpgradePlanReconciler) planActions(ctx context.Context, tcp *talosv1alpha1.TalosControlPlane) (string, []talosv1alpha1.PlannedAction, error) {
+	workerList := &talosv1alpha1.TalosWorkerList{}
+	if err := r.List(ctx, workerList, client.InNamespace(tcp.Namespace)); err != nil {
+		return "", nil, fmt.Errorf("failed to list TalosWorkers: %w", err)
+	}
+	var workers []talosv1alpha1.TalosWorker
+	for _, tw := range workerList.Items {
+		if tw.Spec.ControlPlaneRef.Name == tcp.Name {
+			workers = append(workers, tw)
+		}
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].Name < workers[j].Name })
+
+	specs := []any{tcp.Spec}
+	var actions []talosv1alpha1.PlannedAction
+	if tcp.Spec.Mode == TalosModeMetal {
+		machines := &talosv1alpha1.TalosMachineList{}
+		if err := r.List(ctx, machines, client.InNamespace(tcp.Namespace),
+			client.MatchingFields{IndexControlPlaneRefName: tcp.Name},
+		); err != nil {
+			return "", nil, fmt.Errorf("failed to list TalosMachines: %w", err)
+		}
+		specMachines, err := metalMachines(ctx, r.Client, talosv1alpha1.GroupKindControlPlane, tcp, &tcp.Spec.MetalSpec)
+		if err != nil {
+			return "", nil, err
+		}
+		machineActions, err := plannedMachineActions(ctx, r.Client, tcp.Name, &specMachines, tcp.Spec.RolloutStrategy, machines.Items,
+			func(machine *talosv1alpha1.Machine) string { return machineVersion(tcp, machine) })
+		if err != nil {
+			return "", nil, err
+		}
+		actions = append(actions, machineActions...)
+	}
+	if tcp.Spec.KubeVersion != "" && tcp.Spec.KubeVersion != tcp.Status.ObservedKubeVersion {
+		actions = append(actions, talosv1alpha1.PlannedAction{
+			Type:   talosv1alpha1.PlannedActionKubernetesUpgrade,
+			Target: tcp.Name,
+			From:   tcp.Status.ObservedKubeVersion,
+			To:     tcp.Spec.KubeVersion,
+		})
+	}
+	for i := range workers {
+		tw := &workers[i]
+		specs = append(specs, tw.Spec)
+		if tw.Spec.Mode != TalosModeMetal {
+			continue
+		}
+		machines := &talosv1alpha1.TalosMachineList{}
+		if err := r.List(ctx, machines, client.InNamespace(tw.Namespace),
+			client.MatchingFields{IndexWorkerRefName: tw.Name},
+		); err != nil {
+			return "", nil, fmt.Errorf("failed to list TalosMachines: %w", err)
+		}
+		specMachines, err := metalMachines(ctx, r.Client, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec)
+		if err != nil {
+			return "", nil, err
+		}
+		machineActions, err := plannedMachineActions(ctx, r.Client, tw.Name, &specMachines, tw.Spec.RolloutStrategy, machines.Items,
+			func(machine *talosv1alpha1.Machine) string { return workerMachineVersion(tw, machine) })
+		if err != nil {
+			return "", nil, err
+		}
+		actions = append(actions, machineActions...)
+	}
+
+	data, err := json.Marshal(specs)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal specs: %w", err)
+	}
+	return configHash(data), actions, nil
+}
+
+// updateStatus updates the status of the plan if it changed
+func (r *TalosUpgradePlanReconciler) updateStatus(ctx context.Context, orig, plan *talosv1alpha1.TalosUpgradePlan) error {
+	if equality.Semantic.DeepEqual(orig.Status, plan.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, plan); err != nil {
+		return fmt.Errorf("failed to update TalosUpgradePlan %s status: %w", plan.Name, err)
+	}
+	return nil
+}
+
+// actionsApproved returns true if every pending action was approved. Executed actions are no
+// longer pending, so the pending actions shrink to none while an approved plan is executed.
+func actionsApproved(approved, pending []talosv1alpha1.PlannedAction) bool {
+	for _, action := range pending {
+		if !planContains(approved, action.Type, action.Target, action.To) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+var _ = Describe("TalosUpgradePlan Controller", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	var (
+		talosUpgradePlan     *talosv1alpha1.TalosUpgradePlan
+		talosUpgradePlanName string
+		namespace            string
+		ctx                  context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = DefaultNamespace
+		talosUpgradePlanName = "test-plan-" + RandStringRunes(5)
+
+		talosUpgradePlan = &talosv1alpha1.TalosUpgradePlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      talosUpgradePlanName,
+				Namespace: namespace,
+			},
+			Spec: talosv1alpha1.TalosUpgradePlanSpec{
+				TalosControlPlaneRef: &corev1.LocalObjectReference{
+					Name: "missing-cp-" + RandStringRunes(5),
+				},
+			},
+		}
+	})
+
+	Context("When reconciling a TalosUpgradePlan", func() {
+		It("Should not be approved when the referenced control plane does not exist", func() {
+			By("Creating the TalosUpgradePlan")
+			Expect(k8sClient.Create(ctx, talosUpgradePlan)).To(Succeed())
+
+			By("Checking for the Approved condition")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: talosUpgradePlanName, Namespace: namespace}, talosUpgradePlan)).To(Succeed())
+				cond := meta.FindStatusCondition(talosUpgradePlan.Status.Conditions, talosv1alpha1.ConditionApproved)
+				g.Expect(cond).ToNot(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Reason).To(Equal("ControlPlaneNotFound"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+})
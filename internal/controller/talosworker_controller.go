@@ -20,11 +20,13 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -58,6 +60,7 @@ type TalosWorkerReconciler struct {
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosworkers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosworkers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=talos.alperen.cloud,resources=talosworkers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=talos.alperen.cloud,resources=taloshosts,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
@@ -88,6 +91,11 @@ func (r *TalosWorkerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	} else {
 		// If the TalosWorker is being deleted, we handle the deletion logic
 		if controllerutil.ContainsFinalizer(&tw, talosv1alpha1.TalosWorkerFinalizer) {
+			// Keep the worker and its machines while it is protected from deletion
+			blocked, err := holdProtectedDeletion(ctx, r.Client, r.Recorder, &tw, &tw.Status.Conditions)
+			if err != nil || blocked {
+				return ctrl.Result{RequeueAfter: deletionBlockedRequeue}, err
+			}
 			// Handle the deletion logic here
 			var res ctrl.Result
 			res, finErr = r.handleDeletion(ctx, &tw)
@@ -110,11 +118,27 @@ func (r *TalosWorkerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to get TalosControlPlane ref for TalosWorker %s: %w", tw.Name, err)
 	}
-	if tcp.Status.SecretBundle == "" {
+	if !hasSecretBundle(tcp) {
 		// If the TalosControlPlane does not have a secret bundle, we can requeue the reconciliation
 		r.Recorder.Eventf(&tw, nil, corev1.EventTypeWarning, "SecretBundleNotFound", "SecretBundleNotFound", "TalosControlPlane %s does not have a secret bundle yet", tcp.Name)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil // Requeue to retry
 	}
+	// Move the config out of the status of objects created by older versions. In DryRun mode the
+	// Secret would not be persisted, so the migration waits for a normal pass.
+	if !isDryRun(&tw) {
+		migrated, err := migrateWorkerStatusSecrets(ctx, r.Client, r.Scheme, &tw)
+		if err != nil {
+			logger.Error(err, "failed to migrate status secrets", "name", tw.Name)
+			return ctrl.Result{}, err
+		}
+		if migrated {
+			if err := r.Status().Update(ctx, &tw); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update TalosWorker %s status after migrating secrets: %w", tw.Name, err)
+			}
+			r.Recorder.Eventf(&tw, nil, corev1.EventTypeNormal, "StatusSecretsMigrated", "StatusSecretsMigrated", "Moved config from status into a Secret")
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
 
 	// Get the reconcile mode from the annotation
 	reconcileMode := r.getReconciliationMode(ctx, &tw)
@@ -239,10 +263,6 @@ func (r *TalosWorkerReconciler) reconcileMetalMode(ctx context.Context, tw *talo
 func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *talosv1alpha1.TalosWorker) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	resolvedMachines, err := getMachinesResolved(ctx, r.Client, &tw.Spec.MetalSpec.Machines)
-	if err != nil {
-		return false, fmt.Errorf("failed to get machine IP addresses for TalosWorker %s: %w", tw.Name, err)
-	}
 	// List existing ones
 	existing := &talosv1alpha1.TalosMachineList{}
 	if err := r.List(ctx, existing, client.InNamespace(tw.Namespace),
@@ -250,10 +270,23 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 	); err != nil {
 		return false, fmt.Errorf("failed to list TalosMachines: %w", err)
 	}
+	// Claim the hosts of the inventory that back the machines besides the listed ones
+	hosts, missing, err := claimHosts(ctx, r.Client, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec, existing.Items)
+	if err != nil {
+		return false, err
+	}
+	if err := r.updateClaimedHosts(ctx, tw, hosts, missing); err != nil {
+		return false, err
+	}
+	machines := hostMachines(tw.Spec.MetalSpec.Machines, hosts)
+	resolvedMachines, err := getMachinesResolved(ctx, r.Client, &machines)
+	if err != nil {
+		return false, fmt.Errorf("failed to get machine IP addresses for TalosWorker %s: %w", tw.Name, err)
+	}
 	// Desired state
 	desired := make(map[string]bool)
-	for ip := range resolvedMachines {
-		desired[fmt.Sprintf("%s-%s", tw.Name, ip)] = true
+	for _, resolved := range resolvedMachines {
+		desired[fmt.Sprintf("%s-%s", tw.Name, resolved.IP)] = true
 	}
 	// Index existing machines by name for quick lookup during version decisions.
 	existingByName := make(map[string]*talosv1alpha1.TalosMachine, len(existing.Items))
@@ -265,7 +298,7 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 	for _, m := range existing.Items {
 		if m.Spec.WorkerRef != nil && m.Spec.WorkerRef.Name == tw.Name {
 			if !desired[m.Name] {
-				if err := r.Delete(ctx, &m); err != nil && !kerrors.IsNotFound(err) {
+				if err := deleteChild(ctx, r.Client, &m); err != nil {
 					logger.Error(err, "Failed to delete orphaned TalosMachine", "name", m.Name)
 					return false, fmt.Errorf("failed to delete orphaned TalosMachine %s: %w", m.Name, err)
 				}
@@ -273,12 +306,38 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 		}
 	}
 
-	maxUnavailable := resolveMaxUnavailable(tw.Spec.RolloutStrategy, len(resolvedMachines))
-	inFlight := countInFlightUpgrades(existing.Items, desired)
-	heldUpgrades := false
+	inFlight := countInFlightUpgrades(existing.Items, desired, resolveMinReady(tw.Spec.RolloutStrategy))
+
+	// Pause the rollout while machines fail their health checks for longer than the health timeout
+	paused, err := r.updateRolloutPaused(ctx, tw, existing.Items, desired)
+	if err != nil {
+		return false, err
+	}
+	ro, err := newRollout(tw.Spec.RolloutStrategy, len(resolvedMachines), inFlight, paused)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve rollout strategy of TalosWorker %s: %w", tw.Name, err)
+	}
+	if ro.plans, err = upgradePlansFor(ctx, r.Client, tw.Namespace, tw.Spec.ControlPlaneRef.Name); err != nil {
+		return false, err
+	}
+
+	// Approved upgrades only start inside a maintenance window
+	upgradePending := false
+	for ordinal, resolved := range resolvedMachines {
+		name := fmt.Sprintf("%s-%s", tw.Name, resolved.IP)
+		desiredVersion := workerMachineVersion(tw, &resolved.Machine)
+		if ro.upgradePending(ordinal, &resolved.Machine, existingByName[name], desiredVersion) && ro.approved(name, desiredVersion) {
+			upgradePending = true
+			break
+		}
+	}
+	if ro.blocked, err = r.waitForMaintenanceWindow(ctx, tw, upgradePending); err != nil {
+		return false, err
+	}
 
 	// Create or update machines
-	for ip, machine := range resolvedMachines {
+	for ordinal, resolved := range resolvedMachines {
+		ip, machine := resolved.IP, resolved.Machine
 		name := fmt.Sprintf("%s-%s", tw.Name, ip)
 		// If the talosWorker is imported, then add an annotation to the TalosMachine
 		var annotations map[string]string
@@ -296,24 +355,9 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 			if err := controllerutil.SetControllerReference(tw, tm, r.Scheme); err != nil {
 				return fmt.Errorf("failed to set controller reference for TalosMachine %s: %w", tm.Name, err)
 			}
-			desiredVersion := tw.Spec.Version
-			if machine.Version != "" {
-				// Per-machine pin overrides both the parent version and rollout gating.
-				desiredVersion = machine.Version
-			}
-			version := desiredVersion
-			// For existing machines without an explicit per-machine pin, gate the version bump
-			// behind the rollout strategy so we don't fan out an upgrade to all machines at once.
-			if existingTM, ok := existingByName[name]; ok && machine.Version == "" {
-				if existingTM.Spec.Version != "" && existingTM.Spec.Version != desiredVersion {
-					if inFlight >= maxUnavailable {
-						version = existingTM.Spec.Version
-						heldUpgrades = true
-					} else {
-						inFlight++
-					}
-				}
-			}
+			inheritDeletionProtection(tw, tm)
+			// Per-machine pin overrides both the parent version and rollout gating.
+			version := ro.version(name, ordinal, &machine, existingByName[name], workerMachineVersion(tw, &machine))
 			tm.Spec = talosv1alpha1.TalosMachineSpec{
 				WorkerRef: &corev1.ObjectReference{
 					Kind:       talosv1alpha1.GroupKindWorker,
@@ -326,6 +370,11 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 				MachineSpec:    mergeMachineSpec(tw.Spec.MetalSpec.MachineSpec, &machine),
 				ConfigRef:      tw.Spec.ConfigRef,
 				DeletionPolicy: tw.Spec.DeletionPolicy,
+				Reset:          tw.Spec.Reset,
+				PxeClientSpec:  machine.PxeClientSpec,
+				BMC:            machine.BMC,
+				Drain:          tw.Spec.Drain,
+				Upgrade:        tw.Spec.Upgrade,
 			}
 			return nil
 		})
@@ -334,7 +383,91 @@ func (r *TalosWorkerReconciler) handleTalosMachines(ctx context.Context, tw *tal
 			return false, fmt.Errorf("failed to create or update TalosMachine %s: %w", tm.Name, err)
 		}
 	}
-	return heldUpgrades, nil
+	if err := r.updateRolloutStatus(ctx, tw, &ro.status); err != nil {
+		return false, err
+	}
+	return ro.held, nil
+}
+
+// workerMachineVersion returns the Talos version a worker machine should run
+func workerMachineVersion(tw *talosv1alpha1.TalosWorker, machine *talosv1alpha1.Machine) string {
+	if machine.Version != "" {
+		return machine.Version
+	}
+	return tw.Spec.Version
+}
+
+// updateRolloutStatus records the rollout progress of the worker machines in the status
+func (r *TalosWorkerReconciler) updateRolloutStatus(ctx context.Context, tw *talosv1alpha1.TalosWorker, status *talosv1alpha1.RolloutStatus) error {
+	if equality.Semantic.DeepEqual(tw.Status.Rollout, status) || isDryRun(tw) {
+		return nil
+	}
+	tw.Status.Rollout = status
+	if err := r.Status().Update(ctx, tw); err != nil {
+		return fmt.Errorf("failed to update rollout status of TalosWorker %s: %w", tw.Name, err)
+	}
+	return nil
+}
+
+// updateClaimedHosts records the hosts claimed by the worker in the status
+func (r *TalosWorkerReconciler) updateClaimedHosts(ctx context.Context, tw *talosv1alpha1.TalosWorker, hosts []talosv1alpha1.TalosHost, missing int) error {
+	names := hostNames(hosts)
+	changed := setHostsClaimedCondition(&tw.Status.Conditions, &tw.Spec.MetalSpec, hosts, missing)
+	if (!changed && slices.Equal(tw.Status.ClaimedHosts, names)) || isDryRun(tw) {
+		return nil
+	}
+	if changed && missing > 0 {
+		r.Recorder.Eventf(tw, nil, corev1.EventTypeWarning, "InsufficientHosts", "InsufficientHosts", meta.FindStatusCondition(tw.Status.Conditions, talosv1alpha1.ConditionHostsClaimed).Message)
+	}
+	tw.Status.ClaimedHosts = names
+	if err := r.Status().Update(ctx, tw); err != nil {
+		return fmt.Errorf("failed to update claimed hosts of TalosWorker %s: %w", tw.Name, err)
+	}
+	return nil
+}
+
+// waitForMaintenanceWindow returns true if a pending upgrade has to wait for the next maintenance
+// window of the worker and records the next window in the status
+func (r *TalosWorkerReconciler) waitForMaintenanceWindow(ctx context.Context, tw *talosv1alpha1.TalosWorker, pending bool) (bool, error) {
+	var waiting bool
+	var next time.Time
+	if pending {
+		open, nextWindow, err := maintenanceWindowOpen(tw.Spec.MaintenanceWindows, time.Now())
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate maintenance windows of TalosWorker %s: %w", tw.Name, err)
+		}
+		waiting, next = !open, nextWindow
+	}
+	if !setMaintenanceWindowCondition(&tw.Status.Conditions, &tw.Status.NextMaintenanceWindow, waiting, next) || isDryRun(tw) {
+		return waiting, nil
+	}
+	if waiting && next.IsZero() {
+		r.Recorder.Eventf(tw, nil, corev1.EventTypeWarning, "NoMaintenanceWindow", "NoMaintenanceWindow", "Upgrade is waiting, none of the maintenance window schedules opens a window")
+	} else if waiting {
+		r.Recorder.Eventf(tw, nil, corev1.EventTypeNormal, "WaitingForMaintenanceWindow", "WaitingForMaintenanceWindow", fmt.Sprintf("Upgrade is waiting for the maintenance window opening at %s", next.Format(time.RFC3339)))
+	}
+	if err := r.Status().Update(ctx, tw); err != nil {
+		return false, fmt.Errorf("failed to update maintenance window status of TalosWorker %s: %w", tw.Name, err)
+	}
+	return waiting, nil
+}
+
+// updateRolloutPaused sets the RolloutPaused condition of the worker and returns true if the rollout
+// is paused
+func (r *TalosWorkerReconciler) updateRolloutPaused(ctx context.Context, tw *talosv1alpha1.TalosWorker, items []talosv1alpha1.TalosMachine, desired map[string]bool) (bool, error) {
+	timeout := resolveHealthTimeout(tw.Spec.RolloutStrategy)
+	timedOut := healthTimedOutMachines(items, desired, timeout, time.Now())
+	changed, paused := setRolloutPausedCondition(&tw.Status.Conditions, upgradeFailedMachines(items, desired), timedOut, timeout)
+	if !changed || isDryRun(tw) {
+		return paused, nil
+	}
+	if paused {
+		r.Recorder.Eventf(tw, nil, corev1.EventTypeWarning, "RolloutPaused", "RolloutPaused", fmt.Sprintf("Rollout paused: %s", meta.FindStatusCondition(tw.Status.Conditions, talosv1alpha1.ConditionRolloutPaused).Message))
+	}
+	if err := r.Status().Update(ctx, tw); err != nil {
+		return false, fmt.Errorf("failed to update rollout status of TalosWorker %s: %w", tw.Name, err)
+	}
+	return paused, nil
 }
 
 func (r *TalosWorkerReconciler) reconcileService(ctx context.Context, tw *talosv1alpha1.TalosWorker) error {
@@ -426,12 +559,18 @@ func (r *TalosWorkerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&talosv1alpha1.TalosMachine{}, builder.WithPredicates(talosMachinePredicate)).
 		// Watch ConfigMaps so that changes to a referenced configRef trigger reconciliation.
 		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.configMapToTalosWorkers)).
+		// Watch TalosHosts so that hosts added to or removed from the inventory are claimed or replaced.
+		Watches(&talosv1alpha1.TalosHost{}, handler.EnqueueRequestsFromMapFunc(r.hostToTalosWorkers)).
 		WithEventFilter(predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				if _, ok := e.ObjectNew.(*corev1.ConfigMap); ok {
 					return true
 				}
-				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+				if _, ok := e.ObjectNew.(*talosv1alpha1.TalosHost); ok {
+					return true
+				}
+				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+					deletionProtectionChanged(e.ObjectOld, e.ObjectNew)
 			},
 		}).
 		Named("talosworker").
@@ -459,6 +598,27 @@ func (r *TalosWorkerReconciler) configMapToTalosWorkers(ctx context.Context, obj
 	return requests
 }
 
+// hostToTalosWorkers maps a TalosHost change event to the TalosWorkers of its namespace that claim hosts
+// through a hostSelector.
+func (r *TalosWorkerReconciler) hostToTalosWorkers(ctx context.Context, obj client.Object) []reconcile.Request {
+	var twList talosv1alpha1.TalosWorkerList
+	if err := r.List(ctx, &twList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tw := range twList.Items {
+		if tw.Spec.MetalSpec.HostSelector != nil {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      tw.Name,
+					Namespace: tw.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
+
 func (r *TalosWorkerReconciler) GenerateConfig(ctx context.Context, tw *talosv1alpha1.TalosWorker) error {
 	bundleConfig, err := r.SetConfig(ctx, tw)
 	if err != nil {
@@ -478,11 +638,13 @@ func (r *TalosWorkerReconciler) GenerateConfig(ctx context.Context, tw *talosv1a
 	if err != nil {
 		return err
 	}
-	// Update the status
-	if tw.Status.Config == string(*wkConfig) {
+	configChanged, err := storeMachineConfig(ctx, r.Client, r.Scheme, tw, workerConfigSecretName(tw.Name), &tw.Status.ConfigSecretRef, &tw.Status.Config, *wkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to store worker config for TalosWorker %s: %w", tw.Name, err)
+	}
+	if !configChanged {
 		return nil // No change in config, nothing to do
 	}
-	tw.Status.Config = string(*wkConfig)
 	// Update the status of the TalosWorker
 	if err := r.Status().Update(ctx, tw); err != nil {
 		return fmt.Errorf("failed to update TalosWorker status %s: %w", tw.Name, err)
@@ -561,13 +723,17 @@ func (r *TalosWorkerReconciler) SetConfig(ctx context.Context, tw *talosv1alpha1
 		replicas = int(tw.Spec.Replicas)
 		sans = utils.GenSans(tw.Name, &replicas)
 	}
-	sb, err := utils.SecretBundleDecoder(tcp.Status.SecretBundle)
+	sb, err := getSecretBundle(ctx, r.Client, tcp)
 	if err != nil {
 		return nil, err
 	}
 	var ClientEndpoint []string
 	if tw.Spec.Mode == TalosModeMetal {
-		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &tw.Spec.MetalSpec.Machines)
+		machines, err := metalMachines(ctx, r.Client, talosv1alpha1.GroupKindWorker, tw, &tw.Spec.MetalSpec)
+		if err != nil {
+			return nil, err
+		}
+		ipAddresses, err := getMachinesIPAddresses(ctx, r.Client, &machines)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get machine IP addresses for TalosWorker %s: %w", tw.Name, err)
 		}
@@ -654,7 +820,7 @@ func (r *TalosWorkerReconciler) handleDeletion(ctx context.Context, tw *talosv1a
 			return ctrl.Result{}, err
 		}
 		for _, tm := range talosMachineList.Items {
-			if err := r.Delete(ctx, &tm); err != nil && !kerrors.IsNotFound(err) {
+			if err := deleteChild(ctx, r.Client, &tm); err != nil {
 				logger.Error(err, "failed to delete TalosMachine", "name", tm.Name)
 				return ctrl.Result{}, fmt.Errorf("failed to delete TalosMachine %s: %w", tm.Name, err)
 			}
@@ -669,6 +835,11 @@ func (r *TalosWorkerReconciler) handleDeletion(ctx context.Context, tw *talosv1a
 			// Some machines still exist, requeue and retry after delay
 			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
 		}
+		// The machines are reset, return the claimed hosts to the inventory
+		if err := releaseHosts(ctx, r.Client, tw.Namespace, talosv1alpha1.GroupKindWorker, tw.Name); err != nil {
+			logger.Error(err, "failed to release the hosts of TalosWorker", "name", tw.Name)
+			return ctrl.Result{}, fmt.Errorf("failed to release the hosts of TalosWorker %s: %w", tw.Name, err)
+		}
 	default:
 		logger.Error(nil, "unsupported TalosWorker mode for deletion", "mode", tw.Spec.Mode)
 	}
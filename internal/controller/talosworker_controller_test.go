@@ -138,7 +138,10 @@ var _ = Describe("TalosWorker Controller", func() {
 			Eventually(func(g Gomega) {
 				cp := &talosv1alpha1.TalosControlPlane{}
 				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controlPlaneName, Namespace: namespace}, cp)).To(Succeed())
-				cp.Status.SecretBundle = "stub-secret-bundle"
+				cp.Status.SecretBundleRef = &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: controlPlaneName + "-secret-bundle"},
+					Key:                  "secretBundle",
+				}
 				g.Expect(k8sClient.Status().Update(ctx, cp)).To(Succeed())
 			}, timeout, interval).Should(Succeed())
 
@@ -166,6 +169,7 @@ var _ = Describe("TalosWorker Controller", func() {
 				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: talosWorkerName, Namespace: namespace}, fetched)).To(Succeed())
 				g.Expect(fetched.Status.State).To(BeEmpty())
 				g.Expect(fetched.Status.Config).To(BeEmpty())
+				g.Expect(fetched.Status.ConfigSecretRef).To(BeNil())
 			}, time.Second*3, interval).Should(Succeed())
 		})
 	})
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// newTestClient returns a fake client for the unit tests of the controllers. It serves the objects,
+// the status subresource of every kind of the operator and the indexes the controllers list
+// TalosMachines by, like the manager does.
+func newTestClient(t *testing.T, objects ...client.Object) client.WithWatch {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = talosv1alpha1.AddToScheme(scheme)
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(
+			&talosv1alpha1.TalosCluster{},
+			&talosv1alpha1.TalosClusterAddon{},
+			&talosv1alpha1.TalosClusterAddonRelease{},
+			&talosv1alpha1.TalosControlPlane{},
+			&talosv1alpha1.TalosEtcdBackup{},
+			&talosv1alpha1.TalosEtcdBackupSchedule{},
+			&talosv1alpha1.TalosEtcdRestore{},
+			&talosv1alpha1.TalosHost{},
+			&talosv1alpha1.TalosHostDiscovery{},
+			&talosv1alpha1.TalosMachine{},
+			&talosv1alpha1.TalosMachineHealthCheck{},
+			&talosv1alpha1.TalosUpgradePlan{},
+			&talosv1alpha1.TalosWorker{},
+		).
+		WithIndex(&talosv1alpha1.TalosMachine{}, IndexControlPlaneRefName, func(obj client.Object) []string {
+			if ref := obj.(*talosv1alpha1.TalosMachine).Spec.ControlPlaneRef; ref != nil {
+				return []string{ref.Name}
+			}
+			return nil
+		}).
+		WithIndex(&talosv1alpha1.TalosMachine{}, IndexWorkerRefName, func(obj client.Object) []string {
+			if ref := obj.(*talosv1alpha1.TalosMachine).Spec.WorkerRef; ref != nil {
+				return []string{ref.Name}
+			}
+			return nil
+		}).
+		Build()
+}
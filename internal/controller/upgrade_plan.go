@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// configHash returns the hash identifying a machine config in a TalosUpgradePlan
+func configHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// planRevision returns the revision of a plan with the given actions planned from the specs with
+// the given hash. The config diffs are left out as they are only reported for review.
+func planRevision(specHash string, actions []talosv1alpha1.PlannedAction) (string, error) {
+	stripped := make([]talosv1alpha1.PlannedAction, len(actions))
+	for i, action := range actions {
+		action.Diff = ""
+		stripped[i] = action
+	}
+	data, err := json.Marshal(stripped)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal planned actions: %w", err)
+	}
+	return configHash(append([]byte(specHash), data...))[:12], nil
+}
+
+// approvedRevision returns the revision the plan is approved with, either by its spec or by the
+// approved-revision annotation
+func approvedRevision(plan *talosv1alpha1.TalosUpgradePlan) string {
+	if plan.Spec.ApprovedRevision != "" {
+		return plan.Spec.ApprovedRevision
+	}
+	return plan.Annotations[talosv1alpha1.ApprovedRevisionAnnotation]
+}
+
+// upgradePlansFor returns the TalosUpgradePlans of the cluster with the given control plane
+func upgradePlansFor(ctx context.Context, c client.Client, namespace, controlPlane string) ([]talosv1alpha1.TalosUpgradePlan, error) {
+	plans := &talosv1alpha1.TalosUpgradePlanList{}
+	if err := c.List(ctx, plans, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list TalosUpgradePlans: %w", err)
+	}
+	var matching []talosv1alpha1.TalosUpgradePlan
+	for _, plan := range plans.Items {
+		if plan.Spec.TalosControlPlaneRef != nil && plan.Spec.TalosControlPlaneRef.Name == controlPlane {
+			matching = append(matching, plan)
+		}
+	}
+	return matching, nil
+}
+
+// plansApprove returns true if the action is part of the approved revision of every plan. Clusters
+// without a TalosUpgradePlan are not under change control, so every action is approved.
+func plansApprove(plans []talosv1alpha1.TalosUpgradePlan, actionType, target, to string) bool {
+	for i := range plans {
+		plan := &plans[i]
+		if revision := approvedRevision(plan); revision == "" || revision != plan.Status.Revision {
+			return false
+		}
+		if !planContains(plan.Status.Actions, actionType, target, to) {
+			return false
+		}
+	}
+	return true
+}
+
+// planContains returns true if the actions contain the change of target to the given value
+func planContains(actions []talosv1alpha1.PlannedAction, actionType, target, to string) bool {
+	for _, action := range actions {
+		if action.Type == actionType && action.Target == target && action.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// upgradePlanApproves returns true if the TalosUpgradePlans of the cluster with the given control
+// plane approve the action
+func upgradePlanApproves(ctx context.Context, c client.Client, namespace, controlPlane, actionType, target, to string) (bool, error) {
+	plans, err := upgradePlansFor(ctx, c, namespace, controlPlane)
+	if err != nil {
+		return false, err
+	}
+	return plansApprove(plans, actionType, target, to), nil
+}
+
+// machineControlPlaneName returns the name of the TalosControlPlane of the cluster the machine belongs to
+func machineControlPlaneName(ctx context.Context, c client.Client, tm *talosv1alpha1.TalosMachine) (string, error) {
+	switch {
+	case tm.Spec.ControlPlaneRef != nil:
+		return tm.Spec.ControlPlaneRef.Name, nil
+	case tm.Spec.WorkerRef != nil:
+		tw := &talosv1alpha1.TalosWorker{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: tm.Namespace, Name: tm.Spec.WorkerRef.Name}, tw); err != nil {
+			return "", fmt.Errorf("failed to get TalosWorker %s: %w", tm.Spec.WorkerRef.Name, err)
+		}
+		return tw.Spec.ControlPlaneRef.Name, nil
+	default:
+		return "", nil
+	}
+}
+
+// plannedMachineActions returns the Talos upgrades and config changes of the existing machines of a
+// control plane or worker. Machines kept out by a Partition rollout are not upgraded and left out.
+func plannedMachineActions(ctx context.Context, c client.Client, owner string, machines *[]talosv1alpha1.Machine,
+	rs *talosv1alpha1.RolloutStrategy, items []talosv1alpha1.TalosMachine, version func(*talosv1alpha1.Machine) string,
+) ([]talosv1alpha1.PlannedAction, error) {
+	resolvedMachines, err := getMachinesResolved(ctx, c, machines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine IP addresses of %s: %w", owner, err)
+	}
+	ro, err := newRollout(rs, len(resolvedMachines), 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve rollout strategy of %s: %w", owner, err)
+	}
+	existingByName := make(map[string]*talosv1alpha1.TalosMachine, len(items))
+	for i := range items {
+		existingByName[items[i].Name] = &items[i]
+	}
+	var actions []talosv1alpha1.PlannedAction
+	for ordinal, resolved := range resolvedMachines {
+		name := fmt.Sprintf("%s-%s", owner, resolved.IP)
+		existing, ok := existingByName[name]
+		if !ok {
+			// New machines are installed without approval
+			continue
+		}
+		desiredVersion := version(&resolved.Machine)
+		observed := existing.Status.ObservedVersion
+		if observed != "" && observed != desiredVersion && (resolved.Machine.Version != "" || ro.partitioned(ordinal, existing)) {
+			actions = append(actions, talosv1alpha1.PlannedAction{
+				Type:   talosv1alpha1.PlannedActionTalosUpgrade,
+				Target: name,
+				From:   observed,
+				To:     desiredVersion,
+			})
+		}
+		if planned := existing.Status.PlannedConfig; planned != nil {
+			actions = append(actions, talosv1alpha1.PlannedAction{
+				Type:   talosv1alpha1.PlannedActionConfigChange,
+				Target: name,
+				From:   planned.AppliedHash,
+				To:     planned.Hash,
+				Diff:   planned.Diff,
+			})
+		}
+	}
+	return actions, nil
+}
+
+// configChangeApproved returns true if the TalosUpgradePlans of the cluster approve applying the
+// config to the machine. Otherwise the changes reported by an apply-config dry run are recorded in
+// the status of the machine, where the plan picks them up.
+func (r *TalosMachineReconciler) configChangeApproved(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient, appliedConfig string, config []byte) (bool, error) {
+	if r.isDryRun(tm) {
+		return true, nil
+	}
+	controlPlane, err := machineControlPlaneName(ctx, r.Client, tm)
+	if err != nil {
+		return false, err
+	}
+	hash := configHash(config)
+	approved, err := upgradePlanApproves(ctx, r.Client, tm.Namespace, controlPlane, talosv1alpha1.PlannedActionConfigChange, tm.Name, hash)
+	if err != nil || approved {
+		return approved, err
+	}
+	if tm.Status.PlannedConfig != nil && tm.Status.PlannedConfig.Hash == hash {
+		// The change is planned already
+		return false, nil
+	}
+	diff, err := tc.ApplyConfig(ctx, config, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to plan Talos config change for TalosMachine %s: %w", tm.Name, err)
+	}
+	orig := tm.DeepCopy()
+	tm.Status.PlannedConfig = &talosv1alpha1.PlannedConfigChange{Hash: hash, Diff: diff}
+	if appliedConfig != "" {
+		tm.Status.PlannedConfig.AppliedHash = configHash([]byte(appliedConfig))
+	}
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return false, fmt.Errorf("failed to patch TalosMachine %s status with planned config: %w", tm.Name, err)
+	}
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "WaitingForApproval", "WaitingForApproval",
+		fmt.Sprintf("Config change %s is waiting for the TalosUpgradePlan to be approved", hash))
+	return false, nil
+}
+
+// clearPlannedConfig removes a planned config change from the status once the machine has no
+// pending config change anymore
+func (r *TalosMachineReconciler) clearPlannedConfig(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	if tm.Status.PlannedConfig == nil || r.isDryRun(tm) {
+		return nil
+	}
+	orig := tm.DeepCopy()
+	tm.Status.PlannedConfig = nil
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to clear planned config of TalosMachine %s: %w", tm.Name, err)
+	}
+	return nil
+}
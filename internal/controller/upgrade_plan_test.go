@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// newUpgradePlanTestObjects returns a metal control plane with two machines on v1.13.0 that is
+// to be upgraded to v1.13.1, together with a plan for it
+func newUpgradePlanTestObjects() []client.Object {
+	tcp := newPreUpgradeTestControlPlane()
+	tcp.Spec.Mode = TalosModeMetal
+	tcp.Spec.Version = "v1.13.1"
+	tcp.Spec.PreUpgradeBackup = nil
+	tcp.Status.ObservedKubeVersion = tcp.Spec.KubeVersion
+	objects := []client.Object{tcp}
+	for _, address := range []string{"10.0.0.1", "10.0.0.2"} {
+		tcp.Spec.MetalSpec.Machines = append(tcp.Spec.MetalSpec.Machines, talosv1alpha1.Machine{Address: &address})
+		machine := newRolloutTestMachine(fmt.Sprintf("test-cp-%s", address), "v1.13.0", nil)
+		objects = append(objects, &machine)
+	}
+	plan := &talosv1alpha1.TalosUpgradePlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-plan", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosUpgradePlanSpec{
+			TalosControlPlaneRef: &corev1.LocalObjectReference{Name: tcp.Name},
+		},
+	}
+	return append(objects, plan)
+}
+
+func reconcileUpgradePlan(t *testing.T, r *TalosUpgradePlanReconciler) *talosv1alpha1.TalosUpgradePlan {
+	t.Helper()
+	ctx := context.Background()
+	key := client.ObjectKey{Namespace: DefaultNamespace, Name: "test-plan"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan := &talosv1alpha1.TalosUpgradePlan{}
+	if err := r.Get(ctx, key, plan); err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	return plan
+}
+
+func TestPlansApprove(t *testing.T) {
+	if !plansApprove(nil, talosv1alpha1.PlannedActionTalosUpgrade, "cp-0", "v1.13.1") {
+		t.Error("expected clusters without a plan to approve every action")
+	}
+	plan := talosv1alpha1.TalosUpgradePlan{
+		Status: talosv1alpha1.TalosUpgradePlanStatus{
+			Revision: "abc",
+			Actions: []talosv1alpha1.PlannedAction{{
+				Type:   talosv1alpha1.PlannedActionTalosUpgrade,
+				Target: "cp-0",
+				To:     "v1.13.1",
+			}},
+		},
+	}
+	plans := []talosv1alpha1.TalosUpgradePlan{plan}
+	if plansApprove(plans, talosv1alpha1.PlannedActionTalosUpgrade, "cp-0", "v1.13.1") {
+		t.Error("expected an unapproved plan to hold its actions")
+	}
+	plans[0].Annotations = map[string]string{talosv1alpha1.ApprovedRevisionAnnotation: "abc"}
+	if !plansApprove(plans, talosv1alpha1.PlannedActionTalosUpgrade, "cp-0", "v1.13.1") {
+		t.Error("expected the annotation to approve the plan")
+	}
+	if plansApprove(plans, talosv1alpha1.PlannedActionTalosUpgrade, "cp-0", "v1.13.2") {
+		t.Error("expected an action outside of the plan to be held")
+	}
+	plans[0].Spec.ApprovedRevision = "def"
+	if plansApprove(plans, talosv1alpha1.PlannedActionTalosUpgrade, "cp-0", "v1.13.1") {
+		t.Error("expected an approval of another revision to hold the actions")
+	}
+}
+
+func TestTalosUpgradePlanReconcile(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newUpgradePlanTestObjects()...)
+	r := &TalosUpgradePlanReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	plan := reconcileUpgradePlan(t, r)
+	if plan.Status.Phase != talosv1alpha1.UpgradePlanPhaseAwaitingApproval || plan.Status.Revision == "" {
+		t.Fatalf("expected the plan to await approval, got %+v", plan.Status)
+	}
+	if len(plan.Status.Actions) != 2 {
+		t.Fatalf("expected an upgrade of both machines, got %+v", plan.Status.Actions)
+	}
+	if action := plan.Status.Actions[0]; action.Type != talosv1alpha1.PlannedActionTalosUpgrade ||
+		action.Target != "test-cp-10.0.0.1" || action.From != "v1.13.0" || action.To != "v1.13.1" {
+		t.Errorf("unexpected action %+v", action)
+	}
+
+	plan.Spec.ApprovedRevision = plan.Status.Revision
+	if err := c.Update(ctx, plan); err != nil {
+		t.Fatalf("failed to approve plan: %v", err)
+	}
+	approved := reconcileUpgradePlan(t, r)
+	if approved.Status.Phase != talosv1alpha1.UpgradePlanPhaseApproved || approved.Status.Revision != plan.Status.Revision {
+		t.Fatalf("expected the plan to be approved, got %+v", approved.Status)
+	}
+
+	// Executed actions leave the approval in place
+	tm := &talosv1alpha1.TalosMachine{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: "test-cp-10.0.0.1"}, tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	tm.Status.ObservedVersion = "v1.13.1"
+	if err := c.Status().Update(ctx, tm); err != nil {
+		t.Fatalf("failed to update machine: %v", err)
+	}
+	if executing := reconcileUpgradePlan(t, r); executing.Status.Revision != plan.Status.Revision || len(executing.Status.Actions) != 2 {
+		t.Errorf("expected the approved actions to be kept while they are executed, got %+v", executing.Status)
+	}
+
+	// A spec change after the approval invalidates the plan
+	tcp := &talosv1alpha1.TalosControlPlane{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: "test-cp"}, tcp); err != nil {
+		t.Fatalf("failed to get control plane: %v", err)
+	}
+	tcp.Spec.Version = "v1.13.2"
+	if err := c.Update(ctx, tcp); err != nil {
+		t.Fatalf("failed to update control plane: %v", err)
+	}
+	invalidated := reconcileUpgradePlan(t, r)
+	if invalidated.Status.Phase != talosv1alpha1.UpgradePlanPhaseAwaitingApproval || invalidated.Status.Revision == plan.Status.Revision {
+		t.Fatalf("expected the approval to be invalidated, got %+v", invalidated.Status)
+	}
+	if plansApprove([]talosv1alpha1.TalosUpgradePlan{*invalidated}, talosv1alpha1.PlannedActionTalosUpgrade, "test-cp-10.0.0.2", "v1.13.2") {
+		t.Error("expected the invalidated plan not to approve the new version")
+	}
+}
+
+func TestHandleTalosMachines_UpgradePlan(t *testing.T) {
+	ctx := context.Background()
+	objects := newUpgradePlanTestObjects()
+	tcp := objects[0].(*talosv1alpha1.TalosControlPlane)
+	c := newTestClient(t, objects...)
+	r := &TalosControlPlaneReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	held, err := r.handleTalosMachines(ctx, tcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !held {
+		t.Error("expected the unapproved upgrade to be retried")
+	}
+	tm := &talosv1alpha1.TalosMachine{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultNamespace, Name: "test-cp-10.0.0.1"}, tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.Spec.Version != "v1.13.0" {
+		t.Errorf("expected the upgrade to wait for the plan to be approved, got %s", tm.Spec.Version)
+	}
+
+	planner := &TalosUpgradePlanReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+	plan := reconcileUpgradePlan(t, planner)
+	plan.Annotations = map[string]string{talosv1alpha1.ApprovedRevisionAnnotation: plan.Status.Revision}
+	if err := c.Update(ctx, plan); err != nil {
+		t.Fatalf("failed to approve plan: %v", err)
+	}
+	if _, err := r.handleTalosMachines(ctx, tcp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tm), tm); err != nil {
+		t.Fatalf("failed to get machine: %v", err)
+	}
+	if tm.Spec.Version != "v1.13.1" {
+		t.Errorf("expected the approved upgrade to be rolled out, got %s", tm.Spec.Version)
+	}
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/internal/metrics"
+	"github.com/alperencelik/talos-operator/pkg/talos"
+)
+
+// defaultUpgradeTimeout is used when the machine does not set an upgrade timeout
+const defaultUpgradeTimeout = 30 * time.Minute
+
+// upgradeTimeout returns how long the machine may take to come back on the new Talos version
+func upgradeTimeout(tm *talosv1alpha1.TalosMachine) time.Duration {
+	if tm.Spec.Upgrade == nil || tm.Spec.Upgrade.Timeout == nil {
+		return defaultUpgradeTimeout
+	}
+	return tm.Spec.Upgrade.Timeout.Duration
+}
+
+// upgradeTimedOut returns true if the upgrade of the machine was started longer than its timeout ago.
+// Upgrades started by older versions of the operator have no start time and never time out.
+func upgradeTimedOut(tm *talosv1alpha1.TalosMachine, now time.Time) bool {
+	if tm.Status.UpgradeStartTime == nil {
+		return false
+	}
+	return now.Sub(tm.Status.UpgradeStartTime.Time) > upgradeTimeout(tm)
+}
+
+// upgradeFailed returns true if the machine failed the upgrade to its current version. The upgrade
+// is not retried until the version is changed or status.failedVersion is cleared.
+func upgradeFailed(tm *talosv1alpha1.TalosMachine) bool {
+	return tm.Status.FailedVersion != "" && tm.Status.FailedVersion == tm.Spec.Version
+}
+
+// upgradeFailedMachines returns the names of the desired machines whose upgrade failed
+func upgradeFailedMachines(items []talosv1alpha1.TalosMachine, desired map[string]bool) []string {
+	var names []string
+	for i := range items {
+		if desired[items[i].Name] && upgradeFailed(&items[i]) {
+			names = append(names, items[i].Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// verifyUpgrade checks that an upgrading machine came back on the new Talos version. It returns
+// false while the machine is still upgrading and fails the upgrade once the timeout is exceeded.
+func (r *TalosMachineReconciler) verifyUpgrade(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient) (bool, error) {
+	logger := log.FromContext(ctx)
+	versionCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	version, err := tc.GetTalosVersion(versionCtx)
+	if err == nil && version == tm.Spec.Version {
+		return true, nil
+	}
+	if !upgradeTimedOut(tm, time.Now()) {
+		logger.Info("Waiting for machine to come back on the new Talos version", "name", tm.Name, "version", tm.Spec.Version, "running", version)
+		return false, nil
+	}
+	if err != nil {
+		return false, r.failUpgrade(ctx, tm, tc, "", "UpgradeTimeout",
+			fmt.Sprintf("Machine did not come back within %s after upgrading to %s: %v", upgradeTimeout(tm), tm.Spec.Version, err))
+	}
+	return false, r.failUpgrade(ctx, tm, tc, version, "UpgradeVersionMismatch",
+		fmt.Sprintf("Machine runs %s instead of %s %s after the upgrade was started", version, tm.Spec.Version, upgradeTimeout(tm)))
+}
+
+// failUpgrade marks the upgrade of the machine as failed. If rollback is enabled and the machine is
+// reachable on a version other than the previous one, it is rolled back to the previous boot entry.
+func (r *TalosMachineReconciler) failUpgrade(ctx context.Context, tm *talosv1alpha1.TalosMachine, tc *talos.TalosClient, runningVersion, reason, message string) error {
+	logger := log.FromContext(ctx)
+	if r.isDryRun(tm) {
+		logger.Info("DryRun: would mark Talos upgrade as failed", "name", tm.Name, "reason", message)
+		r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, EventReasonDryRun, EventReasonDryRun, fmt.Sprintf("Would mark upgrade as failed: %s", message))
+		return nil
+	}
+	if tm.Spec.Upgrade != nil && tm.Spec.Upgrade.Rollback && runningVersion != "" && runningVersion != tm.Status.PreviousVersion {
+		if err := tc.RollbackTalosVersion(ctx); err != nil {
+			logger.Error(err, "Failed to roll back Talos version", "name", tm.Name)
+			r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "RollbackFailed", "RollbackFailed", fmt.Sprintf("Failed to roll back Talos version: %v", err))
+		} else {
+			r.Recorder.Eventf(tm, nil, corev1.EventTypeNormal, "RolledBack", "RolledBack", fmt.Sprintf("Rolling back Talos version to %s", tm.Status.PreviousVersion))
+			reason = "UpgradeRolledBack"
+			message = fmt.Sprintf("%s, rolled back to the previous version", message)
+			runningVersion = tm.Status.PreviousVersion
+		}
+	}
+	orig := tm.DeepCopy()
+	tm.Status.State = talosv1alpha1.StateFailed
+	tm.Status.FailedVersion = tm.Spec.Version
+	// An unreachable machine is assumed to be on the previous version, so that a retry upgrades it again
+	if runningVersion == "" {
+		runningVersion = tm.Status.PreviousVersion
+	}
+	if runningVersion != "" {
+		tm.Status.ObservedVersion = runningVersion
+	}
+	tm.Status.UpgradeStartTime = nil
+	meta.SetStatusCondition(&tm.Status.Conditions, metav1.Condition{
+		Type:    talosv1alpha1.ConditionFailed,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to patch TalosMachine %s status with failed upgrade: %w", tm.Name, err)
+	}
+	metrics.RecordUpgradeFailure(metrics.UpgradeTypeTalos, tm.Namespace, tm.Name)
+	r.Recorder.Eventf(tm, nil, corev1.EventTypeWarning, "UpgradeFailed", "UpgradeFailed", message)
+	return nil
+}
+
+// clearFailedUpgrade makes a machine whose upgrade failed Available again once its version was changed
+func (r *TalosMachineReconciler) clearFailedUpgrade(ctx context.Context, tm *talosv1alpha1.TalosMachine) error {
+	if r.isDryRun(tm) {
+		return nil
+	}
+	orig := tm.DeepCopy()
+	tm.Status.State = talosv1alpha1.StateAvailable
+	tm.Status.FailedVersion = ""
+	meta.RemoveStatusCondition(&tm.Status.Conditions, talosv1alpha1.ConditionFailed)
+	if err := r.Status().Patch(ctx, tm, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to patch TalosMachine %s status after failed upgrade: %w", tm.Name, err)
+	}
+	return nil
+}
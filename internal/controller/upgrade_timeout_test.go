@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newUpgradingTestMachine(since time.Duration) *talosv1alpha1.TalosMachine {
+	started := metav1.NewTime(time.Now().Add(-since))
+	tm := &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cp-0", Namespace: DefaultNamespace},
+		Spec: talosv1alpha1.TalosMachineSpec{
+			Version:         "v1.13.1",
+			Endpoint:        "10.0.0.1",
+			ControlPlaneRef: &corev1.ObjectReference{Name: "test-cp"},
+		},
+	}
+	tm.Status.State = talosv1alpha1.StateUpgrading
+	tm.Status.PreviousVersion = "v1.13.0"
+	tm.Status.ObservedVersion = "v1.13.1"
+	tm.Status.UpgradeStartTime = &started
+	return tm
+}
+
+func TestUpgradeTimedOut(t *testing.T) {
+	now := time.Now()
+	tm := newUpgradingTestMachine(20 * time.Minute)
+	if upgradeTimedOut(tm, now) {
+		t.Error("expected upgrade not to time out before the default timeout")
+	}
+	tm.Spec.Upgrade = &talosv1alpha1.UpgradeSpec{Timeout: &metav1.Duration{Duration: 10 * time.Minute}}
+	if !upgradeTimedOut(tm, now) {
+		t.Error("expected upgrade to time out after the configured timeout")
+	}
+	tm.Status.UpgradeStartTime = nil
+	if upgradeTimedOut(tm, now) {
+		t.Error("expected upgrade without start time never to time out")
+	}
+}
+
+func TestUpgradeFailedMachines(t *testing.T) {
+	failed := newRolloutTestMachine("cp-1", "v1.13.1", nil)
+	failed.Status.FailedVersion = "v1.13.1"
+	retried := newRolloutTestMachine("cp-2", "v1.13.2", nil)
+	retried.Status.FailedVersion = "v1.13.1"
+	orphan := newRolloutTestMachine("orphan", "v1.13.1", nil)
+	orphan.Status.FailedVersion = "v1.13.1"
+	items := []talosv1alpha1.TalosMachine{failed, retried, orphan, newRolloutTestMachine("cp-3", "v1.13.1", nil)}
+	desired := map[string]bool{"cp-1": true, "cp-2": true, "cp-3": true}
+
+	names := upgradeFailedMachines(items, desired)
+	if len(names) != 1 || names[0] != "cp-1" {
+		t.Errorf("expected only cp-1 to have failed its upgrade, got %v", names)
+	}
+}
+
+func TestFailUpgrade(t *testing.T) {
+	ctx := context.Background()
+	tm := newUpgradingTestMachine(time.Hour)
+	c := newTestClient(t, tm)
+	r := &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	// The machine is unreachable, so it can neither report its version nor be rolled back
+	if err := r.failUpgrade(ctx, tm, nil, "", "UpgradeTimeout", "Machine did not come back"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated := &talosv1alpha1.TalosMachine{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(tm), updated); err != nil {
+		t.Fatalf("failed to get TalosMachine: %v", err)
+	}
+	if updated.Status.State != talosv1alpha1.StateFailed || updated.Status.FailedVersion != "v1.13.1" {
+		t.Errorf("expected machine to fail on v1.13.1, got state %q and failed version %q", updated.Status.State, updated.Status.FailedVersion)
+	}
+	if updated.Status.ObservedVersion != "v1.13.0" {
+		t.Errorf("expected observed version to fall back to the previous version, got %s", updated.Status.ObservedVersion)
+	}
+	if updated.Status.UpgradeStartTime != nil {
+		t.Error("expected upgrade start time to be cleared")
+	}
+	condition := meta.FindStatusCondition(updated.Status.Conditions, talosv1alpha1.ConditionFailed)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != "UpgradeTimeout" {
+		t.Errorf("expected Failed condition with reason UpgradeTimeout, got %v", updated.Status.Conditions)
+	}
+	if !upgradeFailed(updated) {
+		t.Error("expected upgrade of the machine to count as failed")
+	}
+
+	// Changing the version allows the machine to be upgraded again
+	updated.Spec.Version = "v1.13.2"
+	if upgradeFailed(updated) {
+		t.Error("expected upgrade to a new version not to count as failed")
+	}
+	if err := r.clearFailedUpgrade(ctx, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(tm), updated); err != nil {
+		t.Fatalf("failed to get TalosMachine: %v", err)
+	}
+	if updated.Status.State != talosv1alpha1.StateAvailable || updated.Status.FailedVersion != "" {
+		t.Errorf("expected failure to be cleared, got state %q and failed version %q", updated.Status.State, updated.Status.FailedVersion)
+	}
+	if meta.FindStatusCondition(updated.Status.Conditions, talosv1alpha1.ConditionFailed) != nil {
+		t.Error("expected Failed condition to be removed")
+	}
+}
+
+func TestFailUpgrade_VersionMismatch(t *testing.T) {
+	ctx := context.Background()
+	tm := newUpgradingTestMachine(time.Hour)
+	// The machine fell back to the previous version on its own, so there is nothing to roll back
+	tm.Spec.Upgrade = &talosv1alpha1.UpgradeSpec{Rollback: true}
+	c := newTestClient(t, tm)
+	r := &TalosMachineReconciler{Client: c, Scheme: c.Scheme(), Recorder: events.NewFakeRecorder(10)}
+
+	if err := r.failUpgrade(ctx, tm, nil, "v1.13.0", "UpgradeVersionMismatch", "Machine runs v1.13.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tm.Status.ObservedVersion != "v1.13.0" {
+		t.Errorf("expected observed version to be the running version, got %s", tm.Status.ObservedVersion)
+	}
+	condition := meta.FindStatusCondition(tm.Status.Conditions, talosv1alpha1.ConditionFailed)
+	if condition == nil || condition.Reason != "UpgradeVersionMismatch" {
+		t.Errorf("expected Failed condition with reason UpgradeVersionMismatch, got %v", tm.Status.Conditions)
+	}
+}
@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// collectTimeout bounds the time a scrape spends listing resources
+const collectTimeout = 10 * time.Second
+
+// Values of the role label of the machine metric
+const (
+	RoleControlPlane = "controlplane"
+	RoleWorker       = "worker"
+	RoleUnknown      = "unknown"
+)
+
+var (
+	machinesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "machines"),
+		"Number of TalosMachines by role and state.",
+		[]string{"namespace", "role", "state"}, nil,
+	)
+	talosVersionSkewDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "talos_version_skew"),
+		"Number of machines of a control plane and its workers that run a different Talos version than the control plane spec.",
+		[]string{"namespace", "controlplane"}, nil,
+	)
+	kubernetesVersionSkewDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "kubernetes_version_skew"),
+		"1 if the Kubernetes version of a control plane differs from its spec, 0 otherwise.",
+		[]string{"namespace", "controlplane"}, nil,
+	)
+	upgradeStartDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "upgrade_start_timestamp_seconds"),
+		"Start time of an upgrade that is in progress, as a Unix timestamp.",
+		[]string{"type", "namespace", "name"}, nil,
+	)
+	lastSuccessfulBackupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "etcd_backup_schedule_last_success_timestamp_seconds"),
+		"Time of the last successful backup of a TalosEtcdBackupSchedule, as a Unix timestamp.",
+		[]string{"namespace", "schedule"}, nil,
+	)
+)
+
+// Collector exposes metrics derived from the current state of the Talos resources. They are read
+// from the manager cache on every scrape, so they never drift from the objects.
+type Collector struct {
+	reader client.Reader
+}
+
+// NewCollector returns a Collector that reads the Talos resources through reader
+func NewCollector(reader client.Reader) *Collector {
+	return &Collector{reader: reader}
+}
+
+// RegisterCollector registers a Collector with the controller-runtime metrics registry
+func RegisterCollector(reader client.Reader) error {
+	return metrics.Registry.Register(NewCollector(reader))
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- machinesDesc
+	ch <- talosVersionSkewDesc
+	ch <- kubernetesVersionSkewDesc
+	ch <- upgradeStartDesc
+	ch <- lastSuccessfulBackupDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	logger := logf.Log.WithName("metrics")
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	var machines talosv1alpha1.TalosMachineList
+	if err := c.reader.List(ctx, &machines); err != nil {
+		logger.Error(err, "failed to list TalosMachines")
+		return
+	}
+	var controlPlanes talosv1alpha1.TalosControlPlaneList
+	if err := c.reader.List(ctx, &controlPlanes); err != nil {
+		logger.Error(err, "failed to list TalosControlPlanes")
+		return
+	}
+	var workers talosv1alpha1.TalosWorkerList
+	if err := c.reader.List(ctx, &workers); err != nil {
+		logger.Error(err, "failed to list TalosWorkers")
+		return
+	}
+	var schedules talosv1alpha1.TalosEtcdBackupScheduleList
+	if err := c.reader.List(ctx, &schedules); err != nil {
+		logger.Error(err, "failed to list TalosEtcdBackupSchedules")
+		return
+	}
+
+	collectMachines(ch, machines.Items)
+	collectVersionSkew(ch, controlPlanes.Items, workers.Items, machines.Items)
+	collectUpgrades(ch, controlPlanes.Items, machines.Items)
+	for _, schedule := range schedules.Items {
+		if schedule.Status.LastSuccessfulBackupTime == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(lastSuccessfulBackupDesc, prometheus.GaugeValue,
+			float64(schedule.Status.LastSuccessfulBackupTime.Unix()), schedule.Namespace, schedule.Name)
+	}
+}
+
+type machineKey struct {
+	namespace, role, state string
+}
+
+func collectMachines(ch chan<- prometheus.Metric, machines []talosv1alpha1.TalosMachine) {
+	counts := map[machineKey]int{}
+	for _, tm := range machines {
+		state := tm.Status.State
+		if state == "" {
+			state = "Unknown"
+		}
+		counts[machineKey{namespace: tm.Namespace, role: machineRole(&tm), state: state}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(machinesDesc, prometheus.GaugeValue, float64(count), key.namespace, key.role, key.state)
+	}
+}
+
+func machineRole(tm *talosv1alpha1.TalosMachine) string {
+	switch {
+	case tm.Spec.ControlPlaneRef != nil:
+		return RoleControlPlane
+	case tm.Spec.WorkerRef != nil:
+		return RoleWorker
+	default:
+		return RoleUnknown
+	}
+}
+
+// collectVersionSkew compares the versions the machines run with the spec of the control plane they
+// belong to. Worker machines belong to the control plane their TalosWorker references.
+func collectVersionSkew(ch chan<- prometheus.Metric, controlPlanes []talosv1alpha1.TalosControlPlane, workers []talosv1alpha1.TalosWorker, machines []talosv1alpha1.TalosMachine) {
+	type objectKey struct{ namespace, name string }
+	workerControlPlane := map[objectKey]string{}
+	for _, tw := range workers {
+		workerControlPlane[objectKey{tw.Namespace, tw.Name}] = tw.Spec.ControlPlaneRef.Name
+	}
+	versions := map[objectKey]string{}
+	skew := map[objectKey]int{}
+	for _, tcp := range controlPlanes {
+		key := objectKey{tcp.Namespace, tcp.Name}
+		versions[key] = tcp.Spec.Version
+		skew[key] = 0
+	}
+	for _, tm := range machines {
+		if tm.Status.ObservedVersion == "" {
+			continue
+		}
+		var controlPlane string
+		switch {
+		case tm.Spec.ControlPlaneRef != nil:
+			controlPlane = tm.Spec.ControlPlaneRef.Name
+		case tm.Spec.WorkerRef != nil:
+			controlPlane = workerControlPlane[objectKey{tm.Namespace, tm.Spec.WorkerRef.Name}]
+		}
+		key := objectKey{tm.Namespace, controlPlane}
+		if version, ok := versions[key]; ok && version != tm.Status.ObservedVersion {
+			skew[key]++
+		}
+	}
+	for key, count := range skew {
+		ch <- prometheus.MustNewConstMetric(talosVersionSkewDesc, prometheus.GaugeValue, float64(count), key.namespace, key.name)
+	}
+	for _, tcp := range controlPlanes {
+		var kubeSkew float64
+		if tcp.Status.ObservedKubeVersion != "" && tcp.Status.ObservedKubeVersion != tcp.Spec.KubeVersion {
+			kubeSkew = 1
+		}
+		ch <- prometheus.MustNewConstMetric(kubernetesVersionSkewDesc, prometheus.GaugeValue, kubeSkew, tcp.Namespace, tcp.Name)
+	}
+}
+
+// collectUpgrades exposes the start time of Talos upgrades of machines and of Kubernetes upgrades
+// of control planes that are in progress.
+func collectUpgrades(ch chan<- prometheus.Metric, controlPlanes []talosv1alpha1.TalosControlPlane, machines []talosv1alpha1.TalosMachine) {
+	for _, tm := range machines {
+		if tm.Status.State != talosv1alpha1.StateUpgrading || tm.Status.UpgradeStartTime == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(upgradeStartDesc, prometheus.GaugeValue,
+			float64(tm.Status.UpgradeStartTime.Unix()), UpgradeTypeTalos, tm.Namespace, tm.Name)
+	}
+	for _, tcp := range controlPlanes {
+		condition := meta.FindStatusCondition(tcp.Status.Conditions, talosv1alpha1.ConditionKubernetesUpgradeInProgress)
+		if condition == nil || condition.Status != metav1.ConditionTrue {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(upgradeStartDesc, prometheus.GaugeValue,
+			float64(condition.LastTransitionTime.Unix()), UpgradeTypeKubernetes, tcp.Namespace, tcp.Name)
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newCollectorTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := talosv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newMachine(name, state, version string, cpRef, workerRef string) *talosv1alpha1.TalosMachine {
+	tm := &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+	if cpRef != "" {
+		tm.Spec.ControlPlaneRef = &corev1.ObjectReference{Name: cpRef}
+	}
+	if workerRef != "" {
+		tm.Spec.WorkerRef = &corev1.ObjectReference{Name: workerRef}
+	}
+	tm.Status.State = state
+	tm.Status.ObservedVersion = version
+	return tm
+}
+
+func TestCollector(t *testing.T) {
+	upgradeStart := metav1.NewTime(time.Unix(1700000000, 0))
+	lastBackup := metav1.NewTime(time.Unix(1700000100, 0))
+
+	tcp := &talosv1alpha1.TalosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "cp", Namespace: "default"},
+		Spec:       talosv1alpha1.TalosControlPlaneSpec{Version: "v1.13.0", KubeVersion: "v1.35.0"},
+	}
+	tcp.Status.ObservedKubeVersion = "v1.34.0"
+	tcp.Status.Conditions = []metav1.Condition{{
+		Type:               talosv1alpha1.ConditionKubernetesUpgradeInProgress,
+		Status:             metav1.ConditionTrue,
+		Reason:             "UpgradeStarted",
+		LastTransitionTime: upgradeStart,
+	}}
+	tw := &talosv1alpha1.TalosWorker{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+		Spec:       talosv1alpha1.TalosWorkerSpec{ControlPlaneRef: corev1.LocalObjectReference{Name: "cp"}},
+	}
+	upgrading := newMachine("cp-1", talosv1alpha1.StateUpgrading, "v1.13.0", "cp", "")
+	upgrading.Status.UpgradeStartTime = &upgradeStart
+	schedule := &talosv1alpha1.TalosEtcdBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+	}
+	schedule.Status.LastSuccessfulBackupTime = &lastBackup
+
+	c := newCollectorTestClient(t, tcp, tw, schedule,
+		upgrading,
+		newMachine("cp-2", talosv1alpha1.StateAvailable, "v1.12.0", "cp", ""),
+		newMachine("worker-1", talosv1alpha1.StateAvailable, "v1.12.0", "", "workers"),
+		newMachine("worker-2", "", "", "", "workers"),
+	)
+
+	expected := `
+# HELP talos_operator_etcd_backup_schedule_last_success_timestamp_seconds Time of the last successful backup of a TalosEtcdBackupSchedule, as a Unix timestamp.
+# TYPE talos_operator_etcd_backup_schedule_last_success_timestamp_seconds gauge
+talos_operator_etcd_backup_schedule_last_success_timestamp_seconds{namespace="default",schedule="nightly"} 1.7000001e+09
+# HELP talos_operator_kubernetes_version_skew 1 if the Kubernetes version of a control plane differs from its spec, 0 otherwise.
+# TYPE talos_operator_kubernetes_version_skew gauge
+talos_operator_kubernetes_version_skew{controlplane="cp",namespace="default"} 1
+# HELP talos_operator_machines Number of TalosMachines by role and state.
+# TYPE talos_operator_machines gauge
+talos_operator_machines{namespace="default",role="controlplane",state="Available"} 1
+talos_operator_machines{namespace="default",role="controlplane",state="Upgrading"} 1
+talos_operator_machines{namespace="default",role="worker",state="Available"} 1
+talos_operator_machines{namespace="default",role="worker",state="Unknown"} 1
+# HELP talos_operator_talos_version_skew Number of machines of a control plane and its workers that run a different Talos version than the control plane spec.
+# TYPE talos_operator_talos_version_skew gauge
+talos_operator_talos_version_skew{controlplane="cp",namespace="default"} 2
+# HELP talos_operator_upgrade_start_timestamp_seconds Start time of an upgrade that is in progress, as a Unix timestamp.
+# TYPE talos_operator_upgrade_start_timestamp_seconds gauge
+talos_operator_upgrade_start_timestamp_seconds{name="cp",namespace="default",type="kubernetes"} 1.7e+09
+talos_operator_upgrade_start_timestamp_seconds{name="cp-1",namespace="default",type="talos"} 1.7e+09
+`
+	if err := testutil.CollectAndCompare(NewCollector(c), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_NoResources(t *testing.T) {
+	c := newCollectorTestClient(t)
+	if count := testutil.CollectAndCount(NewCollector(c)); count != 0 {
+		t.Errorf("expected no metrics without resources, got %d", count)
+	}
+}
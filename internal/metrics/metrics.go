@@ -0,0 +1,121 @@
+// Package metrics defines the Prometheus metrics of the operator. They are registered with the
+// controller-runtime registry and served on the manager metrics endpoint.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "talos_operator"
+
+// Values of the type label of the upgrade metrics
+const (
+	UpgradeTypeTalos      = "talos"
+	UpgradeTypeKubernetes = "kubernetes"
+)
+
+// Values of the status label of the addon release metric when the release could not be read from Helm
+const (
+	AddonReleaseStatusFailed  = "failed"
+	AddonReleaseStatusUnknown = "unknown"
+)
+
+// Values of the result label of the backup metrics
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	upgradeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "upgrade_duration_seconds",
+		Help:      "Duration of completed Talos upgrades of a machine and Kubernetes upgrades of a control plane.",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{"type"})
+	upgradeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "upgrade_failures_total",
+		Help:      "Number of failed Talos and Kubernetes upgrades.",
+	}, []string{"type", "namespace", "name"})
+	bootstrapDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "bootstrap_duration_seconds",
+		Help:      "Time from the creation of a control plane until it was bootstrapped.",
+		Buckets:   prometheus.ExponentialBuckets(60, 2, 8),
+	})
+	etcdBackups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "etcd_backups_total",
+		Help:      "Number of etcd backup attempts by result.",
+	}, []string{"namespace", "controlplane", "result"})
+	etcdBackupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "etcd_backup_duration_seconds",
+		Help:      "Duration of successful etcd backups, from the snapshot until the upload completed.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"namespace", "controlplane"})
+	etcdBackupSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "etcd_backup_size_bytes",
+		Help:      "Size of the most recent successful etcd snapshot.",
+	}, []string{"namespace", "controlplane"})
+	addonReleaseStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "addon_release_status",
+		Help:      "Status of the Helm release of a TalosClusterAddonRelease. The series of the current status is 1.",
+	}, []string{"namespace", "name", "status"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		upgradeDuration,
+		upgradeFailures,
+		bootstrapDuration,
+		etcdBackups,
+		etcdBackupDuration,
+		etcdBackupSize,
+		addonReleaseStatus,
+	)
+}
+
+// ObserveUpgrade records the duration of a completed upgrade
+func ObserveUpgrade(upgradeType string, duration time.Duration) {
+	upgradeDuration.WithLabelValues(upgradeType).Observe(duration.Seconds())
+}
+
+// RecordUpgradeFailure counts a failed upgrade of the named object
+func RecordUpgradeFailure(upgradeType, namespace, name string) {
+	upgradeFailures.WithLabelValues(upgradeType, namespace, name).Inc()
+}
+
+// ObserveBootstrap records how long it took to bootstrap a control plane
+func ObserveBootstrap(duration time.Duration) {
+	bootstrapDuration.Observe(duration.Seconds())
+}
+
+// RecordEtcdBackupSuccess counts a successful etcd backup and records its duration and size
+func RecordEtcdBackupSuccess(namespace, controlPlane string, duration time.Duration, size int64) {
+	etcdBackups.WithLabelValues(namespace, controlPlane, ResultSuccess).Inc()
+	etcdBackupDuration.WithLabelValues(namespace, controlPlane).Observe(duration.Seconds())
+	etcdBackupSize.WithLabelValues(namespace, controlPlane).Set(float64(size))
+}
+
+// RecordEtcdBackupFailure counts a failed etcd backup attempt
+func RecordEtcdBackupFailure(namespace, controlPlane string) {
+	etcdBackups.WithLabelValues(namespace, controlPlane, ResultFailure).Inc()
+}
+
+// SetAddonReleaseStatus records the Helm release status of a TalosClusterAddonRelease
+func SetAddonReleaseStatus(namespace, name, status string) {
+	addonReleaseStatus.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
+	addonReleaseStatus.WithLabelValues(namespace, name, status).Set(1)
+}
+
+// DeleteAddonReleaseStatus removes the status series of a deleted TalosClusterAddonRelease
+func DeleteAddonReleaseStatus(namespace, name string) {
+	addonReleaseStatus.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
+}
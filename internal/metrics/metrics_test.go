@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSetAddonReleaseStatus(t *testing.T) {
+	SetAddonReleaseStatus("default", "cilium", "pending-install")
+	SetAddonReleaseStatus("default", "cilium", "deployed")
+	SetAddonReleaseStatus("default", "metrics-server", AddonReleaseStatusFailed)
+
+	if got := testutil.ToFloat64(addonReleaseStatus.WithLabelValues("default", "cilium", "deployed")); got != 1 {
+		t.Errorf("expected the current status to be 1, got %v", got)
+	}
+	if count := testutil.CollectAndCount(addonReleaseStatus); count != 2 {
+		t.Errorf("expected one series per release, got %d", count)
+	}
+
+	DeleteAddonReleaseStatus("default", "cilium")
+	if count := testutil.CollectAndCount(addonReleaseStatus); count != 1 {
+		t.Errorf("expected the series of the deleted release to be removed, got %d", count)
+	}
+	DeleteAddonReleaseStatus("default", "metrics-server")
+}
+
+func TestRecordEtcdBackup(t *testing.T) {
+	RecordEtcdBackupSuccess("default", "cp", 3*time.Second, 2048)
+	RecordEtcdBackupFailure("default", "cp")
+	RecordEtcdBackupFailure("default", "cp")
+
+	if got := testutil.ToFloat64(etcdBackups.WithLabelValues("default", "cp", ResultSuccess)); got != 1 {
+		t.Errorf("expected one successful backup, got %v", got)
+	}
+	if got := testutil.ToFloat64(etcdBackups.WithLabelValues("default", "cp", ResultFailure)); got != 2 {
+		t.Errorf("expected two failed backups, got %v", got)
+	}
+	if got := testutil.ToFloat64(etcdBackupSize.WithLabelValues("default", "cp")); got != 2048 {
+		t.Errorf("unexpected backup size %v", got)
+	}
+}
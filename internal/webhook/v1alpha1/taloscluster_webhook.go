@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var talosclusterlog = logf.Log.WithName("taloscluster-resource")
+
+// SetupTalosClusterWebhookWithManager registers the webhook for TalosCluster in the manager.
+func SetupTalosClusterWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &talosv1alpha1.TalosCluster{}).
+		WithValidator(&TalosClusterCustomValidator{}).
+		WithDefaulter(&TalosClusterCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-talos-alperen-cloud-v1alpha1-taloscluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosclusters,verbs=create;update,versions=v1alpha1,name=mtaloscluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosClusterCustomDefaulter sets default values on the TalosCluster resource
+// when it is created or updated.
+type TalosClusterCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the Kind TalosCluster.
+func (d *TalosClusterCustomDefaulter) Default(_ context.Context, tc *talosv1alpha1.TalosCluster) error {
+	talosclusterlog.Info("Defaulting for TalosCluster", "name", tc.GetName())
+	if tc.Spec.ControlPlane != nil {
+		defaultControlPlaneSpec(tc.Spec.ControlPlane)
+	}
+	if tc.Spec.Worker != nil {
+		defaultWorkerSpec(tc.Spec.Worker)
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-talos-alperen-cloud-v1alpha1-taloscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosclusters,verbs=create;update;delete,versions=v1alpha1,name=vtaloscluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosClusterCustomValidator validates the TalosCluster resource when it is created or updated.
+type TalosClusterCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TalosCluster.
+func (v *TalosClusterCustomValidator) ValidateCreate(_ context.Context, tc *talosv1alpha1.TalosCluster) (admission.Warnings, error) {
+	talosclusterlog.Info("Validation for TalosCluster upon creation", "name", tc.GetName())
+	return nil, toInvalidError("TalosCluster", tc.Name, validateClusterSpec(field.NewPath("spec"), &tc.Spec))
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TalosCluster.
+func (v *TalosClusterCustomValidator) ValidateUpdate(_ context.Context, oldTc, tc *talosv1alpha1.TalosCluster) (admission.Warnings, error) {
+	talosclusterlog.Info("Validation for TalosCluster upon update", "name", tc.GetName())
+	if isDeleting(tc) {
+		return nil, nil
+	}
+	path := field.NewPath("spec")
+	allErrs := validateClusterSpec(path, &tc.Spec)
+	var warnings admission.Warnings
+	if skipUpgradeChecks(tc) {
+		return warnings, toInvalidError("TalosCluster", tc.Name, allErrs)
+	}
+	if oldTc.Spec.ControlPlane != nil && tc.Spec.ControlPlane != nil {
+		upgradeWarnings, upgradeErrs := validateControlPlaneUpgrade(path.Child("controlPlane"), oldTc.Spec.ControlPlane, tc.Spec.ControlPlane, oldTc.Spec.ControlPlane.KubeVersion)
+		warnings = append(warnings, upgradeWarnings...)
+		allErrs = append(allErrs, upgradeErrs...)
+	}
+	if oldTc.Spec.Worker != nil && tc.Spec.Worker != nil {
+		upgradeWarnings, upgradeErrs := validateWorkerUpgrade(path.Child("worker"), oldTc.Spec.Worker, tc.Spec.Worker)
+		warnings = append(warnings, upgradeWarnings...)
+		allErrs = append(allErrs, upgradeErrs...)
+	}
+	return warnings, toInvalidError("TalosCluster", tc.Name, allErrs)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TalosCluster.
+func (v *TalosClusterCustomValidator) ValidateDelete(_ context.Context, tc *talosv1alpha1.TalosCluster) (admission.Warnings, error) {
+	talosclusterlog.Info("Validation for TalosCluster upon deletion", "name", tc.GetName())
+	return nil, validateDeletionProtection("talosclusters", tc)
+}
+
+// validateClusterSpec validates the inline control plane and worker specs of a TalosCluster
+func validateClusterSpec(path *field.Path, spec *talosv1alpha1.TalosClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.ControlPlane != nil {
+		allErrs = append(allErrs, validateControlPlaneSpec(path.Child("controlPlane"), spec.ControlPlane)...)
+	}
+	if spec.Worker != nil {
+		allErrs = append(allErrs, validateWorkerSpec(path.Child("worker"), spec.Worker)...)
+	}
+	return allErrs
+}
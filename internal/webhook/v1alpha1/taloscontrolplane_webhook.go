@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var taloscontrolplanelog = logf.Log.WithName("taloscontrolplane-resource")
+
+// SetupTalosControlPlaneWebhookWithManager registers the webhook for TalosControlPlane in the manager.
+func SetupTalosControlPlaneWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &talosv1alpha1.TalosControlPlane{}).
+		WithValidator(&TalosControlPlaneCustomValidator{}).
+		WithDefaulter(&TalosControlPlaneCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-talos-alperen-cloud-v1alpha1-taloscontrolplane,mutating=true,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=create;update,versions=v1alpha1,name=mtaloscontrolplane-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosControlPlaneCustomDefaulter sets default values on the TalosControlPlane resource
+// when it is created or updated.
+type TalosControlPlaneCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the Kind TalosControlPlane.
+func (d *TalosControlPlaneCustomDefaulter) Default(_ context.Context, tcp *talosv1alpha1.TalosControlPlane) error {
+	taloscontrolplanelog.Info("Defaulting for TalosControlPlane", "name", tcp.GetName())
+	defaultControlPlaneSpec(&tcp.Spec)
+	return nil
+}
+
+// defaultControlPlaneSpec sets the defaults of a control plane spec. It is shared with the TalosCluster
+// webhook which embeds the spec.
+func defaultControlPlaneSpec(spec *talosv1alpha1.TalosControlPlaneSpec) {
+	if spec.Mode == modeContainer && spec.Replicas == 0 {
+		spec.Replicas = 1
+	}
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = deletionPolicyReset
+	}
+	if spec.CNI != nil && spec.CNI.Name == "" {
+		spec.CNI.Name = cniFlannel
+	}
+	if spec.Mode == modeMetal {
+		spec.RolloutStrategy = defaultRolloutStrategy(spec.RolloutStrategy)
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-talos-alperen-cloud-v1alpha1-taloscontrolplane,mutating=false,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=taloscontrolplanes,verbs=create;update;delete,versions=v1alpha1,name=vtaloscontrolplane-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosControlPlaneCustomValidator validates the TalosControlPlane resource when it is created or updated.
+type TalosControlPlaneCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TalosControlPlane.
+func (v *TalosControlPlaneCustomValidator) ValidateCreate(_ context.Context, tcp *talosv1alpha1.TalosControlPlane) (admission.Warnings, error) {
+	taloscontrolplanelog.Info("Validation for TalosControlPlane upon creation", "name", tcp.GetName())
+	allErrs := validateControlPlaneSpec(field.NewPath("spec"), &tcp.Spec)
+	return nil, toInvalidError("TalosControlPlane", tcp.Name, allErrs)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TalosControlPlane.
+func (v *TalosControlPlaneCustomValidator) ValidateUpdate(_ context.Context, oldTcp, tcp *talosv1alpha1.TalosControlPlane) (admission.Warnings, error) {
+	taloscontrolplanelog.Info("Validation for TalosControlPlane upon update", "name", tcp.GetName())
+	if isDeleting(tcp) {
+		return nil, nil
+	}
+	allErrs := validateControlPlaneSpec(field.NewPath("spec"), &tcp.Spec)
+	var warnings admission.Warnings
+	if !skipUpgradeChecks(tcp) {
+		// Compare against the Kubernetes version the cluster runs so a failed upgrade can be retried
+		currentKubeVersion := tcp.Status.ObservedKubeVersion
+		if currentKubeVersion == "" {
+			currentKubeVersion = oldTcp.Spec.KubeVersion
+		}
+		upgradeWarnings, upgradeErrs := validateControlPlaneUpgrade(field.NewPath("spec"), &oldTcp.Spec, &tcp.Spec, currentKubeVersion)
+		warnings = append(warnings, upgradeWarnings...)
+		allErrs = append(allErrs, upgradeErrs...)
+	}
+	return warnings, toInvalidError("TalosControlPlane", tcp.Name, allErrs)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TalosControlPlane.
+func (v *TalosControlPlaneCustomValidator) ValidateDelete(_ context.Context, tcp *talosv1alpha1.TalosControlPlane) (admission.Warnings, error) {
+	taloscontrolplanelog.Info("Validation for TalosControlPlane upon deletion", "name", tcp.GetName())
+	return nil, validateDeletionProtection("taloscontrolplanes", tcp)
+}
+
+// validateControlPlaneSpec validates a control plane spec. It is shared with the TalosCluster webhook.
+func validateControlPlaneSpec(path *field.Path, spec *talosv1alpha1.TalosControlPlaneSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if err := validateVersion(path.Child("version"), spec.Version); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateVersion(path.Child("kubeVersion"), spec.KubeVersion); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	switch spec.Mode {
+	case modeMetal:
+		if spec.Replicas != 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("replicas"), "replicas is only supported when mode is 'container', the machines define the control plane size when mode is 'metal'"))
+		}
+		allErrs = append(allErrs, validateMetalSpec(path.Child("metalSpec"), &spec.MetalSpec)...)
+		allErrs = append(allErrs, validateRolloutStrategy(path.Child("rolloutStrategy"), spec.RolloutStrategy)...)
+	case modeContainer:
+		if spec.Replicas < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("replicas"), spec.Replicas, "must be at least 1 when mode is 'container'"))
+		}
+		if len(spec.MetalSpec.Machines) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("metalSpec", "machines"), "machines are only supported when mode is 'metal'"))
+		}
+		if spec.MetalSpec.HostSelector != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("metalSpec", "hostSelector"), "hostSelector is only supported when mode is 'metal'"))
+		}
+	}
+	allErrs = append(allErrs, validateCNI(path.Child("cni"), spec.CNI)...)
+	allErrs = append(allErrs, validateMaintenanceWindows(path.Child("maintenanceWindows"), spec.MaintenanceWindows)...)
+	return allErrs
+}
+
+// validateControlPlaneUpgrade applies the upgrade path rules to a control plane spec update
+func validateControlPlaneUpgrade(path *field.Path, oldSpec, spec *talosv1alpha1.TalosControlPlaneSpec, currentKubeVersion string) (admission.Warnings, field.ErrorList) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	talosWarnings, err := validateTalosUpgrade(path.Child("version"), oldSpec.Version, spec.Version)
+	warnings = append(warnings, talosWarnings...)
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if oldSpec.KubeVersion != spec.KubeVersion {
+		kubeWarnings, err := validateKubernetesUpgrade(path.Child("kubeVersion"), currentKubeVersion, spec.KubeVersion)
+		warnings = append(warnings, kubeWarnings...)
+		if err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	// Per-machine pins must not be downgraded either
+	oldMachineVersions := make(map[string]string, len(oldSpec.MetalSpec.Machines))
+	for _, machine := range oldSpec.MetalSpec.Machines {
+		if machine.Address != nil {
+			oldMachineVersions[*machine.Address] = machine.Version
+		}
+	}
+	for i, machine := range spec.MetalSpec.Machines {
+		if machine.Address == nil || machine.Version == "" {
+			continue
+		}
+		machineWarnings, err := validateTalosUpgrade(path.Child("metalSpec", "machines").Index(i).Child("version"), oldMachineVersions[*machine.Address], machine.Version)
+		warnings = append(warnings, machineWarnings...)
+		if err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return warnings, allErrs
+}
+
+// validateCNI checks that the CNI manifests and options match the selected CNI
+func validateCNI(path *field.Path, cni *talosv1alpha1.CNIConfig) field.ErrorList {
+	if cni == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	switch cni.Name {
+	case cniCustom:
+		if len(cni.URLs) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("urls"), "urls are required when name is 'custom'"))
+		}
+	case cniFlannel, cniNone, "":
+		if len(cni.URLs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("urls"), "urls are only supported when name is 'custom'"))
+		}
+	}
+	if cni.Flannel != nil && cni.Name != cniFlannel && cni.Name != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child("flannel"), "flannel options are only supported when name is 'flannel'"))
+	}
+	return allErrs
+}
+
+// toInvalidError converts a list of field errors into the Invalid error returned by the webhook
+func toInvalidError(kind, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(talosv1alpha1.GroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}
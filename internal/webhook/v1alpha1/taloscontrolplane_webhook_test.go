@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newTestControlPlane(mode string) *talosv1alpha1.TalosControlPlane {
+	tcp := &talosv1alpha1.TalosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tcp", Namespace: "default"},
+		Spec: talosv1alpha1.TalosControlPlaneSpec{
+			Mode:        mode,
+			Version:     "v1.12.0",
+			KubeVersion: "v1.33.0",
+		},
+	}
+	if mode == modeMetal {
+		tcp.Spec.MetalSpec.Machines = []talosv1alpha1.Machine{{Address: ptr.To("10.0.0.1")}}
+	} else {
+		tcp.Spec.Replicas = 1
+	}
+	return tcp
+}
+
+func TestTalosControlPlaneDefault(t *testing.T) {
+	tcp := newTestControlPlane(modeMetal)
+	tcp.Spec.CNI = &talosv1alpha1.CNIConfig{}
+	if err := (&TalosControlPlaneCustomDefaulter{}).Default(context.Background(), tcp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tcp.Spec.DeletionPolicy != deletionPolicyReset {
+		t.Errorf("expected deletionPolicy %q, got %q", deletionPolicyReset, tcp.Spec.DeletionPolicy)
+	}
+	if tcp.Spec.CNI.Name != cniFlannel {
+		t.Errorf("expected cni name %q, got %q", cniFlannel, tcp.Spec.CNI.Name)
+	}
+	if tcp.Spec.RolloutStrategy == nil || tcp.Spec.RolloutStrategy.Type != talosv1alpha1.RollingUpdateStrategyType {
+		t.Errorf("expected a RollingUpdate rollout strategy, got %+v", tcp.Spec.RolloutStrategy)
+	}
+
+	container := newTestControlPlane(modeContainer)
+	container.Spec.Replicas = 0
+	if err := (&TalosControlPlaneCustomDefaulter{}).Default(context.Background(), container); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if container.Spec.Replicas != 1 {
+		t.Errorf("expected replicas to default to 1, got %d", container.Spec.Replicas)
+	}
+	if container.Spec.RolloutStrategy != nil {
+		t.Errorf("expected no rollout strategy in container mode, got %+v", container.Spec.RolloutStrategy)
+	}
+}
+
+func TestTalosControlPlaneValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(tcp *talosv1alpha1.TalosControlPlane)
+		mode    string
+		wantErr bool
+	}{
+		{name: "valid metal", mode: modeMetal},
+		{name: "valid container", mode: modeContainer},
+		{
+			name:    "metal without machines",
+			mode:    modeMetal,
+			mutate:  func(tcp *talosv1alpha1.TalosControlPlane) { tcp.Spec.MetalSpec.Machines = nil },
+			wantErr: true,
+		},
+		{
+			name:    "metal with replicas",
+			mode:    modeMetal,
+			mutate:  func(tcp *talosv1alpha1.TalosControlPlane) { tcp.Spec.Replicas = 3 },
+			wantErr: true,
+		},
+		{
+			name:    "container with machines",
+			mode:    modeContainer,
+			mutate:  func(tcp *talosv1alpha1.TalosControlPlane) { tcp.Spec.MetalSpec.Machines = []talosv1alpha1.Machine{{}} },
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			mode:    modeContainer,
+			mutate:  func(tcp *talosv1alpha1.TalosControlPlane) { tcp.Spec.Version = "1.12" },
+			wantErr: true,
+		},
+		{
+			name: "flannel with urls",
+			mode: modeContainer,
+			mutate: func(tcp *talosv1alpha1.TalosControlPlane) {
+				tcp.Spec.CNI = &talosv1alpha1.CNIConfig{Name: cniFlannel, URLs: []string{"https://example.com/cni.yaml"}}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "custom cni without urls",
+			mode:    modeContainer,
+			mutate:  func(tcp *talosv1alpha1.TalosControlPlane) { tcp.Spec.CNI = &talosv1alpha1.CNIConfig{Name: cniCustom} },
+			wantErr: true,
+		},
+		{
+			name: "custom cni with urls",
+			mode: modeContainer,
+			mutate: func(tcp *talosv1alpha1.TalosControlPlane) {
+				tcp.Spec.CNI = &talosv1alpha1.CNIConfig{Name: cniCustom, URLs: []string{"https://example.com/cni.yaml"}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcp := newTestControlPlane(tt.mode)
+			if tt.mutate != nil {
+				tt.mutate(tcp)
+			}
+			_, err := (&TalosControlPlaneCustomValidator{}).ValidateCreate(context.Background(), tcp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("expected an Invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTalosControlPlaneValidateUpdate(t *testing.T) {
+	validator := &TalosControlPlaneCustomValidator{}
+	oldTcp := newTestControlPlane(modeMetal)
+
+	// Kubernetes upgrades must not skip minor versions
+	tcp := oldTcp.DeepCopy()
+	tcp.Spec.KubeVersion = "v1.35.0"
+	if _, err := validator.ValidateUpdate(context.Background(), oldTcp, tcp); err == nil {
+		t.Error("expected an error for a Kubernetes upgrade that skips a minor version")
+	}
+
+	// The annotation disables the upgrade path checks
+	tcp.Annotations = map[string]string{talosv1alpha1.SkipUpgradeChecksAnnotation: "true"}
+	if _, err := validator.ValidateUpdate(context.Background(), oldTcp, tcp); err != nil {
+		t.Errorf("expected the skip annotation to allow the upgrade, got %v", err)
+	}
+
+	// A failed upgrade can be retried with a lower version than the one in the spec
+	failedTcp := oldTcp.DeepCopy()
+	failedTcp.Spec.KubeVersion = "v1.34.0"
+	failedTcp.Status.ObservedKubeVersion = "v1.33.0"
+	retryTcp := failedTcp.DeepCopy()
+	retryTcp.Spec.KubeVersion = "v1.33.2"
+	warnings, err := validator.ValidateUpdate(context.Background(), failedTcp, retryTcp)
+	if err != nil {
+		t.Errorf("expected the retry to be allowed, got %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings for the retry, got %v", warnings)
+	}
+
+	// Talos downgrades are rejected, also for pinned machine versions
+	tcp = oldTcp.DeepCopy()
+	tcp.Spec.Version = "v1.11.0"
+	if _, err := validator.ValidateUpdate(context.Background(), oldTcp, tcp); err == nil {
+		t.Error("expected an error for a Talos downgrade")
+	}
+	pinnedTcp := oldTcp.DeepCopy()
+	pinnedTcp.Spec.MetalSpec.Machines[0].Version = "v1.12.0"
+	tcp = pinnedTcp.DeepCopy()
+	tcp.Spec.MetalSpec.Machines[0].Version = "v1.11.5"
+	if _, err := validator.ValidateUpdate(context.Background(), pinnedTcp, tcp); err == nil {
+		t.Error("expected an error for a pinned machine downgrade")
+	}
+
+	// Objects being deleted are not validated
+	tcp = oldTcp.DeepCopy()
+	tcp.Spec.MetalSpec.Machines = nil
+	tcp.DeletionTimestamp = ptr.To(metav1.Now())
+	if _, err := validator.ValidateUpdate(context.Background(), oldTcp, tcp); err != nil {
+		t.Errorf("expected updates of a deleting object to be allowed, got %v", err)
+	}
+}
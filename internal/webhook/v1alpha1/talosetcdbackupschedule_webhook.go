@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// defaultBackupRetention mirrors the default of the retention field
+const defaultBackupRetention = 5
+
+// log is for logging in this package.
+var talosetcdbackupschedulelog = logf.Log.WithName("talosetcdbackupschedule-resource")
+
+// SetupTalosEtcdBackupScheduleWebhookWithManager registers the webhook for TalosEtcdBackupSchedule in the manager.
+func SetupTalosEtcdBackupScheduleWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &talosv1alpha1.TalosEtcdBackupSchedule{}).
+		WithValidator(&TalosEtcdBackupScheduleCustomValidator{}).
+		WithDefaulter(&TalosEtcdBackupScheduleCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-talos-alperen-cloud-v1alpha1-talosetcdbackupschedule,mutating=true,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosetcdbackupschedules,verbs=create;update,versions=v1alpha1,name=mtalosetcdbackupschedule-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosEtcdBackupScheduleCustomDefaulter sets default values on the TalosEtcdBackupSchedule resource
+// when it is created or updated.
+type TalosEtcdBackupScheduleCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the Kind TalosEtcdBackupSchedule.
+func (d *TalosEtcdBackupScheduleCustomDefaulter) Default(_ context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule) error {
+	talosetcdbackupschedulelog.Info("Defaulting for TalosEtcdBackupSchedule", "name", schedule.GetName())
+	if schedule.Spec.ConcurrencyPolicy == "" {
+		schedule.Spec.ConcurrencyPolicy = talosv1alpha1.AllowConcurrent
+	}
+	// Keep the legacy default unless a retention policy replaces it
+	if schedule.Spec.Retention == nil && schedule.Spec.RetentionPolicy == nil {
+		schedule.Spec.Retention = ptr.To[int32](defaultBackupRetention)
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-talos-alperen-cloud-v1alpha1-talosetcdbackupschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosetcdbackupschedules,verbs=create;update,versions=v1alpha1,name=vtalosetcdbackupschedule-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosEtcdBackupScheduleCustomValidator validates the TalosEtcdBackupSchedule resource when it is created or updated.
+type TalosEtcdBackupScheduleCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TalosEtcdBackupSchedule.
+func (v *TalosEtcdBackupScheduleCustomValidator) ValidateCreate(_ context.Context, schedule *talosv1alpha1.TalosEtcdBackupSchedule) (admission.Warnings, error) {
+	talosetcdbackupschedulelog.Info("Validation for TalosEtcdBackupSchedule upon creation", "name", schedule.GetName())
+	return validateBackupSchedule(schedule)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TalosEtcdBackupSchedule.
+func (v *TalosEtcdBackupScheduleCustomValidator) ValidateUpdate(_ context.Context, _, schedule *talosv1alpha1.TalosEtcdBackupSchedule) (admission.Warnings, error) {
+	talosetcdbackupschedulelog.Info("Validation for TalosEtcdBackupSchedule upon update", "name", schedule.GetName())
+	if isDeleting(schedule) {
+		return nil, nil
+	}
+	return validateBackupSchedule(schedule)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TalosEtcdBackupSchedule.
+func (v *TalosEtcdBackupScheduleCustomValidator) ValidateDelete(_ context.Context, _ *talosv1alpha1.TalosEtcdBackupSchedule) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateBackupSchedule checks that the cron expression and the time zone can be parsed
+func validateBackupSchedule(schedule *talosv1alpha1.TalosEtcdBackupSchedule) (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	if _, err := cron.ParseStandard(schedule.Spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), schedule.Spec.Schedule, fmt.Sprintf("invalid cron expression: %v", err)))
+	}
+	if schedule.Spec.TimeZone != nil {
+		if _, err := time.LoadLocation(*schedule.Spec.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("timeZone"), *schedule.Spec.TimeZone, "unknown time zone"))
+		}
+		if strings.HasPrefix(schedule.Spec.Schedule, "TZ=") || strings.HasPrefix(schedule.Spec.Schedule, "CRON_TZ=") {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("schedule"), "a TZ or CRON_TZ prefix cannot be combined with timeZone"))
+		}
+	}
+	if deadline := schedule.Spec.StartingDeadlineSeconds; deadline != nil && *deadline < 10 {
+		warnings = append(warnings, "spec.startingDeadlineSeconds: values below 10 seconds may cause scheduled backups to be skipped")
+	}
+	return warnings, toInvalidError("TalosEtcdBackupSchedule", schedule.Name, allErrs)
+}
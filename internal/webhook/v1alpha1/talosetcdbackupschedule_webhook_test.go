@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newTestBackupSchedule(schedule string) *talosv1alpha1.TalosEtcdBackupSchedule {
+	return &talosv1alpha1.TalosEtcdBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-schedule", Namespace: "default"},
+		Spec: talosv1alpha1.TalosEtcdBackupScheduleSpec{
+			Schedule: schedule,
+		},
+	}
+}
+
+func TestTalosEtcdBackupScheduleDefault(t *testing.T) {
+	schedule := newTestBackupSchedule("0 * * * *")
+	if err := (&TalosEtcdBackupScheduleCustomDefaulter{}).Default(context.Background(), schedule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule.Spec.ConcurrencyPolicy != talosv1alpha1.AllowConcurrent {
+		t.Errorf("expected concurrencyPolicy %q, got %q", talosv1alpha1.AllowConcurrent, schedule.Spec.ConcurrencyPolicy)
+	}
+	if schedule.Spec.Retention == nil || *schedule.Spec.Retention != defaultBackupRetention {
+		t.Errorf("expected retention %d, got %v", defaultBackupRetention, schedule.Spec.Retention)
+	}
+}
+
+func TestTalosEtcdBackupScheduleValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    *talosv1alpha1.TalosEtcdBackupSchedule
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "valid", schedule: newTestBackupSchedule("0 */6 * * *")},
+		{name: "descriptor", schedule: newTestBackupSchedule("@daily")},
+		{name: "invalid cron", schedule: newTestBackupSchedule("every day"), wantErr: true},
+		{
+			name: "unknown time zone",
+			schedule: func() *talosv1alpha1.TalosEtcdBackupSchedule {
+				s := newTestBackupSchedule("0 2 * * *")
+				s.Spec.TimeZone = ptr.To("Mars/Olympus_Mons")
+				return s
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "time zone prefix with timeZone",
+			schedule: func() *talosv1alpha1.TalosEtcdBackupSchedule {
+				s := newTestBackupSchedule("CRON_TZ=UTC 0 2 * * *")
+				s.Spec.TimeZone = ptr.To("Europe/Istanbul")
+				return s
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "short starting deadline",
+			schedule: func() *talosv1alpha1.TalosEtcdBackupSchedule {
+				s := newTestBackupSchedule("0 2 * * *")
+				s.Spec.StartingDeadlineSeconds = ptr.To[int64](5)
+				return s
+			}(),
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := (&TalosEtcdBackupScheduleCustomValidator{}).ValidateCreate(context.Background(), tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, warnings)
+			}
+		})
+	}
+}
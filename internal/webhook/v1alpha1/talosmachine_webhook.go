@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var talosmachinelog = logf.Log.WithName("talosmachine-resource")
+
+// SetupTalosMachineWebhookWithManager registers the webhook for TalosMachine in the manager.
+func SetupTalosMachineWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &talosv1alpha1.TalosMachine{}).
+		WithValidator(&TalosMachineCustomValidator{}).
+		WithDefaulter(&TalosMachineCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-talos-alperen-cloud-v1alpha1-talosmachine,mutating=true,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosmachines,verbs=create;update,versions=v1alpha1,name=mtalosmachine-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosMachineCustomDefaulter sets default values on the TalosMachine resource
+// when it is created or updated.
+type TalosMachineCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the Kind TalosMachine.
+func (d *TalosMachineCustomDefaulter) Default(_ context.Context, tm *talosv1alpha1.TalosMachine) error {
+	talosmachinelog.Info("Defaulting for TalosMachine", "name", tm.GetName())
+	if tm.Spec.DeletionPolicy == "" {
+		tm.Spec.DeletionPolicy = deletionPolicyReset
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-talos-alperen-cloud-v1alpha1-talosmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosmachines,verbs=create;update;delete,versions=v1alpha1,name=vtalosmachine-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosMachineCustomValidator validates the TalosMachine resource when it is created or updated.
+type TalosMachineCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TalosMachine.
+func (v *TalosMachineCustomValidator) ValidateCreate(_ context.Context, tm *talosv1alpha1.TalosMachine) (admission.Warnings, error) {
+	talosmachinelog.Info("Validation for TalosMachine upon creation", "name", tm.GetName())
+	return nil, toInvalidError("TalosMachine", tm.Name, validateMachineSpec(field.NewPath("spec"), &tm.Spec))
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TalosMachine.
+func (v *TalosMachineCustomValidator) ValidateUpdate(_ context.Context, oldTm, tm *talosv1alpha1.TalosMachine) (admission.Warnings, error) {
+	talosmachinelog.Info("Validation for TalosMachine upon update", "name", tm.GetName())
+	if isDeleting(tm) {
+		return nil, nil
+	}
+	allErrs := validateMachineSpec(field.NewPath("spec"), &tm.Spec)
+	var warnings admission.Warnings
+	if !skipUpgradeChecks(tm) {
+		talosWarnings, err := validateTalosUpgrade(field.NewPath("spec", "version"), oldTm.Spec.Version, tm.Spec.Version)
+		warnings = append(warnings, talosWarnings...)
+		if err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return warnings, toInvalidError("TalosMachine", tm.Name, allErrs)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TalosMachine.
+func (v *TalosMachineCustomValidator) ValidateDelete(_ context.Context, tm *talosv1alpha1.TalosMachine) (admission.Warnings, error) {
+	talosmachinelog.Info("Validation for TalosMachine upon deletion", "name", tm.GetName())
+	return nil, validateDeletionProtection("talosmachines", tm)
+}
+
+// validateMachineSpec validates the spec of a TalosMachine
+func validateMachineSpec(path *field.Path, spec *talosv1alpha1.TalosMachineSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Endpoint == "" {
+		allErrs = append(allErrs, field.Required(path.Child("endpoint"), "endpoint is required"))
+	}
+	if err := validateVersion(path.Child("version"), spec.Version); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if spec.ControlPlaneRef != nil && spec.WorkerRef != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("workerRef"), "controlPlaneRef and workerRef are mutually exclusive"))
+	}
+	allErrs = append(allErrs, validateInstallDiskSelector(path.Child("machineSpec"), spec.MachineSpec)...)
+	allErrs = append(allErrs, validateBMC(path.Child("bmc"), spec.BMC)...)
+	return allErrs
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestTalosMachineValidation(t *testing.T) {
+	validator := &TalosMachineCustomValidator{}
+	tm := &talosv1alpha1.TalosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tm", Namespace: "default"},
+		Spec: talosv1alpha1.TalosMachineSpec{
+			Endpoint: "10.0.0.1",
+			Version:  "v1.12.0",
+		},
+	}
+	if err := (&TalosMachineCustomDefaulter{}).Default(context.Background(), tm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tm.Spec.DeletionPolicy != deletionPolicyReset {
+		t.Errorf("expected deletionPolicy %q, got %q", deletionPolicyReset, tm.Spec.DeletionPolicy)
+	}
+	if _, err := validator.ValidateCreate(context.Background(), tm); err != nil {
+		t.Errorf("expected a valid machine, got %v", err)
+	}
+
+	invalid := tm.DeepCopy()
+	invalid.Spec.Endpoint = ""
+	invalid.Spec.ControlPlaneRef = &corev1.ObjectReference{Name: "cp"}
+	invalid.Spec.WorkerRef = &corev1.ObjectReference{Name: "worker"}
+	if _, err := validator.ValidateCreate(context.Background(), invalid); err == nil {
+		t.Error("expected an error for a machine without endpoint and with both refs")
+	}
+
+	downgrade := tm.DeepCopy()
+	downgrade.Spec.Version = "v1.11.0"
+	if _, err := validator.ValidateUpdate(context.Background(), tm, downgrade); err == nil {
+		t.Error("expected an error for a Talos downgrade")
+	}
+}
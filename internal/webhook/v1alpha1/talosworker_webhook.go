@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var talosworkerlog = logf.Log.WithName("talosworker-resource")
+
+// SetupTalosWorkerWebhookWithManager registers the webhook for TalosWorker in the manager.
+func SetupTalosWorkerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &talosv1alpha1.TalosWorker{}).
+		WithValidator(&TalosWorkerCustomValidator{}).
+		WithDefaulter(&TalosWorkerCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-talos-alperen-cloud-v1alpha1-talosworker,mutating=true,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosworkers,verbs=create;update,versions=v1alpha1,name=mtalosworker-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosWorkerCustomDefaulter sets default values on the TalosWorker resource
+// when it is created or updated.
+type TalosWorkerCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the Kind TalosWorker.
+func (d *TalosWorkerCustomDefaulter) Default(_ context.Context, tw *talosv1alpha1.TalosWorker) error {
+	talosworkerlog.Info("Defaulting for TalosWorker", "name", tw.GetName())
+	defaultWorkerSpec(&tw.Spec)
+	return nil
+}
+
+// defaultWorkerSpec sets the defaults of a worker spec. It is shared with the TalosCluster webhook
+// which embeds the spec.
+func defaultWorkerSpec(spec *talosv1alpha1.TalosWorkerSpec) {
+	if spec.Mode == modeContainer && spec.Replicas == 0 {
+		spec.Replicas = 1
+	}
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = deletionPolicyReset
+	}
+	if spec.Mode == modeMetal {
+		spec.RolloutStrategy = defaultRolloutStrategy(spec.RolloutStrategy)
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-talos-alperen-cloud-v1alpha1-talosworker,mutating=false,failurePolicy=fail,sideEffects=None,groups=talos.alperen.cloud,resources=talosworkers,verbs=create;update;delete,versions=v1alpha1,name=vtalosworker-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TalosWorkerCustomValidator validates the TalosWorker resource when it is created or updated.
+type TalosWorkerCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TalosWorker.
+func (v *TalosWorkerCustomValidator) ValidateCreate(_ context.Context, tw *talosv1alpha1.TalosWorker) (admission.Warnings, error) {
+	talosworkerlog.Info("Validation for TalosWorker upon creation", "name", tw.GetName())
+	allErrs := validateWorkerSpec(field.NewPath("spec"), &tw.Spec)
+	return nil, toInvalidError("TalosWorker", tw.Name, allErrs)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TalosWorker.
+func (v *TalosWorkerCustomValidator) ValidateUpdate(_ context.Context, oldTw, tw *talosv1alpha1.TalosWorker) (admission.Warnings, error) {
+	talosworkerlog.Info("Validation for TalosWorker upon update", "name", tw.GetName())
+	if isDeleting(tw) {
+		return nil, nil
+	}
+	allErrs := validateWorkerSpec(field.NewPath("spec"), &tw.Spec)
+	var warnings admission.Warnings
+	if !skipUpgradeChecks(tw) {
+		upgradeWarnings, upgradeErrs := validateWorkerUpgrade(field.NewPath("spec"), &oldTw.Spec, &tw.Spec)
+		warnings = append(warnings, upgradeWarnings...)
+		allErrs = append(allErrs, upgradeErrs...)
+	}
+	return warnings, toInvalidError("TalosWorker", tw.Name, allErrs)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TalosWorker.
+func (v *TalosWorkerCustomValidator) ValidateDelete(_ context.Context, tw *talosv1alpha1.TalosWorker) (admission.Warnings, error) {
+	talosworkerlog.Info("Validation for TalosWorker upon deletion", "name", tw.GetName())
+	return nil, validateDeletionProtection("talosworkers", tw)
+}
+
+// validateWorkerSpec validates a worker spec. It is shared with the TalosCluster webhook.
+func validateWorkerSpec(path *field.Path, spec *talosv1alpha1.TalosWorkerSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if err := validateVersion(path.Child("version"), spec.Version); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateVersion(path.Child("kubeVersion"), spec.KubeVersion); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	switch spec.Mode {
+	case modeMetal:
+		// replicas defaults to 1, so only values that conflict with the machines are rejected
+		if spec.Replicas > 1 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("replicas"), "replicas is only supported when mode is 'container', the machines define the number of workers when mode is 'metal'"))
+		}
+		allErrs = append(allErrs, validateMetalSpec(path.Child("metalSpec"), &spec.MetalSpec)...)
+		allErrs = append(allErrs, validateRolloutStrategy(path.Child("rolloutStrategy"), spec.RolloutStrategy)...)
+	case modeContainer:
+		if spec.Replicas < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("replicas"), spec.Replicas, "must be at least 1 when mode is 'container'"))
+		}
+		if len(spec.MetalSpec.Machines) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("metalSpec", "machines"), "machines are only supported when mode is 'metal'"))
+		}
+		if spec.MetalSpec.HostSelector != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("metalSpec", "hostSelector"), "hostSelector is only supported when mode is 'metal'"))
+		}
+	}
+	allErrs = append(allErrs, validateMaintenanceWindows(path.Child("maintenanceWindows"), spec.MaintenanceWindows)...)
+	return allErrs
+}
+
+// validateWorkerUpgrade applies the upgrade path rules to a worker spec update
+func validateWorkerUpgrade(path *field.Path, oldSpec, spec *talosv1alpha1.TalosWorkerSpec) (admission.Warnings, field.ErrorList) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	talosWarnings, err := validateTalosUpgrade(path.Child("version"), oldSpec.Version, spec.Version)
+	warnings = append(warnings, talosWarnings...)
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	kubeWarnings, err := validateKubernetesUpgrade(path.Child("kubeVersion"), oldSpec.KubeVersion, spec.KubeVersion)
+	warnings = append(warnings, kubeWarnings...)
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return warnings, allErrs
+}
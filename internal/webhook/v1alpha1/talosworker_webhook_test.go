@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func newTestWorker() *talosv1alpha1.TalosWorker {
+	return &talosv1alpha1.TalosWorker{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tw", Namespace: "default"},
+		Spec: talosv1alpha1.TalosWorkerSpec{
+			Mode:        modeMetal,
+			Version:     "v1.12.0",
+			KubeVersion: "v1.33.0",
+			Replicas:    1,
+			MetalSpec: talosv1alpha1.MetalSpec{
+				Machines: []talosv1alpha1.Machine{{Address: ptr.To("10.0.0.10")}, {Address: ptr.To("10.0.0.11")}},
+			},
+		},
+	}
+}
+
+func TestTalosWorkerValidateCreate(t *testing.T) {
+	validator := &TalosWorkerCustomValidator{}
+	if _, err := validator.ValidateCreate(context.Background(), newTestWorker()); err != nil {
+		t.Errorf("expected a valid worker, got %v", err)
+	}
+
+	tw := newTestWorker()
+	tw.Spec.Replicas = 3
+	if _, err := validator.ValidateCreate(context.Background(), tw); err == nil {
+		t.Error("expected an error for replicas in metal mode")
+	}
+
+	tw = newTestWorker()
+	tw.Spec.KubeVersion = "latest"
+	if _, err := validator.ValidateCreate(context.Background(), tw); err == nil {
+		t.Error("expected an error for an invalid kubeVersion")
+	}
+}
+
+func TestTalosWorkerValidateUpdate(t *testing.T) {
+	validator := &TalosWorkerCustomValidator{}
+	oldTw := newTestWorker()
+
+	tw := oldTw.DeepCopy()
+	tw.Spec.Version = "v1.14.0"
+	warnings, err := validator.ValidateUpdate(context.Background(), oldTw, tw)
+	if err != nil {
+		t.Errorf("expected a Talos upgrade that skips a minor version to be allowed, got %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a warning for a Talos upgrade that skips a minor version, got %v", warnings)
+	}
+
+	tw = oldTw.DeepCopy()
+	tw.Spec.KubeVersion = "v1.35.0"
+	if _, err := validator.ValidateUpdate(context.Background(), oldTw, tw); err == nil {
+		t.Error("expected an error for a Kubernetes upgrade that skips a minor version")
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/mod/semver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+	"github.com/alperencelik/talos-operator/pkg/utils"
+)
+
+const (
+	modeContainer = "container"
+	modeMetal     = "metal"
+
+	cniFlannel = "flannel"
+	cniCustom  = "custom"
+	cniNone    = "none"
+
+	deletionPolicyReset = "reset"
+)
+
+// skipUpgradeChecks returns true if the upgrade path checks are disabled for the object
+func skipUpgradeChecks(obj metav1.Object) bool {
+	return strings.EqualFold(obj.GetAnnotations()[talosv1alpha1.SkipUpgradeChecksAnnotation], "true")
+}
+
+// isDeleting returns true if the object is being deleted. Updates of deleting objects are not validated
+// so finalizers can always be removed, even from objects created before the webhooks were enabled.
+func isDeleting(obj metav1.Object) bool {
+	return obj.GetDeletionTimestamp() != nil
+}
+
+// validateDeletionProtection rejects the deletion of an object that is protected from deletion, either
+// by itself or by its parent
+func validateDeletionProtection(resource string, obj metav1.Object) error {
+	value := obj.GetAnnotations()[talosv1alpha1.DeletionProtectionAnnotation]
+	if !strings.EqualFold(value, "true") && value != talosv1alpha1.DeletionProtectionInherited {
+		return nil
+	}
+	return apierrors.NewForbidden(talosv1alpha1.GroupVersion.WithResource(resource).GroupResource(), obj.GetName(),
+		fmt.Errorf("deletion protection is enabled, remove the %s annotation of the resource or its parent first", talosv1alpha1.DeletionProtectionAnnotation))
+}
+
+// validateVersion checks that version is a valid Talos or Kubernetes version
+func validateVersion(path *field.Path, version string) *field.Error {
+	if !utils.IsValidTalosVersion(version) || !semver.IsValid(version) {
+		return field.Invalid(path, version, "must be a semantic version with a leading v, e.g. v1.13.0")
+	}
+	return nil
+}
+
+// majorMinor returns the major and minor number of a valid semantic version
+func majorMinor(version string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(semver.MajorMinor(version), "v"), ".", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// validateTalosUpgrade rejects Talos downgrades and warns about upgrades that skip minor versions
+func validateTalosUpgrade(path *field.Path, oldVersion, newVersion string) (admission.Warnings, *field.Error) {
+	if oldVersion == "" || oldVersion == newVersion || !semver.IsValid(oldVersion) || !semver.IsValid(newVersion) {
+		return nil, nil
+	}
+	if semver.Compare(newVersion, oldVersion) < 0 {
+		return nil, field.Forbidden(path, fmt.Sprintf("downgrading Talos from %s to %s is not supported", oldVersion, newVersion))
+	}
+	oldMajor, oldMinor, ok1 := majorMinor(oldVersion)
+	newMajor, newMinor, ok2 := majorMinor(newVersion)
+	if ok1 && ok2 && oldMajor == newMajor && newMinor > oldMinor+1 {
+		return admission.Warnings{fmt.Sprintf("%s: upgrading Talos from %s to %s skips minor versions, Talos recommends upgrading one minor version at a time", path, oldVersion, newVersion)}, nil
+	}
+	return nil, nil
+}
+
+// validateKubernetesUpgrade rejects Kubernetes upgrades that skip minor versions. currentVersion is
+// the version the cluster is running, so a failed upgrade can be retried with a lower target.
+func validateKubernetesUpgrade(path *field.Path, currentVersion, newVersion string) (admission.Warnings, *field.Error) {
+	if currentVersion == "" || currentVersion == newVersion || !semver.IsValid(currentVersion) || !semver.IsValid(newVersion) {
+		return nil, nil
+	}
+	currentMajor, currentMinor, ok1 := majorMinor(currentVersion)
+	newMajor, newMinor, ok2 := majorMinor(newVersion)
+	if !ok1 || !ok2 {
+		return nil, nil
+	}
+	if newMajor != currentMajor {
+		return nil, field.Forbidden(path, fmt.Sprintf("upgrading Kubernetes across major versions from %s to %s is not supported", currentVersion, newVersion))
+	}
+	if newMinor > currentMinor+1 {
+		return nil, field.Forbidden(path, fmt.Sprintf("upgrading Kubernetes from %s to %s skips minor versions, upgrade to v%d.%d first", currentVersion, newVersion, currentMajor, currentMinor+1))
+	}
+	if semver.Compare(newVersion, currentVersion) < 0 {
+		return admission.Warnings{fmt.Sprintf("%s: downgrading Kubernetes from %s to %s is not supported by Kubernetes, only use it to retry a failed upgrade", path, currentVersion, newVersion)}, nil
+	}
+	return nil, nil
+}
+
+// validateMetalSpec checks the machines of a metal mode control plane or worker
+func validateMetalSpec(path *field.Path, metalSpec *talosv1alpha1.MetalSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	machinesPath := path.Child("machines")
+	if len(metalSpec.Machines) == 0 && metalSpec.HostSelector == nil {
+		allErrs = append(allErrs, field.Required(machinesPath, "at least one machine or a hostSelector is required when mode is 'metal'"))
+	}
+	// Machines are claimed from the host inventory with a selector and the number of hosts to claim
+	switch {
+	case metalSpec.HostSelector != nil && metalSpec.Replicas == nil:
+		allErrs = append(allErrs, field.Required(path.Child("replicas"), "replicas is required with a hostSelector"))
+	case metalSpec.HostSelector == nil && metalSpec.Replicas != nil:
+		allErrs = append(allErrs, field.Required(path.Child("hostSelector"), "hostSelector is required with replicas"))
+	}
+	if metalSpec.HostSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(metalSpec.HostSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("hostSelector"), metalSpec.HostSelector, err.Error()))
+		}
+	}
+	allErrs = append(allErrs, validateInstallDiskSelector(path.Child("machineSpec"), metalSpec.MachineSpec)...)
+	addresses := make(map[string]bool, len(metalSpec.Machines))
+	for i, machine := range metalSpec.Machines {
+		machinePath := machinesPath.Index(i)
+		if machine.Address != nil {
+			if addresses[*machine.Address] {
+				allErrs = append(allErrs, field.Duplicate(machinePath.Child("address"), *machine.Address))
+			}
+			addresses[*machine.Address] = true
+		}
+		if machine.Version != "" {
+			if err := validateVersion(machinePath.Child("version"), machine.Version); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		allErrs = append(allErrs, validateBMC(machinePath.Child("bmc"), machine.BMC)...)
+	}
+	return allErrs
+}
+
+// validateBMC checks that the BMC has a credentials Secret and that the address of an IPMI BMC is a
+// host with an optional port
+func validateBMC(path *field.Path, spec *talosv1alpha1.BMCSpec) field.ErrorList {
+	if spec == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if spec.CredentialsSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("credentialsSecretRef", "name"), "the Secret with the BMC credentials is required"))
+	}
+	if spec.Protocol == "ipmi" && strings.Contains(spec.Address, "/") {
+		allErrs = append(allErrs, field.Invalid(path.Child("address"), spec.Address, "the address of an ipmi BMC must be a host with an optional port"))
+	}
+	return allErrs
+}
+
+// validateInstallDiskSelector checks that the size range of the install disk selector is not empty
+// and that its model is a valid glob
+func validateInstallDiskSelector(path *field.Path, spec *talosv1alpha1.MachineSpec) field.ErrorList {
+	if spec == nil || spec.InstallDiskSelector == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	selector := spec.InstallDiskSelector
+	selectorPath := path.Child("installDiskSelector")
+	if selector.MinSize != nil && selector.MaxSize != nil && selector.MinSize.Cmp(*selector.MaxSize) > 0 {
+		allErrs = append(allErrs, field.Invalid(selectorPath.Child("maxSize"), selector.MaxSize.String(), "must not be less than minSize"))
+	}
+	if _, err := filepath.Match(selector.Model, ""); err != nil {
+		allErrs = append(allErrs, field.Invalid(selectorPath.Child("model"), selector.Model, err.Error()))
+	}
+	return allErrs
+}
+
+// validateRolloutStrategy checks that maxUnavailable is a positive number or a percentage, that
+// the health timeout is positive and that the partition is only set for the Partition strategy
+func validateRolloutStrategy(path *field.Path, rs *talosv1alpha1.RolloutStrategy) field.ErrorList {
+	if rs == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	switch {
+	case rs.Type == talosv1alpha1.PartitionStrategyType && rs.Partition == nil:
+		allErrs = append(allErrs, field.Required(path.Child("partition"), "must be set for the Partition strategy"))
+	case rs.Type != talosv1alpha1.PartitionStrategyType && rs.Partition != nil:
+		allErrs = append(allErrs, field.Forbidden(path.Child("partition"), "may only be set for the Partition strategy"))
+	case rs.Partition != nil && rs.Partition.Selector != nil:
+		if _, err := metav1.LabelSelectorAsSelector(rs.Partition.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("partition", "selector"), rs.Partition.Selector, err.Error()))
+		}
+	}
+	if rs.RollingUpdate == nil {
+		return allErrs
+	}
+	if maxUnavailable := rs.RollingUpdate.MaxUnavailable; maxUnavailable != nil {
+		maxUnavailablePath := path.Child("rollingUpdate", "maxUnavailable")
+		if _, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, 100, false); err != nil {
+			allErrs = append(allErrs, field.Invalid(maxUnavailablePath, maxUnavailable.String(), "must be an integer or a percentage, e.g. 25%"))
+		} else if maxUnavailable.Type == intstr.Int && maxUnavailable.IntVal < 1 {
+			allErrs = append(allErrs, field.Invalid(maxUnavailablePath, maxUnavailable.IntVal, "must be at least 1"))
+		}
+	}
+	if healthTimeout := rs.RollingUpdate.HealthTimeout; healthTimeout != nil && healthTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("rollingUpdate", "healthTimeout"), healthTimeout.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// validateMaintenanceWindows checks that the schedules and time zones of the maintenance windows can
+// be parsed, that the schedules fire and that the durations are positive
+func validateMaintenanceWindows(path *field.Path, windows []talosv1alpha1.MaintenanceWindow) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, window := range windows {
+		windowPath := path.Index(i)
+		if schedule, err := cron.ParseStandard(window.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("schedule"), window.Schedule, fmt.Sprintf("invalid cron expression: %v", err)))
+		} else if schedule.Next(time.Now()).IsZero() {
+			// e.g. the 30th of February, upgrades would never start
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("schedule"), window.Schedule, "schedule never opens a window"))
+		}
+		if window.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("duration"), window.Duration.Duration.String(), "must be positive"))
+		}
+		if window.TimeZone != nil {
+			if _, err := time.LoadLocation(*window.TimeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("timeZone"), *window.TimeZone, "unknown time zone"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// defaultRolloutStrategy fills in the RollingUpdate defaults of a rolling or partitioned rollout strategy
+func defaultRolloutStrategy(rs *talosv1alpha1.RolloutStrategy) *talosv1alpha1.RolloutStrategy {
+	if rs == nil {
+		rs = &talosv1alpha1.RolloutStrategy{}
+	}
+	if rs.Type == "" {
+		rs.Type = talosv1alpha1.RollingUpdateStrategyType
+	}
+	if rs.Type == talosv1alpha1.RollingUpdateStrategyType || rs.Type == talosv1alpha1.PartitionStrategyType {
+		if rs.RollingUpdate == nil {
+			rs.RollingUpdate = &talosv1alpha1.RollingUpdateRolloutStrategy{}
+		}
+		if rs.RollingUpdate.MaxUnavailable == nil {
+			rs.RollingUpdate.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+		}
+	}
+	return rs
+}
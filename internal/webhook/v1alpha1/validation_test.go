@@ -0,0 +1,271 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestValidateVersion(t *testing.T) {
+	for version, valid := range map[string]bool{
+		"v1.13.0":        true,
+		"v1.33.2":        true,
+		"v1.13.0-beta.1": true,
+		"1.13.0":         false,
+		"v1.10.x":        false,
+		"":               false,
+	} {
+		err := validateVersion(field.NewPath("spec", "version"), version)
+		if valid && err != nil {
+			t.Errorf("expected %q to be valid, got %v", version, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("expected %q to be invalid", version)
+		}
+	}
+}
+
+func TestValidateDeletionProtection(t *testing.T) {
+	for value, protected := range map[string]bool{
+		"true": true,
+		"True": true,
+		talosv1alpha1.DeletionProtectionInherited: true,
+		"false": false,
+		"":      false,
+	} {
+		tc := &talosv1alpha1.TalosCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-tc",
+			Annotations: map[string]string{talosv1alpha1.DeletionProtectionAnnotation: value},
+		}}
+		_, err := (&TalosClusterCustomValidator{}).ValidateDelete(context.Background(), tc)
+		if protected && !apierrors.IsForbidden(err) {
+			t.Errorf("expected the deletion to be forbidden with %q, got %v", value, err)
+		}
+		if !protected && err != nil {
+			t.Errorf("expected the deletion to be allowed with %q, got %v", value, err)
+		}
+	}
+}
+
+func TestValidateTalosUpgrade(t *testing.T) {
+	path := field.NewPath("spec", "version")
+	tests := []struct {
+		name        string
+		oldVersion  string
+		newVersion  string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "unchanged", oldVersion: "v1.12.0", newVersion: "v1.12.0"},
+		{name: "patch upgrade", oldVersion: "v1.12.0", newVersion: "v1.12.3"},
+		{name: "minor upgrade", oldVersion: "v1.12.3", newVersion: "v1.13.0"},
+		{name: "skips a minor", oldVersion: "v1.11.0", newVersion: "v1.13.0", wantWarning: true},
+		{name: "downgrade", oldVersion: "v1.13.0", newVersion: "v1.12.5", wantErr: true},
+		{name: "no previous version", oldVersion: "", newVersion: "v1.13.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := validateTalosUpgrade(path, tt.oldVersion, tt.newVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateKubernetesUpgrade(t *testing.T) {
+	path := field.NewPath("spec", "kubeVersion")
+	tests := []struct {
+		name        string
+		current     string
+		newVersion  string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "minor upgrade", current: "v1.32.4", newVersion: "v1.33.1"},
+		{name: "skips a minor", current: "v1.31.0", newVersion: "v1.33.0", wantErr: true},
+		{name: "major upgrade", current: "v1.33.0", newVersion: "v2.0.0", wantErr: true},
+		{name: "downgrade", current: "v1.33.1", newVersion: "v1.32.4", wantWarning: true},
+		{name: "no current version", current: "", newVersion: "v1.33.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := validateKubernetesUpgrade(path, tt.current, tt.newVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateMetalSpec(t *testing.T) {
+	path := field.NewPath("spec", "metalSpec")
+	if errs := validateMetalSpec(path, &talosv1alpha1.MetalSpec{}); len(errs) != 1 || errs[0].Type != field.ErrorTypeRequired {
+		t.Errorf("expected a required error for missing machines, got %v", errs)
+	}
+	duplicate := &talosv1alpha1.MetalSpec{Machines: []talosv1alpha1.Machine{
+		{Address: ptr.To("10.0.0.1")},
+		{Address: ptr.To("10.0.0.1")},
+	}}
+	if errs := validateMetalSpec(path, duplicate); len(errs) != 1 || errs[0].Type != field.ErrorTypeDuplicate {
+		t.Errorf("expected a duplicate error, got %v", errs)
+	}
+	badVersion := &talosv1alpha1.MetalSpec{Machines: []talosv1alpha1.Machine{
+		{Address: ptr.To("10.0.0.1"), Version: "1.13"},
+	}}
+	if errs := validateMetalSpec(path, badVersion); len(errs) != 1 || errs[0].Field != "spec.metalSpec.machines[0].version" {
+		t.Errorf("expected an invalid machine version error, got %v", errs)
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "b"}}
+	pool := &talosv1alpha1.MetalSpec{HostSelector: selector, Replicas: ptr.To[int32](5)}
+	if errs := validateMetalSpec(path, pool); len(errs) != 0 {
+		t.Errorf("expected a host pool without machines to be valid, got %v", errs)
+	}
+	noReplicas := &talosv1alpha1.MetalSpec{HostSelector: selector}
+	if errs := validateMetalSpec(path, noReplicas); len(errs) != 1 || errs[0].Field != "spec.metalSpec.replicas" {
+		t.Errorf("expected a required replicas error, got %v", errs)
+	}
+}
+
+func TestValidateInstallDiskSelector(t *testing.T) {
+	path := field.NewPath("spec", "metalSpec", "machineSpec")
+	spec := &talosv1alpha1.MachineSpec{InstallDiskSelector: &talosv1alpha1.InstallDiskSelector{
+		MinSize: ptr.To(resource.MustParse("200Gi")),
+		MaxSize: ptr.To(resource.MustParse("2Ti")),
+		Model:   "Samsung SSD 9*",
+	}}
+	if errs := validateInstallDiskSelector(path, spec); len(errs) != 0 {
+		t.Errorf("expected the selector to be valid, got %v", errs)
+	}
+	spec.InstallDiskSelector.MaxSize = ptr.To(resource.MustParse("100Gi"))
+	spec.InstallDiskSelector.Model = "Samsung [SSD"
+	if errs := validateInstallDiskSelector(path, spec); len(errs) != 2 {
+		t.Errorf("expected an empty size range and a bad model glob, got %v", errs)
+	}
+}
+
+func TestValidateBMC(t *testing.T) {
+	path := field.NewPath("spec", "metalSpec", "machines").Index(0).Child("bmc")
+	spec := &talosv1alpha1.BMCSpec{
+		Protocol:             "ipmi",
+		Address:              "10.0.0.5:623",
+		CredentialsSecretRef: corev1.LocalObjectReference{Name: "bmc-credentials"},
+	}
+	if errs := validateBMC(path, spec); len(errs) != 0 {
+		t.Errorf("expected the BMC to be valid, got %v", errs)
+	}
+	spec.Address = "https://10.0.0.5"
+	spec.CredentialsSecretRef.Name = ""
+	if errs := validateBMC(path, spec); len(errs) != 2 {
+		t.Errorf("expected a URL for an ipmi BMC and a missing Secret, got %v", errs)
+	}
+}
+
+func TestRolloutStrategy(t *testing.T) {
+	rs := defaultRolloutStrategy(nil)
+	if rs.Type != talosv1alpha1.RollingUpdateStrategyType || rs.RollingUpdate == nil || rs.RollingUpdate.MaxUnavailable.IntValue() != 1 {
+		t.Fatalf("unexpected default rollout strategy: %+v", rs)
+	}
+	if errs := validateRolloutStrategy(field.NewPath("spec", "rolloutStrategy"), rs); len(errs) != 0 {
+		t.Errorf("expected the default rollout strategy to be valid, got %v", errs)
+	}
+	for _, maxUnavailable := range []intstr.IntOrString{intstr.FromInt32(0), intstr.FromString("one")} {
+		rs.RollingUpdate.MaxUnavailable = ptr.To(maxUnavailable)
+		if errs := validateRolloutStrategy(field.NewPath("spec", "rolloutStrategy"), rs); len(errs) != 1 {
+			t.Errorf("expected maxUnavailable %s to be rejected, got %v", maxUnavailable.String(), errs)
+		}
+	}
+	rs.RollingUpdate.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+	rs.RollingUpdate.HealthTimeout = &metav1.Duration{}
+	if errs := validateRolloutStrategy(field.NewPath("spec", "rolloutStrategy"), rs); len(errs) != 1 || errs[0].Field != "spec.rolloutStrategy.rollingUpdate.healthTimeout" {
+		t.Errorf("expected a zero healthTimeout to be rejected, got %v", errs)
+	}
+}
+
+func TestPartitionRolloutStrategy(t *testing.T) {
+	path := field.NewPath("spec", "rolloutStrategy")
+	rs := defaultRolloutStrategy(&talosv1alpha1.RolloutStrategy{Type: talosv1alpha1.PartitionStrategyType})
+	if rs.RollingUpdate == nil || rs.RollingUpdate.MaxUnavailable.IntValue() != 1 {
+		t.Fatalf("expected the Partition strategy to default maxUnavailable, got %+v", rs)
+	}
+	if errs := validateRolloutStrategy(path, rs); len(errs) != 1 || errs[0].Type != field.ErrorTypeRequired {
+		t.Errorf("expected the partition to be required, got %v", errs)
+	}
+	rs.Partition = &talosv1alpha1.PartitionRolloutStrategy{
+		Partition: 2,
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "canary",
+			Operator: metav1.LabelSelectorOpExists,
+		}}},
+	}
+	if errs := validateRolloutStrategy(path, rs); len(errs) != 0 {
+		t.Errorf("expected the partition to be valid, got %v", errs)
+	}
+	rs.Partition.Selector.MatchExpressions[0].Operator = "Near"
+	if errs := validateRolloutStrategy(path, rs); len(errs) != 1 || errs[0].Field != "spec.rolloutStrategy.partition.selector" {
+		t.Errorf("expected an invalid selector to be rejected, got %v", errs)
+	}
+
+	recreate := &talosv1alpha1.RolloutStrategy{Type: talosv1alpha1.RecreateStrategyType, Partition: rs.Partition}
+	if errs := validateRolloutStrategy(path, recreate); len(errs) != 1 || errs[0].Type != field.ErrorTypeForbidden {
+		t.Errorf("expected the partition to be forbidden for the Recreate strategy, got %v", errs)
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	path := field.NewPath("spec", "maintenanceWindows")
+	windows := []talosv1alpha1.MaintenanceWindow{{
+		Schedule: "0 22 * * 1-5",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: ptr.To("Europe/Berlin"),
+	}}
+	if errs := validateMaintenanceWindows(path, windows); len(errs) != 0 {
+		t.Errorf("expected the maintenance window to be valid, got %v", errs)
+	}
+	windows = append(windows, talosv1alpha1.MaintenanceWindow{Schedule: "nightly", TimeZone: ptr.To("Mars/Olympus")})
+	errs := validateMaintenanceWindows(path, windows)
+	if len(errs) != 3 {
+		t.Fatalf("expected schedule, duration and time zone errors, got %v", errs)
+	}
+	for i, want := range []string{"spec.maintenanceWindows[1].schedule", "spec.maintenanceWindows[1].duration", "spec.maintenanceWindows[1].timeZone"} {
+		if errs[i].Field != want {
+			t.Errorf("expected error for %s, got %s", want, errs[i].Field)
+		}
+	}
+	never := []talosv1alpha1.MaintenanceWindow{{Schedule: "0 22 30 2 *", Duration: metav1.Duration{Duration: time.Hour}}}
+	if errs := validateMaintenanceWindows(path, never); len(errs) != 1 || errs[0].Field != "spec.maintenanceWindows[0].schedule" {
+		t.Errorf("expected a schedule that never fires to be rejected, got %v", errs)
+	}
+}
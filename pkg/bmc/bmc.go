@@ -0,0 +1,63 @@
+package bmc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Protocols a BMC is managed with
+const (
+	ProtocolRedfish = "redfish"
+	ProtocolIPMI    = "ipmi"
+)
+
+// PowerState is the power state of a machine as reported by its BMC
+type PowerState string
+
+// Power states of a machine
+const (
+	PowerOn      PowerState = "On"
+	PowerOff     PowerState = "Off"
+	PowerUnknown PowerState = "Unknown"
+)
+
+// Client is implemented by every protocol a BMC can be managed with
+type Client interface {
+	// PowerState returns the power state of the machine
+	PowerState(ctx context.Context) (PowerState, error)
+	// PowerOn powers the machine on
+	PowerOn(ctx context.Context) error
+	// PowerOff powers the machine off right away, without waiting for the OS to shut down
+	PowerOff(ctx context.Context) error
+	// PowerCycle powers the machine off and on again
+	PowerCycle(ctx context.Context) error
+	// SetPXEBootOnce makes the machine boot from the network on its next boot only
+	SetPXEBootOnce(ctx context.Context) error
+}
+
+// Config is the address and credentials of a BMC
+type Config struct {
+	// Protocol is redfish or ipmi
+	Protocol string
+	// Address is the URL of the Redfish service or the host[:port] of the IPMI interface
+	Address string
+	// Username and Password authenticate against the BMC
+	Username string
+	Password string
+	// InsecureSkipVerify skips the verification of the TLS certificate of the Redfish service
+	InsecureSkipVerify bool
+	// SystemID is the Redfish system of the machine. The first system of the service is used if it is empty.
+	SystemID string
+}
+
+// New returns the client of the protocol of the BMC
+func New(cfg Config) (Client, error) {
+	switch cfg.Protocol {
+	case ProtocolRedfish, "":
+		return NewRedfishClient(cfg)
+	case ProtocolIPMI:
+		return NewIPMIClient(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported BMC protocol %q", cfg.Protocol)
+	}
+}
@@ -0,0 +1,162 @@
+// Package bmctest provides a Redfish service for tests of code that manages machines through their BMC.
+package bmctest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// SystemPath is the path of the only system of the Redfish service
+const SystemPath = "/redfish/v1/Systems/1"
+
+// RedfishServer is a Redfish service with a single system. It keeps the power state and the boot
+// source override of the system and records the resets it was asked for.
+type RedfishServer struct {
+	*httptest.Server
+
+	username string
+	password string
+
+	mu           sync.Mutex
+	powerState   string
+	bootOverride string
+	bootedFrom   string
+	resets       []string
+}
+
+// NewRedfishServer starts a Redfish service whose system is powered off. Requests must authenticate
+// with the username and password.
+func NewRedfishServer(username, password string) *RedfishServer {
+	s := &RedfishServer{username: username, password: password, powerState: "Off"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /redfish/v1/Systems", s.systems)
+	mux.HandleFunc("GET "+SystemPath, s.system)
+	mux.HandleFunc("PATCH "+SystemPath, s.patchSystem)
+	mux.HandleFunc("POST "+SystemPath+"/Actions/ComputerSystem.Reset", s.reset)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// SetPowerState sets the power state of the system, On or Off
+func (s *RedfishServer) SetPowerState(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.powerState = state
+}
+
+// PowerState returns the power state of the system
+func (s *RedfishServer) PowerState() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.powerState
+}
+
+// BootedFrom returns the boot source override the system used on its last boot, empty if it booted
+// without an override
+func (s *RedfishServer) BootedFrom() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bootedFrom
+}
+
+// Resets returns the reset types the system was reset with, in order
+func (s *RedfishServer) Resets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.resets...)
+}
+
+func (s *RedfishServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != s.username || password != s.password {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *RedfishServer) systems(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"Members": []map[string]string{{"@odata.id": SystemPath}},
+	})
+}
+
+func (s *RedfishServer) system(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enabled := "Disabled"
+	if s.bootOverride != "" {
+		enabled = "Once"
+	}
+	writeJSON(w, map[string]any{
+		"@odata.id":  SystemPath,
+		"PowerState": s.powerState,
+		"Boot": map[string]string{
+			"BootSourceOverrideTarget":  s.bootOverride,
+			"BootSourceOverrideEnabled": enabled,
+		},
+		"Actions": map[string]any{
+			"#ComputerSystem.Reset": map[string]any{
+				"target":                            SystemPath + "/Actions/ComputerSystem.Reset",
+				"ResetType@Redfish.AllowableValues": []string{"On", "ForceOff", "ForceRestart", "PowerCycle"},
+			},
+		},
+	})
+}
+
+func (s *RedfishServer) patchSystem(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Boot struct {
+			BootSourceOverrideTarget  string
+			BootSourceOverrideEnabled string
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bootOverride = ""
+	if body.Boot.BootSourceOverrideEnabled == "Once" {
+		s.bootOverride = body.Boot.BootSourceOverrideTarget
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *RedfishServer) reset(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ResetType string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch body.ResetType {
+	case "On", "ForceRestart", "PowerCycle":
+		if body.ResetType == "On" && s.powerState == "On" {
+			break
+		}
+		// The system boots and uses up a one-time boot source override
+		s.powerState = "On"
+		s.bootedFrom = s.bootOverride
+		s.bootOverride = ""
+	case "ForceOff":
+		s.powerState = "Off"
+	default:
+		http.Error(w, "unsupported reset type "+body.ResetType, http.StatusBadRequest)
+		return
+	}
+	s.resets = append(s.resets, body.ResetType)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,105 @@
+package bmc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	ipmi "github.com/bougou/go-ipmi/pkg/client"
+	"github.com/bougou/go-ipmi/pkg/command/chassis"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// DefaultIPMIPort is the port of the IPMI over LAN interface of a BMC
+const DefaultIPMIPort = 623
+
+// IPMIClient manages a machine through the IPMI over LAN (lanplus) interface of its BMC. Every
+// call opens its own session, BMCs only keep a few sessions and drop idle ones.
+type IPMIClient struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+// NewIPMIClient returns a client for the IPMI interface at the address of the config
+func NewIPMIClient(cfg Config) (*IPMIClient, error) {
+	host, port := cfg.Address, DefaultIPMIPort
+	if h, p, err := net.SplitHostPort(cfg.Address); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IPMI address %s: %w", cfg.Address, err)
+		}
+		host, port = h, n
+	}
+	if host == "" {
+		return nil, fmt.Errorf("invalid IPMI address %s: the host is empty", cfg.Address)
+	}
+	return &IPMIClient{host: host, port: port, username: cfg.Username, password: cfg.Password}, nil
+}
+
+// PowerState returns the power state of the chassis
+func (c *IPMIClient) PowerState(ctx context.Context) (PowerState, error) {
+	state := PowerUnknown
+	err := c.session(ctx, func(client *ipmi.Client) error {
+		status, err := client.GetChassisStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get chassis status: %w", err)
+		}
+		state = PowerOff
+		if status.PowerIsOn {
+			state = PowerOn
+		}
+		return nil
+	})
+	return state, err
+}
+
+// PowerOn powers the chassis on
+func (c *IPMIClient) PowerOn(ctx context.Context) error {
+	return c.chassisControl(ctx, chassis.ChassisControlPowerUp)
+}
+
+// PowerOff powers the chassis off right away
+func (c *IPMIClient) PowerOff(ctx context.Context) error {
+	return c.chassisControl(ctx, chassis.ChassisControlPowerDown)
+}
+
+// PowerCycle power cycles the chassis
+func (c *IPMIClient) PowerCycle(ctx context.Context) error {
+	return c.chassisControl(ctx, chassis.ChassisControlPowerCycle)
+}
+
+// SetPXEBootOnce makes the machine boot from the network in UEFI mode on its next boot
+func (c *IPMIClient) SetPXEBootOnce(ctx context.Context) error {
+	return c.session(ctx, func(client *ipmi.Client) error {
+		if err := client.SetBootDevice(ctx, types.BootDeviceSelectorForcePXE, types.BIOSBootTypeEFI, false); err != nil {
+			return fmt.Errorf("failed to set the PXE boot device: %w", err)
+		}
+		return nil
+	})
+}
+
+// chassisControl sends the chassis control command to the BMC
+func (c *IPMIClient) chassisControl(ctx context.Context, control chassis.ChassisControl) error {
+	return c.session(ctx, func(client *ipmi.Client) error {
+		if _, err := client.ChassisControl(ctx, control); err != nil {
+			return fmt.Errorf("failed to send chassis control %d: %w", control, err)
+		}
+		return nil
+	})
+}
+
+// session opens a session with the BMC, runs fn and closes the session again
+func (c *IPMIClient) session(ctx context.Context, fn func(*ipmi.Client) error) error {
+	client, err := ipmi.NewClient(c.host, c.port, c.username, c.password)
+	if err != nil {
+		return fmt.Errorf("failed to create IPMI client for %s: %w", c.host, err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to the BMC at %s: %w", c.host, err)
+	}
+	defer client.Close(ctx) //nolint:errcheck
+	return fn(client)
+}
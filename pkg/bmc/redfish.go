@@ -0,0 +1,231 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// redfishTimeout is how long a single request to a Redfish service may take
+	redfishTimeout = 30 * time.Second
+	// redfishSystems is the collection of the systems of a Redfish service
+	redfishSystems = "/redfish/v1/Systems"
+	// redfishResetAction is the action that changes the power state of a system
+	redfishResetAction = "#ComputerSystem.Reset"
+)
+
+// RedfishClient manages a machine through the Redfish API of its BMC. Only the parts of the
+// ComputerSystem resource that every Redfish service implements are used.
+type RedfishClient struct {
+	endpoint string
+	username string
+	password string
+	systemID string
+	http     *http.Client
+
+	mu     sync.Mutex
+	system string
+}
+
+// redfishSystem is the part of a ComputerSystem resource the client reads
+type redfishSystem struct {
+	PowerState string `json:"PowerState"`
+	Actions    map[string]struct {
+		Target          string   `json:"target"`
+		AllowableValues []string `json:"ResetType@Redfish.AllowableValues"`
+	} `json:"Actions"`
+}
+
+// redfishCollection is a collection of Redfish resources
+type redfishCollection struct {
+	Members []struct {
+		ID string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+// NewRedfishClient returns a client for the Redfish service at the address of the config
+func NewRedfishClient(cfg Config) (*RedfishClient, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redfish address %s: %w", cfg.Address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Redfish address %s: the scheme must be http or https", cfg.Address)
+	}
+	return &RedfishClient{
+		endpoint: u.Scheme + "://" + u.Host,
+		username: cfg.Username,
+		password: cfg.Password,
+		systemID: cfg.SystemID,
+		http: &http.Client{
+			Timeout: redfishTimeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // BMCs often serve self-signed certificates
+				},
+			},
+		},
+	}, nil
+}
+
+// PowerState returns the power state of the system
+func (c *RedfishClient) PowerState(ctx context.Context) (PowerState, error) {
+	system, _, err := c.getSystem(ctx)
+	if err != nil {
+		return PowerUnknown, err
+	}
+	switch system.PowerState {
+	case "On", "PoweringOn":
+		return PowerOn, nil
+	case "Off":
+		return PowerOff, nil
+	default:
+		return PowerUnknown, nil
+	}
+}
+
+// PowerOn powers the system on
+func (c *RedfishClient) PowerOn(ctx context.Context) error {
+	return c.reset(ctx, "On", "ForceOn")
+}
+
+// PowerOff powers the system off right away
+func (c *RedfishClient) PowerOff(ctx context.Context) error {
+	return c.reset(ctx, "ForceOff")
+}
+
+// PowerCycle power cycles the system. Services that cannot power cycle restart the system instead.
+func (c *RedfishClient) PowerCycle(ctx context.Context) error {
+	return c.reset(ctx, "PowerCycle", "ForceRestart")
+}
+
+// SetPXEBootOnce overrides the boot source of the system with PXE for its next boot
+func (c *RedfishClient) SetPXEBootOnce(ctx context.Context) error {
+	path, err := c.systemPath(ctx)
+	if err != nil {
+		return err
+	}
+	body := map[string]any{
+		"Boot": map[string]string{
+			"BootSourceOverrideTarget":  "Pxe",
+			"BootSourceOverrideEnabled": "Once",
+		},
+	}
+	if err := c.do(ctx, http.MethodPatch, path, body, nil); err != nil {
+		return fmt.Errorf("failed to set the PXE boot override: %w", err)
+	}
+	return nil
+}
+
+// reset runs the reset action of the system with the first of the reset types the service allows
+func (c *RedfishClient) reset(ctx context.Context, resetTypes ...string) error {
+	system, path, err := c.getSystem(ctx)
+	if err != nil {
+		return err
+	}
+	target := path + "/Actions/ComputerSystem.Reset"
+	resetType := resetTypes[0]
+	if action, ok := system.Actions[redfishResetAction]; ok {
+		if action.Target != "" {
+			target = action.Target
+		}
+		if len(action.AllowableValues) > 0 {
+			i := slices.IndexFunc(resetTypes, func(t string) bool { return slices.Contains(action.AllowableValues, t) })
+			if i < 0 {
+				return fmt.Errorf("the system allows none of the reset types %v", resetTypes)
+			}
+			resetType = resetTypes[i]
+		}
+	}
+	if err := c.do(ctx, http.MethodPost, target, map[string]string{"ResetType": resetType}, nil); err != nil {
+		return fmt.Errorf("failed to reset the system with %s: %w", resetType, err)
+	}
+	return nil
+}
+
+// getSystem returns the system of the machine and its path
+func (c *RedfishClient) getSystem(ctx context.Context) (*redfishSystem, string, error) {
+	path, err := c.systemPath(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	system := &redfishSystem{}
+	if err := c.do(ctx, http.MethodGet, path, nil, system); err != nil {
+		return nil, "", fmt.Errorf("failed to get system %s: %w", path, err)
+	}
+	return system, path, nil
+}
+
+// systemPath returns the path of the system of the machine, the first system of the service if
+// no system ID is configured
+func (c *RedfishClient) systemPath(ctx context.Context) (string, error) {
+	if c.systemID != "" {
+		return redfishSystems + "/" + c.systemID, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.system != "" {
+		return c.system, nil
+	}
+	systems := &redfishCollection{}
+	if err := c.do(ctx, http.MethodGet, redfishSystems, nil, systems); err != nil {
+		return "", fmt.Errorf("failed to list systems: %w", err)
+	}
+	if len(systems.Members) == 0 || systems.Members[0].ID == "" {
+		return "", fmt.Errorf("the Redfish service has no systems")
+	}
+	c.system = strings.TrimSuffix(systems.Members[0].ID, "/")
+	return c.system, nil
+}
+
+// do sends a request to the Redfish service and decodes the response into out if it is set
+func (c *RedfishClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		target = c.endpoint + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", path, err)
+	}
+	return nil
+}
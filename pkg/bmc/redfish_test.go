@@ -0,0 +1,98 @@
+package bmc
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/alperencelik/talos-operator/pkg/bmc/bmctest"
+)
+
+func TestRedfishClient(t *testing.T) {
+	ctx := context.Background()
+	server := bmctest.NewRedfishServer("admin", "secret")
+	defer server.Close()
+
+	c, err := New(Config{Protocol: ProtocolRedfish, Address: server.URL + "/", Username: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if state, err := c.PowerState(ctx); err != nil || state != PowerOff {
+		t.Fatalf("expected the system to be off, got %s, %v", state, err)
+	}
+
+	// A one-time PXE boot is used up by the next power on
+	if err := c.SetPXEBootOnce(ctx); err != nil {
+		t.Fatalf("failed to set PXE boot: %v", err)
+	}
+	if err := c.PowerOn(ctx); err != nil {
+		t.Fatalf("failed to power on: %v", err)
+	}
+	if state, err := c.PowerState(ctx); err != nil || state != PowerOn || server.BootedFrom() != "Pxe" {
+		t.Fatalf("expected the system to boot from PXE, got %s booted from %q, %v", state, server.BootedFrom(), err)
+	}
+	if err := c.PowerCycle(ctx); err != nil {
+		t.Fatalf("failed to power cycle: %v", err)
+	}
+	if server.BootedFrom() != "" {
+		t.Errorf("expected the system to boot without an override, got %q", server.BootedFrom())
+	}
+	if err := c.PowerOff(ctx); err != nil {
+		t.Fatalf("failed to power off: %v", err)
+	}
+	if want := []string{"On", "PowerCycle", "ForceOff"}; !slices.Equal(server.Resets(), want) {
+		t.Errorf("expected resets %v, got %v", want, server.Resets())
+	}
+	if server.PowerState() != "Off" {
+		t.Errorf("expected the system to be off, got %s", server.PowerState())
+	}
+}
+
+func TestRedfishClientErrors(t *testing.T) {
+	ctx := context.Background()
+	server := bmctest.NewRedfishServer("admin", "secret")
+	defer server.Close()
+
+	c, err := NewRedfishClient(Config{Address: server.URL, Username: "admin", Password: "wrong"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := c.PowerState(ctx); err == nil {
+		t.Errorf("expected wrong credentials to fail")
+	}
+	c, err = NewRedfishClient(Config{Address: server.URL, Username: "admin", Password: "secret", SystemID: "2"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := c.PowerOn(ctx); err == nil {
+		t.Errorf("expected a missing system to fail")
+	}
+	if _, err := NewRedfishClient(Config{Address: "10.0.0.5"}); err == nil {
+		t.Errorf("expected an address without scheme to fail")
+	}
+}
+
+func TestNewIPMIClient(t *testing.T) {
+	tests := []struct {
+		address string
+		host    string
+		port    int
+		wantErr bool
+	}{
+		{address: "10.0.0.5", host: "10.0.0.5", port: DefaultIPMIPort},
+		{address: "10.0.0.5:6230", host: "10.0.0.5", port: 6230},
+		{address: "10.0.0.5:ipmi", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, err := NewIPMIClient(Config{Address: tt.address})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: unexpected error %v", tt.address, err)
+		}
+		if err == nil && (c.host != tt.host || c.port != tt.port) {
+			t.Errorf("%s: expected %s:%d, got %s:%d", tt.address, tt.host, tt.port, c.host, c.port)
+		}
+	}
+	if _, err := New(Config{Protocol: "amt"}); err == nil {
+		t.Errorf("expected an unsupported protocol to fail")
+	}
+}
@@ -0,0 +1,154 @@
+// Package kube provides access to the Kubernetes API of the clusters managed by the operator.
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// skipTerminatingPodsAfterSeconds is how long a pod on a Node that is not Ready may be terminating
+// before the drain stops waiting for it. The kubelet of such a Node cannot confirm the deletion.
+const skipTerminatingPodsAfterSeconds = 60
+
+// Client is a client for the Kubernetes API of a cluster.
+type Client struct {
+	kubernetes.Interface
+}
+
+// DrainOptions configures the eviction of the pods of a Node.
+type DrainOptions struct {
+	// Timeout is how long evictions, including evictions blocked by a PodDisruptionBudget, are retried.
+	Timeout time.Duration
+	// Force also deletes pods that are not managed by a controller.
+	Force bool
+}
+
+// NewClient constructs a Kubernetes client from a kubeconfig.
+func NewClient(kubeconfig []byte) (*Client, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return &Client{Interface: clientset}, nil
+}
+
+// FindNode returns the name of the Node that has the given address or name, or an empty string if
+// there is none.
+func (c *Client) FindNode(ctx context.Context, address string) (string, error) {
+	nodes, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list Nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		if node.Name == address {
+			return node.Name, nil
+		}
+		for _, nodeAddress := range node.Status.Addresses {
+			if nodeAddress.Address == address {
+				return node.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// IsNodeReady returns true if the Node reports the Ready condition
+func (c *Client) IsNodeReady(ctx context.Context, name string) (bool, error) {
+	node, err := c.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get Node %s: %w", name, err)
+	}
+	return isNodeReady(node), nil
+}
+
+// CordonNode marks the Node as unschedulable
+func (c *Client) CordonNode(ctx context.Context, name string) error {
+	return c.setUnschedulable(ctx, name, true)
+}
+
+// UncordonNode marks the Node as schedulable
+func (c *Client) UncordonNode(ctx context.Context, name string) error {
+	return c.setUnschedulable(ctx, name, false)
+}
+
+func (c *Client) setUnschedulable(ctx context.Context, name string, unschedulable bool) error {
+	node, err := c.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", name, err)
+	}
+	if err := drain.RunCordonOrUncordon(c.drainHelper(ctx, DrainOptions{}), node, unschedulable); err != nil {
+		return fmt.Errorf("failed to set Node %s unschedulable to %t: %w", name, unschedulable, err)
+	}
+	return nil
+}
+
+// DrainNode evicts the pods of a cordoned Node. Evictions go through the eviction API, so
+// PodDisruptionBudgets are respected. DaemonSet pods are left running and pods with emptyDir volumes
+// are evicted. It returns an error if pods are left on the Node after the timeout.
+func (c *Client) DrainNode(ctx context.Context, name string, opts DrainOptions) error {
+	node, err := c.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", name, err)
+	}
+	helper := c.drainHelper(ctx, opts)
+	if !isNodeReady(node) {
+		helper.SkipWaitForDeleteTimeoutSeconds = skipTerminatingPodsAfterSeconds
+	}
+	if err := drain.RunNodeDrain(helper, name); err != nil {
+		return fmt.Errorf("failed to drain Node %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteNode deletes the Node. A Node that does not exist is not an error.
+func (c *Client) DeleteNode(ctx context.Context, name string) error {
+	if err := c.CoreV1().Nodes().Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Node %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *Client) drainHelper(ctx context.Context, opts DrainOptions) *drain.Helper {
+	logger := log.FromContext(ctx)
+	return &drain.Helper{
+		Ctx:                 ctx,
+		Client:              c.Interface,
+		Force:               opts.Force,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  true,
+		Timeout:             opts.Timeout,
+		Out:                 io.Discard,
+		ErrOut:              io.Discard,
+		OnPodDeletionOrEvictionFinished: func(pod *corev1.Pod, usingEviction bool, err error) {
+			if err != nil {
+				logger.Info("Failed to evict pod", "pod", pod.Name, "namespace", pod.Namespace, "error", err.Error())
+				return
+			}
+			logger.Info("Evicted pod", "pod", pod.Name, "namespace", pod.Namespace, "eviction", usingEviction)
+		},
+	}
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
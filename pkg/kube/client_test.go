@@ -0,0 +1,180 @@
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func newTestNode(name, address string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: address}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func newTestPod(name, node string, owner *metav1.OwnerReference) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func controllerRef(kind, name string) *metav1.OwnerReference {
+	controller := true
+	return &metav1.OwnerReference{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &controller}
+}
+
+// newTestClient returns a client backed by a fake clientset that supports the eviction API. An
+// eviction deletes the pod right away.
+func newTestClient(objs ...runtime.Object) (*Client, *fake.Clientset) {
+	clientset := fake.NewClientset(objs...)
+	clientset.Resources = append(clientset.Resources,
+		&metav1.APIResourceList{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction", Group: "policy", Version: "v1"}},
+		},
+		&metav1.APIResourceList{GroupVersion: "policy/v1"},
+	)
+	clientset.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(ktesting.CreateAction).GetObject().(*policyv1.Eviction)
+		err := clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+		return true, nil, err
+	})
+	return &Client{Interface: clientset}, clientset
+}
+
+func TestFindNode(t *testing.T) {
+	c, _ := newTestClient(newTestNode("worker-1", "10.0.0.11", true), newTestNode("worker-2", "10.0.0.12", true))
+	ctx := context.Background()
+
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{address: "10.0.0.12", want: "worker-2"},
+		{address: "worker-1", want: "worker-1"},
+		{address: "10.0.0.99", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := c.FindNode(ctx, tt.address)
+		if err != nil {
+			t.Fatalf("FindNode(%q) failed: %v", tt.address, err)
+		}
+		if got != tt.want {
+			t.Errorf("FindNode(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestCordonAndUncordonNode(t *testing.T) {
+	c, clientset := newTestClient(newTestNode("worker-1", "10.0.0.11", true))
+	ctx := context.Background()
+
+	if err := c.CordonNode(ctx, "worker-1"); err != nil {
+		t.Fatalf("CordonNode failed: %v", err)
+	}
+	node, _ := clientset.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Error("expected Node to be unschedulable after cordoning it")
+	}
+
+	if err := c.UncordonNode(ctx, "worker-1"); err != nil {
+		t.Fatalf("UncordonNode failed: %v", err)
+	}
+	node, _ = clientset.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{})
+	if node.Spec.Unschedulable {
+		t.Error("expected Node to be schedulable after uncordoning it")
+	}
+}
+
+func TestIsNodeReady(t *testing.T) {
+	c, _ := newTestClient(newTestNode("ready", "10.0.0.11", true), newTestNode("not-ready", "10.0.0.12", false))
+	ctx := context.Background()
+
+	if ready, err := c.IsNodeReady(ctx, "ready"); err != nil || !ready {
+		t.Errorf("expected Node to be Ready, got %v, %v", ready, err)
+	}
+	if ready, err := c.IsNodeReady(ctx, "not-ready"); err != nil || ready {
+		t.Errorf("expected Node not to be Ready, got %v, %v", ready, err)
+	}
+}
+
+func TestDrainNode(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "default"}}
+	c, clientset := newTestClient(
+		newTestNode("worker-1", "10.0.0.11", true),
+		daemonSet,
+		newTestPod("app", "worker-1", controllerRef("ReplicaSet", "app")),
+		newTestPod("cni", "worker-1", controllerRef("DaemonSet", "cni")),
+	)
+	ctx := context.Background()
+
+	if err := c.DrainNode(ctx, "worker-1", DrainOptions{Timeout: 5 * time.Second}); err != nil {
+		t.Fatalf("DrainNode failed: %v", err)
+	}
+	pods, err := clientset.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list pods: %v", err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].Name != "cni" {
+		t.Errorf("expected only the DaemonSet pod to be left, got %v", pods.Items)
+	}
+}
+
+func TestDrainNode_UnmanagedPod(t *testing.T) {
+	c, clientset := newTestClient(newTestNode("worker-1", "10.0.0.11", true), newTestPod("standalone", "worker-1", nil))
+	ctx := context.Background()
+
+	if err := c.DrainNode(ctx, "worker-1", DrainOptions{Timeout: 5 * time.Second}); err == nil {
+		t.Fatal("expected drain to fail for a pod without controller")
+	}
+	if _, err := clientset.CoreV1().Pods("default").Get(ctx, "standalone", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected pod to be left without force: %v", err)
+	}
+
+	if err := c.DrainNode(ctx, "worker-1", DrainOptions{Timeout: 5 * time.Second, Force: true}); err != nil {
+		t.Fatalf("DrainNode with force failed: %v", err)
+	}
+	if _, err := clientset.CoreV1().Pods("default").Get(ctx, "standalone", metav1.GetOptions{}); err == nil {
+		t.Error("expected pod to be evicted with force")
+	}
+}
+
+func TestDeleteNode(t *testing.T) {
+	c, clientset := newTestClient(newTestNode("worker-1", "10.0.0.11", true))
+	ctx := context.Background()
+
+	if err := c.DeleteNode(ctx, "worker-1"); err != nil {
+		t.Fatalf("DeleteNode failed: %v", err)
+	}
+	if _, err := clientset.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{}); err == nil {
+		t.Error("expected Node to be deleted")
+	}
+	if err := c.DeleteNode(ctx, "worker-1"); err != nil {
+		t.Errorf("expected deleting a missing Node to succeed, got %v", err)
+	}
+}
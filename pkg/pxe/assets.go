@@ -0,0 +1,180 @@
+package pxe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// iPXE binaries PXE firmware loads over TFTP
+const (
+	IpxeEfiX8664File = "ipxe-efi-x86_64.efi"
+	IpxeEfiArm64File = "ipxe-efi-arm64.efi"
+)
+
+// Subdirectories of the assets directory
+const (
+	talosAssetsDir = "talos"
+	ipxeAssetsDir  = "ipxe"
+)
+
+// asset is a file the boot service serves and where it is downloaded from
+type asset struct {
+	// path of the file in the assets directory
+	path string
+	url  string
+}
+
+// ipxeAsset returns the iPXE binary with the file name PXE firmware asks for
+func (s *Server) ipxeAsset(file string) (asset, bool) {
+	var dir string
+	switch file {
+	case IpxeEfiX8664File:
+		dir = "x86_64-efi"
+	case IpxeEfiArm64File:
+		dir = "arm64-efi"
+	default:
+		return asset{}, false
+	}
+	return asset{path: path.Join(ipxeAssetsDir, file), url: s.opts.IPXEBaseURL + "/" + dir + "/ipxe.efi"}, true
+}
+
+// kernelPath and initramfsPath return the URL paths of the boot images of the Talos version
+func kernelPath(version, arch string) string {
+	return "/assets/" + version + "/vmlinuz-" + arch
+}
+
+func initramfsPath(version, arch string) string {
+	return "/assets/" + version + "/initramfs-" + arch + ".xz"
+}
+
+// assets returns the files the machines of the configuration boot from by their path
+func (s *Server) assets(cfg Config) map[string]asset {
+	assets := make(map[string]asset)
+	for _, n := range cfg.Networks {
+		for _, m := range n.Machines {
+			if !validVersion(m.TalosVersion) || (m.Architecture != "amd64" && m.Architecture != "arm64") {
+				continue
+			}
+			for _, file := range []string{path.Base(kernelPath(m.TalosVersion, m.Architecture)), path.Base(initramfsPath(m.TalosVersion, m.Architecture))} {
+				p := path.Join(talosAssetsDir, m.TalosVersion, file)
+				assets[p] = asset{path: p, url: s.opts.TalosImagesBaseURL + "/" + m.TalosVersion + "/" + file}
+			}
+			file := IpxeEfiX8664File
+			if m.Architecture == "arm64" {
+				file = IpxeEfiArm64File
+			}
+			a, _ := s.ipxeAsset(file)
+			assets[a.path] = a
+		}
+	}
+	return assets
+}
+
+// validVersion reports whether the Talos version can be used as a directory name
+func validVersion(version string) bool {
+	return version != "" && version != "." && version != ".." && path.Base(version) == version && filepath.Base(version) == version
+}
+
+// SyncAssets downloads the iPXE binaries and Talos boot images the machines boot from that are
+// missing and removes the ones no machine boots from anymore
+func (s *Server) SyncAssets(ctx context.Context) error {
+	s.assetsMu.Lock()
+	defer s.assetsMu.Unlock()
+	assets := s.assets(s.Config())
+	for _, dir := range []string{talosAssetsDir, ipxeAssetsDir} {
+		root := filepath.Join(s.opts.AssetsDir, dir)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(s.opts.AssetsDir, p)
+			if err != nil {
+				return err
+			}
+			if _, ok := assets[filepath.ToSlash(rel)]; !ok {
+				return os.Remove(p)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to clean up boot images: %w", err)
+		}
+	}
+	// Directories of Talos versions no machine boots anymore
+	versions, err := os.ReadDir(filepath.Join(s.opts.AssetsDir, talosAssetsDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to clean up boot images: %w", err)
+	}
+	for _, v := range versions {
+		dir := filepath.Join(s.opts.AssetsDir, talosAssetsDir, v.Name())
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	for _, a := range assets {
+		if _, err := s.download(ctx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch returns the local path of the asset and downloads it first when it is missing, e.g. when a
+// machine boots before the reconciliation downloaded it
+func (s *Server) fetch(ctx context.Context, a asset) (string, error) {
+	local := filepath.Join(s.opts.AssetsDir, filepath.FromSlash(a.path))
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+	s.assetsMu.Lock()
+	defer s.assetsMu.Unlock()
+	return s.download(ctx, a)
+}
+
+// download downloads the asset unless it exists already and returns its local path. The file is
+// written next to its destination and renamed, so a failed download is not served. The caller
+// holds assetsMu.
+func (s *Server) download(ctx context.Context, a asset) (string, error) {
+	local := filepath.Join(s.opts.AssetsDir, filepath.FromSlash(a.path))
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", a.path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to download '%s' (HTTP status code: %d)", a.url, resp.StatusCode)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(local), "."+filepath.Base(local)+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	if err := os.Rename(tmp.Name(), local); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.url, err)
+	}
+	return local, nil
+}
@@ -0,0 +1,489 @@
+package pxe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/net/ipv4"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DHCP message types
+const (
+	dhcpDiscover = 1
+	dhcpOffer    = 2
+	dhcpRequest  = 3
+	dhcpDecline  = 4
+	dhcpAck      = 5
+	dhcpNak      = 6
+	dhcpRelease  = 7
+)
+
+// DHCP options
+const (
+	optPad           = 0
+	optSubnetMask    = 1
+	optRouter        = 3
+	optVendorInfo    = 43
+	optRequestedIP   = 50
+	optLeaseTime     = 51
+	optMessageType   = 53
+	optServerID      = 54
+	optVendorClass   = 60
+	optUserClass     = 77
+	optClientArch    = 93
+	optClientMachine = 97
+	optEnd           = 255
+)
+
+const (
+	bootRequest = 1
+	bootReply   = 2
+	// dhcpHeaderLen is the length of a BOOTP message up to and including the magic cookie
+	dhcpHeaderLen = 240
+	// dhcpMinLen is the length BOOTP relays and old clients expect a message to have at least
+	dhcpMinLen = 300
+	// dhcpClientPort is the port DHCP clients listen on
+	dhcpClientPort = 68
+	// pxeVendorClass is the vendor class of the PXE firmware and the boot service answers it with
+	pxeVendorClass = "PXEClient"
+	// ipxeUserClass is the user class iPXE identifies itself with
+	ipxeUserClass = "iPXE"
+)
+
+var magicCookie = []byte{99, 130, 83, 99}
+
+// Client system architectures of PXE firmware, RFC 4578
+const (
+	archEFIX8664 = 7
+	archEFIBC    = 9
+	archEFIARM64 = 11
+)
+
+// packet is a DHCP message
+type packet struct {
+	op      byte
+	xid     uint32
+	secs    uint16
+	flags   uint16
+	ciaddr  netip.Addr
+	yiaddr  netip.Addr
+	siaddr  netip.Addr
+	giaddr  netip.Addr
+	chaddr  net.HardwareAddr
+	file    string
+	options map[byte][]byte
+}
+
+// parsePacket parses a DHCP message of an Ethernet client
+func parsePacket(b []byte) (*packet, error) {
+	if len(b) < dhcpHeaderLen || !bytes.Equal(b[236:240], magicCookie) {
+		return nil, errors.New("not a DHCP message")
+	}
+	if b[1] != 1 || b[2] != 6 {
+		return nil, fmt.Errorf("unsupported hardware type %d", b[1])
+	}
+	p := &packet{
+		op:      b[0],
+		xid:     binary.BigEndian.Uint32(b[4:8]),
+		secs:    binary.BigEndian.Uint16(b[8:10]),
+		flags:   binary.BigEndian.Uint16(b[10:12]),
+		ciaddr:  netip.AddrFrom4([4]byte(b[12:16])),
+		yiaddr:  netip.AddrFrom4([4]byte(b[16:20])),
+		siaddr:  netip.AddrFrom4([4]byte(b[20:24])),
+		giaddr:  netip.AddrFrom4([4]byte(b[24:28])),
+		chaddr:  net.HardwareAddr(bytes.Clone(b[28:34])),
+		options: make(map[byte][]byte),
+	}
+	for opts := b[dhcpHeaderLen:]; len(opts) > 0; {
+		code := opts[0]
+		if code == optEnd {
+			break
+		}
+		if code == optPad {
+			opts = opts[1:]
+			continue
+		}
+		if len(opts) < 2 || len(opts) < 2+int(opts[1]) {
+			return nil, fmt.Errorf("option %d is truncated", code)
+		}
+		end := 2 + int(opts[1])
+		// Options that are split are concatenated, RFC 3396
+		p.options[code] = append(p.options[code], opts[2:end]...)
+		opts = opts[end:]
+	}
+	return p, nil
+}
+
+// marshal returns the message in the wire format
+func (p *packet) marshal() []byte {
+	b := make([]byte, dhcpHeaderLen, dhcpMinLen)
+	b[0] = p.op
+	b[1], b[2] = 1, 6
+	binary.BigEndian.PutUint32(b[4:8], p.xid)
+	binary.BigEndian.PutUint16(b[8:10], p.secs)
+	binary.BigEndian.PutUint16(b[10:12], p.flags)
+	for off, addr := range map[int]netip.Addr{12: p.ciaddr, 16: p.yiaddr, 20: p.siaddr, 24: p.giaddr} {
+		if addr.Is4() {
+			a := addr.As4()
+			copy(b[off:off+4], a[:])
+		}
+	}
+	copy(b[28:44], p.chaddr)
+	copy(b[108:236], p.file)
+	copy(b[236:240], magicCookie)
+	// The message type comes first, the remaining options in the order of their code
+	b = appendOption(b, optMessageType, p.options[optMessageType])
+	for code := 1; code < optEnd; code++ {
+		if v, ok := p.options[byte(code)]; ok && code != optMessageType {
+			b = appendOption(b, byte(code), v)
+		}
+	}
+	b = append(b, optEnd)
+	for len(b) < dhcpMinLen {
+		b = append(b, optPad)
+	}
+	return b
+}
+
+func appendOption(b []byte, code byte, v []byte) []byte {
+	for len(v) > 255 {
+		b = append(append(b, code, 255), v[:255]...)
+		v = v[255:]
+	}
+	return append(append(b, code, byte(len(v))), v...)
+}
+
+func (p *packet) messageType() byte {
+	if v := p.options[optMessageType]; len(v) == 1 {
+		return v[0]
+	}
+	return 0
+}
+
+// addrOption returns the IPv4 address of the option, the zero address if it is not set
+func (p *packet) addrOption(code byte) netip.Addr {
+	if v := p.options[code]; len(v) == 4 {
+		return netip.AddrFrom4([4]byte(v))
+	}
+	return netip.Addr{}
+}
+
+// pxeClient reports whether the message comes from PXE firmware or iPXE
+func (p *packet) pxeClient() bool {
+	return bytes.HasPrefix(p.options[optVendorClass], []byte(pxeVendorClass))
+}
+
+// ipxe reports whether the message comes from iPXE
+func (p *packet) ipxe() bool {
+	return bytes.Contains(p.options[optUserClass], []byte(ipxeUserClass))
+}
+
+// clientArch returns the client system architecture of the PXE firmware, -1 if it is not set
+func (p *packet) clientArch() int {
+	if v := p.options[optClientArch]; len(v) >= 2 {
+		return int(binary.BigEndian.Uint16(v))
+	}
+	return -1
+}
+
+// reply returns the answer of the boot service to the message it received on the interface, nil if
+// it does not answer. proxy is set for messages to the ProxyDHCP port.
+func (s *Server) reply(iface string, req *packet, proxy bool) *packet {
+	snap := s.config.Load()
+	n := snap.interfaces[iface]
+	if n == nil || req.op != bootRequest || !n.ServerAddress.Is4() {
+		return nil
+	}
+	machine := snap.machines[req.chaddr.String()]
+	if machine != nil && !n.owns(machine) {
+		machine = nil
+	}
+	if proxy || n.ProxyDHCP {
+		return s.proxyReply(n, machine, req, proxy)
+	}
+	return s.serverReply(n, machine, req)
+}
+
+// proxyReply adds the boot information of the machine to the address another DHCP server offers.
+// PXE firmware asks for it on the ProxyDHCP port after it accepted the address, iPXE takes it from
+// the offer directly.
+func (s *Server) proxyReply(n *Network, machine *Machine, req *packet, proxy bool) *packet {
+	if machine == nil || !req.pxeClient() {
+		return nil
+	}
+	var resp *packet
+	switch {
+	case req.messageType() == dhcpDiscover && !proxy:
+		resp = newReply(req, dhcpOffer, n)
+	case req.messageType() == dhcpRequest && proxy:
+		resp = newReply(req, dhcpAck, n)
+	default:
+		return nil
+	}
+	if !s.setBootFile(resp, n, machine, req) {
+		return nil
+	}
+	// Boot the file right away instead of searching for boot servers
+	resp.options[optVendorInfo] = []byte{6, 1, 8, optEnd}
+	return resp
+}
+
+// serverReply leases the address of the machine, or one of the discovery range to other machines,
+// and adds the boot information of the machine
+func (s *Server) serverReply(n *Network, machine *Machine, req *packet) *packet {
+	mac := req.chaddr.String()
+	var resp *packet
+	switch req.messageType() {
+	case dhcpDiscover:
+		addr, ok := s.leaseAddress(n, machine, mac, req.addrOption(optRequestedIP))
+		if !ok {
+			return nil
+		}
+		resp = newReply(req, dhcpOffer, n)
+		resp.yiaddr = addr
+	case dhcpRequest:
+		// The client accepted the offer of another server
+		if id := req.addrOption(optServerID); id.IsValid() && id != n.ServerAddress {
+			return nil
+		}
+		want := req.addrOption(optRequestedIP)
+		if !want.IsValid() {
+			want = req.ciaddr
+		}
+		addr, ok := s.leaseAddress(n, machine, mac, want)
+		if !ok || addr != want {
+			// Only addresses of the network are refused, others belong to another server
+			if machine == nil && !n.inDiscoveryRange(want) {
+				return nil
+			}
+			return newReply(req, dhcpNak, n)
+		}
+		s.leases.add(mac, addr, n.Name)
+		resp = newReply(req, dhcpAck, n)
+		resp.yiaddr = addr
+	case dhcpRelease, dhcpDecline:
+		s.leases.release(mac)
+		return nil
+	default:
+		return nil
+	}
+	resp.options[optLeaseTime] = binary.BigEndian.AppendUint32(nil, uint32(leaseDuration.Seconds()))
+	prefix := interfacePrefix(n.Interface, n.ServerAddress)
+	mask := net.CIDRMask(prefix.Bits(), 32)
+	resp.options[optSubnetMask] = mask
+	// The PXE server is the default router of the machines
+	resp.options[optRouter] = n.ServerAddress.AsSlice()
+	if machine != nil {
+		s.setBootFile(resp, n, machine, req)
+	}
+	return resp
+}
+
+// setBootFile sets the file the client boots: the iPXE script of the machine for iPXE, the iPXE
+// binary for its architecture for PXE firmware. It reports whether the client can boot.
+func (s *Server) setBootFile(resp *packet, n *Network, machine *Machine, req *packet) bool {
+	if req.ipxe() {
+		resp.file = s.httpURL(n, "/boot/"+machine.MACAddress.String()+".ipxe")
+		return true
+	}
+	file := ipxeFile(req.clientArch())
+	if file == "" {
+		return false
+	}
+	resp.siaddr = n.ServerAddress
+	resp.file = file
+	return true
+}
+
+// ipxeFile returns the iPXE binary for the client system architecture of the PXE firmware
+func ipxeFile(arch int) string {
+	switch arch {
+	case archEFIX8664, archEFIBC:
+		return IpxeEfiX8664File
+	case archEFIARM64:
+		return IpxeEfiArm64File
+	}
+	return ""
+}
+
+// newReply returns a reply of the message type to the request
+func newReply(req *packet, msgType byte, n *Network) *packet {
+	resp := &packet{
+		op:      bootReply,
+		xid:     req.xid,
+		flags:   req.flags,
+		giaddr:  req.giaddr,
+		chaddr:  req.chaddr,
+		options: map[byte][]byte{optMessageType: {msgType}, optServerID: n.ServerAddress.AsSlice()},
+	}
+	if msgType == dhcpNak {
+		return resp
+	}
+	resp.ciaddr = req.ciaddr
+	if req.pxeClient() {
+		resp.options[optVendorClass] = []byte(pxeVendorClass)
+		// PXE firmware expects its machine identifier back
+		if uuid, ok := req.options[optClientMachine]; ok {
+			resp.options[optClientMachine] = uuid
+		}
+	}
+	return resp
+}
+
+// owns reports whether the machine boots from the network
+func (n *Network) owns(m *Machine) bool {
+	for i := range n.Machines {
+		if &n.Machines[i] == m {
+			return true
+		}
+	}
+	return false
+}
+
+// inDiscoveryRange reports whether the address belongs to the discovery range of the network
+func (n *Network) inDiscoveryRange(addr netip.Addr) bool {
+	return n.DiscoveryStart.Is4() && n.DiscoveryEnd.Is4() && addr.IsValid() &&
+		n.DiscoveryStart.Compare(addr) <= 0 && addr.Compare(n.DiscoveryEnd) <= 0
+}
+
+// reserved reports whether the address is the one of the boot service or leased to a machine
+func (n *Network) reserved(addr netip.Addr) bool {
+	if addr == n.ServerAddress {
+		return true
+	}
+	for _, m := range n.Machines {
+		if m.Address == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// leaseAddress returns the address of the machine, or else an address of the discovery range for
+// the client: the one it has, the one it asks for or the first that is free
+func (s *Server) leaseAddress(n *Network, machine *Machine, mac string, want netip.Addr) (netip.Addr, bool) {
+	if machine != nil {
+		return machine.Address, machine.Address.Is4()
+	}
+	if !n.inDiscoveryRange(n.DiscoveryStart) {
+		return netip.Addr{}, false
+	}
+	free := func(addr netip.Addr) bool {
+		return n.inDiscoveryRange(addr) && !n.reserved(addr) && s.leases.free(addr, mac)
+	}
+	if lease, ok := s.leases.get(mac); ok && free(lease.Address) {
+		return lease.Address, true
+	}
+	if free(want) {
+		return want, true
+	}
+	for addr, i := n.DiscoveryStart, 0; n.inDiscoveryRange(addr) && i < maxDiscoveryRange; addr, i = addr.Next(), i+1 {
+		if free(addr) {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// interfacePrefix returns the network of the address on the interface. It is a variable so tests
+// do not depend on the interfaces of the host.
+var interfacePrefix = func(iface string, addr netip.Addr) netip.Prefix {
+	if i, err := net.InterfaceByName(iface); err == nil {
+		addrs, _ := i.Addrs()
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok {
+				if p, ok := netip.AddrFromSlice(ipnet.IP); ok && p.Unmap() == addr {
+					bits, _ := ipnet.Mask.Size()
+					return netip.PrefixFrom(addr, bits)
+				}
+			}
+		}
+	}
+	return netip.PrefixFrom(addr, 24)
+}
+
+// listenDHCP listens for DHCP messages on the port of all interfaces. The interface of a message is
+// read from its control message, replies can be broadcast.
+func listenDHCP(ctx context.Context, port int) (*ipv4.PacketConn, error) {
+	lc := net.ListenConfig{Control: func(_, _ string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}}
+	conn, err := lc.ListenPacket(ctx, "udp4", ":"+strconv.Itoa(port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for DHCP on port %d: %w", port, err)
+	}
+	pc := ipv4.NewPacketConn(conn)
+	if err := pc.SetControlMessage(ipv4.FlagInterface, true); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read the interface of DHCP messages: %w", err)
+	}
+	return pc, nil
+}
+
+// serveDHCP answers the DHCP messages of the connection until it is closed
+func (s *Server) serveDHCP(ctx context.Context, conn *ipv4.PacketConn, proxy bool) error {
+	logger := log.FromContext(ctx).WithName("pxe")
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	buf := make([]byte, 1500)
+	for {
+		n, cm, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read DHCP message: %w", err)
+		}
+		if cm == nil {
+			continue
+		}
+		req, err := parsePacket(buf[:n])
+		if err != nil {
+			continue
+		}
+		iface, err := net.InterfaceByIndex(cm.IfIndex)
+		if err != nil {
+			continue
+		}
+		resp := s.reply(iface.Name, req, proxy)
+		if resp == nil {
+			continue
+		}
+		dst := replyDestination(req, src, proxy)
+		out := &ipv4.ControlMessage{IfIndex: cm.IfIndex}
+		if _, err := conn.WriteTo(resp.marshal(), out, dst); err != nil {
+			logger.Error(err, "failed to answer DHCP message", "mac", req.chaddr.String(), "interface", iface.Name)
+		}
+	}
+}
+
+// replyDestination returns where the reply to the request goes, RFC 2131 section 4.1. The boot
+// service cannot unicast to an address the client does not have yet, so it broadcasts instead.
+func replyDestination(req *packet, src net.Addr, proxy bool) net.Addr {
+	switch {
+	case proxy:
+		return src
+	case req.giaddr.IsValid() && !req.giaddr.IsUnspecified():
+		return &net.UDPAddr{IP: req.giaddr.AsSlice(), Port: DHCPPort}
+	case req.ciaddr.IsValid() && !req.ciaddr.IsUnspecified():
+		return &net.UDPAddr{IP: req.ciaddr.AsSlice(), Port: dhcpClientPort}
+	}
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpClientPort}
+}
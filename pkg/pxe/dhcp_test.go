@@ -0,0 +1,170 @@
+package pxe
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func newTestServer(t *testing.T, proxy bool) *Server {
+	t.Helper()
+	orig := interfacePrefix
+	interfacePrefix = func(_ string, addr netip.Addr) netip.Prefix { return netip.PrefixFrom(addr, 24) }
+	t.Cleanup(func() { interfacePrefix = orig })
+
+	s := NewServer(Options{HTTPPort: 8000, AssetsDir: t.TempDir()})
+	s.Update(Config{Networks: []Network{{
+		Name:           "test",
+		Interface:      "eth1",
+		ServerAddress:  netip.MustParseAddr("10.0.0.1"),
+		ProxyDHCP:      proxy,
+		DiscoveryStart: netip.MustParseAddr("10.0.0.100"),
+		DiscoveryEnd:   netip.MustParseAddr("10.0.0.101"),
+		Machines: []Machine{{
+			MACAddress:   mustParseMAC("52:54:00:00:00:02"),
+			Address:      netip.MustParseAddr("10.0.0.2"),
+			TalosVersion: "v1.13.0",
+			Architecture: "amd64",
+		}},
+	}}})
+	return s
+}
+
+func mustParseMAC(mac string) net.HardwareAddr {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		panic(err)
+	}
+	return hw
+}
+
+// newRequest returns a DHCP message of the client as it goes over the wire
+func newRequest(t *testing.T, msgType byte, mac string, options map[byte][]byte) *packet {
+	t.Helper()
+	req := &packet{op: bootRequest, xid: 42, chaddr: mustParseMAC(mac), options: map[byte][]byte{optMessageType: {msgType}}}
+	for code, v := range options {
+		req.options[code] = v
+	}
+	parsed, err := parsePacket(req.marshal())
+	if err != nil {
+		t.Fatalf("failed to parse request: %v", err)
+	}
+	return parsed
+}
+
+// pxeOptions are the options of UEFI PXE firmware on x86_64
+var pxeOptions = map[byte][]byte{optVendorClass: []byte("PXEClient:Arch:00007:UNDI:003016"), optClientArch: {0, archEFIX8664}}
+
+func TestServerReply(t *testing.T) {
+	s := newTestServer(t, false)
+
+	// PXE firmware of a machine of the network gets its address and the iPXE binary
+	offer := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:00:00:02", pxeOptions), false)
+	if offer == nil || offer.messageType() != dhcpOffer || offer.yiaddr != netip.MustParseAddr("10.0.0.2") {
+		t.Fatalf("expected an offer of 10.0.0.2, got %+v", offer)
+	}
+	if offer.file != IpxeEfiX8664File || offer.siaddr != netip.MustParseAddr("10.0.0.1") || string(offer.options[optVendorClass]) != pxeVendorClass {
+		t.Errorf("expected the iPXE binary from 10.0.0.1, got %q from %s", offer.file, offer.siaddr)
+	}
+	if mask := net.IPMask(offer.options[optSubnetMask]).String(); mask != "ffffff00" {
+		t.Errorf("expected a /24 subnet mask, got %s", mask)
+	}
+
+	// iPXE of the machine gets its script
+	ipxe := map[byte][]byte{optUserClass: []byte(ipxeUserClass), optRequestedIP: {10, 0, 0, 2}, optServerID: {10, 0, 0, 1}}
+	ack := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:00:00:02", ipxe), false)
+	if ack == nil || ack.messageType() != dhcpAck || ack.file != "http://10.0.0.1:8000/boot/52:54:00:00:00:02.ipxe" {
+		t.Fatalf("expected the iPXE script of the machine, got %+v", ack)
+	}
+
+	// Other machines get an address of the discovery range without boot information
+	offer = s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:aa:bb:cc", pxeOptions), false)
+	if offer == nil || offer.yiaddr != netip.MustParseAddr("10.0.0.100") || offer.file != "" {
+		t.Fatalf("expected an offer of 10.0.0.100 without boot file, got %+v", offer)
+	}
+	request := map[byte][]byte{optRequestedIP: {10, 0, 0, 100}, optServerID: {10, 0, 0, 1}}
+	if ack := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:aa:bb:cc", request), false); ack == nil || ack.messageType() != dhcpAck {
+		t.Fatalf("expected the lease of 10.0.0.100 to be acknowledged, got %+v", ack)
+	}
+	leases := s.Leases()
+	if len(leases) != 2 || leases[1].MACAddress != "52:54:00:aa:bb:cc" || leases[1].Address != netip.MustParseAddr("10.0.0.100") {
+		t.Errorf("expected the leases of both machines, got %+v", leases)
+	}
+
+	// The leased address is not given to another machine, nor the address of a machine of the network
+	if nak := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:aa:bb:cd", request), false); nak == nil || nak.messageType() != dhcpNak {
+		t.Errorf("expected a leased address to be refused, got %+v", nak)
+	}
+	if offer := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:aa:bb:cd", map[byte][]byte{optRequestedIP: {10, 0, 0, 2}}), false); offer == nil || offer.yiaddr != netip.MustParseAddr("10.0.0.101") {
+		t.Errorf("expected an offer of 10.0.0.101, got %+v", offer)
+	}
+
+	// Requests for another server and on other interfaces are ignored
+	request[optServerID] = []byte{10, 0, 0, 254}
+	if resp := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:aa:bb:cc", request), false); resp != nil {
+		t.Errorf("expected a request for another server to be ignored, got %+v", resp)
+	}
+	if resp := s.reply("eth0", newRequest(t, dhcpDiscover, "52:54:00:00:00:02", pxeOptions), false); resp != nil {
+		t.Errorf("expected a request on another interface to be ignored, got %+v", resp)
+	}
+
+	// A released address is free again
+	s.reply("eth1", newRequest(t, dhcpRelease, "52:54:00:aa:bb:cc", nil), false)
+	if leases := s.Leases(); len(leases) != 1 {
+		t.Errorf("expected the lease to be released, got %+v", leases)
+	}
+}
+
+func TestProxyReply(t *testing.T) {
+	s := newTestServer(t, true)
+
+	// The offer only carries the boot information, the address comes from another DHCP server
+	offer := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:00:00:02", pxeOptions), false)
+	if offer == nil || offer.messageType() != dhcpOffer || offer.yiaddr.IsValid() && !offer.yiaddr.IsUnspecified() {
+		t.Fatalf("expected an offer without address, got %+v", offer)
+	}
+	if offer.file != IpxeEfiX8664File || offer.options[optLeaseTime] != nil {
+		t.Errorf("expected only the iPXE binary, got %q with options %v", offer.file, offer.options)
+	}
+	// The request for the address goes to the other server, the firmware asks for the boot file on the ProxyDHCP port
+	if resp := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:00:00:02", pxeOptions), false); resp != nil {
+		t.Errorf("expected the request for the address to be ignored, got %+v", resp)
+	}
+	if ack := s.reply("eth1", newRequest(t, dhcpRequest, "52:54:00:00:00:02", pxeOptions), true); ack == nil || ack.messageType() != dhcpAck || ack.file != IpxeEfiX8664File {
+		t.Errorf("expected the boot file on the ProxyDHCP port, got %+v", ack)
+	}
+
+	// Machines that are not part of the network and clients that are not PXE firmware are ignored
+	if resp := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:aa:bb:cc", pxeOptions), false); resp != nil {
+		t.Errorf("expected an unknown machine to be ignored, got %+v", resp)
+	}
+	if resp := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:00:00:02", nil), false); resp != nil {
+		t.Errorf("expected a client without PXE to be ignored, got %+v", resp)
+	}
+
+	// The configuration is replaced while the service runs
+	s.Update(Config{})
+	if resp := s.reply("eth1", newRequest(t, dhcpDiscover, "52:54:00:00:00:02", pxeOptions), false); resp != nil {
+		t.Errorf("expected no answer after the network was removed, got %+v", resp)
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	long := make([]byte, 300)
+	req := &packet{op: bootReply, xid: 7, chaddr: mustParseMAC("52:54:00:00:00:02"), file: "ipxe.efi",
+		yiaddr: netip.MustParseAddr("10.0.0.2"), options: map[byte][]byte{optMessageType: {dhcpAck}, optVendorInfo: long}}
+	b := req.marshal()
+	if b[dhcpHeaderLen] != optMessageType {
+		t.Errorf("expected the message type to be the first option, got %d", b[dhcpHeaderLen])
+	}
+	p, err := parsePacket(b)
+	if err != nil {
+		t.Fatalf("failed to parse packet: %v", err)
+	}
+	if p.xid != 7 || p.yiaddr != req.yiaddr || p.chaddr.String() != "52:54:00:00:00:02" || len(p.options[optVendorInfo]) != len(long) {
+		t.Errorf("unexpected packet %+v", p)
+	}
+	if _, err := parsePacket(b[:100]); err == nil {
+		t.Errorf("expected a truncated packet to fail")
+	}
+}
@@ -0,0 +1,76 @@
+package pxe
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// talosKernelArgs are the kernel arguments Talos boots from the network with
+const talosKernelArgs = "initrd=initramfs.xz slab_nomerge pti=on console=tty0 printk.devkmsg=on talos.platform=metal"
+
+// Handler returns the HTTP endpoint that serves the iPXE scripts of the machines by their MAC
+// address and the Talos boot images they load
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /boot/{file}", s.serveScript)
+	mux.HandleFunc("GET /assets/{version}/{file}", s.serveAsset)
+	return mux
+}
+
+// serveScript serves the iPXE script of the machine with the MAC address of the file name
+func (s *Server) serveScript(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	if !strings.HasSuffix(file, ".ipxe") {
+		http.NotFound(w, r)
+		return
+	}
+	snap := s.config.Load()
+	machine := snap.machines[normalizeMAC(strings.TrimSuffix(file, ".ipxe"))]
+	if machine == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var n *Network
+	for i := range snap.config.Networks {
+		if snap.config.Networks[i].owns(machine) {
+			n = &snap.config.Networks[i]
+		}
+	}
+	if n == nil || !validVersion(machine.TalosVersion) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = fmt.Fprint(w, s.script(n, machine))
+}
+
+// script returns the iPXE script that boots Talos on the machine
+func (s *Server) script(n *Network, m *Machine) string {
+	args := talosKernelArgs
+	if m.KernelArgs != "" {
+		args += " " + m.KernelArgs
+	}
+	return fmt.Sprintf("#!ipxe\nkernel %s %s\ninitrd --name initramfs.xz %s\nboot\n",
+		s.httpURL(n, kernelPath(m.TalosVersion, m.Architecture)), args,
+		s.httpURL(n, initramfsPath(m.TalosVersion, m.Architecture)))
+}
+
+// serveAsset serves a Talos boot image a machine of the configuration boots from
+func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.assets(s.Config())[path.Join(talosAssetsDir, r.PathValue("version"), r.PathValue("file"))]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	local, err := s.fetch(r.Context(), a)
+	if err != nil {
+		log.FromContext(r.Context()).WithName("pxe").Error(err, "failed to download boot image", "path", a.path)
+		http.Error(w, "boot image is not available", http.StatusBadGateway)
+		return
+	}
+	http.ServeFile(w, r, local)
+}
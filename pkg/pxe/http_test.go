@@ -0,0 +1,114 @@
+package pxe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newImageServer serves every file with its path as content and counts the downloads
+func newImageServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &downloads
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	images, downloads := newImageServer(t)
+	s := newTestServer(t, false)
+	s.opts.TalosImagesBaseURL = images.URL
+	h := s.Handler()
+
+	rec := get(t, h, "/boot/52-54-00-00-00-02.ipxe")
+	want := "#!ipxe\n" +
+		"kernel http://10.0.0.1:8000/assets/v1.13.0/vmlinuz-amd64 " + talosKernelArgs + "\n" +
+		"initrd --name initramfs.xz http://10.0.0.1:8000/assets/v1.13.0/initramfs-amd64.xz\n" +
+		"boot\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Fatalf("unexpected script %d:\n%s", rec.Code, rec.Body.String())
+	}
+	if rec := get(t, h, "/boot/52:54:00:aa:bb:cc.ipxe"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected no script for an unknown machine, got %d", rec.Code)
+	}
+
+	// Boot images are downloaded once on the first request
+	for range 2 {
+		rec = get(t, h, "/assets/v1.13.0/vmlinuz-amd64")
+		if rec.Code != http.StatusOK || rec.Body.String() != "/v1.13.0/vmlinuz-amd64" {
+			t.Fatalf("unexpected kernel %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if downloads.Load() != 1 {
+		t.Errorf("expected the kernel to be downloaded once, got %d downloads", downloads.Load())
+	}
+	// Only the boot images of the machines are served
+	for _, path := range []string{"/assets/v1.12.0/vmlinuz-amd64", "/assets/v1.13.0/vmlinuz-arm64", "/assets/../../etc/passwd"} {
+		if rec := get(t, h, path); rec.Code == http.StatusOK {
+			t.Errorf("expected %s not to be served, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestSyncAssets(t *testing.T) {
+	ctx := context.Background()
+	images, downloads := newImageServer(t)
+	s := newTestServer(t, false)
+	s.opts.TalosImagesBaseURL = images.URL
+	s.opts.IPXEBaseURL = images.URL
+	stale := filepath.Join(s.opts.AssetsDir, talosAssetsDir, "v1.12.0", "vmlinuz-amd64")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatalf("failed to create stale image: %v", err)
+	}
+	if err := os.WriteFile(stale, nil, 0o600); err != nil {
+		t.Fatalf("failed to create stale image: %v", err)
+	}
+
+	if err := s.SyncAssets(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, file := range []string{"talos/v1.13.0/vmlinuz-amd64", "talos/v1.13.0/initramfs-amd64.xz", "ipxe/" + IpxeEfiX8664File} {
+		if _, err := os.Stat(filepath.Join(s.opts.AssetsDir, file)); err != nil {
+			t.Errorf("expected %s to be downloaded: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(stale)); !os.IsNotExist(err) {
+		t.Errorf("expected the images of an unused version to be removed, got %v", err)
+	}
+	if err := s.SyncAssets(ctx); err != nil || downloads.Load() != 3 {
+		t.Errorf("expected no downloads of existing images, got %d downloads, %v", downloads.Load(), err)
+	}
+
+	// A failed download is reported and leaves no partial file behind
+	cfg := s.Config()
+	cfg.Networks[0].Machines[0].TalosVersion = "v1.14.0-missing"
+	s.Update(cfg)
+	if err := s.SyncAssets(ctx); err == nil {
+		t.Fatalf("expected a missing image to fail")
+	}
+	entries, err := os.ReadDir(filepath.Join(s.opts.AssetsDir, talosAssetsDir, "v1.14.0-missing"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected no partial download, got %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,86 @@
+package pxe
+
+import (
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// leaseDuration is how long an address is leased, clients renew it halfway through
+	leaseDuration = time.Hour
+	// maxDiscoveryRange is the number of addresses of a discovery range that are leased at most
+	maxDiscoveryRange = 4096
+)
+
+// Lease is an address the boot service leased to a machine
+type Lease struct {
+	MACAddress string
+	Address    netip.Addr
+	// Network is the name of the network the address belongs to
+	Network string
+	Expiry  time.Time
+}
+
+// leaseTable keeps the leases of the boot service by the MAC address of the machines. Leases are
+// kept in memory, clients renew theirs with the boot service after it restarted.
+type leaseTable struct {
+	mu     sync.Mutex
+	leases map[string]Lease
+}
+
+func newLeaseTable() *leaseTable {
+	return &leaseTable{leases: make(map[string]Lease)}
+}
+
+// add leases the address to the MAC address
+func (t *leaseTable) add(mac string, addr netip.Addr, network string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.leases[mac] = Lease{MACAddress: mac, Address: addr, Network: network, Expiry: time.Now().Add(leaseDuration)}
+}
+
+// release gives the address leased to the MAC address back
+func (t *leaseTable) release(mac string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.leases, mac)
+}
+
+// get returns the lease of the MAC address
+func (t *leaseTable) get(mac string) (Lease, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lease, ok := t.leases[mac]
+	return lease, ok
+}
+
+// free reports whether the address is not leased to another MAC address
+func (t *leaseTable) free(addr netip.Addr, mac string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for m, lease := range t.leases {
+		if lease.Address == addr && m != mac && lease.Expiry.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// active returns the leases that did not expire by their address and drops the expired ones
+func (t *leaseTable) active(now time.Time) []Lease {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	leases := make([]Lease, 0, len(t.leases))
+	for mac, lease := range t.leases {
+		if !lease.Expiry.After(now) {
+			delete(t.leases, mac)
+			continue
+		}
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Address.Less(leases[j].Address) })
+	return leases
+}
@@ -0,0 +1,198 @@
+// Package pxe implements the boot service the operator PXE boots bare metal machines with. It answers
+// DHCP and ProxyDHCP requests, serves the iPXE binaries over TFTP and the iPXE scripts and Talos boot
+// images of the machines over HTTP. Its configuration is replaced as a whole while it is running.
+package pxe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Ports the boot service listens on
+const (
+	DHCPPort        = 67
+	ProxyDHCPPort   = 4011
+	TFTPPort        = 69
+	DefaultHTTPPort = 8000
+)
+
+// DefaultAssetsDir is the directory the iPXE binaries and the Talos boot images are cached in
+const DefaultAssetsDir = "/var/lib/talos-operator/pxe"
+
+// Config is the configuration of the boot service
+type Config struct {
+	Networks []Network
+}
+
+// Network is a network the boot service boots machines on
+type Network struct {
+	// Name of the network, the TalosCluster it belongs to
+	Name string
+	// Interface the network is attached to, as given by Linux
+	Interface string
+	// ServerAddress is the address of the boot service on the network
+	ServerAddress netip.Addr
+	// ProxyDHCP makes the boot service only add the boot information to the offers of another DHCP
+	// server on the network, which leases the addresses
+	ProxyDHCP bool
+	// DiscoveryStart and DiscoveryEnd are the range of addresses leased to machines that are not
+	// part of the network, the range is not leased when they are invalid
+	DiscoveryStart netip.Addr
+	DiscoveryEnd   netip.Addr
+	// Machines are the machines that boot Talos from the network
+	Machines []Machine
+}
+
+// Machine is a machine that boots Talos from the network
+type Machine struct {
+	MACAddress net.HardwareAddr
+	// Address is leased to the machine, unless the network uses ProxyDHCP
+	Address      netip.Addr
+	TalosVersion string
+	// Architecture is the CPU architecture of the machine, amd64 or arm64
+	Architecture string
+	// KernelArgs are added to the kernel command line
+	KernelArgs string
+}
+
+// Options configure where the boot service serves from and downloads the boot images from
+type Options struct {
+	// HTTPPort is the port of the HTTP endpoint that serves the iPXE scripts and the boot images
+	HTTPPort int
+	// AssetsDir caches the iPXE binaries and the Talos boot images
+	AssetsDir string
+	// TalosImagesBaseURL is where the kernel and initramfs of a Talos version are downloaded from
+	TalosImagesBaseURL string
+	// IPXEBaseURL is where the iPXE binaries are downloaded from
+	IPXEBaseURL string
+}
+
+// Server is the boot service. It runs as a Runnable of the manager of the operator.
+type Server struct {
+	opts   Options
+	config atomic.Pointer[snapshot]
+	leases *leaseTable
+	// assetsMu serializes the downloads and the cleanup of the assets directory
+	assetsMu   sync.Mutex
+	httpClient *http.Client
+}
+
+// snapshot is a configuration indexed for the lookups of the requests
+type snapshot struct {
+	config     Config
+	interfaces map[string]*Network
+	machines   map[string]*Machine
+}
+
+// NewServer returns a boot service without networks
+func NewServer(opts Options) *Server {
+	if opts.HTTPPort == 0 {
+		opts.HTTPPort = DefaultHTTPPort
+	}
+	if opts.AssetsDir == "" {
+		opts.AssetsDir = DefaultAssetsDir
+	}
+	s := &Server{opts: opts, leases: newLeaseTable(), httpClient: &http.Client{}}
+	s.Update(Config{})
+	return s
+}
+
+// Update replaces the configuration of the boot service. Requests that are answered already keep
+// the configuration they started with.
+func (s *Server) Update(cfg Config) {
+	snap := &snapshot{config: cfg, interfaces: make(map[string]*Network), machines: make(map[string]*Machine)}
+	for i := range cfg.Networks {
+		n := &snap.config.Networks[i]
+		snap.interfaces[n.Interface] = n
+		for j := range n.Machines {
+			snap.machines[n.Machines[j].MACAddress.String()] = &n.Machines[j]
+		}
+	}
+	s.config.Store(snap)
+}
+
+// Config returns the current configuration of the boot service
+func (s *Server) Config() Config {
+	return s.config.Load().config
+}
+
+// Leases returns the addresses the boot service leased and that did not expire
+func (s *Server) Leases() []Lease {
+	return s.leases.active(time.Now())
+}
+
+// NeedLeaderElection makes only the leader answer requests, so machines do not get two answers
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+// Start serves DHCP, ProxyDHCP, TFTP and HTTP until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("pxe")
+	dhcp, err := listenDHCP(ctx, DHCPPort)
+	if err != nil {
+		return err
+	}
+	defer dhcp.Close() //nolint:errcheck
+	proxy, err := listenDHCP(ctx, ProxyDHCPPort)
+	if err != nil {
+		return err
+	}
+	defer proxy.Close() //nolint:errcheck
+	tftp, err := net.ListenPacket("udp4", ":"+strconv.Itoa(TFTPPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for TFTP: %w", err)
+	}
+	defer tftp.Close() //nolint:errcheck
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(s.opts.HTTPPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for HTTP: %w", err)
+	}
+	httpServer := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 4)
+	go func() { errs <- s.serveDHCP(ctx, dhcp, false) }()
+	go func() { errs <- s.serveDHCP(ctx, proxy, true) }()
+	go func() { errs <- s.serveTFTP(ctx, tftp) }()
+	go func() {
+		if err := httpServer.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("failed to serve HTTP: %w", err)
+		}
+	}()
+	logger.Info("Started PXE boot service", "httpPort", s.opts.HTTPPort)
+
+	select {
+	case <-ctx.Done():
+		err = nil
+	case err = <-errs:
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = httpServer.Shutdown(shutdownCtx)
+	return err
+}
+
+// httpURL returns the URL of the HTTP endpoint on the network for the path
+func (s *Server) httpURL(n *Network, path string) string {
+	return "http://" + net.JoinHostPort(n.ServerAddress.String(), strconv.Itoa(s.opts.HTTPPort)) + path
+}
+
+// normalizeMAC returns the MAC address in the form net.HardwareAddr prints it, empty if it is invalid
+func normalizeMAC(mac string) string {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return ""
+	}
+	return hw.String()
+}
@@ -0,0 +1,220 @@
+package pxe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TFTP opcodes, RFC 1350 and RFC 2347
+const (
+	tftpRRQ   = 1
+	tftpData  = 3
+	tftpAck   = 4
+	tftpError = 5
+	tftpOAck  = 6
+)
+
+// TFTP error codes
+const (
+	tftpErrUndefined  = 0
+	tftpErrNotFound   = 1
+	tftpErrIllegalOp  = 4
+	tftpErrUnknownTID = 5
+)
+
+const (
+	tftpMinBlockSize     = 8
+	tftpDefaultBlockSize = 512
+	// tftpMaxBlockSize keeps the blocks within an Ethernet frame
+	tftpMaxBlockSize    = 1468
+	tftpDefaultTimeout  = 2 * time.Second
+	tftpRetransmissions = 5
+)
+
+// tftpRequest is a read request and the options the server accepted, RFC 2348 and RFC 2349
+type tftpRequest struct {
+	file      string
+	blockSize int
+	timeout   time.Duration
+	// options the server acknowledges, tsize is filled in once the size of the file is known
+	options []string
+}
+
+// parseReadRequest parses a TFTP read request. Write requests are refused, the boot service only
+// serves files.
+func parseReadRequest(b []byte) (*tftpRequest, error) {
+	if len(b) < 2 || binary.BigEndian.Uint16(b) != tftpRRQ {
+		return nil, errors.New("only read requests are supported")
+	}
+	fields := strings.Split(strings.TrimSuffix(string(b[2:]), "\x00"), "\x00")
+	if len(fields) < 2 || fields[0] == "" {
+		return nil, errors.New("malformed read request")
+	}
+	if mode := strings.ToLower(fields[1]); mode != "octet" {
+		return nil, fmt.Errorf("unsupported transfer mode %s", fields[1])
+	}
+	req := &tftpRequest{file: strings.TrimPrefix(fields[0], "/"), blockSize: tftpDefaultBlockSize, timeout: tftpDefaultTimeout}
+	for i := 2; i+1 < len(fields); i += 2 {
+		name, value := strings.ToLower(fields[i]), fields[i+1]
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "blksize":
+			req.blockSize = min(max(n, tftpMinBlockSize), tftpMaxBlockSize)
+			req.options = append(req.options, name, strconv.Itoa(req.blockSize))
+		case "timeout":
+			if n >= 1 && n <= 255 {
+				req.timeout = time.Duration(n) * time.Second
+				req.options = append(req.options, name, value)
+			}
+		case "tsize":
+			req.options = append(req.options, name, "")
+		}
+	}
+	return req, nil
+}
+
+// serveTFTP answers the read requests of the connection until it is closed. Every transfer runs on
+// its own port, as TFTP requires.
+func (s *Server) serveTFTP(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	buf := make([]byte, 1500)
+	for {
+		n, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read TFTP request: %w", err)
+		}
+		go s.tftpTransfer(ctx, bytes.Clone(buf[:n]), src)
+	}
+}
+
+// tftpTransfer sends the iPXE binary of the read request to the client
+func (s *Server) tftpTransfer(ctx context.Context, b []byte, client net.Addr) {
+	logger := log.FromContext(ctx).WithName("pxe")
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		logger.Error(err, "failed to open TFTP transfer", "client", client.String())
+		return
+	}
+	defer conn.Close() //nolint:errcheck
+	req, err := parseReadRequest(b)
+	if err != nil {
+		sendTFTPError(conn, client, tftpErrIllegalOp, err.Error())
+		return
+	}
+	a, ok := s.ipxeAsset(req.file)
+	if !ok {
+		sendTFTPError(conn, client, tftpErrNotFound, "file not found")
+		return
+	}
+	local, err := s.fetch(ctx, a)
+	if err != nil {
+		logger.Error(err, "failed to download iPXE binary", "file", req.file)
+		sendTFTPError(conn, client, tftpErrUndefined, "file is not available")
+		return
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		sendTFTPError(conn, client, tftpErrUndefined, "file is not available")
+		return
+	}
+	if err := sendTFTPFile(conn, client, req, data); err != nil {
+		logger.V(1).Info("TFTP transfer did not complete", "file", req.file, "client", client.String(), "reason", err.Error())
+	}
+}
+
+// sendTFTPFile sends the file block by block and waits for the acknowledgement of every block
+func sendTFTPFile(conn net.PacketConn, client net.Addr, req *tftpRequest, data []byte) error {
+	if len(req.options) > 0 {
+		oack := binary.BigEndian.AppendUint16(nil, tftpOAck)
+		for i := 0; i < len(req.options); i += 2 {
+			value := req.options[i+1]
+			if req.options[i] == "tsize" {
+				value = strconv.Itoa(len(data))
+			}
+			oack = append(append(append(append(oack, req.options[i]...), 0), value...), 0)
+		}
+		if err := sendTFTPBlock(conn, client, req.timeout, oack, 0); err != nil {
+			return err
+		}
+	}
+	for block := 1; ; block++ {
+		start := min((block-1)*req.blockSize, len(data))
+		end := min(start+req.blockSize, len(data))
+		pkt := binary.BigEndian.AppendUint16(nil, tftpData)
+		pkt = binary.BigEndian.AppendUint16(pkt, uint16(block))
+		pkt = append(pkt, data[start:end]...)
+		if err := sendTFTPBlock(conn, client, req.timeout, pkt, uint16(block)); err != nil {
+			return err
+		}
+		// A block shorter than the block size ends the transfer
+		if end-start < req.blockSize {
+			return nil
+		}
+	}
+}
+
+// sendTFTPBlock sends the packet until the client acknowledges the block
+func sendTFTPBlock(conn net.PacketConn, client net.Addr, timeout time.Duration, pkt []byte, block uint16) error {
+	buf := make([]byte, 516)
+	for range tftpRetransmissions {
+		if _, err := conn.WriteTo(pkt, client); err != nil {
+			return err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+		for {
+			n, src, err := conn.ReadFrom(buf)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if src.String() != client.String() {
+				sendTFTPError(conn, src, tftpErrUnknownTID, "unknown transfer")
+				continue
+			}
+			if n < 4 {
+				continue
+			}
+			switch binary.BigEndian.Uint16(buf) {
+			case tftpError:
+				// Firmware aborts the transfer once it read the size of the file
+				return fmt.Errorf("client aborted the transfer: %s", strings.TrimRight(string(buf[4:n]), "\x00"))
+			case tftpAck:
+				if binary.BigEndian.Uint16(buf[2:]) == block {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("client did not acknowledge block %d", block)
+}
+
+func sendTFTPError(conn net.PacketConn, client net.Addr, code uint16, msg string) {
+	pkt := binary.BigEndian.AppendUint16(nil, tftpError)
+	pkt = binary.BigEndian.AppendUint16(pkt, code)
+	pkt = append(append(pkt, msg...), 0)
+	_, _ = conn.WriteTo(pkt, client)
+}
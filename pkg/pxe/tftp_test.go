@@ -0,0 +1,99 @@
+package pxe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// readTFTP reads the next packet of the transfer and returns it with the address it came from
+func readTFTP(t *testing.T, conn net.PacketConn) ([]byte, net.Addr) {
+	t.Helper()
+	buf := make([]byte, 1500)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, src, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read TFTP packet: %v", err)
+	}
+	return buf[:n], src
+}
+
+func ackTFTP(t *testing.T, conn net.PacketConn, dst net.Addr, block uint16) {
+	t.Helper()
+	pkt := binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(nil, tftpAck), block)
+	if _, err := conn.WriteTo(pkt, dst); err != nil {
+		t.Fatalf("failed to acknowledge block %d: %v", block, err)
+	}
+}
+
+func TestServeTFTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	images, _ := newImageServer(t)
+	s := newTestServer(t, false)
+	s.opts.IPXEBaseURL = images.URL
+	server, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = s.serveTFTP(ctx, server) }()
+	client, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	// The firmware negotiates the block size and asks for the size of the file
+	rrq := append(binary.BigEndian.AppendUint16(nil, tftpRRQ), "/"+IpxeEfiX8664File+"\x00octet\x00blksize\x008\x00tsize\x000\x00"...)
+	if _, err := client.WriteTo(rrq, server.LocalAddr()); err != nil {
+		t.Fatalf("failed to send read request: %v", err)
+	}
+	oack, transfer := readTFTP(t, client)
+	if want := "\x00\x06blksize\x008\x00tsize\x0020\x00"; string(oack) != want {
+		t.Fatalf("expected the options to be acknowledged, got %q", oack)
+	}
+	ackTFTP(t, client, transfer, 0)
+	var file []byte
+	for block := uint16(1); ; block++ {
+		data, _ := readTFTP(t, client)
+		if binary.BigEndian.Uint16(data) != tftpData || binary.BigEndian.Uint16(data[2:]) != block {
+			t.Fatalf("expected block %d, got %v", block, data)
+		}
+		file = append(file, data[4:]...)
+		ackTFTP(t, client, transfer, block)
+		if len(data[4:]) < 8 {
+			break
+		}
+	}
+	if want := "/x86_64-efi/ipxe.efi"; !bytes.Equal(file, []byte(want)) {
+		t.Errorf("expected %q, got %q", want, file)
+	}
+
+	// Only the iPXE binaries are served
+	rrq = append(binary.BigEndian.AppendUint16(nil, tftpRRQ), "../etc/passwd\x00octet\x00"...)
+	if _, err := client.WriteTo(rrq, server.LocalAddr()); err != nil {
+		t.Fatalf("failed to send read request: %v", err)
+	}
+	if pkt, _ := readTFTP(t, client); binary.BigEndian.Uint16(pkt) != tftpError || binary.BigEndian.Uint16(pkt[2:]) != tftpErrNotFound {
+		t.Errorf("expected file not found, got %q", pkt)
+	}
+}
+
+func TestParseReadRequest(t *testing.T) {
+	req, err := parseReadRequest(append([]byte{0, tftpRRQ}, "ipxe.efi\x00OCTET\x00blksize\x0065464\x00timeout\x00300\x00"...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.file != "ipxe.efi" || req.blockSize != tftpMaxBlockSize || req.timeout != tftpDefaultTimeout {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if _, err := parseReadRequest(append([]byte{0, 2}, "ipxe.efi\x00octet\x00"...)); err == nil {
+		t.Errorf("expected a write request to fail")
+	}
+	if _, err := parseReadRequest(append([]byte{0, tftpRRQ}, "ipxe.efi\x00netascii\x00"...)); err == nil {
+		t.Errorf("expected the netascii mode to fail")
+	}
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// AzureBlobClient wraps the Azure Blob client for etcd backup operations
+type AzureBlobClient struct {
+	client    *azblob.Client
+	container string
+}
+
+var _ Backend = (*AzureBlobClient)(nil)
+
+// AzureBlobConfig contains configuration for Azure Blob client
+type AzureBlobConfig struct {
+	AccountName string
+	// AccountKey is the shared key of the storage account. If empty, the default Azure
+	// credential chain (e.g. workload identity) of the operator is used.
+	AccountKey string
+	Container  string
+	Endpoint   string
+}
+
+// NewAzureBlobClient creates a new Azure Blob client with the provided configuration
+func NewAzureBlobClient(cfg *AzureBlobConfig) (*AzureBlobClient, error) {
+	if cfg.Container == "" {
+		return nil, fmt.Errorf("container name is required")
+	}
+	if cfg.AccountName == "" {
+		return nil, fmt.Errorf("account name is required")
+	}
+	serviceURL := cfg.Endpoint
+	if serviceURL == "" {
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", cfg.AccountName)
+	}
+
+	var (
+		client *azblob.Client
+		err    error
+	)
+	if cfg.AccountKey != "" {
+		cred, credErr := azblob.NewSharedKeyCredential(cfg.AccountName, cfg.AccountKey)
+		if credErr != nil {
+			return nil, fmt.Errorf("failed to create Azure shared key credential: %w", credErr)
+		}
+		client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	} else {
+		cred, credErr := azidentity.NewDefaultAzureCredential(nil)
+		if credErr != nil {
+			return nil, fmt.Errorf("failed to create Azure default credential: %w", credErr)
+		}
+		client, err = azblob.NewClient(serviceURL, cred, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob client: %w", err)
+	}
+
+	return &AzureBlobClient{
+		client:    client,
+		container: cfg.Container,
+	}, nil
+}
+
+// Upload streams data from a reader to the blob with the specified key
+func (a *AzureBlobClient) Upload(ctx context.Context, key string, reader io.Reader) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if _, err := a.client.UploadStream(ctx, a.container, key, reader, nil); err != nil {
+		return fmt.Errorf("failed to upload to Azure Blob: %w", err)
+	}
+	return nil
+}
+
+// Download returns a reader streaming the blob stored under the specified key
+func (a *AzureBlobClient) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	resp, err := a.client.DownloadStream(ctx, a.container, key, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to download blob from Azure Blob: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the blob with the specified key
+func (a *AzureBlobClient) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if _, err := a.client.DeleteBlob(ctx, a.container, key, nil); err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete blob from Azure Blob: %w", err)
+	}
+	return nil
+}
+
+// SetMetadata replaces the user metadata of the blob with the specified key
+func (a *AzureBlobClient) SetMetadata(ctx context.Context, key string, metadata map[string]string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	blobMetadata := make(map[string]*string, len(metadata))
+	for k, v := range metadata {
+		blobMetadata[k] = to.Ptr(v)
+	}
+	blobClient := a.client.ServiceClient().NewContainerClient(a.container).NewBlobClient(key)
+	if _, err := blobClient.SetMetadata(ctx, blobMetadata, nil); err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return fmt.Errorf("failed to set blob metadata in Azure Blob: %w", err)
+	}
+	return nil
+}
+
+// List returns the keys of all blobs in the container that start with the specified prefix
+func (a *AzureBlobClient) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	pager := a.client.NewListBlobsFlatPager(a.container, &azblob.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blobs in Azure Blob: %w", err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil {
+				keys = append(keys, *item.Name)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Close releases the resources of the client. The Azure client holds no connections that need to be released.
+func (a *AzureBlobClient) Close() error {
+	return nil
+}
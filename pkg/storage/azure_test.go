@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+const (
+	testContainer   = "test-container"
+	testAccountName = "testaccount"
+)
+
+func TestNewAzureBlobClient_ValidConfig(t *testing.T) {
+	cfg := &AzureBlobConfig{
+		AccountName: testAccountName,
+		AccountKey:  base64.StdEncoding.EncodeToString([]byte(testSecretKey)),
+		Container:   testContainer,
+	}
+
+	client, err := NewAzureBlobClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if client == nil {
+		t.Fatal("Expected client to be non-nil")
+	}
+
+	if client.container != testContainer {
+		t.Errorf("Expected container to be '%s', got '%s'", testContainer, client.container)
+	}
+}
+
+func TestNewAzureBlobClient_MissingContainer(t *testing.T) {
+	cfg := &AzureBlobConfig{
+		AccountName: testAccountName,
+	}
+
+	_, err := NewAzureBlobClient(cfg)
+	if err == nil {
+		t.Fatal("Expected error for missing container, got nil")
+	}
+
+	expectedError := "container name is required"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+func TestNewAzureBlobClient_MissingAccountName(t *testing.T) {
+	cfg := &AzureBlobConfig{
+		Container: testContainer,
+	}
+
+	_, err := NewAzureBlobClient(cfg)
+	if err == nil {
+		t.Fatal("Expected error for missing account name, got nil")
+	}
+
+	expectedError := "account name is required"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// ChecksumReader computes the SHA-256 checksum and the size of the data read through it
+type ChecksumReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+// NewChecksumReader wraps a reader so the checksum of the data is computed while it is streamed
+func NewChecksumReader(reader io.Reader) *ChecksumReader {
+	h := sha256.New()
+	return &ChecksumReader{
+		reader: io.TeeReader(reader, h),
+		hash:   h,
+	}
+}
+
+func (c *ChecksumReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.size += int64(n)
+	return n, err
+}
+
+// SHA256 returns the hex encoded SHA-256 checksum of the data read so far
+func (c *ChecksumReader) SHA256() string {
+	return hex.EncodeToString(c.hash.Sum(nil))
+}
+
+// Size returns the number of bytes read so far
+func (c *ChecksumReader) Size() int64 {
+	return c.size
+}
+
+// Checksum reads the reader to the end and returns the hex encoded SHA-256 checksum and the size of the data
+func Checksum(reader io.Reader) (string, int64, error) {
+	cr := NewChecksumReader(reader)
+	if _, err := io.Copy(io.Discard, cr); err != nil {
+		return "", 0, err
+	}
+	return cr.SHA256(), cr.Size(), nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestChecksumReader(t *testing.T) {
+	cr := NewChecksumReader(strings.NewReader("hello"))
+	data, err := io.ReadAll(cr)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected data to pass through unchanged, got %q", data)
+	}
+	expectedSum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if cr.SHA256() != expectedSum {
+		t.Errorf("expected checksum %s, got %s", expectedSum, cr.SHA256())
+	}
+	if cr.Size() != 5 {
+		t.Errorf("expected size 5, got %d", cr.Size())
+	}
+}
+
+func TestChecksum_Empty(t *testing.T) {
+	sum, size, err := Checksum(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("failed to compute checksum: %v", err)
+	}
+	expectedSum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if sum != expectedSum || size != 0 {
+		t.Errorf("expected checksum %s and size 0, got %s and %d", expectedSum, sum, size)
+	}
+}
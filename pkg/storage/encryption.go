@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+const (
+	// EncryptionTypeAge encrypts backups with an age X25519 identity
+	EncryptionTypeAge = "age"
+	// EncryptionTypeAESGCM encrypts backups with a 256-bit AES-GCM key
+	EncryptionTypeAESGCM = "aes-gcm"
+)
+
+const (
+	// aesGCMMagic identifies the chunked AES-GCM stream format
+	aesGCMMagic = "TALOSAES"
+	// aesGCMVersion is the version of the chunked AES-GCM stream format
+	aesGCMVersion = 1
+	// aesGCMChunkSize is the size of a plaintext chunk
+	aesGCMChunkSize = 64 * 1024
+	// aesGCMNoncePrefixSize is the size of the random nonce prefix in the header,
+	// the remaining 5 bytes of the nonce are the chunk counter and the last chunk flag
+	aesGCMNoncePrefixSize = 7
+)
+
+// Encryptor encrypts backup objects before they are uploaded and decrypts them after they are downloaded.
+// Both directions are streaming so snapshots never have to fit in memory.
+type Encryptor interface {
+	// EncryptReader returns a reader producing the ciphertext of src
+	EncryptReader(src io.Reader) (io.ReadCloser, error)
+	// DecryptReader returns a reader producing the plaintext of src
+	DecryptReader(src io.Reader) (io.Reader, error)
+}
+
+// NewEncryptor creates an encryptor for the given encryption type. For age the key is an
+// X25519 identity (AGE-SECRET-KEY-1...), for aes-gcm it is a 32 byte key, raw or base64 encoded.
+func NewEncryptor(encryptionType string, key []byte) (Encryptor, error) {
+	switch encryptionType {
+	case EncryptionTypeAge:
+		identity, err := age.ParseX25519Identity(strings.TrimSpace(string(key)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age identity: %w", err)
+		}
+		return &ageEncryptor{identity: identity}, nil
+	case EncryptionTypeAESGCM:
+		aesKey, err := parseAESKey(key)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(aesKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AES-GCM cipher: %w", err)
+		}
+		return &aesGCMEncryptor{aead: aead}, nil
+	default:
+		return nil, fmt.Errorf("unsupported encryption type %q", encryptionType)
+	}
+}
+
+// parseAESKey accepts a raw 32 byte key or its base64 encoding
+func parseAESKey(key []byte) ([]byte, error) {
+	if len(key) == 32 {
+		return key, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(key)))
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("aes-gcm key must be 32 bytes, raw or base64 encoded")
+	}
+	return decoded, nil
+}
+
+// ageEncryptor encrypts with the recipient of an age identity and decrypts with the identity itself
+type ageEncryptor struct {
+	identity *age.X25519Identity
+}
+
+func (a *ageEncryptor) EncryptReader(src io.Reader) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := age.Encrypt(pw, a.identity.Recipient())
+		if err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to start age encryption: %w", err))
+			return
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		// Closing the age writer flushes the final chunk
+		pw.CloseWithError(w.Close())
+	}()
+	return pr, nil
+}
+
+func (a *ageEncryptor) DecryptReader(src io.Reader) (io.Reader, error) {
+	r, err := age.Decrypt(src, a.identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age stream: %w", err)
+	}
+	return r, nil
+}
+
+// aesGCMEncryptor splits the stream into chunks sealed with AES-GCM. Every nonce is made of a random
+// prefix stored in the header, the chunk counter and a flag marking the last chunk, so reordered,
+// dropped or truncated chunks fail authentication.
+type aesGCMEncryptor struct {
+	aead cipher.AEAD
+}
+
+func (a *aesGCMEncryptor) EncryptReader(src io.Reader) (io.ReadCloser, error) {
+	prefix := make([]byte, aesGCMNoncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header := make([]byte, 0, len(aesGCMMagic)+1+aesGCMNoncePrefixSize)
+	header = append(header, aesGCMMagic...)
+	header = append(header, aesGCMVersion)
+	header = append(header, prefix...)
+	return &aesGCMReader{
+		aead:    a.aead,
+		src:     bufio.NewReaderSize(src, aesGCMChunkSize),
+		prefix:  prefix,
+		pending: header,
+		chunk:   make([]byte, aesGCMChunkSize),
+		seal:    true,
+	}, nil
+}
+
+func (a *aesGCMEncryptor) DecryptReader(src io.Reader) (io.Reader, error) {
+	header := make([]byte, len(aesGCMMagic)+1+aesGCMNoncePrefixSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+	if string(header[:len(aesGCMMagic)]) != aesGCMMagic {
+		return nil, fmt.Errorf("object is not aes-gcm encrypted")
+	}
+	if header[len(aesGCMMagic)] != aesGCMVersion {
+		return nil, fmt.Errorf("unsupported aes-gcm stream version %d", header[len(aesGCMMagic)])
+	}
+	return &aesGCMReader{
+		aead:   a.aead,
+		src:    bufio.NewReaderSize(src, aesGCMChunkSize+a.aead.Overhead()),
+		prefix: header[len(aesGCMMagic)+1:],
+		chunk:  make([]byte, aesGCMChunkSize+a.aead.Overhead()),
+	}, nil
+}
+
+// aesGCMReader seals or opens the source stream one chunk at a time
+type aesGCMReader struct {
+	aead    cipher.AEAD
+	src     *bufio.Reader
+	prefix  []byte
+	counter uint32
+	pending []byte
+	out     []byte
+	chunk   []byte
+	seal    bool
+	done    bool
+}
+
+func (r *aesGCMReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *aesGCMReader) Close() error {
+	return nil
+}
+
+// next reads the next chunk from the source and seals or opens it into pending
+func (r *aesGCMReader) next() error {
+	n, err := io.ReadFull(r.src, r.chunk)
+	last := false
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		// A full chunk is the last one only if nothing follows it
+		if _, peekErr := r.src.Peek(1); errors.Is(peekErr, io.EOF) {
+			last = true
+		}
+	}
+
+	nonce := make([]byte, r.aead.NonceSize())
+	copy(nonce, r.prefix)
+	binary.BigEndian.PutUint32(nonce[aesGCMNoncePrefixSize:], r.counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	if r.counter == ^uint32(0) {
+		return fmt.Errorf("aes-gcm stream is too large")
+	}
+	r.counter++
+	r.done = last
+
+	if r.seal {
+		r.out = r.aead.Seal(r.out[:0], nonce, r.chunk[:n], nil)
+		r.pending = r.out
+		return nil
+	}
+	r.out, err = r.aead.Open(r.out[:0], nonce, r.chunk[:n], nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt chunk %d: %w", r.counter-1, err)
+	}
+	r.pending = r.out
+	return nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func newTestEncryptors(t *testing.T) map[string]Encryptor {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+	ageEncryptor, err := NewEncryptor(EncryptionTypeAge, []byte(identity.String()+"\n"))
+	if err != nil {
+		t.Fatalf("failed to create age encryptor: %v", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	aesEncryptor, err := NewEncryptor(EncryptionTypeAESGCM, []byte(base64.StdEncoding.EncodeToString(key)))
+	if err != nil {
+		t.Fatalf("failed to create aes-gcm encryptor: %v", err)
+	}
+	return map[string]Encryptor{
+		EncryptionTypeAge:    ageEncryptor,
+		EncryptionTypeAESGCM: aesEncryptor,
+	}
+}
+
+func encryptAll(t *testing.T, enc Encryptor, plaintext []byte) []byte {
+	t.Helper()
+	r, err := enc.EncryptReader(bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	defer r.Close() //nolint:errcheck
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read ciphertext: %v", err)
+	}
+	return ciphertext
+}
+
+func decryptAll(enc Encryptor, ciphertext []byte) ([]byte, error) {
+	r, err := enc.DecryptReader(bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	sizes := []int{0, 1, aesGCMChunkSize - 1, aesGCMChunkSize, aesGCMChunkSize + 1, 3*aesGCMChunkSize + 17}
+	for name, enc := range newTestEncryptors(t) {
+		for _, size := range sizes {
+			plaintext := make([]byte, size)
+			if _, err := rand.Read(plaintext); err != nil {
+				t.Fatalf("failed to generate plaintext: %v", err)
+			}
+			ciphertext := encryptAll(t, enc, plaintext)
+			if size >= 16 && bytes.Contains(ciphertext, plaintext) {
+				t.Errorf("%s: ciphertext of %d bytes contains the plaintext", name, size)
+			}
+			decrypted, err := decryptAll(enc, ciphertext)
+			if err != nil {
+				t.Fatalf("%s: failed to decrypt %d bytes: %v", name, size, err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("%s: round trip of %d bytes does not match", name, size)
+			}
+		}
+	}
+}
+
+func TestEncryptor_DetectsTampering(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("etcd"), aesGCMChunkSize)
+	for name, enc := range newTestEncryptors(t) {
+		ciphertext := encryptAll(t, enc, plaintext)
+
+		flipped := bytes.Clone(ciphertext)
+		flipped[len(flipped)/2] ^= 0xff
+		if _, err := decryptAll(enc, flipped); err == nil {
+			t.Errorf("%s: expected an error for modified ciphertext", name)
+		}
+
+		truncated := ciphertext[:len(ciphertext)-aesGCMChunkSize/2]
+		if _, err := decryptAll(enc, truncated); err == nil {
+			t.Errorf("%s: expected an error for truncated ciphertext", name)
+		}
+	}
+}
+
+func TestAESGCMEncryptor_DetectsTruncationAtChunkBoundary(t *testing.T) {
+	enc := newTestEncryptors(t)[EncryptionTypeAESGCM]
+	ciphertext := encryptAll(t, enc, make([]byte, 2*aesGCMChunkSize))
+	// Keep the header and the first sealed chunk only, which is not flagged as the last chunk
+	headerSize := len(aesGCMMagic) + 1 + aesGCMNoncePrefixSize
+	truncated := ciphertext[:headerSize+aesGCMChunkSize+16]
+	if _, err := decryptAll(enc, truncated); err == nil {
+		t.Error("expected an error for ciphertext truncated at a chunk boundary")
+	}
+}
+
+func TestEncryptor_WrongKey(t *testing.T) {
+	encryptors := newTestEncryptors(t)
+	others := newTestEncryptors(t)
+	for name, enc := range encryptors {
+		ciphertext := encryptAll(t, enc, []byte("secretBundle"))
+		if _, err := decryptAll(others[name], ciphertext); err == nil {
+			t.Errorf("%s: expected an error when decrypting with a different key", name)
+		}
+	}
+}
+
+func TestNewEncryptor_InvalidKey(t *testing.T) {
+	_, err := NewEncryptor(EncryptionTypeAESGCM, []byte("too-short"))
+	if err == nil {
+		t.Fatal("expected error for short aes-gcm key, got nil")
+	}
+	expectedError := "aes-gcm key must be 32 bytes, raw or base64 encoded"
+	if err.Error() != expectedError {
+		t.Errorf("expected error '%s', got '%s'", expectedError, err.Error())
+	}
+
+	if _, err := NewEncryptor(EncryptionTypeAge, []byte("not-an-identity")); err == nil {
+		t.Error("expected error for invalid age identity, got nil")
+	}
+}
+
+func TestNewEncryptor_UnsupportedType(t *testing.T) {
+	_, err := NewEncryptor("rot13", []byte("key"))
+	if err == nil {
+		t.Fatal("expected error for unsupported encryption type, got nil")
+	}
+	expectedError := `unsupported encryption type "rot13"`
+	if err.Error() != expectedError {
+		t.Errorf("expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
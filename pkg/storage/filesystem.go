@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FilesystemClient stores etcd backups in a local directory, typically a PersistentVolumeClaim
+// mounted into the operator pod
+type FilesystemClient struct {
+	root string
+}
+
+var _ Backend = (*FilesystemClient)(nil)
+
+// FilesystemConfig contains configuration for the filesystem client
+type FilesystemConfig struct {
+	Path string
+}
+
+// NewFilesystemClient creates a new filesystem client rooted at the configured path
+func NewFilesystemClient(cfg *FilesystemConfig) (*FilesystemClient, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if !filepath.IsAbs(cfg.Path) {
+		return nil, fmt.Errorf("path must be absolute")
+	}
+	if err := os.MkdirAll(cfg.Path, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return &FilesystemClient{root: filepath.Clean(cfg.Path)}, nil
+}
+
+// Upload writes data from a reader to the file with the specified key. The data is written
+// to a temporary file first so a failed upload never leaves a truncated backup behind.
+func (f *FilesystemClient) Upload(_ context.Context, key string, reader io.Reader) error {
+	path, err := f.pathForKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", key, err)
+	}
+	return nil
+}
+
+// Download opens the file with the specified key for reading
+func (f *FilesystemClient) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := f.pathForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return file, nil
+}
+
+// Delete removes the file with the specified key
+func (f *FilesystemClient) Delete(_ context.Context, key string) error {
+	path, err := f.pathForKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	if err := os.Remove(metadataPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete metadata of %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetMetadata writes the metadata to a hidden JSON file next to the file with the specified key
+func (f *FilesystemClient) SetMetadata(_ context.Context, key string, metadata map[string]string) error {
+	path, err := f.pathForKey(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata of %s: %w", key, err)
+	}
+	if err := os.WriteFile(metadataPath(path), data, 0o640); err != nil {
+		return fmt.Errorf("failed to write metadata of %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the keys of all files that start with the specified prefix
+func (f *FilesystemClient) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(f.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Hidden files are in-flight uploads and metadata
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup directory: %w", err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Close releases the resources of the client. The filesystem backend holds no resources.
+func (f *FilesystemClient) Close() error {
+	return nil
+}
+
+// metadataPath returns the path of the metadata file of a backup file
+func metadataPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".metadata.json")
+}
+
+// pathForKey maps an object key to a path below the root directory
+func (f *FilesystemClient) pathForKey(key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("key is required")
+	}
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("key %s escapes the backup directory", key)
+	}
+	return filepath.Join(f.root, rel), nil
+}
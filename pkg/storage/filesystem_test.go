@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBackupKey = "talos-operator-etcd-backups/test-cluster/etcd-snapshot-2025-01-01T02-00-00Z.db"
+
+func TestNewFilesystemClient_MissingPath(t *testing.T) {
+	_, err := NewFilesystemClient(&FilesystemConfig{})
+	if err == nil {
+		t.Fatal("Expected error for missing path, got nil")
+	}
+
+	expectedError := "path is required"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+func TestNewFilesystemClient_RelativePath(t *testing.T) {
+	_, err := NewFilesystemClient(&FilesystemConfig{Path: "backups"})
+	if err == nil {
+		t.Fatal("Expected error for relative path, got nil")
+	}
+}
+
+func TestFilesystemClient_UploadDownload(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFilesystemClient(&FilesystemConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := client.Upload(ctx, testBackupKey, strings.NewReader("snapshot")); err != nil {
+		t.Fatalf("Expected no error on upload, got %v", err)
+	}
+
+	reader, err := client.Download(ctx, testBackupKey)
+	if err != nil {
+		t.Fatalf("Expected no error on download, got %v", err)
+	}
+	defer reader.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Expected no error reading download, got %v", err)
+	}
+	if string(data) != "snapshot" {
+		t.Errorf("Expected downloaded data to be 'snapshot', got '%s'", string(data))
+	}
+}
+
+func TestFilesystemClient_DownloadNotFound(t *testing.T) {
+	client, err := NewFilesystemClient(&FilesystemConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = client.Download(context.Background(), testBackupKey)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFilesystemClient_ListAndDelete(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewFilesystemClient(&FilesystemConfig{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stateKey := StateKeyForBackupKey(testBackupKey)
+	otherKey := "talos-operator-etcd-backups/other-cluster/etcd-snapshot-2025-01-01T02-00-00Z.db"
+	for _, key := range []string{testBackupKey, stateKey, otherKey} {
+		if err := client.Upload(ctx, key, strings.NewReader(key)); err != nil {
+			t.Fatalf("Expected no error on upload of %s, got %v", key, err)
+		}
+	}
+
+	keys, err := client.List(ctx, "talos-operator-etcd-backups/test-cluster/")
+	if err != nil {
+		t.Fatalf("Expected no error on list, got %v", err)
+	}
+	expected := []string{testBackupKey, stateKey}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	if err := client.Delete(ctx, testBackupKey); err != nil {
+		t.Fatalf("Expected no error on delete, got %v", err)
+	}
+	// Deleting a missing object is not an error
+	if err := client.Delete(ctx, testBackupKey); err != nil {
+		t.Fatalf("Expected no error on second delete, got %v", err)
+	}
+
+	keys, err = client.List(ctx, "talos-operator-etcd-backups/test-cluster/")
+	if err != nil {
+		t.Fatalf("Expected no error on list, got %v", err)
+	}
+	if !reflect.DeepEqual(keys, []string{stateKey}) {
+		t.Errorf("Expected keys %v, got %v", []string{stateKey}, keys)
+	}
+}
+
+func TestFilesystemClient_KeyEscapesRoot(t *testing.T) {
+	root := t.TempDir()
+	client, err := NewFilesystemClient(&FilesystemConfig{Path: filepath.Join(root, "backups")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := client.Upload(context.Background(), "../escaped.db", strings.NewReader("snapshot")); err == nil {
+		t.Fatal("Expected error for key escaping the backup directory, got nil")
+	}
+}
+
+func TestFilesystemClient_SetMetadata(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	client, err := NewFilesystemClient(&FilesystemConfig{Path: root})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err = client.SetMetadata(ctx, testBackupKey, map[string]string{MetadataSHA256: "abc"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for missing file, got %v", err)
+	}
+
+	if err := client.Upload(ctx, testBackupKey, strings.NewReader("snapshot")); err != nil {
+		t.Fatalf("Expected no error on upload, got %v", err)
+	}
+	if err := client.SetMetadata(ctx, testBackupKey, map[string]string{MetadataSHA256: "abc"}); err != nil {
+		t.Fatalf("Expected no error on set metadata, got %v", err)
+	}
+	data, err := os.ReadFile(metadataPath(filepath.Join(root, filepath.FromSlash(testBackupKey))))
+	if err != nil {
+		t.Fatalf("Expected metadata file, got %v", err)
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("Expected valid metadata JSON, got %v", err)
+	}
+	if metadata[MetadataSHA256] != "abc" {
+		t.Errorf("Expected sha256 metadata 'abc', got '%s'", metadata[MetadataSHA256])
+	}
+
+	// The metadata file is not a backup object and goes away with the backup
+	keys, err := client.List(ctx, "")
+	if err != nil {
+		t.Fatalf("Expected no error on list, got %v", err)
+	}
+	if !reflect.DeepEqual(keys, []string{testBackupKey}) {
+		t.Errorf("Expected keys %v, got %v", []string{testBackupKey}, keys)
+	}
+	if err := client.Delete(ctx, testBackupKey); err != nil {
+		t.Fatalf("Expected no error on delete, got %v", err)
+	}
+	keys, err = client.List(ctx, "")
+	if err != nil {
+		t.Fatalf("Expected no error on list, got %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("Expected no keys after delete, got %v", keys)
+	}
+	entries, err := os.ReadDir(filepath.Dir(filepath.Join(root, filepath.FromSlash(testBackupKey))))
+	if err != nil {
+		t.Fatalf("Expected no error reading backup directory, got %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected metadata file to be deleted, found %d entries", len(entries))
+	}
+}
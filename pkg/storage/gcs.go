@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCSClient wraps the Google Cloud Storage client for etcd backup operations
+type GCSClient struct {
+	client *gcs.Client
+	bucket string
+}
+
+var _ Backend = (*GCSClient)(nil)
+
+// GCSConfig contains configuration for GCS client
+type GCSConfig struct {
+	Bucket string
+	// CredentialsJSON is a service account key. If empty, the application default
+	// credentials (e.g. workload identity) of the operator are used.
+	CredentialsJSON []byte
+	Endpoint        string
+}
+
+// NewGCSClient creates a new GCS client with the provided configuration
+func NewGCSClient(ctx context.Context, cfg *GCSConfig) (*GCSClient, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket name is required")
+	}
+
+	var opts []option.ClientOption
+	if len(cfg.CredentialsJSON) > 0 {
+		opts = append(opts, option.WithAuthCredentialsJSON(option.ServiceAccount, cfg.CredentialsJSON))
+	}
+	// Set custom endpoint if provided (for GCS emulators)
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+	}
+
+	client, err := gcs.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return &GCSClient{
+		client: client,
+		bucket: cfg.Bucket,
+	}, nil
+}
+
+// Upload streams data from a reader to the object with the specified key
+func (g *GCSClient) Upload(ctx context.Context, key string, reader io.Reader) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	// Cancelling the context of the writer aborts the upload, closing it would commit a partial object
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := g.client.Bucket(g.bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, reader); err != nil {
+		cancel()
+		return fmt.Errorf("failed to upload to GCS: %w", err)
+	}
+	// The object is only committed once the writer is closed
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to upload to GCS: %w", err)
+	}
+	return nil
+}
+
+// Download returns a reader streaming the object stored under the specified key
+func (g *GCSClient) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	r, err := g.client.Bucket(g.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to download object from GCS: %w", err)
+	}
+	return r, nil
+}
+
+// Delete removes the object with the specified key
+func (g *GCSClient) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if err := g.client.Bucket(g.bucket).Object(key).Delete(ctx); err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete object from GCS: %w", err)
+	}
+	return nil
+}
+
+// SetMetadata replaces the user metadata of the object with the specified key
+func (g *GCSClient) SetMetadata(ctx context.Context, key string, metadata map[string]string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	_, err := g.client.Bucket(g.bucket).Object(key).Update(ctx, gcs.ObjectAttrsToUpdate{Metadata: metadata})
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return fmt.Errorf("failed to set object metadata in GCS: %w", err)
+	}
+	return nil
+}
+
+// List returns the keys of all objects in the bucket that start with the specified prefix
+func (g *GCSClient) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	it := g.client.Bucket(g.bucket).Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in GCS: %w", err)
+		}
+		keys = append(keys, attrs.Name)
+	}
+	return keys, nil
+}
+
+// Close releases the connections of the GCS client
+func (g *GCSClient) Close() error {
+	return g.client.Close()
+}
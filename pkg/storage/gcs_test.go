@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewGCSClient_MissingBucket(t *testing.T) {
+	_, err := NewGCSClient(context.Background(), &GCSConfig{})
+	if err == nil {
+		t.Fatal("Expected error for missing bucket, got nil")
+	}
+
+	expectedError := "bucket name is required"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
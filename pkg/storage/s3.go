@@ -3,17 +3,27 @@ package storage
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
-	"time"
+	"net/url"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// s3MaxCopySize is the size of the largest object S3 copies in a single request
+	s3MaxCopySize = 5 << 30
+	// s3CopyPartSize is the smallest size of the parts larger objects are copied in
+	s3CopyPartSize = 512 << 20
+	// s3MaxParts is how many parts a multipart upload may have
+	s3MaxParts = 10000
 )
 
 // S3Client wraps the AWS S3 client for etcd backup operations
@@ -23,6 +33,8 @@ type S3Client struct {
 	bucket   string
 }
 
+var _ Backend = (*S3Client)(nil)
+
 // S3Config contains configuration for S3 client
 type S3Config struct {
 	Bucket             string
@@ -120,6 +132,26 @@ func (s *S3Client) Upload(ctx context.Context, key string, reader io.Reader) err
 	return nil
 }
 
+// Download returns a reader streaming the object stored under the specified key.
+// The caller is responsible for closing the returned reader.
+func (s *S3Client) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to download object from S3: %w", err)
+	}
+	return out.Body, nil
+}
+
 func (s *S3Client) Delete(ctx context.Context, key string) error {
 	if key == "" {
 		return fmt.Errorf("key is required")
@@ -134,19 +166,129 @@ func (s *S3Client) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
-// GenerateBackupKey generates a standardized key for etcd backups
-func GenerateBackupKey(clusterName string) string {
-	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05Z")
-	return fmt.Sprintf("talos-operator-etcd-backups/%s/etcd-snapshot-%s.db", clusterName, timestamp)
+// SetMetadata replaces the user metadata of the object by copying it onto itself. Objects larger
+// than S3 copies in a single request are copied in parts.
+func (s *S3Client) SetMetadata(ctx context.Context, key string, metadata map[string]string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return fmt.Errorf("failed to get object from S3: %w", err)
+	}
+	copySource := (&url.URL{Path: s.bucket + "/" + key}).EscapedPath()
+	if size := aws.ToInt64(head.ContentLength); size > s3MaxCopySize {
+		return s.copyMultipart(ctx, key, copySource, size, metadata)
+	}
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(copySource),
+		Metadata:          metadata,
+		MetadataDirective: types.MetadataDirectiveReplace,
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return fmt.Errorf("failed to set object metadata in S3: %w", err)
+	}
+	return nil
 }
 
-// StateKeyForBackupKey derives the state-secret object key that pairs with a given
-// etcd backup key, so each backup and its matching state are stored side-by-side.
-func StateKeyForBackupKey(backupKey string) string {
-	dir, base := backupKey, ""
-	if i := strings.LastIndex(backupKey, "/"); i >= 0 {
-		dir, base = backupKey[:i], backupKey[i+1:]
+// copyMultipart copies the object onto itself with the metadata in parts. The object is only
+// replaced once all parts are copied, a failed copy is aborted.
+func (s *S3Client) copyMultipart(ctx context.Context, key, copySource string, size int64, metadata map[string]string) error {
+	upload, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		Metadata: metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start multipart copy in S3: %w", err)
 	}
-	timestamp := strings.TrimSuffix(strings.TrimPrefix(base, "etcd-snapshot-"), ".db")
-	return fmt.Sprintf("%s/state-%s.yaml", dir, timestamp)
+	copyParts := func() error {
+		var parts []types.CompletedPart
+		for i, byteRange := range copyPartRanges(size) {
+			partNumber := aws.Int32(int32(i + 1))
+			out, err := s.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+				Bucket:          aws.String(s.bucket),
+				Key:             aws.String(key),
+				UploadId:        upload.UploadId,
+				PartNumber:      partNumber,
+				CopySource:      aws.String(copySource),
+				CopySourceRange: aws.String(byteRange),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to copy part %d in S3: %w", i+1, err)
+			}
+			parts = append(parts, types.CompletedPart{ETag: out.CopyPartResult.ETag, PartNumber: partNumber})
+		}
+		_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             aws.String(key),
+			UploadId:        upload.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to complete multipart copy in S3: %w", err)
+		}
+		return nil
+	}
+	if err := copyParts(); err != nil {
+		// The parts copied so far are stored, and billed, until the upload is aborted
+		if _, abortErr := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+		}); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to abort multipart copy in S3: %w", abortErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// copyPartRanges returns the byte ranges an object of the size is copied in. The parts grow beyond
+// s3CopyPartSize for objects that would need more than s3MaxParts otherwise.
+func copyPartRanges(size int64) []string {
+	partSize := max(s3CopyPartSize, (size+s3MaxParts-1)/s3MaxParts)
+	var ranges []string
+	for start := int64(0); start < size; start += partSize {
+		end := min(start+partSize, size) - 1
+		ranges = append(ranges, fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+	return ranges
+}
+
+// List returns the keys of all objects in the bucket that start with the specified prefix
+func (s *S3Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in S3: %w", err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+// Close releases the resources of the client. The S3 client holds no connections that need to be released.
+func (s *S3Client) Close() error {
+	return nil
 }
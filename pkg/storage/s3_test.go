@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -73,37 +75,6 @@ func TestNewS3Client_MissingRegion(t *testing.T) {
 	}
 }
 
-func TestGenerateBackupKey(t *testing.T) {
-	clusterName := "test-cluster"
-	key := GenerateBackupKey(clusterName)
-
-	if key == "" {
-		t.Fatal("Expected non-empty key")
-	}
-
-	// Key should start with the talos-operator-etcd-backups prefix
-	expectedPrefix := "talos-operator-etcd-backups/test-cluster/etcd-snapshot-"
-	if len(key) < len(expectedPrefix) {
-		t.Fatalf("Key too short: %s", key)
-	}
-
-	actualPrefix := key[:len(expectedPrefix)]
-	if actualPrefix != expectedPrefix {
-		t.Errorf("Expected key to start with '%s', got '%s'", expectedPrefix, actualPrefix)
-	}
-
-	// Key should end with .db
-	expectedSuffix := ".db"
-	if len(key) < len(expectedSuffix) {
-		t.Fatalf("Key too short: %s", key)
-	}
-
-	actualSuffix := key[len(key)-len(expectedSuffix):]
-	if actualSuffix != expectedSuffix {
-		t.Errorf("Expected key to end with '%s', got '%s'", expectedSuffix, actualSuffix)
-	}
-}
-
 func TestNewS3Client_WithEndpoint(t *testing.T) {
 	ctx := context.Background()
 	cfg := &S3Config{
@@ -144,3 +115,51 @@ func TestNewS3Client_WithInsecureSkipVerify(t *testing.T) {
 		t.Fatal("Expected client to be non-nil")
 	}
 }
+
+func TestS3Client_Download_MissingKey(t *testing.T) {
+	ctx := context.Background()
+	cfg := &S3Config{
+		Bucket:          testBucket,
+		Region:          testRegion,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: testSecretKey,
+	}
+
+	client, err := NewS3Client(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = client.Download(ctx, "")
+	if err == nil {
+		t.Fatal("Expected error for missing key, got nil")
+	}
+
+	expectedError := "key is required"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+func TestCopyPartRanges(t *testing.T) {
+	if ranges := copyPartRanges(s3CopyPartSize); len(ranges) != 1 || ranges[0] != fmt.Sprintf("bytes=0-%d", s3CopyPartSize-1) {
+		t.Errorf("Expected a single part, got %v", ranges)
+	}
+	size := int64(s3MaxCopySize + 1)
+	ranges := copyPartRanges(size)
+	if len(ranges) != 11 {
+		t.Fatalf("Expected 11 parts, got %d", len(ranges))
+	}
+	if last := ranges[len(ranges)-1]; last != fmt.Sprintf("bytes=%d-%d", size-1, size-1) {
+		t.Errorf("Expected the last part to end with the object, got %s", last)
+	}
+	// The largest object S3 stores
+	size = 5 << 40
+	ranges = copyPartRanges(size)
+	if len(ranges) > s3MaxParts {
+		t.Errorf("Expected at most %d parts, got %d", s3MaxParts, len(ranges))
+	}
+	if last := ranges[len(ranges)-1]; !strings.HasSuffix(last, fmt.Sprintf("-%d", size-1)) {
+		t.Errorf("Expected the last part to end with the object, got %s", last)
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the requested object does not exist in the storage backend
+var ErrNotFound = errors.New("object not found")
+
+// Object metadata keys written next to etcd backups. They only use characters that are valid
+// metadata keys on every backend (Azure requires C# identifiers).
+const (
+	MetadataSHA256            = "sha256"
+	MetadataSize              = "size"
+	MetadataEtcdRevision      = "etcd_revision"
+	MetadataTalosVersion      = "talos_version"
+	MetadataKubernetesVersion = "kubernetes_version"
+	MetadataCompletionTime    = "completion_time"
+	MetadataEncryption        = "encryption"
+)
+
+// Backend is implemented by every storage backend etcd backups can be written to
+type Backend interface {
+	// Upload streams data from a reader to the object with the specified key
+	Upload(ctx context.Context, key string, reader io.Reader) error
+	// Download returns a reader streaming the object with the specified key.
+	// The caller is responsible for closing the returned reader.
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object with the specified key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SetMetadata replaces the user metadata of the object with the specified key
+	SetMetadata(ctx context.Context, key string, metadata map[string]string) error
+	// List returns the keys of all objects whose key starts with the specified prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Close releases the resources of the backend, it is not usable afterwards
+	Close() error
+}
+
+// GenerateBackupKey generates a standardized key for etcd backups
+func GenerateBackupKey(clusterName string) string {
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05Z")
+	return fmt.Sprintf("talos-operator-etcd-backups/%s/etcd-snapshot-%s.db", clusterName, timestamp)
+}
+
+// StateKeyForBackupKey derives the state-secret object key that pairs with a given
+// etcd backup key, so each backup and its matching state are stored side-by-side.
+func StateKeyForBackupKey(backupKey string) string {
+	dir, base := backupKey, ""
+	if i := strings.LastIndex(backupKey, "/"); i >= 0 {
+		dir, base = backupKey[:i], backupKey[i+1:]
+	}
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(base, "etcd-snapshot-"), ".db")
+	return fmt.Sprintf("%s/state-%s.yaml", dir, timestamp)
+}
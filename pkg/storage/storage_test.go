@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestGenerateBackupKey(t *testing.T) {
+	clusterName := "test-cluster"
+	key := GenerateBackupKey(clusterName)
+
+	if key == "" {
+		t.Fatal("Expected non-empty key")
+	}
+
+	// Key should start with the talos-operator-etcd-backups prefix
+	expectedPrefix := "talos-operator-etcd-backups/test-cluster/etcd-snapshot-"
+	if len(key) < len(expectedPrefix) {
+		t.Fatalf("Key too short: %s", key)
+	}
+
+	actualPrefix := key[:len(expectedPrefix)]
+	if actualPrefix != expectedPrefix {
+		t.Errorf("Expected key to start with '%s', got '%s'", expectedPrefix, actualPrefix)
+	}
+
+	// Key should end with .db
+	expectedSuffix := ".db"
+	if len(key) < len(expectedSuffix) {
+		t.Fatalf("Key too short: %s", key)
+	}
+
+	actualSuffix := key[len(key)-len(expectedSuffix):]
+	if actualSuffix != expectedSuffix {
+		t.Errorf("Expected key to end with '%s', got '%s'", expectedSuffix, actualSuffix)
+	}
+}
+
+func TestStateKeyForBackupKey(t *testing.T) {
+	backupKey := "talos-operator-etcd-backups/test-cluster/etcd-snapshot-2025-01-01T02-00-00Z.db"
+	expected := "talos-operator-etcd-backups/test-cluster/state-2025-01-01T02-00-00Z.yaml"
+
+	if got := StateKeyForBackupKey(backupKey); got != expected {
+		t.Errorf("Expected state key '%s', got '%s'", expected, got)
+	}
+}
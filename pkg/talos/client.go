@@ -32,6 +32,11 @@ type TalosClient struct {
 const (
 	KUBELET_SERVICE_NAME   = "kubelet"
 	KUBELET_STATUS_RUNNING = "Running"
+	APID_SERVICE_NAME      = "apid"
+	ETCD_SERVICE_NAME      = "etcd"
+	SERVICE_STATUS_RUNNING = "Running"
+	// SERVICE_STATUS_PREPARING is the state of etcd while it waits for the bootstrap
+	SERVICE_STATUS_PREPARING = "Preparing"
 )
 
 // NewClient constructs a Talos API client using the default talosconfig file
@@ -204,6 +209,14 @@ func (tc *TalosClient) upgradeViaMachineService(ctx context.Context, image strin
 	return nil
 }
 
+// RollbackTalosVersion makes the machine reboot into the previous Talos installation, like `talosctl rollback`.
+func (tc *TalosClient) RollbackTalosVersion(ctx context.Context) error {
+	if err := tc.Rollback(ctx); err != nil && !isGracefulStop(err) {
+		return fmt.Errorf("error rolling back Talos version: %w", err)
+	}
+	return nil
+}
+
 // pullInstallerImage pulls the specificied image as pre-upgrade step.
 func (tc *TalosClient) pullInstallerImage(
 	ctx context.Context, containerd *common.ContainerdInstance, image string,
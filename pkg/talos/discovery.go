@@ -0,0 +1,76 @@
+package talos
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+)
+
+// DefaultAPIPort is the port the Talos API listens on
+const DefaultAPIPort = 50000
+
+// ProbeMaintenance connects to the Talos API of a machine without client certificates, like
+// `talosctl --insecure`, and reads its hardware facts. Only machines in maintenance mode answer,
+// machines that run with a config require mTLS and fail the probe.
+func ProbeMaintenance(ctx context.Context, address string, port int) (*HostFacts, error) {
+	c, err := client.New(ctx,
+		client.WithEndpoints(net.JoinHostPort(address, strconv.Itoa(port))),
+		client.WithTLSConfig(&tls.Config{
+			InsecureSkipVerify: true, // Machines in maintenance mode serve a self-signed certificate
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Talos client for %s: %w", address, err)
+	}
+	defer c.Close() //nolint:errcheck
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Talos version of %s: %w", address, err)
+	}
+	facts, err := hardwareFacts(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hardware of %s: %w", address, err)
+	}
+	for _, m := range version.GetMessages() {
+		facts.Architecture = m.GetVersion().GetArch()
+	}
+
+	addresses, err := safe.StateListAll[*network.AddressStatus](ctx, c.COSI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses of %s: %w", address, err)
+	}
+	var specs []network.AddressStatusSpec
+	for a := range addresses.All() {
+		specs = append(specs, *a.TypedSpec())
+	}
+	if link := linkForAddress(specs, address); link != "" {
+		status, err := safe.StateGetByID[*network.LinkStatus](ctx, c.COSI, link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get link %s of %s: %w", link, address, err)
+		}
+		facts.MACAddress = status.TypedSpec().HardwareAddr.String()
+	}
+	return facts, nil
+}
+
+// linkForAddress returns the name of the link that holds the address
+func linkForAddress(addresses []network.AddressStatusSpec, address string) string {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return ""
+	}
+	for _, a := range addresses {
+		if a.Address.Addr() == ip {
+			return a.LinkName
+		}
+	}
+	return ""
+}
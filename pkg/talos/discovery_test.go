@@ -0,0 +1,21 @@
+package talos
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+)
+
+func TestLinkForAddress(t *testing.T) {
+	addresses := []network.AddressStatusSpec{
+		{Address: netip.MustParsePrefix("127.0.0.1/8"), LinkName: "lo"},
+		{Address: netip.MustParsePrefix("10.0.2.11/24"), LinkName: "enp1s0"},
+	}
+	if link := linkForAddress(addresses, "10.0.2.11"); link != "enp1s0" {
+		t.Errorf("expected enp1s0, got %q", link)
+	}
+	if link := linkForAddress(addresses, "10.0.2.12"); link != "" {
+		t.Errorf("expected no link for an unknown address, got %q", link)
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
+	"strconv"
 
+	"github.com/siderolabs/talos/pkg/cluster"
 	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // EtcdSnapshotReader returns an io.ReadCloser for streaming the etcd snapshot
-// This avoids writing the snapshot to disk, allowing direct streaming to S3
+// This avoids writing the snapshot to disk, allowing direct streaming to the storage backend
 func (tc *TalosClient) EtcdSnapshotReader(ctx context.Context) (io.ReadCloser, error) {
 	req := &machineapi.EtcdSnapshotRequest{}
 	resp, err := tc.EtcdSnapshot(ctx, req)
@@ -19,3 +24,107 @@ func (tc *TalosClient) EtcdSnapshotReader(ctx context.Context) (io.ReadCloser, e
 
 	return resp, nil
 }
+
+// EtcdRevision returns the current etcd revision of the cluster. The Talos API does not expose it,
+// so it is read from the resourceVersion of a Kubernetes list, which is the etcd revision it was served at.
+func (tc *TalosClient) EtcdRevision(ctx context.Context) (int64, error) {
+	kubernetesProvider := &cluster.KubernetesClient{
+		ClientProvider: &cluster.ConfigClientProvider{
+			DefaultClient: tc.Client,
+		},
+	}
+	clientset, err := kubernetesProvider.K8sClient(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	// A quorum read with a limit is served from etcd and returns the current revision
+	list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	revision, err := strconv.ParseInt(list.ResourceVersion, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse resource version %q: %w", list.ResourceVersion, err)
+	}
+	return revision, nil
+}
+
+// RecoverEtcdFromSnapshot replicates `talosctl bootstrap --recover-from`: it uploads the snapshot
+// to the node and then bootstraps etcd from it. The node must be waiting for bootstrap, i.e. its
+// etcd data directory has to be empty.
+func (tc *TalosClient) RecoverEtcdFromSnapshot(ctx context.Context, snapshot io.Reader, skipHashCheck bool) error {
+	if _, err := tc.EtcdRecover(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to upload etcd snapshot: %w", err)
+	}
+	req := &machineapi.BootstrapRequest{
+		RecoverEtcd:          true,
+		RecoverSkipHashCheck: skipHashCheck,
+	}
+	if err := tc.Bootstrap(ctx, req); err != nil {
+		return fmt.Errorf("failed to bootstrap node from etcd snapshot: %w", err)
+	}
+	return nil
+}
+
+// EtcdWaitingForBootstrap returns true if etcd on the node is not running yet and waits for the bootstrap,
+// which is the only state etcd can be recovered from a snapshot in
+func (tc *TalosClient) EtcdWaitingForBootstrap(ctx context.Context) (bool, error) {
+	state, err := tc.GetServiceStatus(ctx, ETCD_SERVICE_NAME)
+	if err != nil {
+		return false, err
+	}
+	return state != nil && *state == SERVICE_STATUS_PREPARING, nil
+}
+
+// EtcdMembers returns the members of the etcd cluster as seen by the node
+func (tc *TalosClient) EtcdMembers(ctx context.Context) ([]*machineapi.EtcdMember, error) {
+	resp, err := tc.EtcdMemberList(ctx, &machineapi.EtcdMemberListRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd members: %w", err)
+	}
+	var members []*machineapi.EtcdMember
+	for _, msg := range resp.GetMessages() {
+		members = append(members, msg.GetMembers()...)
+	}
+	return members, nil
+}
+
+// LeaveEtcd replicates `talosctl etcd leave`: the node hands over the leadership if it is the leader
+// and removes its own member from the etcd cluster.
+func (tc *TalosClient) LeaveEtcd(ctx context.Context) error {
+	if _, err := tc.EtcdForfeitLeadership(ctx, &machineapi.EtcdForfeitLeadershipRequest{}); err != nil {
+		return fmt.Errorf("failed to forfeit etcd leadership: %w", err)
+	}
+	if err := tc.EtcdLeaveCluster(ctx, &machineapi.EtcdLeaveClusterRequest{}); err != nil {
+		return fmt.Errorf("failed to leave etcd cluster: %w", err)
+	}
+	return nil
+}
+
+// RemoveEtcdMember replicates `talosctl etcd remove-member`: the node removes the member with the
+// given ID from the etcd cluster. It is used for members whose node cannot leave on its own anymore.
+func (tc *TalosClient) RemoveEtcdMember(ctx context.Context, id uint64) error {
+	if err := tc.EtcdRemoveMemberByID(ctx, &machineapi.EtcdRemoveMemberByIDRequest{MemberId: id}); err != nil {
+		return fmt.Errorf("failed to remove etcd member %x: %w", id, err)
+	}
+	return nil
+}
+
+// EtcdMemberByAddress returns the member whose peer URLs point to the given address, or nil if the
+// address is not a member
+func EtcdMemberByAddress(members []*machineapi.EtcdMember, address string) *machineapi.EtcdMember {
+	ip := net.ParseIP(address)
+	for _, member := range members {
+		for _, peerURL := range member.PeerUrls {
+			u, err := url.Parse(peerURL)
+			if err != nil {
+				continue
+			}
+			// IPv6 addresses may be written differently in the peer URL
+			if u.Hostname() == address || (ip != nil && ip.Equal(net.ParseIP(u.Hostname()))) {
+				return member
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package talos
+
+import (
+	"testing"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+)
+
+func TestEtcdMemberByAddress(t *testing.T) {
+	members := []*machineapi.EtcdMember{
+		{Id: 1, Hostname: "cp-1", PeerUrls: []string{"https://10.0.0.1:2380"}},
+		{Id: 2, Hostname: "cp-2", PeerUrls: []string{"https://[fd00::2]:2380"}},
+		{Id: 3, Hostname: "cp-3", PeerUrls: []string{"https://cp-3.example.com:2380"}},
+	}
+	for address, want := range map[string]uint64{
+		"10.0.0.1":         1,
+		"fd00:0:0:0::2":    2,
+		"cp-3.example.com": 3,
+	} {
+		if member := EtcdMemberByAddress(members, address); member == nil || member.Id != want {
+			t.Errorf("expected %s to be member %d, got %v", address, want, member)
+		}
+	}
+	for _, address := range []string{"10.0.0.4", "cp-4.example.com"} {
+		if member := EtcdMemberByAddress(members, address); member != nil {
+			t.Errorf("expected %s not to be a member, got %d", address, member.Id)
+		}
+	}
+}
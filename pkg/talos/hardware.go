@@ -0,0 +1,125 @@
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/cosi-project/runtime/pkg/state"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/block"
+	"github.com/siderolabs/talos/pkg/machinery/resources/hardware"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+)
+
+// HostFacts are the facts a machine reports about its hardware
+type HostFacts struct {
+	Architecture string
+	// MACAddress is the hardware address of the link that holds the probed address
+	MACAddress   string
+	SystemUUID   string
+	Manufacturer string
+	ProductName  string
+	CPUModel     string
+	CPUs         int32
+	Threads      int32
+	MemoryBytes  uint64
+	Disks        []DiskFacts
+	NICs         []NICFacts
+}
+
+// DiskFacts are the facts of a disk of a machine
+type DiskFacts struct {
+	Name       string
+	Size       uint64
+	Model      string
+	Serial     string
+	Transport  string
+	Rotational bool
+}
+
+// NICFacts are the facts of a physical network interface of a machine
+type NICFacts struct {
+	Name       string
+	MACAddress string
+	LinkUp     bool
+	SpeedMbps  int32
+}
+
+// HardwareFacts reads the hardware facts of the machine the client is connected to
+func (tc *TalosClient) HardwareFacts(ctx context.Context) (*HostFacts, error) {
+	return hardwareFacts(ctx, tc.Client)
+}
+
+// hardwareFacts reads the system information, processors, memory modules, disks and physical
+// network interfaces of the machine
+func hardwareFacts(ctx context.Context, c *client.Client) (*HostFacts, error) {
+	facts := &HostFacts{}
+	system, err := safe.StateGetByID[*hardware.SystemInformation](ctx, c.COSI, hardware.SystemInformationID)
+	// Machines without SMBIOS, e.g. some VMs and SBCs, have no system information
+	if err != nil && !state.IsNotFoundError(err) {
+		return nil, fmt.Errorf("failed to get system information: %w", err)
+	}
+	if err == nil {
+		facts.SystemUUID = system.TypedSpec().UUID
+		facts.Manufacturer = system.TypedSpec().Manufacturer
+		facts.ProductName = system.TypedSpec().ProductName
+	}
+
+	processors, err := safe.StateListAll[*hardware.Processor](ctx, c.COSI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processors: %w", err)
+	}
+	for p := range processors.All() {
+		spec := p.TypedSpec()
+		facts.CPUs += int32(spec.CoreCount)
+		facts.Threads += int32(spec.ThreadCount)
+		if facts.CPUModel == "" {
+			facts.CPUModel = spec.ProductName
+		}
+	}
+	modules, err := safe.StateListAll[*hardware.MemoryModule](ctx, c.COSI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory modules: %w", err)
+	}
+	for m := range modules.All() {
+		facts.MemoryBytes += uint64(m.TypedSpec().Size) * 1024 * 1024
+	}
+
+	disks, err := safe.StateListAll[*block.Disk](ctx, c.COSI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disks: %w", err)
+	}
+	for d := range disks.All() {
+		spec := d.TypedSpec()
+		if spec.CDROM || spec.Readonly || spec.Size == 0 {
+			continue
+		}
+		facts.Disks = append(facts.Disks, DiskFacts{
+			Name:       spec.DevPath,
+			Size:       spec.Size,
+			Model:      spec.Model,
+			Serial:     spec.Serial,
+			Transport:  spec.Transport,
+			Rotational: spec.Rotational,
+		})
+	}
+
+	links, err := safe.StateListAll[*network.LinkStatus](ctx, c.COSI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	for l := range links.All() {
+		spec := l.TypedSpec()
+		if !spec.Physical() {
+			continue
+		}
+		facts.NICs = append(facts.NICs, NICFacts{
+			Name:       l.Metadata().ID(),
+			MACAddress: spec.HardwareAddr.String(),
+			LinkUp:     spec.LinkState,
+			SpeedMbps:  int32(spec.SpeedMegabits),
+		})
+	}
+	return facts, nil
+}
@@ -0,0 +1,163 @@
+package talos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+)
+
+// etcdMaxAppliedIndexLag is how many raft entries a member may have committed but not applied yet
+// and still count as in sync. A member that was restarted lags far behind while it replays the log.
+const etcdMaxAppliedIndexLag = 100
+
+// HealthCheck is a single stage of the health checks a machine has to pass after it was installed or
+// upgraded, similar to the checks of `talosctl health`. Checks are run in order by RunHealthChecks.
+type HealthCheck interface {
+	// Name identifies the check in errors
+	Name() string
+	// Check returns an error describing why the machine is not healthy
+	Check(ctx context.Context) error
+}
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (h *healthCheck) Name() string {
+	return h.name
+}
+
+func (h *healthCheck) Check(ctx context.Context) error {
+	return h.check(ctx)
+}
+
+// NewHealthCheck returns a HealthCheck that runs the given function. It allows callers to add checks
+// that do not go through the Talos API, e.g. the readiness of the Kubernetes Node.
+func NewHealthCheck(name string, check func(ctx context.Context) error) HealthCheck {
+	return &healthCheck{name: name, check: check}
+}
+
+// RunHealthChecks runs the checks in order and returns the error of the first one that fails
+func RunHealthChecks(ctx context.Context, checks ...HealthCheck) error {
+	for _, check := range checks {
+		if err := check.Check(ctx); err != nil {
+			return fmt.Errorf("%s health check failed: %w", check.Name(), err)
+		}
+	}
+	return nil
+}
+
+// MachineHealthChecks returns the Talos API checks for a machine: its services are running and, on
+// control plane machines, etcd is healthy.
+func (tc *TalosClient) MachineHealthChecks(controlPlane bool) []HealthCheck {
+	if !controlPlane {
+		return []HealthCheck{tc.ServicesHealthCheck(APID_SERVICE_NAME, KUBELET_SERVICE_NAME)}
+	}
+	return []HealthCheck{
+		tc.ServicesHealthCheck(APID_SERVICE_NAME, KUBELET_SERVICE_NAME, ETCD_SERVICE_NAME),
+		tc.EtcdHealthCheck(),
+	}
+}
+
+// ServicesHealthCheck checks that the services are running and, if they report health, healthy
+func (tc *TalosClient) ServicesHealthCheck(services ...string) HealthCheck {
+	return NewHealthCheck("services", func(ctx context.Context) error {
+		for _, name := range services {
+			infos, err := tc.ServiceInfo(ctx, name)
+			if err != nil {
+				return fmt.Errorf("error getting service info for %s: %w", name, err)
+			}
+			if err := checkService(name, infos); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func checkService(name string, infos []client.ServiceInfo) error {
+	if len(infos) == 0 || infos[0].Service == nil {
+		return fmt.Errorf("service %s is not registered", name)
+	}
+	svc := infos[0].Service
+	if svc.State != SERVICE_STATUS_RUNNING {
+		return fmt.Errorf("service %s is %s", name, svc.State)
+	}
+	if health := svc.Health; health != nil && !health.Unknown && !health.Healthy {
+		return fmt.Errorf("service %s is not healthy: %s", name, health.LastMessage)
+	}
+	return nil
+}
+
+// EtcdHealthCheck checks that etcd has quorum and that the member of the machine is in sync with
+// the cluster. It fails while the member is still a learner or replays the raft log after a reboot,
+// or if an alarm is raised.
+func (tc *TalosClient) EtcdHealthCheck() HealthCheck {
+	return NewHealthCheck("etcd", func(ctx context.Context) error {
+		members, err := tc.EtcdMemberList(ctx, &machineapi.EtcdMemberListRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to list etcd members: %w", err)
+		}
+		status, err := tc.EtcdStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get etcd status: %w", err)
+		}
+		alarms, err := tc.EtcdAlarmList(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list etcd alarms: %w", err)
+		}
+		var memberList []*machineapi.EtcdMember
+		for _, msg := range members.GetMessages() {
+			memberList = append(memberList, msg.GetMembers()...)
+		}
+		var memberStatus *machineapi.EtcdMemberStatus
+		for _, msg := range status.GetMessages() {
+			memberStatus = msg.GetMemberStatus()
+		}
+		var memberAlarms []*machineapi.EtcdMemberAlarm
+		for _, msg := range alarms.GetMessages() {
+			memberAlarms = append(memberAlarms, msg.GetMemberAlarms()...)
+		}
+		return checkEtcd(memberList, memberStatus, memberAlarms)
+	})
+}
+
+func checkEtcd(members []*machineapi.EtcdMember, status *machineapi.EtcdMemberStatus, alarms []*machineapi.EtcdMemberAlarm) error {
+	if len(members) == 0 {
+		return fmt.Errorf("etcd has no members")
+	}
+	var learners []string
+	for _, member := range members {
+		if member.IsLearner {
+			learners = append(learners, member.Hostname)
+		}
+	}
+	if len(learners) > 0 {
+		return fmt.Errorf("etcd members %s are still learners", strings.Join(learners, ", "))
+	}
+	if status == nil {
+		return fmt.Errorf("etcd member reported no status")
+	}
+	if len(status.Errors) > 0 {
+		return fmt.Errorf("etcd member reports errors: %s", strings.Join(status.Errors, "; "))
+	}
+	if status.IsLearner {
+		return fmt.Errorf("etcd member is still a learner")
+	}
+	if status.Leader == 0 {
+		return fmt.Errorf("etcd has no leader, quorum of %d members is lost", len(members)/2+1)
+	}
+	if status.RaftIndex > status.RaftAppliedIndex+etcdMaxAppliedIndexLag {
+		return fmt.Errorf("etcd member is resyncing, applied index %d of %d", status.RaftAppliedIndex, status.RaftIndex)
+	}
+	for _, alarm := range alarms {
+		if alarm.Alarm != machineapi.EtcdMemberAlarm_NONE {
+			return fmt.Errorf("etcd member %x raised alarm %s", alarm.MemberId, alarm.Alarm)
+		}
+	}
+	return nil
+}
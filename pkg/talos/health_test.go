@@ -0,0 +1,154 @@
+package talos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+)
+
+func TestRunHealthChecks(t *testing.T) {
+	var ran []string
+	check := func(name string, err error) HealthCheck {
+		return NewHealthCheck(name, func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		})
+	}
+
+	if err := RunHealthChecks(context.Background(), check("services", nil), check("etcd", nil)); err != nil {
+		t.Fatalf("expected checks to pass, got %v", err)
+	}
+
+	ran = nil
+	err := RunHealthChecks(context.Background(), check("services", nil), check("etcd", errors.New("no leader")), check("node", nil))
+	if err == nil || err.Error() != "etcd health check failed: no leader" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Join(ran, ",") != "services,etcd" {
+		t.Errorf("expected checks to stop at the first failure, ran %v", ran)
+	}
+}
+
+func TestCheckService(t *testing.T) {
+	tests := []struct {
+		name    string
+		infos   []client.ServiceInfo
+		wantErr string
+	}{
+		{
+			name:    "not registered",
+			wantErr: "service etcd is not registered",
+		},
+		{
+			name:    "not running",
+			infos:   []client.ServiceInfo{{Service: &machineapi.ServiceInfo{State: "Preparing"}}},
+			wantErr: "service etcd is Preparing",
+		},
+		{
+			name: "unhealthy",
+			infos: []client.ServiceInfo{{Service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{LastMessage: "connection refused"},
+			}}},
+			wantErr: "service etcd is not healthy: connection refused",
+		},
+		{
+			name: "health unknown",
+			infos: []client.ServiceInfo{{Service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{Unknown: true},
+			}}},
+		},
+		{
+			name: "healthy",
+			infos: []client.ServiceInfo{{Service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{Healthy: true},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkService("etcd", tt.infos)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected service to be healthy, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckEtcd(t *testing.T) {
+	members := []*machineapi.EtcdMember{{Id: 1, Hostname: "cp-1"}, {Id: 2, Hostname: "cp-2"}, {Id: 3, Hostname: "cp-3"}}
+	healthy := func() *machineapi.EtcdMemberStatus {
+		return &machineapi.EtcdMemberStatus{MemberId: 2, Leader: 1, RaftIndex: 1000, RaftAppliedIndex: 1000}
+	}
+
+	tests := []struct {
+		name    string
+		members []*machineapi.EtcdMember
+		status  func(*machineapi.EtcdMemberStatus)
+		alarms  []*machineapi.EtcdMemberAlarm
+		wantErr string
+	}{
+		{name: "healthy", members: members},
+		{name: "small lag", members: members, status: func(s *machineapi.EtcdMemberStatus) { s.RaftAppliedIndex = 950 }},
+		{name: "no members", wantErr: "etcd has no members"},
+		{
+			name:    "learner",
+			members: []*machineapi.EtcdMember{{Hostname: "cp-1"}, {Hostname: "cp-2", IsLearner: true}},
+			wantErr: "etcd members cp-2 are still learners",
+		},
+		{
+			name:    "errors",
+			members: members,
+			status:  func(s *machineapi.EtcdMemberStatus) { s.Errors = []string{"timeout"} },
+			wantErr: "etcd member reports errors: timeout",
+		},
+		{
+			name:    "no leader",
+			members: members,
+			status:  func(s *machineapi.EtcdMemberStatus) { s.Leader = 0 },
+			wantErr: "etcd has no leader, quorum of 2 members is lost",
+		},
+		{
+			name:    "resyncing",
+			members: members,
+			status:  func(s *machineapi.EtcdMemberStatus) { s.RaftAppliedIndex = 200 },
+			wantErr: "etcd member is resyncing, applied index 200 of 1000",
+		},
+		{
+			name:    "alarm",
+			members: members,
+			alarms:  []*machineapi.EtcdMemberAlarm{{MemberId: 3, Alarm: machineapi.EtcdMemberAlarm_NOSPACE}},
+			wantErr: "etcd member 3 raised alarm NOSPACE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := healthy()
+			if tt.status != nil {
+				tt.status(status)
+			}
+			err := checkEtcd(tt.members, status, tt.alarms)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected etcd to be healthy, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
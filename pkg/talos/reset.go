@@ -0,0 +1,37 @@
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+// ResetPowerOff halts the machine after the reset instead of rebooting it
+const ResetPowerOff = "PowerOff"
+
+// NewResetRequest returns the reset request for the reset spec of a machine. Without a spec the
+// machine is reset forcefully, all of its system partitions are wiped and it is rebooted.
+func NewResetRequest(spec *talosv1alpha1.ResetSpec) *machineapi.ResetRequest {
+	req := &machineapi.ResetRequest{Reboot: true}
+	if spec == nil {
+		return req
+	}
+	req.Graceful = spec.Graceful
+	req.Reboot = spec.AfterReset != ResetPowerOff
+	for _, label := range spec.SystemPartitionsToWipe {
+		req.SystemPartitionsToWipe = append(req.SystemPartitionsToWipe, &machineapi.ResetPartitionSpec{Label: string(label), Wipe: true})
+	}
+	req.UserDisksToWipe = spec.UserDisksToWipe
+	return req
+}
+
+// ResetMachine resets the machine with the given request
+func (tc *TalosClient) ResetMachine(ctx context.Context, req *machineapi.ResetRequest) error {
+	if err := tc.ResetGeneric(ctx, req); err != nil {
+		return fmt.Errorf("failed to reset machine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package talos
+
+import (
+	"testing"
+
+	talosv1alpha1 "github.com/alperencelik/talos-operator/api/v1alpha1"
+)
+
+func TestNewResetRequest(t *testing.T) {
+	req := NewResetRequest(nil)
+	if req.Graceful || !req.Reboot || len(req.SystemPartitionsToWipe) != 0 {
+		t.Errorf("expected a forced reset with reboot wiping all partitions, got %v", req)
+	}
+
+	req = NewResetRequest(&talosv1alpha1.ResetSpec{
+		Graceful:               true,
+		SystemPartitionsToWipe: []talosv1alpha1.SystemPartitionLabel{"STATE", "EPHEMERAL"},
+		UserDisksToWipe:        []string{"/dev/sdb"},
+		AfterReset:             ResetPowerOff,
+	})
+	if !req.Graceful || req.Reboot {
+		t.Errorf("expected a graceful reset that powers the machine off, got %v", req)
+	}
+	if len(req.SystemPartitionsToWipe) != 2 || req.SystemPartitionsToWipe[1].Label != "EPHEMERAL" || !req.SystemPartitionsToWipe[1].Wipe {
+		t.Errorf("expected STATE and EPHEMERAL to be wiped, got %v", req.SystemPartitionsToWipe)
+	}
+	if len(req.UserDisksToWipe) != 1 || req.UserDisksToWipe[0] != "/dev/sdb" {
+		t.Errorf("expected /dev/sdb to be wiped, got %v", req.UserDisksToWipe)
+	}
+}
@@ -50,6 +50,10 @@ func removeMetadata(meta *metav1.ObjectMeta) {
 	}
 }
 
+// redactedStatusFields are the deprecated status fields that still hold the secrets bundle or the
+// machine configs on objects the operator has not migrated to Secrets yet. They are never sent to the UI.
+var redactedStatusFields = []string{"secretBundle", "config"}
+
 func main() {
 	log.SetLogger(zap.New(zap.UseDevMode(true)))
 
@@ -146,12 +150,16 @@ func getResources(w http.ResponseWriter, r *http.Request) {
 	}
 	for i := range controlPlanes.Items {
 		removeMetadata(&controlPlanes.Items[i].ObjectMeta)
+		controlPlanes.Items[i].Status.SecretBundle = ""
+		controlPlanes.Items[i].Status.Config = ""
 	}
 	for i := range workers.Items {
 		removeMetadata(&workers.Items[i].ObjectMeta)
+		workers.Items[i].Status.Config = ""
 	}
 	for i := range machines.Items {
 		removeMetadata(&machines.Items[i].ObjectMeta)
+		machines.Items[i].Status.Config = ""
 	}
 	for i := range addons.Items {
 		removeMetadata(&addons.Items[i].ObjectMeta)
@@ -304,6 +312,9 @@ func getResource(w http.ResponseWriter, r *http.Request, kind, namespace, name s
 		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
 		obj.SetAnnotations(annotations)
 	}
+	for _, field := range redactedStatusFields {
+		unstructured.RemoveNestedField(obj.Object, "status", field)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(obj.Object); err != nil {